@@ -3,6 +3,8 @@ package app
 import (
 	"context"
 
+	collectorcmd "github.com/networkmachinery/networkmachinery-operators/pkg/collector/cmd/app"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/controllers"
 	networkconnectivitycmd "github.com/networkmachinery/networkmachinery-operators/pkg/controllers/networkconnectivity/cmd/app"
 	networkcontrolcmd "github.com/networkmachinery/networkmachinery-operators/pkg/controllers/networkcontrol/cmd/app"
 	networktrafficshapercmd "github.com/networkmachinery/networkmachinery-operators/pkg/controllers/networktrafficshaper/cmd/app"
@@ -17,13 +19,16 @@ func NewHyperCommand(ctx context.Context) *cobra.Command {
 	cmd := &cobra.Command{
 		Use: "networkmachinery-hyper",
 	}
+	operatorOptions := controllers.NewOperatorOptions()
+	operatorOptions.AddFlags(cmd.PersistentFlags())
 
 	cmd.AddCommand(
 		versioncmd.NewVersionCmd(),
-		networkmonitorcmd.NewNetworkMonitorCmd(ctx),
-		networkcontrolcmd.NewNetworkContrlCmd(ctx),
-		networkconnectivitycmd.NewNetworkConnectivityTestCmd(ctx),
-		networktrafficshapercmd.NewNetworkTrafficShaperCmd(ctx),
+		networkmonitorcmd.NewNetworkMonitorCmd(ctx, operatorOptions),
+		networkcontrolcmd.NewNetworkContrlCmd(ctx, operatorOptions),
+		networkconnectivitycmd.NewNetworkConnectivityTestCmd(ctx, operatorOptions),
+		networktrafficshapercmd.NewNetworkTrafficShaperCmd(ctx, operatorOptions),
+		collectorcmd.NewSFlowCollectorCmd(ctx, operatorOptions.Collector),
 	)
 
 	return cmd
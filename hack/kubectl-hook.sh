@@ -60,6 +60,13 @@ createValidationWebhookConfig() {
   sed -e "s/\${CA_BUNDLE}/${caCert}/" "${BASH_DIR}"/templates/"${FILE}" | kubectl apply -f -
 }
 
+createMutationWebhookConfig() {
+  caCert=$(kubectl config view --raw -o go-template --template='{{ range .clusters }}{{ index .cluster "certificate-authority-data" }}{{ end }}')
+
+  FILE=mutation-webhook-config.tpl.yaml
+  sed -e "s/\${CA_BUNDLE}/${caCert}/" "${BASH_DIR}"/templates/"${FILE}" | kubectl apply -f -
+}
+
 createWebhookService() {
   FILE=validation-webhook-service.tpl.yaml
   cat "${BASH_DIR}"/templates/"${FILE}" | kubectl apply -f -
@@ -81,6 +88,7 @@ if [[ "${BASH_SOURCE[0]}" = "$0" ]]; then
   approveCSRAndFetchCert
   createSecret
   createValidationWebhookConfig
+  createMutationWebhookConfig
   createWebhookService
 fi
 
@@ -54,10 +54,16 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&NetworkMonitorList{},
 		&NetworkNotification{},
 		&NetworkNotificationList{},
+		&NetworkNotificationRoute{},
+		&NetworkNotificationRouteList{},
 		&NetworkConnectivityTest{},
 		&NetworkConnectivityTestList{},
 		&NetworkTrafficShaper{},
 		&NetworkTrafficShaperList{},
+		&NetworkExecPolicy{},
+		&NetworkExecPolicyList{},
+		&NetworkAuditRecord{},
+		&NetworkAuditRecordList{},
 		&PingStatus{},
 		&NetcatStatus{},
 	)
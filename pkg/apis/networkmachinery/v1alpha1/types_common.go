@@ -32,6 +32,22 @@ const (
 	EventTypeError string = "Error"
 )
 
+const (
+	// EventTypeCommandFailed an event reason to describe a command which failed inside a target pod.
+	EventTypeCommandFailed string = "CommandFailed"
+	// EventTypeTargetNotEligible an event reason to describe a pod which was skipped as exec target.
+	EventTypeTargetNotEligible string = "TargetNotEligible"
+	// EventTypeInvalidConfiguration an event reason to describe a spec which can not be applied.
+	EventTypeInvalidConfiguration string = "InvalidConfiguration"
+	// EventTypeDrift an event reason to describe applied configuration which was changed outside of the operators.
+	EventTypeDrift string = "Drift"
+	// EventTypeRolledBack an event reason to describe applied configuration which was undone when its time window
+	// ended or its target was no longer matched.
+	EventTypeRolledBack string = "RolledBack"
+	// EventTypeBlocked an event reason to describe configuration which is not applied because of a safety guard.
+	EventTypeBlocked string = "Blocked"
+)
+
 // LastOperationType is a string alias.
 type LastOperationType string
 
@@ -94,3 +110,27 @@ type Params struct {
 	IP        string `json:"ip"`
 	Port      string `json:"port,omitempty"`
 }
+
+// ExecStatus describes the outcome of a command executed inside a pod.
+type ExecStatus struct {
+	// ExitCode is the exit code of the command, -1 if the command could not be started.
+	ExitCode int32 `json:"exitCode"`
+	// Message is a short human readable description of the failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// Stdout is the truncated standard output of the command.
+	// +optional
+	Stdout string `json:"stdout,omitempty"`
+	// Stderr is the truncated standard error of the command.
+	// +optional
+	Stderr string `json:"stderr,omitempty"`
+	// Duration is the time it took to run the command.
+	// +optional
+	Duration string `json:"duration,omitempty"`
+	// Container is the container the command ran in.
+	// +optional
+	Container string `json:"container,omitempty"`
+	// Strategy is the strategy used to reach the container, one of exec, ephemeral-container.
+	// +optional
+	Strategy string `json:"strategy,omitempty"`
+}
@@ -41,4 +41,6 @@ type NetcatServiceEndpoint struct {
 
 type NetcatResult struct {
 	State NetcatResultState `json:"state"`
+	// Exec holds the details of the netcat command if it failed.
+	Exec *ExecStatus `json:"exec,omitempty"`
 }
@@ -0,0 +1,76 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NetworkAuditRecord records a command the operators executed inside a pod. Records are written by the
+// "record" audit sink and deleted once they expire.
+type NetworkAuditRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NetworkAuditRecordSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NetworkAuditRecordList is a list of network audit records
+type NetworkAuditRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []NetworkAuditRecord `json:"items,omitempty"`
+}
+
+// NetworkAuditRecordSpec is the audit record of a single execution, it is also the format of the records written
+// by the other audit sinks.
+type NetworkAuditRecordSpec struct {
+	// Timestamp is the time the execution started.
+	Timestamp metav1.Time `json:"timestamp"`
+	// ExpiresAt is the time after which the record is deleted, never if unset.
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+	// Origin is the object which triggered the execution.
+	Origin AuditOrigin `json:"origin"`
+	// Target is the pod and container the command was executed in.
+	Target AuditTarget `json:"target"`
+	// Command is the script passed to the shell of the target container.
+	Command string `json:"command"`
+	// Strategy is the strategy used to reach the target container, i.e. exec or ephemeral-container.
+	Strategy string `json:"strategy,omitempty"`
+	// Result is the outcome of the execution.
+	Result AuditResult `json:"result"`
+}
+
+// AuditOrigin identifies the object which triggered an execution.
+type AuditOrigin struct {
+	Kind string    `json:"kind,omitempty"`
+	Name string    `json:"name,omitempty"`
+	UID  types.UID `json:"uid,omitempty"`
+	// Tool is the category of the executed tool.
+	Tool ExecTool `json:"tool,omitempty"`
+	// Requester is the name of the user who requested the object, if known.
+	Requester string `json:"requester,omitempty"`
+}
+
+// AuditTarget is the pod and container a command was executed in.
+type AuditTarget struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container,omitempty"`
+}
+
+// AuditResult is the outcome of an execution.
+type AuditResult struct {
+	// ExitCode is the exit code of the command, -1 if it could not be started.
+	ExitCode int32 `json:"exitCode"`
+	// Duration is the time it took to run the command.
+	Duration string `json:"duration,omitempty"`
+	// Error describes why the execution failed, if it did.
+	Error string `json:"error,omitempty"`
+}
@@ -40,10 +40,14 @@ type NetworkConnectivityTestStatus struct {
 }
 
 type NetworkSourceEndpoint struct {
-	Name           string                `json:"name,omitempty"`
-	Namespace      string                `json:"namespace"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace"`
+	// Container is the container the tests are executed in, if empty the container named by the
+	// networkmachinery.io/container annotation or the first container providing the required tools is used.
 	Container      string                `json:"container"`
 	SourceSelector *metav1.LabelSelector `json:"sourceSelector,omitempty"`
+	// AllowHostNetwork allows using a source pod in the host network.
+	AllowHostNetwork bool `json:"allowHostNetwork,omitempty"`
 }
 
 type EndpointKind string
@@ -53,6 +57,8 @@ const (
 	Pod      EndpointKind = "pod"
 	Service  EndpointKind = "service"
 	Selector EndpointKind = "selector"
+	// Node targets a node, commands are executed in a privileged helper pod in the host network of the node.
+	Node EndpointKind = "node"
 )
 
 type NetworkDestinationEndpoint struct {
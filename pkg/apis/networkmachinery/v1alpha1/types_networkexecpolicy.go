@@ -0,0 +1,61 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NetworkExecPolicy restricts the pods the operators may execute network tools in. As long as no policy exists,
+// all pods may be targeted. Once a policy exists, a pod may only be targeted if an allow rule of any policy
+// matches it and no deny rule of any policy does.
+type NetworkExecPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NetworkExecPolicySpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NetworkExecPolicyList is a list of network exec policies
+type NetworkExecPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []NetworkExecPolicy `json:"items,omitempty"`
+}
+
+type NetworkExecPolicySpec struct {
+	// Allow lists the rules for pods which may be targeted.
+	Allow []ExecPolicyRule `json:"allow,omitempty"`
+	// Deny lists the rules for pods which must not be targeted, they take precedence over the allow rules.
+	Deny []ExecPolicyRule `json:"deny,omitempty"`
+	// RequireRequester denies executions for objects without a captured requesting user, e.g. objects created
+	// before the admission webhook was installed.
+	RequireRequester bool `json:"requireRequester,omitempty"`
+}
+
+// ExecTool is the category of tools executed inside a pod.
+type ExecTool string
+
+const (
+	// ExecToolProbe are read-only tools like ping or netcat.
+	ExecToolProbe ExecTool = "probe"
+	// ExecToolMutation are tools which change the network configuration of a pod like tc.
+	ExecToolMutation ExecTool = "mutation"
+)
+
+// ExecPolicyRule matches pods, all given fields have to match.
+type ExecPolicyRule struct {
+	// Namespaces are the names of the namespaces of the pods.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// NamespaceSelector selects the namespaces of the pods by their labels.
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// PodSelector selects the pods by their labels.
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+	// Tools are the tool categories the rule applies to, all categories if empty.
+	Tools []ExecTool `json:"tools,omitempty"`
+}
@@ -30,6 +30,26 @@ const (
 	EventTypeDeletion string = "NetworkMonitorDeletion"
 )
 
+// BackendType is the flow telemetry system the flows and thresholds of a NetworkMonitor are installed in.
+type BackendType string
+
+const (
+	// BackendTypeSFlowRT installs flows and thresholds in the sFlow-RT at the monitoring endpoint.
+	BackendTypeSFlowRT BackendType = "sflow-rt"
+	// BackendTypeEmbedded installs flows and thresholds in the collector embedded in the controller.
+	BackendTypeEmbedded BackendType = "embedded"
+)
+
+// ThresholdDirection is the bound a threshold puts on the value of its metric.
+type ThresholdDirection string
+
+const (
+	// ThresholdDirectionAbove raises events when the value exceeds the threshold.
+	ThresholdDirectionAbove ThresholdDirection = "above"
+	// ThresholdDirectionBelow raises events when the value falls below the threshold.
+	ThresholdDirectionBelow ThresholdDirection = "below"
+)
+
 // +genclient
 // +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -56,20 +76,43 @@ type NetworkMonitorList struct {
 
 // NetworkMonitorSpec defines the spec for the network monitor resource
 type NetworkMonitorSpec struct {
-	MonitoringEndpoint MonitoringEndpoint `json:"monitoringEndpoint"`
+	// Backend is the flow telemetry system the flows and thresholds are installed in, sflow-rt or embedded. It
+	// defaults to sflow-rt.
+	// +optional
+	Backend BackendType `json:"backend,omitempty"`
+	// MonitoringEndpoint is the endpoint of sFlow-RT, it is ignored by the embedded backend.
+	// +optional
+	MonitoringEndpoint MonitoringEndpoint `json:"monitoringEndpoint,omitempty"`
 	Flows              []Flow             `json:"flows"`
 	Thresholds         []Threshold        `json:"thresholds"`
 	EventsConfig       EventsConfig       `json:"eventsConfig"`
+	// Retention limits the NetworkNotifications kept for the NetworkMonitor.
+	// +optional
+	Retention NotificationRetention `json:"retention,omitempty"`
 }
 
 // NetworkMonitorSpec defines the spec for the network monitor resource
 type NetworkMonitorStatus struct {
 	Status `json:",inline"`
+	// InstalledFlows are the names of the flows installed in sFlow-RT, they are prefixed with the UID of the
+	// NetworkMonitor.
+	// +optional
+	InstalledFlows []string `json:"installedFlows,omitempty"`
+	// InstalledThresholds are the names of the thresholds installed in sFlow-RT, they are prefixed with the UID of the
+	// NetworkMonitor.
+	// +optional
+	InstalledThresholds []string `json:"installedThresholds,omitempty"`
+	// LastEventID is the ID of the most recent sFlow-RT event which was processed, only later events are queried.
+	// +optional
+	LastEventID int32 `json:"lastEventID,omitempty"`
 }
 
 type MonitoringEndpoint struct {
 	IP   string `json:"ip"`
 	Port string `json:"port"`
+	// Scheme is the scheme of the sFlow-RT REST API, http or https, it defaults to http.
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
 }
 
 //TODO: Replace string for native non-string types
@@ -83,6 +126,14 @@ type Flow struct {
 	ActiveTimeout string `json:"activeTimeout,omitempty"`
 	Log           string `json:"log,omitempty"`
 	FlowStart     string `json:"flowStart,omitempty"`
+	// Export exposes the values of the largest keys of the flow as Prometheus gauges on the metrics endpoint of the
+	// controller. It is not installed in sFlow-RT.
+	// +optional
+	Export bool `json:"export,omitempty"`
+	// MaxExportedKeys is the number of keys with the largest values which are exported, it defaults to
+	// --networkmonitor-export-max-keys.
+	// +optional
+	MaxExportedKeys int32 `json:"maxExportedKeys,omitempty"`
 }
 
 // Threshold is the threshold to define for the flows
@@ -92,6 +143,36 @@ type Threshold struct {
 	Metric   string `json:"metric,omitempty"`
 	ByFlow   string `json:"byFlow,omitempty"`
 	FlowName string `json:"flowName"`
+	// Severity is the severity of the NetworkNotifications of the threshold, info, warning or critical, it defaults to
+	// warning.
+	// +optional
+	Severity string `json:"severity,omitempty"`
+	// Direction is above if events are raised when the value exceeds the threshold and below if they are raised when
+	// it falls below it, it defaults to above. Lower bounds are evaluated by the controller, they are only supported
+	// for the metric of a flow of the NetworkMonitor which is not by flow.
+	// +optional
+	Direction ThresholdDirection `json:"direction,omitempty"`
+	// Hysteresis is the distance the value has to move back from the threshold before its NetworkNotifications are
+	// resolved, it defaults to 0.
+	// +optional
+	Hysteresis int32 `json:"hysteresis,omitempty"`
+	// Timeout is the number of seconds the value has to stay clear of the threshold before its NetworkNotifications
+	// are resolved. NetworkNotifications of metrics which are not flows of the NetworkMonitor are resolved when no
+	// event occurred for the timeout, they are never resolved if it is not set.
+	// +optional
+	Timeout int32 `json:"timeout,omitempty"`
+}
+
+// NotificationRetention limits the NetworkNotifications kept for a NetworkMonitor, the least recently seen ones are
+// deleted first.
+type NotificationRetention struct {
+	// MaxNotifications is the maximum number of NetworkNotifications kept, it defaults to 100.
+	// +optional
+	MaxNotifications int32 `json:"maxNotifications,omitempty"`
+	// TTL deletes NetworkNotifications which were last seen longer ago, they are kept regardless of their age if it is
+	// not set.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
 }
 
 // EventsConfig contains configuration parameters for event queries
@@ -5,7 +5,6 @@ import (
 )
 
 // +genclient
-// +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // NetworkNotification represents the notification resource
@@ -13,7 +12,8 @@ type NetworkNotification struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec NetworkNotificationSpec `json:"spec,omitempty"`
+	Spec   NetworkNotificationSpec   `json:"spec,omitempty"`
+	Status NetworkNotificationStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -26,13 +26,103 @@ type NetworkNotificationList struct {
 	Items []NetworkNotification `json:"items,omitempty"`
 }
 
+// NotificationState is the state of the threshold a NetworkNotification was raised for.
+type NotificationState string
+
+const (
+	// NotificationStateFiring indicates that the value of the flow key violates the threshold.
+	NotificationStateFiring NotificationState = "Firing"
+	// NotificationStateResolved indicates that the value of the flow key cleared the threshold.
+	NotificationStateResolved NotificationState = "Resolved"
+)
+
 type NetworkNotificationSpec struct {
+	// Event is the most recent occurrence of the event.
 	Event NetworkEvent `json:"networkEvent"`
+	// Severity is the severity of the threshold which was exceeded.
+	// +optional
+	Severity string `json:"severity,omitempty"`
+	// Occurrences is the number of times the threshold was exceeded by the flow key.
+	// +optional
+	Occurrences int32 `json:"occurrences,omitempty"`
+	// FirstSeen is the time of the first occurrence of the event.
+	// +optional
+	FirstSeen *metav1.Time `json:"firstSeen,omitempty"`
+	// LastSeen is the time of the most recent occurrence of the event.
+	// +optional
+	LastSeen *metav1.Time `json:"lastSeen,omitempty"`
+	// State is Firing while the threshold is violated and Resolved once the value cleared it, a new occurrence fires
+	// the NetworkNotification again. It defaults to Firing.
+	// +optional
+	State NotificationState `json:"state,omitempty"`
+	// ClearSince is the time since which the value has been clear of the threshold while it is firing.
+	// +optional
+	ClearSince *metav1.Time `json:"clearSince,omitempty"`
+	// ResolvedAt is the time the NetworkNotification was resolved.
+	// +optional
+	ResolvedAt *metav1.Time `json:"resolvedAt,omitempty"`
+}
+
+// NetworkNotificationStatus describes the deliveries of a NetworkNotification.
+type NetworkNotificationStatus struct {
+	// Deliveries are the deliveries to the receivers of the matching NetworkNotificationRoutes.
+	// +optional
+	Deliveries []NotificationDelivery `json:"deliveries,omitempty"`
+}
+
+// NotificationDelivery is the delivery of a NetworkNotification to a receiver of a route.
+type NotificationDelivery struct {
+	// Route is the name of the NetworkNotificationRoute.
+	Route string `json:"route"`
+	// Receiver is the name of the receiver of the route.
+	Receiver string `json:"receiver"`
+	// State is the state of the delivery, one of Delivered, Pending or Failed.
+	State DeliveryState `json:"state"`
+	// Occurrences is the number of occurrences of the NetworkNotification which is delivered.
+	Occurrences int32 `json:"occurrences"`
+	// Resolved is true if the resolution of the NetworkNotification is delivered.
+	// +optional
+	Resolved bool `json:"resolved,omitempty"`
+	// Attempts is the number of failed attempts to deliver the occurrences.
+	// +optional
+	Attempts int32 `json:"attempts,omitempty"`
+	// LastAttemptTime is the time of the most recent attempt.
+	// +optional
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
+	// LastError is the error of the most recent attempt if it failed.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
 }
 
 type NetworkEvent struct {
 	Flow  Flow  `json:"flow,omitempty"`
 	Event Event `json:"event"`
+	// Source is the Kubernetes identity of the source address of the flow key.
+	// +optional
+	Source *Endpoint `json:"source,omitempty"`
+	// Destination is the Kubernetes identity of the destination address of the flow key.
+	// +optional
+	Destination *Endpoint `json:"destination,omitempty"`
+	// Agent is the Kubernetes identity of the agent which reported the event.
+	// +optional
+	Agent *Endpoint `json:"agent,omitempty"`
+}
+
+// Endpoint is the pod, service or node an address belongs to.
+type Endpoint struct {
+	IP string `json:"ip"`
+	// Kind is the kind of the object, Pod, Service or Node.
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Workload is the controller of a pod, e.g. Deployment/frontend.
+	// +optional
+	Workload string `json:"workload,omitempty"`
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Node is the node of a pod or the name of a node.
+	// +optional
+	Node string `json:"node,omitempty"`
 }
 
 type Event struct {
@@ -45,4 +135,7 @@ type Event struct {
 	ThresholdID string  `json:"thresholdID"`
 	Agent       string  `json:"agent"`
 	DataSource  string  `json:"dataSource"`
+	// FlowKey is the key of the flow which exceeded a threshold by flow.
+	// +optional
+	FlowKey string `json:"flowKey,omitempty"`
 }
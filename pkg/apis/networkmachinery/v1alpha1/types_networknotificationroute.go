@@ -0,0 +1,114 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReceiverType is the kind of endpoint a NotificationReceiver delivers to.
+type ReceiverType string
+
+const (
+	// ReceiverTypeWebhook posts the NetworkNotification and the message as JSON.
+	ReceiverTypeWebhook ReceiverType = "Webhook"
+	// ReceiverTypeSlack posts the message to a Slack-compatible incoming webhook.
+	ReceiverTypeSlack ReceiverType = "Slack"
+	// ReceiverTypeAlertmanager posts an alert to the v2 API of an Alertmanager.
+	ReceiverTypeAlertmanager ReceiverType = "Alertmanager"
+)
+
+// DeliveryState is the state of the delivery of a NetworkNotification to a receiver.
+type DeliveryState string
+
+const (
+	// DeliveryStateDelivered indicates that the receiver accepted the most recent occurrences.
+	DeliveryStateDelivered DeliveryState = "Delivered"
+	// DeliveryStatePending indicates that the delivery failed or was rate limited and is retried.
+	DeliveryStatePending DeliveryState = "Pending"
+	// DeliveryStateFailed indicates that all attempts failed, the delivery is retried with the next occurrence.
+	DeliveryStateFailed DeliveryState = "Failed"
+)
+
+const (
+	// EventTypeDeliveryFailed an event reason to describe a notification which could not be delivered.
+	EventTypeDeliveryFailed string = "DeliveryFailed"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NetworkNotificationRoute delivers the NetworkNotifications of its namespace to receivers.
+type NetworkNotificationRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec NetworkNotificationRouteSpec `json:"spec,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NetworkNotificationRouteList is a list of network notification routes
+type NetworkNotificationRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []NetworkNotificationRoute `json:"items,omitempty"`
+}
+
+// NetworkNotificationRouteSpec defines the notifications of a route and where they are delivered to.
+type NetworkNotificationRouteSpec struct {
+	// Match selects the NetworkNotifications of the route, all NetworkNotifications of the namespace match if it is
+	// empty.
+	// +optional
+	Match NotificationMatch `json:"match,omitempty"`
+	// Receivers are the endpoints the NetworkNotifications are delivered to.
+	Receivers []NotificationReceiver `json:"receivers"`
+	// Template is a Go template of the message, it is executed with the NetworkNotification.
+	// +optional
+	Template string `json:"template,omitempty"`
+	// RateLimit limits the deliveries of the route to each receiver, deliveries above the limit are delayed.
+	// +optional
+	RateLimit *NotificationRateLimit `json:"rateLimit,omitempty"`
+}
+
+// NotificationMatch selects NetworkNotifications, all conditions which are set have to match.
+type NotificationMatch struct {
+	// NetworkMonitor is the name of the NetworkMonitor which created the NetworkNotifications.
+	// +optional
+	NetworkMonitor string `json:"networkMonitor,omitempty"`
+	// Threshold is the name of the threshold which was exceeded.
+	// +optional
+	Threshold string `json:"threshold,omitempty"`
+	// Severity is the severity of the threshold which was exceeded.
+	// +optional
+	Severity string `json:"severity,omitempty"`
+	// Selector selects NetworkNotifications by their labels, they carry the labels of their NetworkMonitor.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// NotificationReceiver is an endpoint NetworkNotifications are delivered to.
+type NotificationReceiver struct {
+	// Name identifies the receiver in the delivery status of NetworkNotifications.
+	Name string `json:"name"`
+	// Type is the kind of the endpoint, one of Webhook, Slack or Alertmanager.
+	Type ReceiverType `json:"type"`
+	// URL is the URL of the endpoint, the base URL for an Alertmanager.
+	// +optional
+	URL string `json:"url,omitempty"`
+	// URLSecretRef refers to a key of a secret in the namespace of the route holding the URL, e.g. of a Slack
+	// webhook. It takes precedence over the URL.
+	// +optional
+	URLSecretRef *corev1.SecretKeySelector `json:"urlSecretRef,omitempty"`
+	// Headers are added to the requests to the endpoint.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// NotificationRateLimit allows a number of deliveries per period.
+type NotificationRateLimit struct {
+	// Deliveries is the number of deliveries allowed per period.
+	Deliveries int32 `json:"deliveries"`
+	// Period is the period the deliveries are allowed in.
+	Period metav1.Duration `json:"period"`
+}
@@ -29,30 +29,392 @@ type NetworkTrafficShaperList struct {
 
 type NetworkTrafficShaperSpec struct {
 	Targets []ShaperTarget `json:"targets"`
+	// Duration bounds the time the traffic is shaped, the shaping is undone afterwards. Without Schedule, the
+	// window starts when the traffic is shaped for the first time.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// Schedule is a cron expression in UTC, e.g. "0 9 * * mon-fri", starting a window of Duration, which is
+	// required then.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+	// PauseUntil suspends the shaping until the given time, it is undone while paused. A window of Duration
+	// keeps running while it is paused.
+	// +optional
+	PauseUntil *metav1.Time `json:"pauseUntil,omitempty"`
 }
 
+// ShaperTarget are the pods or nodes whose traffic is shaped. Nodes are shaped by executing tc in a privileged
+// helper pod in their host network, the device is a device of the node then, e.g. "eth0" or "vxlan.calico".
 type ShaperTarget struct {
-	Namespace      string                `json:"namespace"`
-	Kind           EndpointKind          `json:"kind"`
-	ShaperConfig   ShaperConfiguration   `json:"configuration"`
-	Name           string                `json:"name,omitempty"`
-	Container      string                `json:"container,omitempty"`
+	// Namespace is the namespace of the pods, it is not used for nodes.
+	// +optional
+	Namespace    string              `json:"namespace,omitempty"`
+	Kind         EndpointKind        `json:"kind"`
+	ShaperConfig ShaperConfiguration `json:"configuration"`
+	// Name is the name of the pod or node.
+	Name string `json:"name,omitempty"`
+	// Container is the container tc is executed in, if empty the container named by the
+	// networkmachinery.io/container annotation or the first container providing tc is used.
+	Container string `json:"container,omitempty"`
+	// SourceSelector selects the pods, or the nodes by their labels for the node kind.
 	SourceSelector *metav1.LabelSelector `json:"targetSelector,omitempty"`
+	// AllowHostNetwork allows shaping pods in the host network, which shapes the traffic of the whole node.
+	AllowHostNetwork bool `json:"allowHostNetwork,omitempty"`
+	// Mode selects which of the pods matched by the TargetSelector are shaped, it defaults to all.
+	// +optional
+	Mode SelectionMode `json:"mode,omitempty"`
+	// Value is the number of pods shaped in the fixed mode, or the percentage of the matched pods, e.g. "30%",
+	// in the percent mode.
+	// +optional
+	Value string `json:"value,omitempty"`
 }
 
+// SelectionMode selects a subset of the pods matched by a target.
+type SelectionMode string
+
+const (
+	// SelectionModeAll selects all matched pods.
+	SelectionModeAll SelectionMode = "all"
+	// SelectionModeOne selects a single pod.
+	SelectionModeOne SelectionMode = "one"
+	// SelectionModeFixed selects the number of pods given as value.
+	SelectionModeFixed SelectionMode = "fixed"
+	// SelectionModePercent selects the percentage of the matched pods given as value, rounded up.
+	SelectionModePercent SelectionMode = "percent"
+)
+
 type ShaperType string
 
 const (
 	Loss  ShaperType = "loss"
 	Delay ShaperType = "delay"
+	// Bandwidth limits the egress bandwidth of the device to the rate given as value, e.g. "10mbit".
+	Bandwidth ShaperType = "bandwidth"
 )
 
 type ShaperConfiguration struct {
-	Type   ShaperType `json:"type"`
-	Device string     `json:"device"`
-	Value  string     `json:"value"`
+	// Type and Value configure a single delay, loss or bandwidth impairment, e.g. "delay" and "200ms". Use Netem
+	// and Bandwidth to configure and combine all impairments.
+	// +optional
+	Type ShaperType `json:"type,omitempty"`
+	// Device is the network interface the traffic is shaped on, e.g. eth0.
+	Device string `json:"device"`
+	// +optional
+	Value string `json:"value,omitempty"`
+	// Netem configures the netem impairments applied to the device, it can not be combined with Type and Value.
+	// +optional
+	Netem *NetemConfiguration `json:"netem,omitempty"`
+	// Bandwidth limits the bandwidth of the device, it can be combined with Netem.
+	// +optional
+	Bandwidth *BandwidthConfiguration `json:"bandwidth,omitempty"`
+	// Match restricts the shaping to the traffic exchanged with certain peers, all traffic of the device is
+	// shaped if it is not given.
+	// +optional
+	Match *TrafficMatch `json:"match,omitempty"`
+}
+
+// MatchProtocol is the transport protocol of matched traffic.
+type MatchProtocol string
+
+const (
+	ProtocolTCP  MatchProtocol = "tcp"
+	ProtocolUDP  MatchProtocol = "udp"
+	ProtocolICMP MatchProtocol = "icmp"
+)
+
+// TrafficMatch selects the shaped traffic. Traffic matches if it is sent to (or, for ingress shaping, received
+// from) one of the destinations or CIDRs, one of the ports and the protocol. Criteria which are not given match
+// all traffic, but at least one has to be given.
+type TrafficMatch struct {
+	// Destinations are pods, services or pods selected by labels, they are resolved to their IPs. Services are
+	// resolved to their cluster IP and the IPs of their endpoints.
+	// +optional
+	Destinations []ShaperDestination `json:"destinations,omitempty"`
+	// CIDRs are networks or single IPs, e.g. "10.0.0.0/8" or "fd00::1".
+	// +optional
+	CIDRs []string `json:"cidrs,omitempty"`
+	// Ports are the ports of the peers. They require tcp or udp, both are matched if Protocol is empty.
+	// +optional
+	Ports []int32 `json:"ports,omitempty"`
+	// +optional
+	Protocol MatchProtocol `json:"protocol,omitempty"`
+}
+
+// ShaperDestination is a peer whose traffic is shaped, a pod or service given by name or pods selected by labels.
+type ShaperDestination struct {
+	Kind      EndpointKind `json:"kind"`
+	Namespace string       `json:"namespace"`
+	// +optional
+	Name string `json:"name,omitempty"`
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// NetemConfiguration configures the netem qdisc, all given impairments are combined. Percentages are given as
+// strings like "0.5%", times as durations like "100ms".
+type NetemConfiguration struct {
+	// Delay delays all outgoing packets.
+	// +optional
+	Delay *NetemDelay `json:"delay,omitempty"`
+	// Loss drops packets, either randomly or following the Gilbert-Elliott model.
+	// +optional
+	Loss *NetemLoss `json:"loss,omitempty"`
+	// Duplicate duplicates packets.
+	// +optional
+	Duplicate *NetemProbability `json:"duplicate,omitempty"`
+	// Corrupt introduces single bit errors into packets.
+	// +optional
+	Corrupt *NetemProbability `json:"corrupt,omitempty"`
+	// Reorder sends packets immediately instead of delaying them, it requires Delay.
+	// +optional
+	Reorder *NetemReorder `json:"reorder,omitempty"`
+	// Rate limits the outgoing bandwidth.
+	// +optional
+	Rate *NetemRate `json:"rate,omitempty"`
+	// Slot delivers packets in bursts, simulating slotted media like WiFi.
+	// +optional
+	Slot *NetemSlot `json:"slot,omitempty"`
+}
+
+// NetemDistribution is the distribution of the jitter of a delay.
+type NetemDistribution string
+
+const (
+	DistributionUniform      NetemDistribution = "uniform"
+	DistributionNormal       NetemDistribution = "normal"
+	DistributionPareto       NetemDistribution = "pareto"
+	DistributionParetoNormal NetemDistribution = "paretonormal"
+)
+
+// NetemDelay delays packets by Time ± Jitter.
+type NetemDelay struct {
+	Time metav1.Duration `json:"time"`
+	// +optional
+	Jitter *metav1.Duration `json:"jitter,omitempty"`
+	// Correlation is the correlation of the jitter of consecutive packets, it requires Jitter.
+	// +optional
+	Correlation string `json:"correlation,omitempty"`
+	// Distribution is the distribution of the jitter, it requires Jitter.
+	// +optional
+	Distribution NetemDistribution `json:"distribution,omitempty"`
+}
+
+// NetemProbability applies an impairment to the given percentage of packets.
+type NetemProbability struct {
+	Percentage string `json:"percentage"`
+	// Correlation is the correlation with the previous packet.
+	// +optional
+	Correlation string `json:"correlation,omitempty"`
+}
+
+// NetemLoss drops packets. Exactly one of Percentage and GilbertElliott has to be set.
+type NetemLoss struct {
+	// Percentage is the percentage of randomly dropped packets.
+	// +optional
+	Percentage string `json:"percentage,omitempty"`
+	// Correlation is the correlation with the previous packet, it requires Percentage.
+	// +optional
+	Correlation string `json:"correlation,omitempty"`
+	// GilbertElliott drops packets in bursts following the Gilbert-Elliott model.
+	// +optional
+	GilbertElliott *GilbertElliottLoss `json:"gilbertElliott,omitempty"`
+}
+
+// GilbertElliottLoss is a two state loss model with a good and a bad state.
+type GilbertElliottLoss struct {
+	// P is the probability to move from the good to the bad state.
+	P string `json:"p"`
+	// R is the probability to move from the bad to the good state, 100% - P if empty.
+	// +optional
+	R string `json:"r,omitempty"`
+	// OneMinusH is the loss probability in the bad state, 100% if empty.
+	// +optional
+	OneMinusH string `json:"oneMinusH,omitempty"`
+	// OneMinusK is the loss probability in the good state, 0% if empty.
+	// +optional
+	OneMinusK string `json:"oneMinusK,omitempty"`
+}
+
+// NetemReorder sends the given percentage of packets immediately, the others are delayed.
+type NetemReorder struct {
+	Percentage string `json:"percentage"`
+	// +optional
+	Correlation string `json:"correlation,omitempty"`
+	// Gap reorders every Gap-th packet instead of random packets.
+	// +optional
+	Gap int32 `json:"gap,omitempty"`
+}
+
+// NetemRate limits the bandwidth, optionally accounting for link layer overhead.
+type NetemRate struct {
+	// Rate is the bandwidth in tc units, e.g. "10mbit" or "1gbit".
+	Rate string `json:"rate"`
+	// PacketOverhead is added to (or subtracted from) the size of each packet.
+	// +optional
+	PacketOverhead int32 `json:"packetOverhead,omitempty"`
+	// CellSize rounds packets up to a multiple of the cell size, e.g. 53 for ATM.
+	// +optional
+	CellSize int32 `json:"cellSize,omitempty"`
+	// CellOverhead is added to each cell.
+	// +optional
+	CellOverhead int32 `json:"cellOverhead,omitempty"`
+}
+
+// NetemSlot holds packets back and releases them in slots, each slot starts MinDelay to MaxDelay after the
+// previous one.
+type NetemSlot struct {
+	MinDelay metav1.Duration `json:"minDelay"`
+	// +optional
+	MaxDelay *metav1.Duration `json:"maxDelay,omitempty"`
+	// Packets is the maximum number of packets released per slot.
+	// +optional
+	Packets int32 `json:"packets,omitempty"`
+	// Bytes is the maximum number of bytes released per slot.
+	// +optional
+	Bytes int32 `json:"bytes,omitempty"`
+}
+
+// BandwidthAlgorithm is the qdisc limiting the bandwidth.
+type BandwidthAlgorithm string
+
+const (
+	// BandwidthTBF limits the bandwidth with a token bucket filter.
+	BandwidthTBF BandwidthAlgorithm = "tbf"
+	// BandwidthHTB limits the bandwidth with a single hierarchical token bucket class.
+	BandwidthHTB BandwidthAlgorithm = "htb"
+)
+
+// BandwidthConfiguration limits the bandwidth of a device. Rates are given in tc units like "10mbit", sizes in
+// bytes or tc units like "32kb".
+type BandwidthConfiguration struct {
+	// Algorithm is either tbf or htb, it defaults to tbf.
+	// +optional
+	Algorithm BandwidthAlgorithm `json:"algorithm,omitempty"`
+	// Rate is the guaranteed rate.
+	Rate string `json:"rate"`
+	// Ceil is the rate htb may borrow up to, it defaults to Rate.
+	// +optional
+	Ceil string `json:"ceil,omitempty"`
+	// Burst is the size of the bucket. For tbf it defaults to 10ms at Rate, but at least 1600 bytes, for htb
+	// tc calculates the minimum burst.
+	// +optional
+	Burst string `json:"burst,omitempty"`
+	// Latency is the maximum time a packet waits in the tbf queue. Only one of Latency and Limit can be given,
+	// the latency defaults to 50ms.
+	// +optional
+	Latency *metav1.Duration `json:"latency,omitempty"`
+	// Limit is the size of the tbf queue.
+	// +optional
+	Limit string `json:"limit,omitempty"`
+	// Ingress limits the traffic received by the device instead of the sent traffic. It is redirected to an IFB
+	// device and shaped there, Netem impairments are applied to the received traffic as well.
+	// +optional
+	Ingress bool `json:"ingress,omitempty"`
+	// IFBDevice is the name of the IFB device created for ingress shaping, it defaults to "ifb-<device>".
+	// +optional
+	IFBDevice string `json:"ifbDevice,omitempty"`
 }
 
+// ShaperPhase is the phase of the shaping window of a NetworkTrafficShaper.
+type ShaperPhase string
+
+const (
+	// ShaperPhaseActive is the phase in which the traffic is shaped.
+	ShaperPhaseActive ShaperPhase = "Active"
+	// ShaperPhaseWaiting is the phase between the windows of a schedule.
+	ShaperPhaseWaiting ShaperPhase = "Waiting"
+	// ShaperPhasePaused is the phase until PauseUntil.
+	ShaperPhasePaused ShaperPhase = "Paused"
+	// ShaperPhaseCompleted is the phase after the window of a Duration without Schedule ended.
+	ShaperPhaseCompleted ShaperPhase = "Completed"
+	// ShaperPhaseBlocked is the phase in which a safety guard prevents the shaping, e.g. the kill switch or a pod
+	// limit.
+	ShaperPhaseBlocked ShaperPhase = "Blocked"
+)
+
 type NetworkTrafficShaperStatus struct {
 	Status `json:",inline"`
+	// Phase is the phase of the shaping window. It is persisted before the traffic is shaped, the shaping is
+	// rolled back if the window ended while the controller was not running.
+	// +optional
+	Phase ShaperPhase `json:"phase,omitempty"`
+	// BlockedReason is the safety guard which prevents the shaping in the Blocked phase.
+	// +optional
+	BlockedReason string `json:"blockedReason,omitempty"`
+	// StartTime is the start of the current or last window.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// EndTime is the end of the current or last window, it is not set if the shaping is not time-bounded.
+	// +optional
+	EndTime *metav1.Time `json:"endTime,omitempty"`
+	// NextStartTime is the start of the next window of the Schedule.
+	// +optional
+	NextStartTime *metav1.Time `json:"nextStartTime,omitempty"`
+	// Targets are the outcomes of the last reconciliation of the targets.
+	// +optional
+	Targets []ShaperTargetStatus `json:"targets,omitempty"`
+	// Selections are the pods selected from the matched pods of targets whose mode is not all.
+	// +optional
+	Selections []TargetSelection `json:"selections,omitempty"`
+	// Pods are the pods currently shaped and the configuration applied to them. Pods which are no longer
+	// targeted are unshaped and removed.
+	// +optional
+	Pods []ShapedPod `json:"pods,omitempty"`
+}
+
+// ShaperTargetStatus is the outcome of the last reconciliation of a target.
+type ShaperTargetStatus struct {
+	// Target is the index of the target in the spec.
+	Target int `json:"target"`
+	// Shaped is the number of pods shaped for the target.
+	Shaped int `json:"shaped"`
+	// Skipped is the number of pods of the target which are not eligible or missing.
+	// +optional
+	Skipped int `json:"skipped,omitempty"`
+	// LastError is the error which prevented the shaping of the target or the reason a pod was skipped.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// TargetSelection are the pods selected from the pods matched by a target whose mode is not all.
+type TargetSelection struct {
+	// Target is the index of the target in the spec.
+	Target int `json:"target"`
+	// Pods are the names of the selected pods, they stay selected as long as they are matched.
+	Pods []string `json:"pods"`
+}
+
+// ShapedPod is the traffic shaping configuration applied to a device of a pod.
+type ShapedPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Container is the container tc was executed in.
+	Container string `json:"container,omitempty"`
+	Device    string `json:"device"`
+	// Qdisc is the root qdisc applied to the device in tc syntax, e.g. "netem delay 200ms".
+	Qdisc string `json:"qdisc"`
+	// AppliedAt is the last time the qdisc was applied, i.e. when it was first applied or a drift was corrected.
+	AppliedAt metav1.Time `json:"appliedAt"`
+	// Node is the shaped node if the pod is a node helper.
+	// +optional
+	Node string `json:"node,omitempty"`
+	// IFBDevice is the IFB device the received traffic is redirected to and shaped on, it is removed together
+	// with the shaping.
+	// +optional
+	IFBDevice string `json:"ifbDevice,omitempty"`
+	// Bandwidth is the bandwidth limit as reported by tc, it may differ slightly from the configured one as
+	// the kernel rounds rates and bursts.
+	// +optional
+	Bandwidth *ShapedBandwidth `json:"bandwidth,omitempty"`
+}
+
+// ShapedBandwidth is a bandwidth limit installed on a device.
+type ShapedBandwidth struct {
+	Algorithm BandwidthAlgorithm `json:"algorithm"`
+	// Device is the device the limit is installed on, the IFB device if ingress traffic is shaped.
+	Device string `json:"device"`
+	Rate   string `json:"rate"`
+	// +optional
+	Ceil  string `json:"ceil,omitempty"`
+	Burst string `json:"burst"`
 }
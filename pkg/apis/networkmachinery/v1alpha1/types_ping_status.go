@@ -42,4 +42,6 @@ type PingResult struct {
 	Min     string          `json:"min,omitempty"`
 	Max     string          `json:"max,omitempty"`
 	Average string          `json:"average,omitempty"`
+	// Exec holds the details of the ping command if it failed.
+	Exec *ExecStatus `json:"exec,omitempty"`
 }
@@ -23,10 +23,103 @@ under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditOrigin) DeepCopyInto(out *AuditOrigin) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditOrigin.
+func (in *AuditOrigin) DeepCopy() *AuditOrigin {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditOrigin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditResult) DeepCopyInto(out *AuditResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditResult.
+func (in *AuditResult) DeepCopy() *AuditResult {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditTarget) DeepCopyInto(out *AuditTarget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditTarget.
+func (in *AuditTarget) DeepCopy() *AuditTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthConfiguration) DeepCopyInto(out *BandwidthConfiguration) {
+	*out = *in
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthConfiguration.
+func (in *BandwidthConfiguration) DeepCopy() *BandwidthConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Endpoint) DeepCopyInto(out *Endpoint) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Endpoint.
+func (in *Endpoint) DeepCopy() *Endpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(Endpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Event) DeepCopyInto(out *Event) {
 	*out = *in
@@ -59,6 +152,58 @@ func (in *EventsConfig) DeepCopy() *EventsConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecPolicyRule) DeepCopyInto(out *ExecPolicyRule) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tools != nil {
+		in, out := &in.Tools, &out.Tools
+		*out = make([]ExecTool, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecPolicyRule.
+func (in *ExecPolicyRule) DeepCopy() *ExecPolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecPolicyRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExecStatus) DeepCopyInto(out *ExecStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExecStatus.
+func (in *ExecStatus) DeepCopy() *ExecStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExecStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Flow) DeepCopyInto(out *Flow) {
 	*out = *in
@@ -75,6 +220,22 @@ func (in *Flow) DeepCopy() *Flow {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GilbertElliottLoss) DeepCopyInto(out *GilbertElliottLoss) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GilbertElliottLoss.
+func (in *GilbertElliottLoss) DeepCopy() *GilbertElliottLoss {
+	if in == nil {
+		return nil
+	}
+	out := new(GilbertElliottLoss)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LastError) DeepCopyInto(out *LastError) {
 	*out = *in
@@ -127,7 +288,7 @@ func (in *MonitoringEndpoint) DeepCopy() *MonitoringEndpoint {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetcatIPEndpoint) DeepCopyInto(out *NetcatIPEndpoint) {
 	*out = *in
-	out.NetcatResult = in.NetcatResult
+	in.NetcatResult.DeepCopyInto(&out.NetcatResult)
 	return
 }
 
@@ -145,7 +306,7 @@ func (in *NetcatIPEndpoint) DeepCopy() *NetcatIPEndpoint {
 func (in *NetcatPodEndpoint) DeepCopyInto(out *NetcatPodEndpoint) {
 	*out = *in
 	out.PodParams = in.PodParams
-	out.NetcatResult = in.NetcatResult
+	in.NetcatResult.DeepCopyInto(&out.NetcatResult)
 	return
 }
 
@@ -162,6 +323,11 @@ func (in *NetcatPodEndpoint) DeepCopy() *NetcatPodEndpoint {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetcatResult) DeepCopyInto(out *NetcatResult) {
 	*out = *in
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(ExecStatus)
+		**out = **in
+	}
 	return
 }
 
@@ -182,9 +348,11 @@ func (in *NetcatServiceEndpoint) DeepCopyInto(out *NetcatServiceEndpoint) {
 	if in.ServiceResults != nil {
 		in, out := &in.ServiceResults, &out.ServiceResults
 		*out = make([]NetcatIPEndpoint, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out.ServiceResultsDirect = in.ServiceResultsDirect
+	in.ServiceResultsDirect.DeepCopyInto(&out.ServiceResultsDirect)
 	return
 }
 
@@ -205,12 +373,16 @@ func (in *NetcatStatus) DeepCopyInto(out *NetcatStatus) {
 	if in.NetcatIPEndpoints != nil {
 		in, out := &in.NetcatIPEndpoints, &out.NetcatIPEndpoints
 		*out = make([]NetcatIPEndpoint, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.NetcatPodEndpoints != nil {
 		in, out := &in.NetcatPodEndpoints, &out.NetcatPodEndpoints
 		*out = make([]NetcatPodEndpoint, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.NetcatServiceEndpoints != nil {
 		in, out := &in.NetcatServiceEndpoints, &out.NetcatServiceEndpoints
@@ -240,6 +412,254 @@ func (in *NetcatStatus) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetemConfiguration) DeepCopyInto(out *NetemConfiguration) {
+	*out = *in
+	if in.Delay != nil {
+		in, out := &in.Delay, &out.Delay
+		*out = new(NetemDelay)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Loss != nil {
+		in, out := &in.Loss, &out.Loss
+		*out = new(NetemLoss)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Duplicate != nil {
+		in, out := &in.Duplicate, &out.Duplicate
+		*out = new(NetemProbability)
+		**out = **in
+	}
+	if in.Corrupt != nil {
+		in, out := &in.Corrupt, &out.Corrupt
+		*out = new(NetemProbability)
+		**out = **in
+	}
+	if in.Reorder != nil {
+		in, out := &in.Reorder, &out.Reorder
+		*out = new(NetemReorder)
+		**out = **in
+	}
+	if in.Rate != nil {
+		in, out := &in.Rate, &out.Rate
+		*out = new(NetemRate)
+		**out = **in
+	}
+	if in.Slot != nil {
+		in, out := &in.Slot, &out.Slot
+		*out = new(NetemSlot)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetemConfiguration.
+func (in *NetemConfiguration) DeepCopy() *NetemConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(NetemConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetemDelay) DeepCopyInto(out *NetemDelay) {
+	*out = *in
+	out.Time = in.Time
+	if in.Jitter != nil {
+		in, out := &in.Jitter, &out.Jitter
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetemDelay.
+func (in *NetemDelay) DeepCopy() *NetemDelay {
+	if in == nil {
+		return nil
+	}
+	out := new(NetemDelay)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetemLoss) DeepCopyInto(out *NetemLoss) {
+	*out = *in
+	if in.GilbertElliott != nil {
+		in, out := &in.GilbertElliott, &out.GilbertElliott
+		*out = new(GilbertElliottLoss)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetemLoss.
+func (in *NetemLoss) DeepCopy() *NetemLoss {
+	if in == nil {
+		return nil
+	}
+	out := new(NetemLoss)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetemProbability) DeepCopyInto(out *NetemProbability) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetemProbability.
+func (in *NetemProbability) DeepCopy() *NetemProbability {
+	if in == nil {
+		return nil
+	}
+	out := new(NetemProbability)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetemRate) DeepCopyInto(out *NetemRate) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetemRate.
+func (in *NetemRate) DeepCopy() *NetemRate {
+	if in == nil {
+		return nil
+	}
+	out := new(NetemRate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetemReorder) DeepCopyInto(out *NetemReorder) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetemReorder.
+func (in *NetemReorder) DeepCopy() *NetemReorder {
+	if in == nil {
+		return nil
+	}
+	out := new(NetemReorder)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetemSlot) DeepCopyInto(out *NetemSlot) {
+	*out = *in
+	out.MinDelay = in.MinDelay
+	if in.MaxDelay != nil {
+		in, out := &in.MaxDelay, &out.MaxDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetemSlot.
+func (in *NetemSlot) DeepCopy() *NetemSlot {
+	if in == nil {
+		return nil
+	}
+	out := new(NetemSlot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkAuditRecord) DeepCopyInto(out *NetworkAuditRecord) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkAuditRecord.
+func (in *NetworkAuditRecord) DeepCopy() *NetworkAuditRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkAuditRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkAuditRecord) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkAuditRecordList) DeepCopyInto(out *NetworkAuditRecordList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NetworkAuditRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkAuditRecordList.
+func (in *NetworkAuditRecordList) DeepCopy() *NetworkAuditRecordList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkAuditRecordList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkAuditRecordList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkAuditRecordSpec) DeepCopyInto(out *NetworkAuditRecordSpec) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+	out.Origin = in.Origin
+	out.Target = in.Target
+	out.Result = in.Result
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkAuditRecordSpec.
+func (in *NetworkAuditRecordSpec) DeepCopy() *NetworkAuditRecordSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkAuditRecordSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkConnectivityTest) DeepCopyInto(out *NetworkConnectivityTest) {
 	*out = *in
@@ -365,6 +785,21 @@ func (in *NetworkEvent) DeepCopyInto(out *NetworkEvent) {
 	*out = *in
 	out.Flow = in.Flow
 	out.Event = in.Event
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(Endpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Destination != nil {
+		in, out := &in.Destination, &out.Destination
+		*out = new(Endpoint)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Agent != nil {
+		in, out := &in.Agent, &out.Agent
+		*out = new(Endpoint)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -378,6 +813,96 @@ func (in *NetworkEvent) DeepCopy() *NetworkEvent {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkExecPolicy) DeepCopyInto(out *NetworkExecPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkExecPolicy.
+func (in *NetworkExecPolicy) DeepCopy() *NetworkExecPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkExecPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkExecPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkExecPolicyList) DeepCopyInto(out *NetworkExecPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NetworkExecPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkExecPolicyList.
+func (in *NetworkExecPolicyList) DeepCopy() *NetworkExecPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkExecPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkExecPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkExecPolicySpec) DeepCopyInto(out *NetworkExecPolicySpec) {
+	*out = *in
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]ExecPolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]ExecPolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkExecPolicySpec.
+func (in *NetworkExecPolicySpec) DeepCopy() *NetworkExecPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkExecPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkMonitor) DeepCopyInto(out *NetworkMonitor) {
 	*out = *in
@@ -454,6 +979,7 @@ func (in *NetworkMonitorSpec) DeepCopyInto(out *NetworkMonitorSpec) {
 		copy(*out, *in)
 	}
 	out.EventsConfig = in.EventsConfig
+	in.Retention.DeepCopyInto(&out.Retention)
 	return
 }
 
@@ -471,6 +997,16 @@ func (in *NetworkMonitorSpec) DeepCopy() *NetworkMonitorSpec {
 func (in *NetworkMonitorStatus) DeepCopyInto(out *NetworkMonitorStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
+	if in.InstalledFlows != nil {
+		in, out := &in.InstalledFlows, &out.InstalledFlows
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.InstalledThresholds != nil {
+		in, out := &in.InstalledThresholds, &out.InstalledThresholds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -479,32 +1015,93 @@ func (in *NetworkMonitorStatus) DeepCopy() *NetworkMonitorStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkMonitorStatus)
+	out := new(NetworkMonitorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkNotification) DeepCopyInto(out *NetworkNotification) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkNotification.
+func (in *NetworkNotification) DeepCopy() *NetworkNotification {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkNotification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkNotification) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkNotificationList) DeepCopyInto(out *NetworkNotificationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NetworkNotification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkNotificationList.
+func (in *NetworkNotificationList) DeepCopy() *NetworkNotificationList {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkNotificationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NetworkNotificationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkNotification) DeepCopyInto(out *NetworkNotification) {
+func (in *NetworkNotificationRoute) DeepCopyInto(out *NetworkNotificationRoute) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkNotification.
-func (in *NetworkNotification) DeepCopy() *NetworkNotification {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkNotificationRoute.
+func (in *NetworkNotificationRoute) DeepCopy() *NetworkNotificationRoute {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkNotification)
+	out := new(NetworkNotificationRoute)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NetworkNotification) DeepCopyObject() runtime.Object {
+func (in *NetworkNotificationRoute) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -512,13 +1109,13 @@ func (in *NetworkNotification) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NetworkNotificationList) DeepCopyInto(out *NetworkNotificationList) {
+func (in *NetworkNotificationRouteList) DeepCopyInto(out *NetworkNotificationRouteList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]NetworkNotification, len(*in))
+		*out = make([]NetworkNotificationRoute, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -526,28 +1123,73 @@ func (in *NetworkNotificationList) DeepCopyInto(out *NetworkNotificationList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkNotificationList.
-func (in *NetworkNotificationList) DeepCopy() *NetworkNotificationList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkNotificationRouteList.
+func (in *NetworkNotificationRouteList) DeepCopy() *NetworkNotificationRouteList {
 	if in == nil {
 		return nil
 	}
-	out := new(NetworkNotificationList)
+	out := new(NetworkNotificationRouteList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NetworkNotificationList) DeepCopyObject() runtime.Object {
+func (in *NetworkNotificationRouteList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkNotificationRouteSpec) DeepCopyInto(out *NetworkNotificationRouteSpec) {
+	*out = *in
+	in.Match.DeepCopyInto(&out.Match)
+	if in.Receivers != nil {
+		in, out := &in.Receivers, &out.Receivers
+		*out = make([]NotificationReceiver, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(NotificationRateLimit)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkNotificationRouteSpec.
+func (in *NetworkNotificationRouteSpec) DeepCopy() *NetworkNotificationRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkNotificationRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkNotificationSpec) DeepCopyInto(out *NetworkNotificationSpec) {
 	*out = *in
-	out.Event = in.Event
+	in.Event.DeepCopyInto(&out.Event)
+	if in.FirstSeen != nil {
+		in, out := &in.FirstSeen, &out.FirstSeen
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSeen != nil {
+		in, out := &in.LastSeen, &out.LastSeen
+		*out = (*in).DeepCopy()
+	}
+	if in.ClearSince != nil {
+		in, out := &in.ClearSince, &out.ClearSince
+		*out = (*in).DeepCopy()
+	}
+	if in.ResolvedAt != nil {
+		in, out := &in.ResolvedAt, &out.ResolvedAt
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -561,6 +1203,29 @@ func (in *NetworkNotificationSpec) DeepCopy() *NetworkNotificationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkNotificationStatus) DeepCopyInto(out *NetworkNotificationStatus) {
+	*out = *in
+	if in.Deliveries != nil {
+		in, out := &in.Deliveries, &out.Deliveries
+		*out = make([]NotificationDelivery, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkNotificationStatus.
+func (in *NetworkNotificationStatus) DeepCopy() *NetworkNotificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkNotificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkSourceEndpoint) DeepCopyInto(out *NetworkSourceEndpoint) {
 	*out = *in
@@ -653,6 +1318,15 @@ func (in *NetworkTrafficShaperSpec) DeepCopyInto(out *NetworkTrafficShaperSpec)
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.PauseUntil != nil {
+		in, out := &in.PauseUntil, &out.PauseUntil
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -670,6 +1344,37 @@ func (in *NetworkTrafficShaperSpec) DeepCopy() *NetworkTrafficShaperSpec {
 func (in *NetworkTrafficShaperStatus) DeepCopyInto(out *NetworkTrafficShaperStatus) {
 	*out = *in
 	in.Status.DeepCopyInto(&out.Status)
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EndTime != nil {
+		in, out := &in.EndTime, &out.EndTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextStartTime != nil {
+		in, out := &in.NextStartTime, &out.NextStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]ShaperTargetStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selections != nil {
+		in, out := &in.Selections, &out.Selections
+		*out = make([]TargetSelection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]ShapedPod, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -683,6 +1388,113 @@ func (in *NetworkTrafficShaperStatus) DeepCopy() *NetworkTrafficShaperStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationDelivery) DeepCopyInto(out *NotificationDelivery) {
+	*out = *in
+	if in.LastAttemptTime != nil {
+		in, out := &in.LastAttemptTime, &out.LastAttemptTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationDelivery.
+func (in *NotificationDelivery) DeepCopy() *NotificationDelivery {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationDelivery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationMatch) DeepCopyInto(out *NotificationMatch) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationMatch.
+func (in *NotificationMatch) DeepCopy() *NotificationMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationRateLimit) DeepCopyInto(out *NotificationRateLimit) {
+	*out = *in
+	out.Period = in.Period
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationRateLimit.
+func (in *NotificationRateLimit) DeepCopy() *NotificationRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationReceiver) DeepCopyInto(out *NotificationReceiver) {
+	*out = *in
+	if in.URLSecretRef != nil {
+		in, out := &in.URLSecretRef, &out.URLSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationReceiver.
+func (in *NotificationReceiver) DeepCopy() *NotificationReceiver {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationReceiver)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationRetention) DeepCopyInto(out *NotificationRetention) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationRetention.
+func (in *NotificationRetention) DeepCopy() *NotificationRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Params) DeepCopyInto(out *Params) {
 	*out = *in
@@ -702,7 +1514,7 @@ func (in *Params) DeepCopy() *Params {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PingIPEndpoint) DeepCopyInto(out *PingIPEndpoint) {
 	*out = *in
-	out.PingResult = in.PingResult
+	in.PingResult.DeepCopyInto(&out.PingResult)
 	return
 }
 
@@ -720,7 +1532,7 @@ func (in *PingIPEndpoint) DeepCopy() *PingIPEndpoint {
 func (in *PingPodEndpoint) DeepCopyInto(out *PingPodEndpoint) {
 	*out = *in
 	out.PodParams = in.PodParams
-	out.PingResult = in.PingResult
+	in.PingResult.DeepCopyInto(&out.PingResult)
 	return
 }
 
@@ -737,6 +1549,11 @@ func (in *PingPodEndpoint) DeepCopy() *PingPodEndpoint {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PingResult) DeepCopyInto(out *PingResult) {
 	*out = *in
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(ExecStatus)
+		**out = **in
+	}
 	return
 }
 
@@ -757,7 +1574,9 @@ func (in *PingServiceEndpoint) DeepCopyInto(out *PingServiceEndpoint) {
 	if in.ServiceResults != nil {
 		in, out := &in.ServiceResults, &out.ServiceResults
 		*out = make([]PingIPEndpoint, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -779,12 +1598,16 @@ func (in *PingStatus) DeepCopyInto(out *PingStatus) {
 	if in.PingIPEndpoints != nil {
 		in, out := &in.PingIPEndpoints, &out.PingIPEndpoints
 		*out = make([]PingIPEndpoint, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.PingPodEndpoints != nil {
 		in, out := &in.PingPodEndpoints, &out.PingPodEndpoints
 		*out = make([]PingPodEndpoint, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.PingServiceEndpoint != nil {
 		in, out := &in.PingServiceEndpoint, &out.PingServiceEndpoint
@@ -814,9 +1637,62 @@ func (in *PingStatus) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShapedBandwidth) DeepCopyInto(out *ShapedBandwidth) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShapedBandwidth.
+func (in *ShapedBandwidth) DeepCopy() *ShapedBandwidth {
+	if in == nil {
+		return nil
+	}
+	out := new(ShapedBandwidth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShapedPod) DeepCopyInto(out *ShapedPod) {
+	*out = *in
+	in.AppliedAt.DeepCopyInto(&out.AppliedAt)
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		*out = new(ShapedBandwidth)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShapedPod.
+func (in *ShapedPod) DeepCopy() *ShapedPod {
+	if in == nil {
+		return nil
+	}
+	out := new(ShapedPod)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ShaperConfiguration) DeepCopyInto(out *ShaperConfiguration) {
 	*out = *in
+	if in.Netem != nil {
+		in, out := &in.Netem, &out.Netem
+		*out = new(NetemConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		*out = new(BandwidthConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = new(TrafficMatch)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -830,10 +1706,31 @@ func (in *ShaperConfiguration) DeepCopy() *ShaperConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShaperDestination) DeepCopyInto(out *ShaperDestination) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShaperDestination.
+func (in *ShaperDestination) DeepCopy() *ShaperDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(ShaperDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ShaperTarget) DeepCopyInto(out *ShaperTarget) {
 	*out = *in
-	out.ShaperConfig = in.ShaperConfig
+	in.ShaperConfig.DeepCopyInto(&out.ShaperConfig)
 	if in.SourceSelector != nil {
 		in, out := &in.SourceSelector, &out.SourceSelector
 		*out = new(v1.LabelSelector)
@@ -852,6 +1749,22 @@ func (in *ShaperTarget) DeepCopy() *ShaperTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShaperTargetStatus) DeepCopyInto(out *ShaperTargetStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShaperTargetStatus.
+func (in *ShaperTargetStatus) DeepCopy() *ShaperTargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ShaperTargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Status) DeepCopyInto(out *Status) {
 	*out = *in
@@ -874,6 +1787,27 @@ func (in *Status) DeepCopy() *Status {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetSelection) DeepCopyInto(out *TargetSelection) {
+	*out = *in
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetSelection.
+func (in *TargetSelection) DeepCopy() *TargetSelection {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetSelection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Threshold) DeepCopyInto(out *Threshold) {
 	*out = *in
@@ -889,3 +1823,36 @@ func (in *Threshold) DeepCopy() *Threshold {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficMatch) DeepCopyInto(out *TrafficMatch) {
+	*out = *in
+	if in.Destinations != nil {
+		in, out := &in.Destinations, &out.Destinations
+		*out = make([]ShaperDestination, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CIDRs != nil {
+		in, out := &in.CIDRs, &out.CIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficMatch.
+func (in *TrafficMatch) DeepCopy() *TrafficMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficMatch)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,122 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeNetworkAuditRecords implements NetworkAuditRecordInterface
+type FakeNetworkAuditRecords struct {
+	Fake *FakeNetworkmachineryV1alpha1
+}
+
+var networkauditrecordsResource = schema.GroupVersionResource{Group: "networkmachinery.io", Version: "v1alpha1", Resource: "networkauditrecords"}
+
+var networkauditrecordsKind = schema.GroupVersionKind{Group: "networkmachinery.io", Version: "v1alpha1", Kind: "NetworkAuditRecord"}
+
+// Get takes name of the networkAuditRecord, and returns the corresponding networkAuditRecord object, and an error if there is any.
+func (c *FakeNetworkAuditRecords) Get(name string, options v1.GetOptions) (result *v1alpha1.NetworkAuditRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(networkauditrecordsResource, name), &v1alpha1.NetworkAuditRecord{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.NetworkAuditRecord), err
+}
+
+// List takes label and field selectors, and returns the list of NetworkAuditRecords that match those selectors.
+func (c *FakeNetworkAuditRecords) List(opts v1.ListOptions) (result *v1alpha1.NetworkAuditRecordList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(networkauditrecordsResource, networkauditrecordsKind, opts), &v1alpha1.NetworkAuditRecordList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.NetworkAuditRecordList{ListMeta: obj.(*v1alpha1.NetworkAuditRecordList).ListMeta}
+	for _, item := range obj.(*v1alpha1.NetworkAuditRecordList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested networkAuditRecords.
+func (c *FakeNetworkAuditRecords) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(networkauditrecordsResource, opts))
+}
+
+// Create takes the representation of a networkAuditRecord and creates it.  Returns the server's representation of the networkAuditRecord, and an error, if there is any.
+func (c *FakeNetworkAuditRecords) Create(networkAuditRecord *v1alpha1.NetworkAuditRecord) (result *v1alpha1.NetworkAuditRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(networkauditrecordsResource, networkAuditRecord), &v1alpha1.NetworkAuditRecord{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.NetworkAuditRecord), err
+}
+
+// Update takes the representation of a networkAuditRecord and updates it. Returns the server's representation of the networkAuditRecord, and an error, if there is any.
+func (c *FakeNetworkAuditRecords) Update(networkAuditRecord *v1alpha1.NetworkAuditRecord) (result *v1alpha1.NetworkAuditRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(networkauditrecordsResource, networkAuditRecord), &v1alpha1.NetworkAuditRecord{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.NetworkAuditRecord), err
+}
+
+// Delete takes name of the networkAuditRecord and deletes it. Returns an error if one occurs.
+func (c *FakeNetworkAuditRecords) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(networkauditrecordsResource, name), &v1alpha1.NetworkAuditRecord{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeNetworkAuditRecords) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(networkauditrecordsResource, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.NetworkAuditRecordList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched networkAuditRecord.
+func (c *FakeNetworkAuditRecords) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.NetworkAuditRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(networkauditrecordsResource, name, pt, data, subresources...), &v1alpha1.NetworkAuditRecord{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.NetworkAuditRecord), err
+}
@@ -0,0 +1,122 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeNetworkExecPolicies implements NetworkExecPolicyInterface
+type FakeNetworkExecPolicies struct {
+	Fake *FakeNetworkmachineryV1alpha1
+}
+
+var networkexecpoliciesResource = schema.GroupVersionResource{Group: "networkmachinery.io", Version: "v1alpha1", Resource: "networkexecpolicies"}
+
+var networkexecpoliciesKind = schema.GroupVersionKind{Group: "networkmachinery.io", Version: "v1alpha1", Kind: "NetworkExecPolicy"}
+
+// Get takes name of the networkExecPolicy, and returns the corresponding networkExecPolicy object, and an error if there is any.
+func (c *FakeNetworkExecPolicies) Get(name string, options v1.GetOptions) (result *v1alpha1.NetworkExecPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(networkexecpoliciesResource, name), &v1alpha1.NetworkExecPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.NetworkExecPolicy), err
+}
+
+// List takes label and field selectors, and returns the list of NetworkExecPolicies that match those selectors.
+func (c *FakeNetworkExecPolicies) List(opts v1.ListOptions) (result *v1alpha1.NetworkExecPolicyList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(networkexecpoliciesResource, networkexecpoliciesKind, opts), &v1alpha1.NetworkExecPolicyList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.NetworkExecPolicyList{ListMeta: obj.(*v1alpha1.NetworkExecPolicyList).ListMeta}
+	for _, item := range obj.(*v1alpha1.NetworkExecPolicyList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested networkExecPolicies.
+func (c *FakeNetworkExecPolicies) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(networkexecpoliciesResource, opts))
+}
+
+// Create takes the representation of a networkExecPolicy and creates it.  Returns the server's representation of the networkExecPolicy, and an error, if there is any.
+func (c *FakeNetworkExecPolicies) Create(networkExecPolicy *v1alpha1.NetworkExecPolicy) (result *v1alpha1.NetworkExecPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(networkexecpoliciesResource, networkExecPolicy), &v1alpha1.NetworkExecPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.NetworkExecPolicy), err
+}
+
+// Update takes the representation of a networkExecPolicy and updates it. Returns the server's representation of the networkExecPolicy, and an error, if there is any.
+func (c *FakeNetworkExecPolicies) Update(networkExecPolicy *v1alpha1.NetworkExecPolicy) (result *v1alpha1.NetworkExecPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(networkexecpoliciesResource, networkExecPolicy), &v1alpha1.NetworkExecPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.NetworkExecPolicy), err
+}
+
+// Delete takes name of the networkExecPolicy and deletes it. Returns an error if one occurs.
+func (c *FakeNetworkExecPolicies) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(networkexecpoliciesResource, name), &v1alpha1.NetworkExecPolicy{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeNetworkExecPolicies) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(networkexecpoliciesResource, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.NetworkExecPolicyList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched networkExecPolicy.
+func (c *FakeNetworkExecPolicies) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.NetworkExecPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(networkexecpoliciesResource, name, pt, data, subresources...), &v1alpha1.NetworkExecPolicy{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.NetworkExecPolicy), err
+}
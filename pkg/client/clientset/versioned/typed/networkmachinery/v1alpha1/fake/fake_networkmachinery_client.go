@@ -30,16 +30,28 @@ type FakeNetworkmachineryV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakeNetworkmachineryV1alpha1) NetworkAuditRecords() v1alpha1.NetworkAuditRecordInterface {
+	return &FakeNetworkAuditRecords{c}
+}
+
 func (c *FakeNetworkmachineryV1alpha1) NetworkConnectivityTests() v1alpha1.NetworkConnectivityTestInterface {
 	return &FakeNetworkConnectivityTests{c}
 }
 
+func (c *FakeNetworkmachineryV1alpha1) NetworkExecPolicies() v1alpha1.NetworkExecPolicyInterface {
+	return &FakeNetworkExecPolicies{c}
+}
+
 func (c *FakeNetworkmachineryV1alpha1) NetworkMonitors() v1alpha1.NetworkMonitorInterface {
 	return &FakeNetworkMonitors{c}
 }
 
-func (c *FakeNetworkmachineryV1alpha1) NetworkNotifications() v1alpha1.NetworkNotificationInterface {
-	return &FakeNetworkNotifications{c}
+func (c *FakeNetworkmachineryV1alpha1) NetworkNotifications(namespace string) v1alpha1.NetworkNotificationInterface {
+	return &FakeNetworkNotifications{c, namespace}
+}
+
+func (c *FakeNetworkmachineryV1alpha1) NetworkNotificationRoutes(namespace string) v1alpha1.NetworkNotificationRouteInterface {
+	return &FakeNetworkNotificationRoutes{c, namespace}
 }
 
 func (c *FakeNetworkmachineryV1alpha1) NetworkTrafficShapers() v1alpha1.NetworkTrafficShaperInterface {
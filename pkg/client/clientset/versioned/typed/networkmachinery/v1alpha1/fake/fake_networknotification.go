@@ -33,6 +33,7 @@ import (
 // FakeNetworkNotifications implements NetworkNotificationInterface
 type FakeNetworkNotifications struct {
 	Fake *FakeNetworkmachineryV1alpha1
+	ns   string
 }
 
 var networknotificationsResource = schema.GroupVersionResource{Group: "networkmachinery.io", Version: "v1alpha1", Resource: "networknotifications"}
@@ -42,7 +43,8 @@ var networknotificationsKind = schema.GroupVersionKind{Group: "networkmachinery.
 // Get takes name of the networkNotification, and returns the corresponding networkNotification object, and an error if there is any.
 func (c *FakeNetworkNotifications) Get(name string, options v1.GetOptions) (result *v1alpha1.NetworkNotification, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootGetAction(networknotificationsResource, name), &v1alpha1.NetworkNotification{})
+		Invokes(testing.NewGetAction(networknotificationsResource, c.ns, name), &v1alpha1.NetworkNotification{})
+
 	if obj == nil {
 		return nil, err
 	}
@@ -52,7 +54,8 @@ func (c *FakeNetworkNotifications) Get(name string, options v1.GetOptions) (resu
 // List takes label and field selectors, and returns the list of NetworkNotifications that match those selectors.
 func (c *FakeNetworkNotifications) List(opts v1.ListOptions) (result *v1alpha1.NetworkNotificationList, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootListAction(networknotificationsResource, networknotificationsKind, opts), &v1alpha1.NetworkNotificationList{})
+		Invokes(testing.NewListAction(networknotificationsResource, networknotificationsKind, c.ns, opts), &v1alpha1.NetworkNotificationList{})
+
 	if obj == nil {
 		return nil, err
 	}
@@ -73,13 +76,15 @@ func (c *FakeNetworkNotifications) List(opts v1.ListOptions) (result *v1alpha1.N
 // Watch returns a watch.Interface that watches the requested networkNotifications.
 func (c *FakeNetworkNotifications) Watch(opts v1.ListOptions) (watch.Interface, error) {
 	return c.Fake.
-		InvokesWatch(testing.NewRootWatchAction(networknotificationsResource, opts))
+		InvokesWatch(testing.NewWatchAction(networknotificationsResource, c.ns, opts))
+
 }
 
 // Create takes the representation of a networkNotification and creates it.  Returns the server's representation of the networkNotification, and an error, if there is any.
 func (c *FakeNetworkNotifications) Create(networkNotification *v1alpha1.NetworkNotification) (result *v1alpha1.NetworkNotification, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootCreateAction(networknotificationsResource, networkNotification), &v1alpha1.NetworkNotification{})
+		Invokes(testing.NewCreateAction(networknotificationsResource, c.ns, networkNotification), &v1alpha1.NetworkNotification{})
+
 	if obj == nil {
 		return nil, err
 	}
@@ -89,7 +94,20 @@ func (c *FakeNetworkNotifications) Create(networkNotification *v1alpha1.NetworkN
 // Update takes the representation of a networkNotification and updates it. Returns the server's representation of the networkNotification, and an error, if there is any.
 func (c *FakeNetworkNotifications) Update(networkNotification *v1alpha1.NetworkNotification) (result *v1alpha1.NetworkNotification, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootUpdateAction(networknotificationsResource, networkNotification), &v1alpha1.NetworkNotification{})
+		Invokes(testing.NewUpdateAction(networknotificationsResource, c.ns, networkNotification), &v1alpha1.NetworkNotification{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.NetworkNotification), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeNetworkNotifications) UpdateStatus(networkNotification *v1alpha1.NetworkNotification) (*v1alpha1.NetworkNotification, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(networknotificationsResource, "status", c.ns, networkNotification), &v1alpha1.NetworkNotification{})
+
 	if obj == nil {
 		return nil, err
 	}
@@ -99,13 +117,14 @@ func (c *FakeNetworkNotifications) Update(networkNotification *v1alpha1.NetworkN
 // Delete takes name of the networkNotification and deletes it. Returns an error if one occurs.
 func (c *FakeNetworkNotifications) Delete(name string, options *v1.DeleteOptions) error {
 	_, err := c.Fake.
-		Invokes(testing.NewRootDeleteAction(networknotificationsResource, name), &v1alpha1.NetworkNotification{})
+		Invokes(testing.NewDeleteAction(networknotificationsResource, c.ns, name), &v1alpha1.NetworkNotification{})
+
 	return err
 }
 
 // DeleteCollection deletes a collection of objects.
 func (c *FakeNetworkNotifications) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
-	action := testing.NewRootDeleteCollectionAction(networknotificationsResource, listOptions)
+	action := testing.NewDeleteCollectionAction(networknotificationsResource, c.ns, listOptions)
 
 	_, err := c.Fake.Invokes(action, &v1alpha1.NetworkNotificationList{})
 	return err
@@ -114,7 +133,8 @@ func (c *FakeNetworkNotifications) DeleteCollection(options *v1.DeleteOptions, l
 // Patch applies the patch and returns the patched networkNotification.
 func (c *FakeNetworkNotifications) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.NetworkNotification, err error) {
 	obj, err := c.Fake.
-		Invokes(testing.NewRootPatchSubresourceAction(networknotificationsResource, name, pt, data, subresources...), &v1alpha1.NetworkNotification{})
+		Invokes(testing.NewPatchSubresourceAction(networknotificationsResource, c.ns, name, pt, data, subresources...), &v1alpha1.NetworkNotification{})
+
 	if obj == nil {
 		return nil, err
 	}
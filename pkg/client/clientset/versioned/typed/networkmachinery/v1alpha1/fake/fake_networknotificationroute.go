@@ -0,0 +1,130 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	v1alpha1 "github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeNetworkNotificationRoutes implements NetworkNotificationRouteInterface
+type FakeNetworkNotificationRoutes struct {
+	Fake *FakeNetworkmachineryV1alpha1
+	ns   string
+}
+
+var networknotificationroutesResource = schema.GroupVersionResource{Group: "networkmachinery.io", Version: "v1alpha1", Resource: "networknotificationroutes"}
+
+var networknotificationroutesKind = schema.GroupVersionKind{Group: "networkmachinery.io", Version: "v1alpha1", Kind: "NetworkNotificationRoute"}
+
+// Get takes name of the networkNotificationRoute, and returns the corresponding networkNotificationRoute object, and an error if there is any.
+func (c *FakeNetworkNotificationRoutes) Get(name string, options v1.GetOptions) (result *v1alpha1.NetworkNotificationRoute, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(networknotificationroutesResource, c.ns, name), &v1alpha1.NetworkNotificationRoute{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.NetworkNotificationRoute), err
+}
+
+// List takes label and field selectors, and returns the list of NetworkNotificationRoutes that match those selectors.
+func (c *FakeNetworkNotificationRoutes) List(opts v1.ListOptions) (result *v1alpha1.NetworkNotificationRouteList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(networknotificationroutesResource, networknotificationroutesKind, c.ns, opts), &v1alpha1.NetworkNotificationRouteList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.NetworkNotificationRouteList{ListMeta: obj.(*v1alpha1.NetworkNotificationRouteList).ListMeta}
+	for _, item := range obj.(*v1alpha1.NetworkNotificationRouteList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested networkNotificationRoutes.
+func (c *FakeNetworkNotificationRoutes) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(networknotificationroutesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a networkNotificationRoute and creates it.  Returns the server's representation of the networkNotificationRoute, and an error, if there is any.
+func (c *FakeNetworkNotificationRoutes) Create(networkNotificationRoute *v1alpha1.NetworkNotificationRoute) (result *v1alpha1.NetworkNotificationRoute, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(networknotificationroutesResource, c.ns, networkNotificationRoute), &v1alpha1.NetworkNotificationRoute{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.NetworkNotificationRoute), err
+}
+
+// Update takes the representation of a networkNotificationRoute and updates it. Returns the server's representation of the networkNotificationRoute, and an error, if there is any.
+func (c *FakeNetworkNotificationRoutes) Update(networkNotificationRoute *v1alpha1.NetworkNotificationRoute) (result *v1alpha1.NetworkNotificationRoute, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(networknotificationroutesResource, c.ns, networkNotificationRoute), &v1alpha1.NetworkNotificationRoute{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.NetworkNotificationRoute), err
+}
+
+// Delete takes name of the networkNotificationRoute and deletes it. Returns an error if one occurs.
+func (c *FakeNetworkNotificationRoutes) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(networknotificationroutesResource, c.ns, name), &v1alpha1.NetworkNotificationRoute{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeNetworkNotificationRoutes) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(networknotificationroutesResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.NetworkNotificationRouteList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched networkNotificationRoute.
+func (c *FakeNetworkNotificationRoutes) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.NetworkNotificationRoute, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(networknotificationroutesResource, c.ns, name, pt, data, subresources...), &v1alpha1.NetworkNotificationRoute{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.NetworkNotificationRoute), err
+}
@@ -20,10 +20,16 @@ under the License.
 
 package v1alpha1
 
+type NetworkAuditRecordExpansion interface{}
+
 type NetworkConnectivityTestExpansion interface{}
 
+type NetworkExecPolicyExpansion interface{}
+
 type NetworkMonitorExpansion interface{}
 
 type NetworkNotificationExpansion interface{}
 
+type NetworkNotificationRouteExpansion interface{}
+
 type NetworkTrafficShaperExpansion interface{}
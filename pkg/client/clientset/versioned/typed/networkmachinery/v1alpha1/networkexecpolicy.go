@@ -0,0 +1,166 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"time"
+
+	v1alpha1 "github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	scheme "github.com/networkmachinery/networkmachinery-operators/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// NetworkExecPoliciesGetter has a method to return a NetworkExecPolicyInterface.
+// A group's client should implement this interface.
+type NetworkExecPoliciesGetter interface {
+	NetworkExecPolicies() NetworkExecPolicyInterface
+}
+
+// NetworkExecPolicyInterface has methods to work with NetworkExecPolicy resources.
+type NetworkExecPolicyInterface interface {
+	Create(*v1alpha1.NetworkExecPolicy) (*v1alpha1.NetworkExecPolicy, error)
+	Update(*v1alpha1.NetworkExecPolicy) (*v1alpha1.NetworkExecPolicy, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.NetworkExecPolicy, error)
+	List(opts v1.ListOptions) (*v1alpha1.NetworkExecPolicyList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.NetworkExecPolicy, err error)
+	NetworkExecPolicyExpansion
+}
+
+// networkExecPolicies implements NetworkExecPolicyInterface
+type networkExecPolicies struct {
+	client rest.Interface
+}
+
+// newNetworkExecPolicies returns a NetworkExecPolicies
+func newNetworkExecPolicies(c *NetworkmachineryV1alpha1Client) *networkExecPolicies {
+	return &networkExecPolicies{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the networkExecPolicy, and returns the corresponding networkExecPolicy object, and an error if there is any.
+func (c *networkExecPolicies) Get(name string, options v1.GetOptions) (result *v1alpha1.NetworkExecPolicy, err error) {
+	result = &v1alpha1.NetworkExecPolicy{}
+	err = c.client.Get().
+		Resource("networkexecpolicies").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of NetworkExecPolicies that match those selectors.
+func (c *networkExecPolicies) List(opts v1.ListOptions) (result *v1alpha1.NetworkExecPolicyList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.NetworkExecPolicyList{}
+	err = c.client.Get().
+		Resource("networkexecpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested networkExecPolicies.
+func (c *networkExecPolicies) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("networkexecpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch()
+}
+
+// Create takes the representation of a networkExecPolicy and creates it.  Returns the server's representation of the networkExecPolicy, and an error, if there is any.
+func (c *networkExecPolicies) Create(networkExecPolicy *v1alpha1.NetworkExecPolicy) (result *v1alpha1.NetworkExecPolicy, err error) {
+	result = &v1alpha1.NetworkExecPolicy{}
+	err = c.client.Post().
+		Resource("networkexecpolicies").
+		Body(networkExecPolicy).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a networkExecPolicy and updates it. Returns the server's representation of the networkExecPolicy, and an error, if there is any.
+func (c *networkExecPolicies) Update(networkExecPolicy *v1alpha1.NetworkExecPolicy) (result *v1alpha1.NetworkExecPolicy, err error) {
+	result = &v1alpha1.NetworkExecPolicy{}
+	err = c.client.Put().
+		Resource("networkexecpolicies").
+		Name(networkExecPolicy.Name).
+		Body(networkExecPolicy).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the networkExecPolicy and deletes it. Returns an error if one occurs.
+func (c *networkExecPolicies) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("networkexecpolicies").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *networkExecPolicies) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	var timeout time.Duration
+	if listOptions.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("networkexecpolicies").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched networkExecPolicy.
+func (c *networkExecPolicies) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.NetworkExecPolicy, err error) {
+	result = &v1alpha1.NetworkExecPolicy{}
+	err = c.client.Patch(pt).
+		Resource("networkexecpolicies").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
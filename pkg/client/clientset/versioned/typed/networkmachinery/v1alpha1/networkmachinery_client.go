@@ -28,9 +28,12 @@ import (
 
 type NetworkmachineryV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	NetworkAuditRecordsGetter
 	NetworkConnectivityTestsGetter
+	NetworkExecPoliciesGetter
 	NetworkMonitorsGetter
 	NetworkNotificationsGetter
+	NetworkNotificationRoutesGetter
 	NetworkTrafficShapersGetter
 }
 
@@ -39,16 +42,28 @@ type NetworkmachineryV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *NetworkmachineryV1alpha1Client) NetworkAuditRecords() NetworkAuditRecordInterface {
+	return newNetworkAuditRecords(c)
+}
+
 func (c *NetworkmachineryV1alpha1Client) NetworkConnectivityTests() NetworkConnectivityTestInterface {
 	return newNetworkConnectivityTests(c)
 }
 
+func (c *NetworkmachineryV1alpha1Client) NetworkExecPolicies() NetworkExecPolicyInterface {
+	return newNetworkExecPolicies(c)
+}
+
 func (c *NetworkmachineryV1alpha1Client) NetworkMonitors() NetworkMonitorInterface {
 	return newNetworkMonitors(c)
 }
 
-func (c *NetworkmachineryV1alpha1Client) NetworkNotifications() NetworkNotificationInterface {
-	return newNetworkNotifications(c)
+func (c *NetworkmachineryV1alpha1Client) NetworkNotifications(namespace string) NetworkNotificationInterface {
+	return newNetworkNotifications(c, namespace)
+}
+
+func (c *NetworkmachineryV1alpha1Client) NetworkNotificationRoutes(namespace string) NetworkNotificationRouteInterface {
+	return newNetworkNotificationRoutes(c, namespace)
 }
 
 func (c *NetworkmachineryV1alpha1Client) NetworkTrafficShapers() NetworkTrafficShaperInterface {
@@ -34,13 +34,14 @@ import (
 // NetworkNotificationsGetter has a method to return a NetworkNotificationInterface.
 // A group's client should implement this interface.
 type NetworkNotificationsGetter interface {
-	NetworkNotifications() NetworkNotificationInterface
+	NetworkNotifications(namespace string) NetworkNotificationInterface
 }
 
 // NetworkNotificationInterface has methods to work with NetworkNotification resources.
 type NetworkNotificationInterface interface {
 	Create(*v1alpha1.NetworkNotification) (*v1alpha1.NetworkNotification, error)
 	Update(*v1alpha1.NetworkNotification) (*v1alpha1.NetworkNotification, error)
+	UpdateStatus(*v1alpha1.NetworkNotification) (*v1alpha1.NetworkNotification, error)
 	Delete(name string, options *v1.DeleteOptions) error
 	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
 	Get(name string, options v1.GetOptions) (*v1alpha1.NetworkNotification, error)
@@ -53,12 +54,14 @@ type NetworkNotificationInterface interface {
 // networkNotifications implements NetworkNotificationInterface
 type networkNotifications struct {
 	client rest.Interface
+	ns     string
 }
 
 // newNetworkNotifications returns a NetworkNotifications
-func newNetworkNotifications(c *NetworkmachineryV1alpha1Client) *networkNotifications {
+func newNetworkNotifications(c *NetworkmachineryV1alpha1Client, namespace string) *networkNotifications {
 	return &networkNotifications{
 		client: c.RESTClient(),
+		ns:     namespace,
 	}
 }
 
@@ -66,6 +69,7 @@ func newNetworkNotifications(c *NetworkmachineryV1alpha1Client) *networkNotifica
 func (c *networkNotifications) Get(name string, options v1.GetOptions) (result *v1alpha1.NetworkNotification, err error) {
 	result = &v1alpha1.NetworkNotification{}
 	err = c.client.Get().
+		Namespace(c.ns).
 		Resource("networknotifications").
 		Name(name).
 		VersionedParams(&options, scheme.ParameterCodec).
@@ -82,6 +86,7 @@ func (c *networkNotifications) List(opts v1.ListOptions) (result *v1alpha1.Netwo
 	}
 	result = &v1alpha1.NetworkNotificationList{}
 	err = c.client.Get().
+		Namespace(c.ns).
 		Resource("networknotifications").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
@@ -98,6 +103,7 @@ func (c *networkNotifications) Watch(opts v1.ListOptions) (watch.Interface, erro
 	}
 	opts.Watch = true
 	return c.client.Get().
+		Namespace(c.ns).
 		Resource("networknotifications").
 		VersionedParams(&opts, scheme.ParameterCodec).
 		Timeout(timeout).
@@ -108,6 +114,7 @@ func (c *networkNotifications) Watch(opts v1.ListOptions) (watch.Interface, erro
 func (c *networkNotifications) Create(networkNotification *v1alpha1.NetworkNotification) (result *v1alpha1.NetworkNotification, err error) {
 	result = &v1alpha1.NetworkNotification{}
 	err = c.client.Post().
+		Namespace(c.ns).
 		Resource("networknotifications").
 		Body(networkNotification).
 		Do().
@@ -119,6 +126,7 @@ func (c *networkNotifications) Create(networkNotification *v1alpha1.NetworkNotif
 func (c *networkNotifications) Update(networkNotification *v1alpha1.NetworkNotification) (result *v1alpha1.NetworkNotification, err error) {
 	result = &v1alpha1.NetworkNotification{}
 	err = c.client.Put().
+		Namespace(c.ns).
 		Resource("networknotifications").
 		Name(networkNotification.Name).
 		Body(networkNotification).
@@ -127,9 +135,26 @@ func (c *networkNotifications) Update(networkNotification *v1alpha1.NetworkNotif
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *networkNotifications) UpdateStatus(networkNotification *v1alpha1.NetworkNotification) (result *v1alpha1.NetworkNotification, err error) {
+	result = &v1alpha1.NetworkNotification{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("networknotifications").
+		Name(networkNotification.Name).
+		SubResource("status").
+		Body(networkNotification).
+		Do().
+		Into(result)
+	return
+}
+
 // Delete takes name of the networkNotification and deletes it. Returns an error if one occurs.
 func (c *networkNotifications) Delete(name string, options *v1.DeleteOptions) error {
 	return c.client.Delete().
+		Namespace(c.ns).
 		Resource("networknotifications").
 		Name(name).
 		Body(options).
@@ -144,6 +169,7 @@ func (c *networkNotifications) DeleteCollection(options *v1.DeleteOptions, listO
 		timeout = time.Duration(*listOptions.TimeoutSeconds) * time.Second
 	}
 	return c.client.Delete().
+		Namespace(c.ns).
 		Resource("networknotifications").
 		VersionedParams(&listOptions, scheme.ParameterCodec).
 		Timeout(timeout).
@@ -156,6 +182,7 @@ func (c *networkNotifications) DeleteCollection(options *v1.DeleteOptions, listO
 func (c *networkNotifications) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.NetworkNotification, err error) {
 	result = &v1alpha1.NetworkNotification{}
 	err = c.client.Patch(pt).
+		Namespace(c.ns).
 		Resource("networknotifications").
 		SubResource(subresources...).
 		Name(name).
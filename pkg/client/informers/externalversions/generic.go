@@ -55,12 +55,18 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=networkmachinery.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithResource("networkauditrecords"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Networkmachinery().V1alpha1().NetworkAuditRecords().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("networkconnectivitytests"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networkmachinery().V1alpha1().NetworkConnectivityTests().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("networkexecpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Networkmachinery().V1alpha1().NetworkExecPolicies().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("networkmonitors"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networkmachinery().V1alpha1().NetworkMonitors().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("networknotifications"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networkmachinery().V1alpha1().NetworkNotifications().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("networknotificationroutes"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Networkmachinery().V1alpha1().NetworkNotificationRoutes().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("networktrafficshapers"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Networkmachinery().V1alpha1().NetworkTrafficShapers().Informer()}, nil
 
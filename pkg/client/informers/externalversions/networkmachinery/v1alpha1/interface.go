@@ -26,12 +26,18 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// NetworkAuditRecords returns a NetworkAuditRecordInformer.
+	NetworkAuditRecords() NetworkAuditRecordInformer
 	// NetworkConnectivityTests returns a NetworkConnectivityTestInformer.
 	NetworkConnectivityTests() NetworkConnectivityTestInformer
+	// NetworkExecPolicies returns a NetworkExecPolicyInformer.
+	NetworkExecPolicies() NetworkExecPolicyInformer
 	// NetworkMonitors returns a NetworkMonitorInformer.
 	NetworkMonitors() NetworkMonitorInformer
 	// NetworkNotifications returns a NetworkNotificationInformer.
 	NetworkNotifications() NetworkNotificationInformer
+	// NetworkNotificationRoutes returns a NetworkNotificationRouteInformer.
+	NetworkNotificationRoutes() NetworkNotificationRouteInformer
 	// NetworkTrafficShapers returns a NetworkTrafficShaperInformer.
 	NetworkTrafficShapers() NetworkTrafficShaperInformer
 }
@@ -47,11 +53,21 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// NetworkAuditRecords returns a NetworkAuditRecordInformer.
+func (v *version) NetworkAuditRecords() NetworkAuditRecordInformer {
+	return &networkAuditRecordInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // NetworkConnectivityTests returns a NetworkConnectivityTestInformer.
 func (v *version) NetworkConnectivityTests() NetworkConnectivityTestInformer {
 	return &networkConnectivityTestInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
 
+// NetworkExecPolicies returns a NetworkExecPolicyInformer.
+func (v *version) NetworkExecPolicies() NetworkExecPolicyInformer {
+	return &networkExecPolicyInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // NetworkMonitors returns a NetworkMonitorInformer.
 func (v *version) NetworkMonitors() NetworkMonitorInformer {
 	return &networkMonitorInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
@@ -59,7 +75,12 @@ func (v *version) NetworkMonitors() NetworkMonitorInformer {
 
 // NetworkNotifications returns a NetworkNotificationInformer.
 func (v *version) NetworkNotifications() NetworkNotificationInformer {
-	return &networkNotificationInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+	return &networkNotificationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// NetworkNotificationRoutes returns a NetworkNotificationRouteInformer.
+func (v *version) NetworkNotificationRoutes() NetworkNotificationRouteInformer {
+	return &networkNotificationRouteInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
 // NetworkTrafficShapers returns a NetworkTrafficShaperInformer.
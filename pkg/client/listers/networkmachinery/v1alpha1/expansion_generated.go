@@ -20,10 +20,18 @@ under the License.
 
 package v1alpha1
 
+// NetworkAuditRecordListerExpansion allows custom methods to be added to
+// NetworkAuditRecordLister.
+type NetworkAuditRecordListerExpansion interface{}
+
 // NetworkConnectivityTestListerExpansion allows custom methods to be added to
 // NetworkConnectivityTestLister.
 type NetworkConnectivityTestListerExpansion interface{}
 
+// NetworkExecPolicyListerExpansion allows custom methods to be added to
+// NetworkExecPolicyLister.
+type NetworkExecPolicyListerExpansion interface{}
+
 // NetworkMonitorListerExpansion allows custom methods to be added to
 // NetworkMonitorLister.
 type NetworkMonitorListerExpansion interface{}
@@ -32,6 +40,18 @@ type NetworkMonitorListerExpansion interface{}
 // NetworkNotificationLister.
 type NetworkNotificationListerExpansion interface{}
 
+// NetworkNotificationNamespaceListerExpansion allows custom methods to be added to
+// NetworkNotificationNamespaceLister.
+type NetworkNotificationNamespaceListerExpansion interface{}
+
+// NetworkNotificationRouteListerExpansion allows custom methods to be added to
+// NetworkNotificationRouteLister.
+type NetworkNotificationRouteListerExpansion interface{}
+
+// NetworkNotificationRouteNamespaceListerExpansion allows custom methods to be added to
+// NetworkNotificationRouteNamespaceLister.
+type NetworkNotificationRouteNamespaceListerExpansion interface{}
+
 // NetworkTrafficShaperListerExpansion allows custom methods to be added to
 // NetworkTrafficShaperLister.
 type NetworkTrafficShaperListerExpansion interface{}
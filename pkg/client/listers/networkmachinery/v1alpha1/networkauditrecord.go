@@ -0,0 +1,67 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NetworkAuditRecordLister helps list NetworkAuditRecords.
+type NetworkAuditRecordLister interface {
+	// List lists all NetworkAuditRecords in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.NetworkAuditRecord, err error)
+	// Get retrieves the NetworkAuditRecord from the index for a given name.
+	Get(name string) (*v1alpha1.NetworkAuditRecord, error)
+	NetworkAuditRecordListerExpansion
+}
+
+// networkAuditRecordLister implements the NetworkAuditRecordLister interface.
+type networkAuditRecordLister struct {
+	indexer cache.Indexer
+}
+
+// NewNetworkAuditRecordLister returns a new NetworkAuditRecordLister.
+func NewNetworkAuditRecordLister(indexer cache.Indexer) NetworkAuditRecordLister {
+	return &networkAuditRecordLister{indexer: indexer}
+}
+
+// List lists all NetworkAuditRecords in the indexer.
+func (s *networkAuditRecordLister) List(selector labels.Selector) (ret []*v1alpha1.NetworkAuditRecord, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.NetworkAuditRecord))
+	})
+	return ret, err
+}
+
+// Get retrieves the NetworkAuditRecord from the index for a given name.
+func (s *networkAuditRecordLister) Get(name string) (*v1alpha1.NetworkAuditRecord, error) {
+	obj, exists, err := s.indexer.GetByKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("networkauditrecord"), name)
+	}
+	return obj.(*v1alpha1.NetworkAuditRecord), nil
+}
@@ -31,8 +31,8 @@ import (
 type NetworkNotificationLister interface {
 	// List lists all NetworkNotifications in the indexer.
 	List(selector labels.Selector) (ret []*v1alpha1.NetworkNotification, err error)
-	// Get retrieves the NetworkNotification from the index for a given name.
-	Get(name string) (*v1alpha1.NetworkNotification, error)
+	// NetworkNotifications returns an object that can list and get NetworkNotifications.
+	NetworkNotifications(namespace string) NetworkNotificationNamespaceLister
 	NetworkNotificationListerExpansion
 }
 
@@ -54,9 +54,38 @@ func (s *networkNotificationLister) List(selector labels.Selector) (ret []*v1alp
 	return ret, err
 }
 
-// Get retrieves the NetworkNotification from the index for a given name.
-func (s *networkNotificationLister) Get(name string) (*v1alpha1.NetworkNotification, error) {
-	obj, exists, err := s.indexer.GetByKey(name)
+// NetworkNotifications returns an object that can list and get NetworkNotifications.
+func (s *networkNotificationLister) NetworkNotifications(namespace string) NetworkNotificationNamespaceLister {
+	return networkNotificationNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// NetworkNotificationNamespaceLister helps list and get NetworkNotifications.
+type NetworkNotificationNamespaceLister interface {
+	// List lists all NetworkNotifications in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.NetworkNotification, err error)
+	// Get retrieves the NetworkNotification from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.NetworkNotification, error)
+	NetworkNotificationNamespaceListerExpansion
+}
+
+// networkNotificationNamespaceLister implements the NetworkNotificationNamespaceLister
+// interface.
+type networkNotificationNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all NetworkNotifications in the indexer for a given namespace.
+func (s networkNotificationNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.NetworkNotification, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.NetworkNotification))
+	})
+	return ret, err
+}
+
+// Get retrieves the NetworkNotification from the indexer for a given namespace and name.
+func (s networkNotificationNamespaceLister) Get(name string) (*v1alpha1.NetworkNotification, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
 	if err != nil {
 		return nil, err
 	}
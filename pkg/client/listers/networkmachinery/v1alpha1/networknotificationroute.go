@@ -0,0 +1,96 @@
+/*
+Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NetworkNotificationRouteLister helps list NetworkNotificationRoutes.
+type NetworkNotificationRouteLister interface {
+	// List lists all NetworkNotificationRoutes in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.NetworkNotificationRoute, err error)
+	// NetworkNotificationRoutes returns an object that can list and get NetworkNotificationRoutes.
+	NetworkNotificationRoutes(namespace string) NetworkNotificationRouteNamespaceLister
+	NetworkNotificationRouteListerExpansion
+}
+
+// networkNotificationRouteLister implements the NetworkNotificationRouteLister interface.
+type networkNotificationRouteLister struct {
+	indexer cache.Indexer
+}
+
+// NewNetworkNotificationRouteLister returns a new NetworkNotificationRouteLister.
+func NewNetworkNotificationRouteLister(indexer cache.Indexer) NetworkNotificationRouteLister {
+	return &networkNotificationRouteLister{indexer: indexer}
+}
+
+// List lists all NetworkNotificationRoutes in the indexer.
+func (s *networkNotificationRouteLister) List(selector labels.Selector) (ret []*v1alpha1.NetworkNotificationRoute, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.NetworkNotificationRoute))
+	})
+	return ret, err
+}
+
+// NetworkNotificationRoutes returns an object that can list and get NetworkNotificationRoutes.
+func (s *networkNotificationRouteLister) NetworkNotificationRoutes(namespace string) NetworkNotificationRouteNamespaceLister {
+	return networkNotificationRouteNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// NetworkNotificationRouteNamespaceLister helps list and get NetworkNotificationRoutes.
+type NetworkNotificationRouteNamespaceLister interface {
+	// List lists all NetworkNotificationRoutes in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.NetworkNotificationRoute, err error)
+	// Get retrieves the NetworkNotificationRoute from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.NetworkNotificationRoute, error)
+	NetworkNotificationRouteNamespaceListerExpansion
+}
+
+// networkNotificationRouteNamespaceLister implements the NetworkNotificationRouteNamespaceLister
+// interface.
+type networkNotificationRouteNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all NetworkNotificationRoutes in the indexer for a given namespace.
+func (s networkNotificationRouteNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.NetworkNotificationRoute, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.NetworkNotificationRoute))
+	})
+	return ret, err
+}
+
+// Get retrieves the NetworkNotificationRoute from the indexer for a given namespace and name.
+func (s networkNotificationRouteNamespaceLister) Get(name string) (*v1alpha1.NetworkNotificationRoute, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("networknotificationroute"), name)
+	}
+	return obj.(*v1alpha1.NetworkNotificationRoute), nil
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/collector"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// SFlowCollectorCmdOptions are the options of the standalone sFlow collector.
+type SFlowCollectorCmdOptions struct {
+	// Address is the UDP address sFlow v5 datagrams are received on.
+	Address string
+	// APIAddress is the address the sFlow-RT compatible REST API is served on.
+	APIAddress string
+}
+
+// AddFlags adds the flags of the sFlow collector to <flags>.
+func (o *SFlowCollectorCmdOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.Address, "address", o.Address, "UDP address sFlow v5 datagrams are received on")
+	flags.StringVar(&o.APIAddress, "api-address", o.APIAddress, "address the sFlow-RT compatible REST API is served on")
+}
+
+// NewSFlowCollectorCmd returns a command running a collector of sFlow v5 datagrams which serves the flows, thresholds
+// and events NetworkMonitors use with the REST API of sFlow-RT, i.e. it can replace an sFlow-RT deployment.
+// The collector is configured by <collectorOptions>, the address flag overrides their sFlow address.
+func NewSFlowCollectorCmd(ctx context.Context, collectorOptions *collector.Options) *cobra.Command {
+	cmdOpts := SFlowCollectorCmdOptions{
+		Address:    ":6343",
+		APIAddress: ":8008",
+	}
+
+	cmd := &cobra.Command{
+		Use:   "sflow-collector",
+		Short: "Collect sFlow v5 datagrams and serve flows, thresholds and events like sFlow-RT",
+		Run: func(cmd *cobra.Command, args []string) {
+			logger := log.Log.WithName("sflow-collector")
+			options := *collectorOptions
+			options.SFlowAddress = cmdOpts.Address
+			c := collector.New(&options)
+
+			server := &http.Server{Addr: cmdOpts.APIAddress, Handler: collector.NewHandler(c)}
+			go func() {
+				<-ctx.Done()
+				_ = server.Close()
+			}()
+			go func() {
+				logger.Info("Serving the REST API", "Address", cmdOpts.APIAddress)
+				if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					utils.LogErrAndExit(err, "Could not serve the REST API")
+				}
+			}()
+
+			if err := c.Start(ctx.Done()); err != nil {
+				utils.LogErrAndExit(err, "Error running the collector")
+			}
+		},
+	}
+
+	cmdOpts.AddFlags(cmd.Flags())
+	return cmd
+}
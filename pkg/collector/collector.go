@@ -0,0 +1,569 @@
+// Package collector is a flow collector which evaluates the flows and thresholds of sFlow-RT in-process. It aggregates
+// the flow samples and counters of sFlow v5 and the records of NetFlow v9 and IPFIX datagrams received on UDP.
+package collector
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// Collector holds flows and thresholds like sFlow-RT. The rates of the keys of its flows are computed from the records
+// it ingests, every key exceeding a threshold raises an event until its rate falls below the threshold again.
+type Collector struct {
+	options *Options
+	clock   clock.Clock
+	logger  logr.Logger
+	decoder *netFlowDecoder
+
+	mu         sync.Mutex
+	flows      map[string]*flow
+	interfaces map[string]*interfaceState
+	thresholds map[string]v1alpha1.Threshold
+	groups     map[string]sflowrt.Groups
+	// networks are the parsed networks of the groups
+	networks map[string][]groupNetwork
+	// exceeded holds the flow keys which exceeded a threshold, by the name of the threshold. Thresholds which are not
+	// by flow use an empty key.
+	exceeded    map[string]sets.String
+	events      []v1alpha1.Event
+	lastEventID int32
+	// newEvents is closed when events are raised, long-polls wait for it
+	newEvents  chan struct{}
+	lastExpiry time.Time
+}
+
+type flow struct {
+	definition v1alpha1.Flow
+	keys       []string
+	// filter selects the records of the flow, all records match if it is nil
+	filter filter
+	rates  map[string]*rate
+}
+
+// groupNetwork is a network of an address group.
+type groupNetwork struct {
+	group   string
+	network *net.IPNet
+}
+
+// rate is the exponentially weighted rate of a flow key per second.
+type rate struct {
+	value      float64
+	updated    time.Time
+	agent      string
+	dataSource string
+}
+
+// at returns the rate decayed to <now>.
+func (r *rate) at(now time.Time, smoothing time.Duration) float64 {
+	return r.value * math.Exp(-now.Sub(r.updated).Seconds()/smoothing.Seconds())
+}
+
+// New returns a collector configured by <options>.
+func New(options *Options) *Collector {
+	return &Collector{
+		options:    options,
+		clock:      clock.RealClock{},
+		logger:     log.Log.WithName("collector"),
+		decoder:    newNetFlowDecoder(),
+		flows:      map[string]*flow{},
+		interfaces: map[string]*interfaceState{},
+		thresholds: map[string]v1alpha1.Threshold{},
+		groups:     map[string]sflowrt.Groups{},
+		networks:   map[string][]groupNetwork{},
+		exceeded:   map[string]sets.String{},
+		newEvents:  make(chan struct{}),
+	}
+}
+
+// Flows returns the flows defined in the collector by their names.
+func (c *Collector) Flows(ctx context.Context) (map[string]v1alpha1.Flow, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	flows := make(map[string]v1alpha1.Flow, len(c.flows))
+	for name, flow := range c.flows {
+		flows[name] = flow.definition
+	}
+	return flows, nil
+}
+
+// ValidateFlow returns an error if the keys, value or filter of <definition> are not supported by the collector.
+// Groups of addresses in the filter are not resolved, they may be defined after the flow.
+func ValidateFlow(definition v1alpha1.Flow) error {
+	if _, err := parseKeys(definition.Keys); err != nil {
+		return err
+	}
+	if err := validValue(definition.Value); err != nil {
+		return err
+	}
+	if len(definition.Filter) > 0 {
+		if _, err := parseFilter(definition.Filter, func(string, string) (string, bool) { return "", false }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PutFlow defines <definition> or replaces its definition, the rates of a flow are reset if its definition changes.
+func (c *Collector) PutFlow(ctx context.Context, definition v1alpha1.Flow) error {
+	keys, err := parseKeys(definition.Keys)
+	if err != nil {
+		return fmt.Errorf("flow %s: %v", definition.Name, err)
+	}
+	if err := validValue(definition.Value); err != nil {
+		return fmt.Errorf("flow %s: %v", definition.Name, err)
+	}
+	var matches filter
+	if len(definition.Filter) > 0 {
+		if matches, err = parseFilter(definition.Filter, c.groupOf); err != nil {
+			return fmt.Errorf("flow %s: %v", definition.Name, err)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if current, ok := c.flows[definition.Name]; ok && current.definition == definition {
+		return nil
+	}
+	c.flows[definition.Name] = &flow{definition: definition, keys: keys, filter: matches, rates: map[string]*rate{}}
+	c.resetThresholdsOf(definition.Name)
+	return nil
+}
+
+// DeleteFlow deletes the flow <name>, flows which do not exist are ignored.
+func (c *Collector) DeleteFlow(ctx context.Context, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.flows, name)
+	c.resetThresholdsOf(name)
+	return nil
+}
+
+// ActiveFlows returns the largest <maxFlows> rates of the flow <name> reported by <agent>, "ALL" for all agents, the
+// largest first. All rates are returned if <maxFlows> is not positive, flows which do not exist have no rates.
+func (c *Collector) ActiveFlows(ctx context.Context, agent, name string, maxFlows int) ([]sflowrt.ActiveFlow, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	flow, ok := c.flows[name]
+	if !ok {
+		return nil, nil
+	}
+
+	now := c.clock.Now()
+	flows := make([]sflowrt.ActiveFlow, 0, len(flow.rates))
+	for key, r := range flow.rates {
+		if agent != "ALL" && agent != r.agent {
+			continue
+		}
+		flows = append(flows, sflowrt.ActiveFlow{Key: key, Value: r.at(now, c.options.Smoothing), Agent: r.agent, DataSource: r.dataSource})
+	}
+	sort.Slice(flows, func(i, j int) bool {
+		if flows[i].Value != flows[j].Value {
+			return flows[i].Value > flows[j].Value
+		}
+		return flows[i].Key < flows[j].Key
+	})
+	if maxFlows > 0 && len(flows) > maxFlows {
+		flows = flows[:maxFlows]
+	}
+	return flows, nil
+}
+
+// Thresholds returns the thresholds defined in the collector by their names.
+func (c *Collector) Thresholds(ctx context.Context) (map[string]v1alpha1.Threshold, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	thresholds := make(map[string]v1alpha1.Threshold, len(c.thresholds))
+	for name, threshold := range c.thresholds {
+		thresholds[name] = threshold
+	}
+	return thresholds, nil
+}
+
+// PutThreshold defines <threshold> or replaces its definition. Thresholds apply to the flow named by their metric.
+func (c *Collector) PutThreshold(ctx context.Context, threshold v1alpha1.Threshold) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if current, ok := c.thresholds[threshold.Name]; ok && current == threshold {
+		return nil
+	}
+	c.thresholds[threshold.Name] = threshold
+	delete(c.exceeded, threshold.Name)
+	return nil
+}
+
+// DeleteThreshold deletes the threshold <name>, thresholds which do not exist are ignored.
+func (c *Collector) DeleteThreshold(ctx context.Context, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.thresholds, name)
+	delete(c.exceeded, name)
+	return nil
+}
+
+// Groups returns the address groups defined in the collector by their names.
+func (c *Collector) Groups(ctx context.Context) (map[string]sflowrt.Groups, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	groups := make(map[string]sflowrt.Groups, len(c.groups))
+	for name, group := range c.groups {
+		groups[name] = group
+	}
+	return groups, nil
+}
+
+// PutGroup defines the address <groups> <name> or replaces their definition. Filters refer to them by keys like
+// "group:ipsource:<name>", the value of such a key is the group with the longest prefix containing the address.
+func (c *Collector) PutGroup(ctx context.Context, name string, groups sflowrt.Groups) error {
+	var networks []groupNetwork
+	for group, cidrs := range groups {
+		for _, cidr := range cidrs {
+			// single addresses are host networks
+			if !strings.Contains(cidr, "/") && strings.Contains(cidr, ":") {
+				cidr += "/128"
+			} else if !strings.Contains(cidr, "/") {
+				cidr += "/32"
+			}
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return fmt.Errorf("group %s: %v", name, err)
+			}
+			networks = append(networks, groupNetwork{group: group, network: network})
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.groups[name] = groups
+	c.networks[name] = networks
+	return nil
+}
+
+// DeleteGroup deletes the address groups <name>, groups which do not exist are ignored.
+func (c *Collector) DeleteGroup(ctx context.Context, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.groups, name)
+	delete(c.networks, name)
+	return nil
+}
+
+// groupOf returns the group of the address groups <name> with the longest prefix containing <address>, it is called
+// with the lock held.
+func (c *Collector) groupOf(name, address string) (string, bool) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return "", false
+	}
+	group, longest := "", -1
+	for _, n := range c.networks[name] {
+		if !n.network.Contains(ip) {
+			continue
+		}
+		if ones, _ := n.network.Mask.Size(); ones > longest || (ones == longest && n.group < group) {
+			group, longest = n.group, ones
+		}
+	}
+	return group, longest >= 0
+}
+
+// Events returns the threshold events selected by <query>, the most recent first. The request waits up to the
+// timeout of the query for new events if there are none.
+func (c *Collector) Events(ctx context.Context, query sflowrt.EventsQuery) ([]v1alpha1.Event, error) {
+	var deadline <-chan time.Time
+	if query.Timeout > 0 {
+		timer := time.NewTimer(query.Timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		c.mu.Lock()
+		var events []v1alpha1.Event
+		for i := len(c.events) - 1; i >= 0 && c.events[i].EventID > query.EventID; i-- {
+			if query.MaxEvents > 0 && len(events) == query.MaxEvents {
+				break
+			}
+			events = append(events, c.events[i])
+		}
+		newEvents := c.newEvents
+		c.mu.Unlock()
+
+		if len(events) > 0 || deadline == nil {
+			return events, nil
+		}
+		select {
+		case <-newEvents:
+		case <-deadline:
+			deadline = nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Ingest adds the counters of <records> to the rates of the flows they match and raises the events of the exceeded
+// thresholds.
+func (c *Collector) Ingest(records ...Record) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	c.expire(now)
+	raised := false
+	for i := range records {
+		record := &records[i]
+		for name, flow := range c.flows {
+			if flow.filter != nil && !flow.filter(record) {
+				continue
+			}
+			key, ok := record.key(flow.keys)
+			if !ok {
+				continue
+			}
+			r, ok := flow.rates[key]
+			if !ok {
+				r = &rate{}
+				flow.rates[key] = r
+			}
+			previous := r.at(now, c.options.Smoothing)
+			r.value = previous + float64(record.value(flow.definition.Value))/c.options.Smoothing.Seconds()
+			r.updated, r.agent, r.dataSource = now, record.Agent, record.DataSource
+
+			if c.evaluate(now, name, flow, key, previous) {
+				raised = true
+			}
+		}
+	}
+
+	if raised {
+		close(c.newEvents)
+		c.newEvents = make(chan struct{})
+	}
+}
+
+// evaluate checks the thresholds of the flow <name> after the rate of <key> changed from <previous>, it returns true
+// if events were raised.
+func (c *Collector) evaluate(now time.Time, name string, flow *flow, key string, previous float64) bool {
+	raised := false
+	for _, thresholdName := range c.thresholdsOf(name) {
+		byFlow, _ := strconv.ParseBool(c.thresholds[thresholdName].ByFlow)
+		if byFlow {
+			// the rate may have fallen below the threshold since the key was seen
+			c.rearm(thresholdName, key, previous)
+			r := flow.rates[key]
+			if c.exceeds(thresholdName, key, r.value) {
+				c.addEvent(now, thresholdName, name, r.value, r.agent, r.dataSource, key)
+				raised = true
+			}
+			continue
+		}
+
+		top, value := largest(now, c.options.Smoothing, flow.rates)
+		if c.exceeds(thresholdName, "", value) {
+			c.addEvent(now, thresholdName, name, value, flow.rates[top].agent, flow.rates[top].dataSource, "")
+			raised = true
+		}
+	}
+	return raised
+}
+
+// exceeds records whether <value> of <key> exceeds the threshold <name>, it returns true if the key did not exceed
+// the threshold before.
+func (c *Collector) exceeds(name, key string, value float64) bool {
+	exceeded, ok := c.exceeded[name]
+	if !ok {
+		exceeded = sets.NewString()
+		c.exceeded[name] = exceeded
+	}
+	if value <= float64(c.thresholds[name].Value) {
+		exceeded.Delete(key)
+		return false
+	}
+	if exceeded.Has(key) {
+		return false
+	}
+	exceeded.Insert(key)
+	return true
+}
+
+// rearm forgets that <key> exceeded the threshold <name> if <value> does not exceed it.
+func (c *Collector) rearm(name, key string, value float64) {
+	if exceeded, ok := c.exceeded[name]; ok && value <= float64(c.thresholds[name].Value) {
+		exceeded.Delete(key)
+	}
+}
+
+// addEvent raises an event of the threshold <name> of <metric>, only the most recent events are kept.
+func (c *Collector) addEvent(now time.Time, name, metric string, value float64, agent, dataSource, flowKey string) {
+	c.lastEventID++
+	c.events = append(c.events, v1alpha1.Event{
+		EventID:     c.lastEventID,
+		Threshold:   c.thresholds[name].Value,
+		TimeStamp:   now.UnixNano() / int64(time.Millisecond),
+		Value:       float32(value),
+		Metric:      metric,
+		ThresholdID: name,
+		Agent:       agent,
+		DataSource:  dataSource,
+		FlowKey:     flowKey,
+	})
+	if len(c.events) > c.options.MaxEvents {
+		c.events = c.events[len(c.events)-c.options.MaxEvents:]
+	}
+}
+
+// thresholdsOf returns the names of the thresholds of the flow <name>.
+func (c *Collector) thresholdsOf(name string) []string {
+	var names []string
+	for thresholdName, threshold := range c.thresholds {
+		if threshold.Metric == name {
+			names = append(names, thresholdName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resetThresholdsOf forgets which keys exceeded the thresholds of the flow <name>.
+func (c *Collector) resetThresholdsOf(name string) {
+	for _, thresholdName := range c.thresholdsOf(name) {
+		delete(c.exceeded, thresholdName)
+	}
+}
+
+// expire forgets the rates of flow keys and the counters of interfaces which were not seen for the flow timeout.
+func (c *Collector) expire(now time.Time) {
+	if now.Sub(c.lastExpiry) < c.options.FlowTimeout/2 {
+		return
+	}
+	c.lastExpiry = now
+	for key, state := range c.interfaces {
+		if now.Sub(state.updated) > c.options.FlowTimeout {
+			delete(c.interfaces, key)
+		}
+	}
+	for name, flow := range c.flows {
+		for key, r := range flow.rates {
+			if now.Sub(r.updated) <= c.options.FlowTimeout {
+				continue
+			}
+			delete(flow.rates, key)
+			for _, thresholdName := range c.thresholdsOf(name) {
+				if exceeded, ok := c.exceeded[thresholdName]; ok {
+					exceeded.Delete(key)
+				}
+			}
+		}
+	}
+}
+
+// largest returns the key with the largest rate of <rates> at <now>.
+func largest(now time.Time, smoothing time.Duration, rates map[string]*rate) (string, float64) {
+	top, value := "", -1.0
+	for key, r := range rates {
+		if v := r.at(now, smoothing); v > value || (v == value && key < top) {
+			top, value = key, v
+		}
+	}
+	return top, value
+}
+
+// HandleDatagram ingests the flow records and counters of an sFlow v5, NetFlow v9 or IPFIX datagram received from
+// <agent>. The agent of sFlow records is the agent address of the datagram.
+func (c *Collector) HandleDatagram(agent string, data []byte) (err error) {
+	// datagrams are received from the network, a decoding bug must not stop the collector
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("could not decode datagram: %v", r)
+		}
+	}()
+
+	var (
+		records  []Record
+		counters []Counters
+	)
+	if isSFlow(data) {
+		records, counters, err = decodeSFlow(data)
+	} else {
+		records, err = c.decoder.decode(agent, data)
+	}
+	if len(records) > 0 {
+		c.Ingest(records...)
+	}
+	if len(counters) > 0 {
+		c.IngestCounters(counters...)
+	}
+	return err
+}
+
+// Serve handles the datagrams received on <conn> until <stop> is closed.
+func (c *Collector) Serve(conn net.PacketConn, stop <-chan struct{}) error {
+	buffer := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buffer)
+		if err != nil {
+			select {
+			case <-stop:
+				return nil
+			default:
+				return err
+			}
+		}
+		agent := addr.String()
+		if udpAddr, ok := addr.(*net.UDPAddr); ok {
+			agent = udpAddr.IP.String()
+		}
+		if err := c.HandleDatagram(agent, buffer[:n]); err != nil {
+			c.logger.Error(err, "Could not decode datagram", "Agent", agent)
+		}
+	}
+}
+
+// Start receives datagrams on the sFlow and NetFlow addresses until <stop> is closed, it implements manager.Runnable.
+func (c *Collector) Start(stop <-chan struct{}) error {
+	var conns []net.PacketConn
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+	for _, address := range []string{c.options.SFlowAddress, c.options.NetFlowAddress} {
+		if len(address) == 0 {
+			continue
+		}
+		conn, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return fmt.Errorf("could not listen for datagrams on %s: %v", address, err)
+		}
+		conns = append(conns, conn)
+	}
+
+	errs := make(chan error, len(conns))
+	for _, conn := range conns {
+		c.logger.Info("Receiving datagrams", "Address", conn.LocalAddr().String())
+		go func(conn net.PacketConn) {
+			errs <- c.Serve(conn, stop)
+		}(conn)
+	}
+	select {
+	case <-stop:
+		return nil
+	case err := <-errs:
+		return err
+	}
+}
@@ -0,0 +1,338 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+var (
+	ctx = context.TODO()
+	now = time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func newTestCollector() (*Collector, *clock.FakeClock) {
+	c := New(&Options{Smoothing: 10 * time.Second, FlowTimeout: time.Minute, MaxEvents: 10})
+	fakeClock := clock.NewFakeClock(now)
+	c.clock = fakeClock
+	return c, fakeClock
+}
+
+func tcpRecord(source, destination string, bytes uint64) Record {
+	return Record{
+		Agent:      "10.0.0.254",
+		DataSource: "3",
+		Fields:     map[string]string{KeyIPSource: source, KeyIPDestination: destination, KeyIPProtocol: "6"},
+		Bytes:      bytes,
+	}
+}
+
+func events(t *testing.T, c *Collector, query sflowrt.EventsQuery) []v1alpha1.Event {
+	events, err := c.Events(ctx, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestDefinitions(t *testing.T) {
+	c, _ := newTestCollector()
+
+	for _, flow := range []v1alpha1.Flow{
+		{Name: "filtered", Keys: "ipsource", Value: "bytes", Filter: "ipprotocol~6"},
+		{Name: "unknown", Keys: "ipsource,dnsqname", Value: "bytes"},
+		{Name: "requests", Keys: "ipsource", Value: "requests"},
+	} {
+		if err := c.PutFlow(ctx, flow); err == nil {
+			t.Errorf("expected flow %s to be rejected", flow.Name)
+		}
+	}
+
+	flow := v1alpha1.Flow{Name: "tcp", Keys: "ipsource,ipdestination", Value: "bytes"}
+	threshold := v1alpha1.Threshold{Name: "elephant", Metric: "tcp", Value: 1000, ByFlow: "true"}
+	if err := c.PutFlow(ctx, flow); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PutThreshold(ctx, threshold); err != nil {
+		t.Fatal(err)
+	}
+	if flows, _ := c.Flows(ctx); len(flows) != 1 || flows["tcp"] != flow {
+		t.Errorf("expected flow %+v, got %v", flow, flows)
+	}
+	if thresholds, _ := c.Thresholds(ctx); len(thresholds) != 1 || thresholds["elephant"] != threshold {
+		t.Errorf("expected threshold %+v, got %v", threshold, thresholds)
+	}
+
+	if err := c.DeleteFlow(ctx, "tcp"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteThreshold(ctx, "elephant"); err != nil {
+		t.Fatal(err)
+	}
+	flows, _ := c.Flows(ctx)
+	thresholds, _ := c.Thresholds(ctx)
+	if len(flows) != 0 || len(thresholds) != 0 {
+		t.Errorf("expected all definitions to be deleted, got %v and %v", flows, thresholds)
+	}
+}
+
+func TestThresholdByFlow(t *testing.T) {
+	c, fakeClock := newTestCollector()
+	_ = c.PutFlow(ctx, v1alpha1.Flow{Name: "tcp", Keys: "ipsource,ipdestination", Value: "bytes"})
+	_ = c.PutThreshold(ctx, v1alpha1.Threshold{Name: "elephant", Metric: "tcp", Value: 1000, ByFlow: "true"})
+
+	// 20000 bytes are a rate of 2000 bytes per second with a smoothing of 10 seconds
+	c.Ingest(tcpRecord("10.0.0.1", "10.0.0.2", 20000), tcpRecord("10.0.0.1", "10.0.0.3", 5000))
+	c.Ingest(tcpRecord("10.0.0.1", "10.0.0.2", 20000))
+	raised := events(t, c, sflowrt.EventsQuery{})
+	if len(raised) != 1 {
+		t.Fatalf("expected one event, got %+v", raised)
+	}
+	expected := v1alpha1.Event{
+		EventID: 1, Threshold: 1000, TimeStamp: now.UnixNano() / int64(time.Millisecond), Value: 2000, Metric: "tcp",
+		ThresholdID: "elephant", Agent: "10.0.0.254", DataSource: "3", FlowKey: "10.0.0.1,10.0.0.2",
+	}
+	if raised[0] != expected {
+		t.Errorf("expected event %+v, got %+v", expected, raised[0])
+	}
+
+	// the threshold is raised again once the rate fell below it
+	fakeClock.Step(30 * time.Second)
+	c.Ingest(tcpRecord("10.0.0.1", "10.0.0.2", 20000))
+	raised = events(t, c, sflowrt.EventsQuery{EventID: 1})
+	if len(raised) != 1 || raised[0].EventID != 2 {
+		t.Errorf("expected a second event, got %+v", raised)
+	}
+
+	// keys which were not seen for the flow timeout are forgotten
+	fakeClock.Step(2 * time.Minute)
+	c.Ingest(tcpRecord("10.0.0.1", "10.0.0.3", 1))
+	if rates := c.flows["tcp"].rates; len(rates) != 1 {
+		t.Errorf("expected expired keys to be forgotten, got %v", rates)
+	}
+}
+
+func TestThresholdOfFlow(t *testing.T) {
+	c, _ := newTestCollector()
+	_ = c.PutFlow(ctx, v1alpha1.Flow{Name: "tcp", Keys: "ipsource,ipdestination", Value: "bytes"})
+	_ = c.PutThreshold(ctx, v1alpha1.Threshold{Name: "busy", Metric: "tcp", Value: 1000})
+
+	c.Ingest(tcpRecord("10.0.0.1", "10.0.0.2", 20000), tcpRecord("10.0.0.1", "10.0.0.3", 30000))
+	raised := events(t, c, sflowrt.EventsQuery{})
+	if len(raised) != 1 || raised[0].Value != 2000 || len(raised[0].FlowKey) != 0 {
+		t.Errorf("expected a single event of the flow, got %+v", raised)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	c, _ := newTestCollector()
+	_ = c.PutFlow(ctx, v1alpha1.Flow{Name: "tcp", Keys: "ipsource,ipdestination", Value: "bytes"})
+	_ = c.PutThreshold(ctx, v1alpha1.Threshold{Name: "elephant", Metric: "tcp", Value: 1000, ByFlow: "true"})
+	c.Ingest(tcpRecord("10.0.0.1", "10.0.0.2", 20000), tcpRecord("10.0.0.1", "10.0.0.3", 20000), tcpRecord("10.0.0.1", "10.0.0.4", 20000))
+
+	raised := events(t, c, sflowrt.EventsQuery{EventID: 1, MaxEvents: 1})
+	if len(raised) != 1 || raised[0].EventID != 3 {
+		t.Errorf("expected the most recent event, got %+v", raised)
+	}
+
+	// the long-poll returns as soon as an event is raised
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		c.Ingest(tcpRecord("10.0.0.1", "10.0.0.5", 20000))
+	}()
+	start := time.Now()
+	raised = events(t, c, sflowrt.EventsQuery{EventID: 3, Timeout: 10 * time.Second})
+	if len(raised) != 1 || raised[0].EventID != 4 || time.Since(start) > 5*time.Second {
+		t.Errorf("expected the new event before the timeout, got %+v after %s", raised, time.Since(start))
+	}
+}
+
+// message encodes <values> in network byte order.
+func message(values ...interface{}) []byte {
+	buffer := &bytes.Buffer{}
+	for _, value := range values {
+		_ = binary.Write(buffer, binary.BigEndian, value)
+	}
+	return buffer.Bytes()
+}
+
+// set returns the set <id> holding <values>, padded to a multiple of four bytes.
+func set(id uint16, values ...interface{}) []byte {
+	body := message(values...)
+	for len(body)%4 != 0 {
+		body = append(body, 0)
+	}
+	return append(message(id, uint16(len(body)+4)), body...)
+}
+
+func netFlowV9Datagram(sets ...[]byte) []byte {
+	datagram := message(uint16(9), uint16(len(sets)), uint32(1000), uint32(now.Unix()), uint32(1), uint32(7))
+	for _, s := range sets {
+		datagram = append(datagram, s...)
+	}
+	return datagram
+}
+
+func ipfixDatagram(sets ...[]byte) []byte {
+	var body []byte
+	for _, s := range sets {
+		body = append(body, s...)
+	}
+	return append(message(uint16(10), uint16(len(body)+16), uint32(now.Unix()), uint32(1), uint32(7)), body...)
+}
+
+func TestNetFlowV9(t *testing.T) {
+	c, _ := newTestCollector()
+	_ = c.PutFlow(ctx, v1alpha1.Flow{Name: "http", Keys: "ipsource,ipdestination,tcpdestinationport", Value: "frames"})
+	_ = c.PutThreshold(ctx, v1alpha1.Threshold{Name: "flood", Metric: "http", Value: 10, ByFlow: "true"})
+
+	template := set(0, uint16(256), uint16(9),
+		uint16(fieldBytes), uint16(4), uint16(fieldPackets), uint16(4), uint16(fieldProtocol), uint16(1),
+		uint16(fieldSourcePort), uint16(2), uint16(fieldSourceIPv4), uint16(4), uint16(fieldDestinationPort), uint16(2),
+		uint16(fieldDestinationIPv4), uint16(4), uint16(fieldInputInterface), uint16(2), uint16(fieldSamplingInterval), uint16(2))
+	data := set(256, uint32(1000), uint32(20), uint8(6), uint16(34567), [4]byte{10, 0, 0, 1}, uint16(80), [4]byte{10, 0, 0, 2}, uint16(3), uint16(10))
+
+	// data of unknown templates is skipped
+	if err := c.HandleDatagram("192.168.0.1", netFlowV9Datagram(data)); err != nil {
+		t.Fatal(err)
+	}
+	if raised := events(t, c, sflowrt.EventsQuery{}); len(raised) != 0 {
+		t.Fatalf("expected no events before the template was received, got %+v", raised)
+	}
+
+	if err := c.HandleDatagram("192.168.0.1", netFlowV9Datagram(template, data)); err != nil {
+		t.Fatal(err)
+	}
+	raised := events(t, c, sflowrt.EventsQuery{})
+	// 20 packets sampled 1 in 10 are a rate of 20 frames per second
+	if len(raised) != 1 || raised[0].FlowKey != "10.0.0.1,10.0.0.2,80" || raised[0].Value != 20 || raised[0].Agent != "192.168.0.1" || raised[0].DataSource != "3" {
+		t.Errorf("expected an event of the decoded record, got %+v", raised)
+	}
+
+	if err := c.HandleDatagram("192.168.0.1", []byte{0, 5, 0, 0}); err == nil {
+		t.Error("expected unsupported versions to be rejected")
+	}
+	if err := c.HandleDatagram("192.168.0.1", netFlowV9Datagram(template[:6])); err == nil {
+		t.Error("expected truncated sets to be rejected")
+	}
+}
+
+func TestIPFIX(t *testing.T) {
+	template := set(2, uint16(300), uint16(6),
+		uint16(fieldBytes), uint16(8), uint16(fieldProtocol), uint16(1),
+		uint16(0x8000|100), uint16(2), uint32(12345),
+		uint16(82), uint16(variableLength),
+		uint16(fieldSourceIPv6), uint16(16), uint16(fieldDestinationPort), uint16(2))
+	source := net.ParseIP("2001:db8::1")
+	data := set(300, uint64(4096), uint8(17), uint16(42), uint8(4), []byte("eth0"), []byte(source), uint16(53))
+
+	decoder := newNetFlowDecoder()
+	records, err := decoder.decode("192.168.0.1", ipfixDatagram(template, data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected one record, got %+v", records)
+	}
+	record := records[0]
+	if record.Bytes != 4096 || record.Fields[KeyIP6Source] != "2001:db8::1" || record.Fields[KeyUDPDestinationPort] != "53" || record.Fields[KeyIPProtocol] != "17" {
+		t.Errorf("unexpected record %+v", record)
+	}
+
+	// templates without fields are withdrawn
+	if _, err := decoder.decode("192.168.0.1", ipfixDatagram(set(2, uint16(300), uint16(0)))); err != nil {
+		t.Fatal(err)
+	}
+	if records, _ := decoder.decode("192.168.0.1", ipfixDatagram(data)); len(records) != 0 {
+		t.Errorf("expected the withdrawn template not to be used, got %+v", records)
+	}
+}
+
+// TestDecodeMalformedNetFlow decodes truncated and corrupted datagrams, they have to be rejected without panicking.
+func TestDecodeMalformedNetFlow(t *testing.T) {
+	v9Template := set(0, uint16(256), uint16(2), uint16(fieldBytes), uint16(4), uint16(fieldSourceIPv4), uint16(4))
+	v9Data := set(256, uint32(1000), [4]byte{10, 0, 0, 1})
+	ipfixTemplate := set(2, uint16(300), uint16(3),
+		uint16(fieldBytes), uint16(8), uint16(0x8000|100), uint16(2), uint32(12345), uint16(82), uint16(variableLength))
+	ipfixData := set(300, uint64(4096), uint16(42), uint8(255), uint16(4), []byte("eth0"))
+
+	tests := []struct {
+		name     string
+		datagram []byte
+	}{
+		{"empty", nil},
+		{"version only", []byte{0, 10}},
+		{"IPFIX length below its header", append(message(uint16(10), uint16(6)), make([]byte, 14)...)},
+		{"IPFIX length beyond the datagram", append(message(uint16(10), uint16(64)), make([]byte, 14)...)},
+		{"NetFlow v9 truncated header", message(uint16(9), uint16(1), uint32(1000))},
+		{"set length below its header", netFlowV9Datagram(message(uint16(256), uint16(2)))},
+		{"set length beyond the datagram", ipfixDatagram(message(uint16(300), uint16(400)))},
+		{"truncated template", netFlowV9Datagram(set(0, uint16(256), uint16(4), uint16(fieldBytes), uint16(4)))},
+		{"truncated enterprise number", ipfixDatagram(set(2, uint16(300), uint16(1), uint16(0x8000|100), uint16(2)))},
+	}
+	for _, test := range tests {
+		decoder := newNetFlowDecoder()
+		if _, err := decoder.decode("192.168.0.1", test.datagram); err == nil {
+			t.Errorf("%s: expected the datagram to be rejected", test.name)
+		}
+	}
+
+	// every truncation and single byte corruption of valid datagrams is decoded without panicking
+	for _, datagram := range [][]byte{netFlowV9Datagram(v9Template, v9Data), ipfixDatagram(ipfixTemplate, ipfixData)} {
+		for n := 0; n <= len(datagram); n++ {
+			_, _ = newNetFlowDecoder().decode("192.168.0.1", datagram[:n])
+		}
+		for i := range datagram {
+			for _, b := range []byte{0x00, 0x01, 0x7f, 0x80, 0xff} {
+				corrupted := append([]byte(nil), datagram...)
+				corrupted[i] = b
+				decoder := newNetFlowDecoder()
+				_, _ = decoder.decode("192.168.0.1", corrupted)
+				// the corrupted template may be stored, the valid datagram decodes against it
+				_, _ = decoder.decode("192.168.0.1", datagram)
+			}
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+	c, _ := newTestCollector()
+	_ = c.PutFlow(ctx, v1alpha1.Flow{Name: "tcp", Keys: "ipsource", Value: "bytes"})
+	_ = c.PutThreshold(ctx, v1alpha1.Threshold{Name: "elephant", Metric: "tcp", Value: 1, ByFlow: "true"})
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- c.Serve(conn, stop) }()
+
+	sender, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sender.Close()
+	_, _ = sender.Write(netFlowV9Datagram(
+		set(0, uint16(256), uint16(2), uint16(fieldBytes), uint16(4), uint16(fieldSourceIPv4), uint16(4)),
+		set(256, uint32(1000), [4]byte{10, 0, 0, 1}),
+	))
+
+	raised := events(t, c, sflowrt.EventsQuery{Timeout: 5 * time.Second})
+	if len(raised) != 1 || raised[0].FlowKey != "10.0.0.1" || raised[0].Agent != "127.0.0.1" {
+		t.Errorf("expected an event of the received datagram, got %+v", raised)
+	}
+
+	close(stop)
+	conn.Close()
+	if err := <-done; err != nil {
+		t.Errorf("expected serving to stop without an error, got %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Metrics computed from interface counters, they are named like the ones of sFlow-RT. Counts are rates per second,
+// utilizations percentages of the speed of the interface.
+const (
+	MetricInOctets       = "ifinoctets"
+	MetricOutOctets      = "ifoutoctets"
+	MetricInPackets      = "ifinpkts"
+	MetricOutPackets     = "ifoutpkts"
+	MetricInErrors       = "ifinerrors"
+	MetricOutErrors      = "ifouterrors"
+	MetricInDiscards     = "ifindiscards"
+	MetricOutDiscards    = "ifoutdiscards"
+	MetricInUtilization  = "ifinutilization"
+	MetricOutUtilization = "ifoututilization"
+)
+
+// Counters are the counters of an interface of an agent.
+type Counters struct {
+	Agent string
+	// DataSource is the ifIndex of the interface.
+	DataSource string
+	// Speed is the speed of the interface in bits per second.
+	Speed       uint64
+	InOctets    uint64
+	InPackets   uint64
+	InErrors    uint64
+	InDiscards  uint64
+	OutOctets   uint64
+	OutPackets  uint64
+	OutErrors   uint64
+	OutDiscards uint64
+}
+
+type interfaceState struct {
+	counters Counters
+	updated  time.Time
+	metrics  map[string]float64
+}
+
+// metrics returns the metrics of the interface computed from the <current> counters, <elapsed> after the <previous>
+// ones. It returns false if a counter was reset.
+func metrics(previous, current *Counters, elapsed time.Duration) (map[string]float64, bool) {
+	seconds := elapsed.Seconds()
+	values := map[string]float64{}
+	for name, counter := range map[string][2]uint64{
+		MetricInOctets:    {previous.InOctets, current.InOctets},
+		MetricOutOctets:   {previous.OutOctets, current.OutOctets},
+		MetricInPackets:   {previous.InPackets, current.InPackets},
+		MetricOutPackets:  {previous.OutPackets, current.OutPackets},
+		MetricInErrors:    {previous.InErrors, current.InErrors},
+		MetricOutErrors:   {previous.OutErrors, current.OutErrors},
+		MetricInDiscards:  {previous.InDiscards, current.InDiscards},
+		MetricOutDiscards: {previous.OutDiscards, current.OutDiscards},
+	} {
+		if counter[1] < counter[0] {
+			return nil, false
+		}
+		values[name] = float64(counter[1]-counter[0]) / seconds
+	}
+	if current.Speed > 0 {
+		values[MetricInUtilization] = values[MetricInOctets] * 8 * 100 / float64(current.Speed)
+		values[MetricOutUtilization] = values[MetricOutOctets] * 8 * 100 / float64(current.Speed)
+	}
+	return values, true
+}
+
+// IngestCounters computes the metrics of the interfaces of <counters> from their previous counters and raises the
+// events of the exceeded thresholds of the metrics.
+func (c *Collector) IngestCounters(counters ...Counters) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	c.expire(now)
+	raised := false
+	for _, current := range counters {
+		key := current.Agent + "/" + current.DataSource
+		state, ok := c.interfaces[key]
+		if !ok {
+			c.interfaces[key] = &interfaceState{counters: current, updated: now}
+			continue
+		}
+		elapsed := now.Sub(state.updated)
+		if elapsed <= 0 {
+			continue
+		}
+		values, ok := metrics(&state.counters, &current, elapsed)
+		state.counters, state.updated, state.metrics = current, now, values
+		if ok && c.evaluateMetrics(now, key, state) {
+			raised = true
+		}
+	}
+
+	if raised {
+		close(c.newEvents)
+		c.newEvents = make(chan struct{})
+	}
+}
+
+// evaluateMetrics checks the thresholds of the metrics of the interface <key>, it returns true if events were raised.
+func (c *Collector) evaluateMetrics(now time.Time, key string, state *interfaceState) bool {
+	raised := false
+	for _, metric := range sets.StringKeySet(state.metrics).List() {
+		value := state.metrics[metric]
+		for _, name := range c.thresholdsOf(metric) {
+			if c.exceeds(name, key, value) {
+				c.addEvent(now, name, metric, value, state.counters.Agent, state.counters.DataSource, "")
+				raised = true
+			}
+		}
+	}
+	return raised
+}
+
+// Metrics returns the values of <metric> reported by <agent>, "ALL" for all agents. The metric may be a comma
+// separated list and use the aggregations max, min, sum or avg over the interfaces, e.g. "sum:ifinoctets", the
+// maximum is returned by default.
+func (c *Collector) Metrics(agent, metric string) []sflowrt.Metric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]string, 0, len(c.interfaces))
+	for key := range c.interfaces {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result []sflowrt.Metric
+	for _, name := range strings.Split(metric, ",") {
+		aggregation, base := "max", name
+		if i := strings.Index(name, ":"); i >= 0 {
+			aggregation, base = name[:i], name[i+1:]
+		}
+
+		var (
+			selected *interfaceState
+			sum      float64
+			count    int
+		)
+		for _, key := range keys {
+			state := c.interfaces[key]
+			value, ok := state.metrics[base]
+			if !ok || (agent != "ALL" && state.counters.Agent != agent) {
+				continue
+			}
+			sum += value
+			count++
+			if selected == nil ||
+				(aggregation == "min" && value < selected.metrics[base]) ||
+				(aggregation != "min" && value > selected.metrics[base]) {
+				selected = state
+			}
+		}
+		if count == 0 {
+			continue
+		}
+
+		m := sflowrt.Metric{MetricName: name}
+		switch aggregation {
+		case "sum":
+			m.MetricValue = sum
+		case "avg":
+			m.MetricValue = sum / float64(count)
+		default:
+			m.Agent, m.DataSource = selected.counters.Agent, selected.counters.DataSource
+			m.MetricValue = selected.metrics[base]
+			m.LastUpdate = int64(c.clock.Since(selected.updated) / time.Millisecond)
+		}
+		result = append(result, m)
+	}
+	return result
+}
@@ -0,0 +1,193 @@
+package collector
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// filter returns true for the records a flow measures.
+type filter func(*Record) bool
+
+// groupResolver returns the group of the address groups <name> the <address> belongs to.
+type groupResolver func(name, address string) (string, bool)
+
+var addressKeys = map[string]bool{
+	KeyIPSource:       true,
+	KeyIPDestination:  true,
+	KeyIP6Source:      true,
+	KeyIP6Destination: true,
+}
+
+// parseFilter compiles a filter of an sFlow-RT flow, e.g. "ipprotocol=6&tcpdestinationport=80,443" or
+// "ipsource=10.0.0.0/8|!(vlan=100)". Comparisons test a key for one of a comma separated list of values, addresses may
+// be matched by CIDRs and the groups of addresses by "group:<key>:<name>" keys which are resolved by <groups>. Records
+// without the key of a comparison do not match it.
+func parseFilter(expression string, groups groupResolver) (filter, error) {
+	p := &filterParser{input: strings.Join(strings.Fields(expression), ""), groups: groups}
+	f, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("invalid filter %q: unexpected %q at %d", expression, p.input[p.pos], p.pos)
+	}
+	return f, nil
+}
+
+type filterParser struct {
+	input  string
+	pos    int
+	groups groupResolver
+}
+
+func (p *filterParser) consume(token byte) bool {
+	if p.pos < len(p.input) && p.input[p.pos] == token {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) or() (filter, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume('|') {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(r *Record) bool { return l(r) || right(r) }
+	}
+	return left, nil
+}
+
+func (p *filterParser) and() (filter, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume('&') {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(r *Record) bool { return l(r) && right(r) }
+	}
+	return left, nil
+}
+
+func (p *filterParser) unary() (filter, error) {
+	if p.consume('!') {
+		f, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(r *Record) bool { return !f(r) }, nil
+	}
+	if p.consume('(') {
+		f, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(')') {
+			return nil, fmt.Errorf("invalid filter %q: missing ) at %d", p.input, p.pos)
+		}
+		return f, nil
+	}
+	return p.comparison()
+}
+
+func (p *filterParser) comparison() (filter, error) {
+	start := p.pos
+	for p.pos < len(p.input) && strings.IndexByte("=!&|()", p.input[p.pos]) < 0 {
+		p.pos++
+	}
+	key := p.input[start:p.pos]
+	field, err := p.field(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %v", p.input, err)
+	}
+
+	negated := p.consume('!')
+	if !p.consume('=') {
+		return nil, fmt.Errorf("invalid filter %q: expected = or != at %d", p.input, p.pos)
+	}
+	start = p.pos
+	for p.pos < len(p.input) && strings.IndexByte("&|()", p.input[p.pos]) < 0 {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, fmt.Errorf("invalid filter %q: missing value of %s", p.input, key)
+	}
+
+	matches, err := valueMatcher(key, strings.Split(p.input[start:p.pos], ","))
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %v", p.input, err)
+	}
+	return func(r *Record) bool {
+		value, ok := field(r)
+		return ok && matches(value) != negated
+	}, nil
+}
+
+// field returns a function returning the value of <key> of a record, the key is either supported by records or the
+// group of an address key, e.g. "group:ipsource:namespaces".
+func (p *filterParser) field(key string) (func(*Record) (string, bool), error) {
+	if supportedKeys[key] {
+		return func(r *Record) (string, bool) { return r.field(key) }, nil
+	}
+
+	parts := strings.Split(key, ":")
+	if len(parts) != 3 || parts[0] != "group" || !addressKeys[parts[1]] || len(parts[2]) == 0 {
+		return nil, fmt.Errorf("unsupported key %q", key)
+	}
+	if p.groups == nil {
+		return nil, fmt.Errorf("address groups are not supported")
+	}
+	return func(r *Record) (string, bool) {
+		address, ok := r.field(parts[1])
+		if !ok {
+			return "", false
+		}
+		return p.groups(parts[2], address)
+	}, nil
+}
+
+// valueMatcher returns a function testing whether a value of <key> is one of <values>.
+func valueMatcher(key string, values []string) (func(string) bool, error) {
+	var (
+		literals = map[string]bool{}
+		networks []*net.IPNet
+	)
+	for _, value := range values {
+		if addressKeys[key] && strings.Contains(value, "/") {
+			_, network, err := net.ParseCIDR(value)
+			if err != nil {
+				return nil, err
+			}
+			networks = append(networks, network)
+			continue
+		}
+		literals[strings.ToUpper(value)] = true
+	}
+	return func(value string) bool {
+		if literals[strings.ToUpper(value)] {
+			return true
+		}
+		if len(networks) == 0 {
+			return false
+		}
+		ip := net.ParseIP(value)
+		for _, network := range networks {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
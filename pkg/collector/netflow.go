@@ -0,0 +1,297 @@
+package collector
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	netFlowV9 = 9
+	ipfix     = 10
+
+	// variableLength is the length of IPFIX fields whose length is encoded in the record.
+	variableLength = 65535
+	// minDataSetID is the ID of the first set holding data records, lower IDs hold templates.
+	minDataSetID = 256
+)
+
+// Information elements of NetFlow v9 and IPFIX which are decoded, they have the same IDs in both.
+const (
+	fieldBytes            = 1
+	fieldPackets          = 2
+	fieldProtocol         = 4
+	fieldSourcePort       = 7
+	fieldSourceIPv4       = 8
+	fieldInputInterface   = 10
+	fieldDestinationPort  = 11
+	fieldDestinationIPv4  = 12
+	fieldOutputInterface  = 14
+	fieldSourceIPv6       = 27
+	fieldDestinationIPv6  = 28
+	fieldSamplingInterval = 34
+	fieldSourceMAC        = 56
+	fieldOutputDestMAC    = 57
+	fieldVLAN             = 58
+	fieldDestinationMAC   = 80
+
+	protocolTCP = "6"
+	protocolUDP = "17"
+)
+
+const (
+	netFlowV9HeaderLength  = 20
+	ipfixHeaderLength      = 16
+	netFlowV9TemplateSetID = 0
+	ipfixTemplateSetID     = 2
+	setHeaderLength        = 4
+	templateHeaderLength   = 4
+	templateFieldLength    = 4
+	enterpriseNumberLength = 4
+	enterpriseBit          = 0x8000
+	// variable length fields longer than 254 bytes encode their length in two more bytes
+	shortVariableLengthMax  = 255
+	longVariableLengthBytes = 2
+)
+
+type templateKey struct {
+	agent  string
+	domain uint32
+	id     uint16
+}
+
+type templateField struct {
+	id         uint16
+	length     uint16
+	enterprise bool
+}
+
+// netFlowDecoder decodes NetFlow v9 and IPFIX datagrams. Data records can only be decoded once the template they
+// refer to was received, templates are kept per agent and observation domain.
+type netFlowDecoder struct {
+	mu        sync.Mutex
+	templates map[templateKey][]templateField
+}
+
+func newNetFlowDecoder() *netFlowDecoder {
+	return &netFlowDecoder{templates: map[templateKey][]templateField{}}
+}
+
+// decode returns the records of the datagram <data> exported by <agent>. Records of unknown templates are skipped.
+func (d *netFlowDecoder) decode(agent string, data []byte) ([]Record, error) {
+	if len(data) < 2 {
+		return nil, fmt.Errorf("datagram of %d bytes is too short", len(data))
+	}
+
+	var (
+		version       = binary.BigEndian.Uint16(data)
+		offset        int
+		domain        uint32
+		templateSetID uint16
+	)
+	switch version {
+	case netFlowV9:
+		if len(data) < netFlowV9HeaderLength {
+			return nil, fmt.Errorf("NetFlow v9 header of %d bytes is too short", len(data))
+		}
+		offset, domain, templateSetID = netFlowV9HeaderLength, binary.BigEndian.Uint32(data[16:]), netFlowV9TemplateSetID
+	case ipfix:
+		if len(data) < ipfixHeaderLength {
+			return nil, fmt.Errorf("IPFIX header of %d bytes is too short", len(data))
+		}
+		// the length of the message includes its header, trailing bytes of the datagram are ignored
+		length := int(binary.BigEndian.Uint16(data[2:]))
+		if length < ipfixHeaderLength || length > len(data) {
+			return nil, fmt.Errorf("invalid IPFIX message length %d of a datagram of %d bytes", length, len(data))
+		}
+		data = data[:length]
+		offset, domain, templateSetID = ipfixHeaderLength, binary.BigEndian.Uint32(data[12:]), ipfixTemplateSetID
+	default:
+		return nil, fmt.Errorf("unsupported datagram version %d", version)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var records []Record
+	for offset < len(data) {
+		if len(data)-offset < setHeaderLength {
+			return records, fmt.Errorf("truncated set at offset %d", offset)
+		}
+		id := binary.BigEndian.Uint16(data[offset:])
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if length < setHeaderLength || offset+length > len(data) {
+			return records, fmt.Errorf("invalid length %d of set %d at offset %d", length, id, offset)
+		}
+		body := data[offset+setHeaderLength : offset+length]
+		offset += length
+
+		switch {
+		case id == templateSetID:
+			if err := d.addTemplates(agent, domain, body, version == ipfix); err != nil {
+				return records, err
+			}
+		case id >= minDataSetID:
+			fields, ok := d.templates[templateKey{agent, domain, id}]
+			if !ok {
+				continue
+			}
+			records = append(records, decodeRecords(agent, fields, body)...)
+		}
+		// options templates and their data are not needed
+	}
+	return records, nil
+}
+
+// addTemplates stores the templates of the template set <body>.
+func (d *netFlowDecoder) addTemplates(agent string, domain uint32, body []byte, enterprise bool) error {
+	for len(body) >= templateHeaderLength {
+		id := binary.BigEndian.Uint16(body)
+		count := int(binary.BigEndian.Uint16(body[2:]))
+		body = body[templateHeaderLength:]
+
+		key := templateKey{agent, domain, id}
+		if count == 0 {
+			// IPFIX withdraws templates without fields
+			delete(d.templates, key)
+			continue
+		}
+
+		fields := make([]templateField, 0, count)
+		for i := 0; i < count; i++ {
+			if len(body) < templateFieldLength {
+				return fmt.Errorf("truncated template %d", id)
+			}
+			field := templateField{id: binary.BigEndian.Uint16(body), length: binary.BigEndian.Uint16(body[2:])}
+			body = body[templateFieldLength:]
+			if enterprise && field.id&enterpriseBit != 0 {
+				if len(body) < enterpriseNumberLength {
+					return fmt.Errorf("truncated template %d", id)
+				}
+				field.id &^= enterpriseBit
+				field.enterprise = true
+				body = body[enterpriseNumberLength:]
+			}
+			fields = append(fields, field)
+		}
+		d.templates[key] = fields
+	}
+	return nil
+}
+
+// decodeRecords decodes the data records of <body> described by <fields>, trailing padding is ignored.
+func decodeRecords(agent string, fields []templateField, body []byte) []Record {
+	var records []Record
+	for len(body) > 0 {
+		record, rest, ok := decodeRecord(agent, fields, body)
+		if !ok {
+			break
+		}
+		records = append(records, record)
+		body = rest
+	}
+	return records
+}
+
+func decodeRecord(agent string, fields []templateField, body []byte) (Record, []byte, bool) {
+	var (
+		record                      = Record{Agent: agent, Fields: map[string]string{}}
+		sourcePort, destinationPort string
+		samplingInterval            uint64
+	)
+	for _, field := range fields {
+		length := int(field.length)
+		if field.length == variableLength {
+			if len(body) < 1 {
+				return record, nil, false
+			}
+			length, body = int(body[0]), body[1:]
+			if length == shortVariableLengthMax {
+				if len(body) < longVariableLengthBytes {
+					return record, nil, false
+				}
+				length, body = int(binary.BigEndian.Uint16(body)), body[longVariableLengthBytes:]
+			}
+		}
+		if length == 0 || len(body) < length {
+			return record, nil, false
+		}
+		value := body[:length]
+		body = body[length:]
+		if field.enterprise {
+			continue
+		}
+
+		switch field.id {
+		case fieldBytes:
+			record.Bytes = unsigned(value)
+		case fieldPackets:
+			record.Frames = unsigned(value)
+		case fieldProtocol:
+			record.Fields[KeyIPProtocol] = strconv.FormatUint(unsigned(value), 10)
+		case fieldSourcePort:
+			sourcePort = strconv.FormatUint(unsigned(value), 10)
+		case fieldDestinationPort:
+			destinationPort = strconv.FormatUint(unsigned(value), 10)
+		case fieldSourceIPv4, fieldSourceIPv6:
+			key := KeyIPSource
+			if field.id == fieldSourceIPv6 {
+				key = KeyIP6Source
+			}
+			record.Fields[key] = net.IP(value).String()
+		case fieldDestinationIPv4, fieldDestinationIPv6:
+			key := KeyIPDestination
+			if field.id == fieldDestinationIPv6 {
+				key = KeyIP6Destination
+			}
+			record.Fields[key] = net.IP(value).String()
+		case fieldInputInterface:
+			record.DataSource = strconv.FormatUint(unsigned(value), 10)
+			record.Fields[KeyInputIfIndex] = record.DataSource
+		case fieldOutputInterface:
+			record.Fields[KeyOutputIfIndex] = strconv.FormatUint(unsigned(value), 10)
+		case fieldSourceMAC:
+			record.Fields[KeyMACSource] = strings.ToUpper(hex.EncodeToString(value))
+		case fieldDestinationMAC, fieldOutputDestMAC:
+			record.Fields[KeyMACDestination] = strings.ToUpper(hex.EncodeToString(value))
+		case fieldVLAN:
+			record.Fields[KeyVLAN] = strconv.FormatUint(unsigned(value), 10)
+		case fieldSamplingInterval:
+			samplingInterval = unsigned(value)
+		}
+	}
+
+	switch record.Fields[KeyIPProtocol] {
+	case protocolTCP:
+		setPorts(record.Fields, KeyTCPSourcePort, sourcePort, KeyTCPDestinationPort, destinationPort)
+	case protocolUDP:
+		setPorts(record.Fields, KeyUDPSourcePort, sourcePort, KeyUDPDestinationPort, destinationPort)
+	}
+	if samplingInterval > 1 {
+		record.Bytes *= samplingInterval
+		record.Frames *= samplingInterval
+	}
+	return record, body, true
+}
+
+func setPorts(fields map[string]string, sourceKey, source, destinationKey, destination string) {
+	if len(source) > 0 {
+		fields[sourceKey] = source
+	}
+	if len(destination) > 0 {
+		fields[destinationKey] = destination
+	}
+}
+
+// unsigned decodes a big endian unsigned integer of up to 8 bytes.
+func unsigned(value []byte) uint64 {
+	var n uint64
+	for _, b := range value {
+		n = n<<8 | uint64(b)
+	}
+	return n
+}
@@ -0,0 +1,40 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Options configure the embedded collector.
+type Options struct {
+	// SFlowAddress is the UDP address sFlow v5 datagrams are received on, the collector does not listen if it is
+	// empty.
+	SFlowAddress string
+	// NetFlowAddress is the UDP address NetFlow v9 and IPFIX datagrams are received on, the collector does not
+	// listen if it is empty.
+	NetFlowAddress string
+	// Smoothing is the time constant of the exponentially weighted rates of flows.
+	Smoothing time.Duration
+	// FlowTimeout is the time after which the rate of a flow key which was not seen is forgotten.
+	FlowTimeout time.Duration
+	// MaxEvents is the number of threshold events kept, older events are dropped.
+	MaxEvents int
+}
+
+// DefaultOptions are the default options of the collector, both the embedded and the standalone collector start
+// from them.
+var DefaultOptions = &Options{
+	Smoothing:   10 * time.Second,
+	FlowTimeout: time.Minute,
+	MaxEvents:   1000,
+}
+
+// AddFlags adds the collector flags to <flags>.
+func (o *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.SFlowAddress, "collector-sflow-address", o.SFlowAddress, "UDP address the embedded collector receives sFlow v5 datagrams on, e.g. :6343, it is disabled if empty")
+	flags.StringVar(&o.NetFlowAddress, "collector-netflow-address", o.NetFlowAddress, "UDP address the embedded collector receives NetFlow v9 and IPFIX datagrams on, e.g. :2055, it is disabled if empty")
+	flags.DurationVar(&o.Smoothing, "collector-smoothing", o.Smoothing, "time constant of the rates of flows computed by the embedded collector")
+	flags.DurationVar(&o.FlowTimeout, "collector-flow-timeout", o.FlowTimeout, "time after which the embedded collector forgets flow keys which were not seen")
+	flags.IntVar(&o.MaxEvents, "collector-max-events", o.MaxEvents, "number of threshold events kept by the embedded collector")
+}
@@ -0,0 +1,113 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Keys of flow records, they are named like the keys of sFlow-RT flows.
+const (
+	KeyAgent              = "agent"
+	KeyInputIfIndex       = "inputifindex"
+	KeyOutputIfIndex      = "outputifindex"
+	KeyMACSource          = "macsource"
+	KeyMACDestination     = "macdestination"
+	KeyVLAN               = "vlan"
+	KeyEthernetProtocol   = "ethernetprotocol"
+	KeyIPProtocol         = "ipprotocol"
+	KeyIPSource           = "ipsource"
+	KeyIPDestination      = "ipdestination"
+	KeyIP6Source          = "ip6source"
+	KeyIP6Destination     = "ip6destination"
+	KeyTCPSourcePort      = "tcpsourceport"
+	KeyTCPDestinationPort = "tcpdestinationport"
+	KeyUDPSourcePort      = "udpsourceport"
+	KeyUDPDestinationPort = "udpdestinationport"
+)
+
+// Values of flow records a flow can measure.
+const (
+	ValueBytes  = "bytes"
+	ValueFrames = "frames"
+)
+
+var supportedKeys = map[string]bool{
+	KeyAgent:              true,
+	KeyInputIfIndex:       true,
+	KeyOutputIfIndex:      true,
+	KeyMACSource:          true,
+	KeyMACDestination:     true,
+	KeyVLAN:               true,
+	KeyEthernetProtocol:   true,
+	KeyIPProtocol:         true,
+	KeyIPSource:           true,
+	KeyIPDestination:      true,
+	KeyIP6Source:          true,
+	KeyIP6Destination:     true,
+	KeyTCPSourcePort:      true,
+	KeyTCPDestinationPort: true,
+	KeyUDPSourcePort:      true,
+	KeyUDPDestinationPort: true,
+}
+
+// Record is a flow observed by an agent. Its counters are scaled by the sampling rate of the agent.
+type Record struct {
+	// Agent is the address of the agent which exported the record.
+	Agent string
+	// DataSource is the interface the flow was observed at, e.g. its ifIndex.
+	DataSource string
+	// Fields holds the values of the keys of the record.
+	Fields map[string]string
+	Bytes  uint64
+	Frames uint64
+}
+
+// parseKeys returns the keys of a comma separated list of <keys>, all keys have to be supported.
+func parseKeys(keys string) ([]string, error) {
+	var parsed []string
+	for _, key := range strings.Split(keys, ",") {
+		key = strings.TrimSpace(key)
+		if !supportedKeys[key] {
+			return nil, fmt.Errorf("unsupported key %q", key)
+		}
+		parsed = append(parsed, key)
+	}
+	return parsed, nil
+}
+
+// key returns the flow key of <record> for <keys>, it returns false if the record lacks one of them.
+func (r *Record) key(keys []string) (string, bool) {
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value, ok := r.field(key)
+		if !ok {
+			return "", false
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, ","), true
+}
+
+// field returns the value of the key <key> of <record>.
+func (r *Record) field(key string) (string, bool) {
+	if key == KeyAgent {
+		return r.Agent, len(r.Agent) > 0
+	}
+	value, ok := r.Fields[key]
+	return value, ok
+}
+
+// value returns the counter <value> of <record>.
+func (r *Record) value(value string) uint64 {
+	if value == ValueFrames {
+		return r.Frames
+	}
+	return r.Bytes
+}
+
+func validValue(value string) error {
+	if value != ValueBytes && value != ValueFrames {
+		return fmt.Errorf("unsupported value %q, one of %s or %s", value, ValueBytes, ValueFrames)
+	}
+	return nil
+}
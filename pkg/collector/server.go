@@ -0,0 +1,261 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+)
+
+// NewHandler returns a handler serving the flows, thresholds, address groups, active flows, events and metrics of <c>
+// with the REST API of sFlow-RT, so that the sFlow-RT client and the NetworkMonitor controller can use the collector
+// like sFlow-RT:
+//
+//	GET /flow/json, GET|PUT|DELETE /flow/{name}/json
+//	GET /threshold/json, GET|PUT|DELETE /threshold/{name}/json
+//	GET /group/json, GET|PUT|DELETE /group/{name}/json
+//	GET /activeflows/{agent}/{name}/json?maxFlows=
+//	GET /events/json?eventID=&maxEvents=&timeout=
+//	GET /metric/{agent}/{metric}/json
+func NewHandler(c *Collector) http.Handler {
+	return &handler{collector: c}
+}
+
+type handler struct {
+	collector *Collector
+}
+
+// ServeHTTP implements http.Handler.
+func (h *handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var parts []string
+	for _, part := range strings.Split(strings.Trim(req.URL.Path, "/"), "/") {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		parts = append(parts, unescaped)
+	}
+	if len(parts) < 2 || parts[len(parts)-1] != "json" {
+		http.NotFound(w, req)
+		return
+	}
+	parts = parts[:len(parts)-1]
+
+	switch {
+	case parts[0] == "flow" && len(parts) <= 2:
+		h.serveFlows(w, req, parts[1:])
+	case parts[0] == "threshold" && len(parts) <= 2:
+		h.serveThresholds(w, req, parts[1:])
+	case parts[0] == "group" && len(parts) <= 2:
+		h.serveGroups(w, req, parts[1:])
+	case parts[0] == "activeflows" && len(parts) == 3 && req.Method == http.MethodGet:
+		h.serveActiveFlows(w, req, parts[1], parts[2])
+	case parts[0] == "events" && len(parts) == 1 && req.Method == http.MethodGet:
+		h.serveEvents(w, req)
+	case parts[0] == "metric" && len(parts) == 3 && req.Method == http.MethodGet:
+		writeJSON(w, h.collector.Metrics(parts[1], parts[2]))
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func (h *handler) serveFlows(w http.ResponseWriter, req *http.Request, name []string) {
+	flows, _ := h.collector.Flows(req.Context())
+	if len(name) == 0 {
+		writeJSON(w, flows)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		flow, ok := flows[name[0]]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		writeJSON(w, flow)
+	case http.MethodPut:
+		flow := v1alpha1.Flow{}
+		if !readDefinition(w, req, &flow) {
+			return
+		}
+		flow.Name = name[0]
+		if err := h.collector.PutFlow(req.Context(), flow); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if _, ok := flows[name[0]]; !ok {
+			http.NotFound(w, req)
+			return
+		}
+		_ = h.collector.DeleteFlow(req.Context(), name[0])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *handler) serveThresholds(w http.ResponseWriter, req *http.Request, name []string) {
+	thresholds, _ := h.collector.Thresholds(req.Context())
+	if len(name) == 0 {
+		writeJSON(w, thresholds)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		threshold, ok := thresholds[name[0]]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		writeJSON(w, threshold)
+	case http.MethodPut:
+		threshold := v1alpha1.Threshold{}
+		if !readDefinition(w, req, &threshold) {
+			return
+		}
+		threshold.Name = name[0]
+		if byFlow := req.URL.Query().Get("byFlow"); len(byFlow) > 0 {
+			threshold.ByFlow = byFlow
+		}
+		if err := h.collector.PutThreshold(req.Context(), threshold); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if _, ok := thresholds[name[0]]; !ok {
+			http.NotFound(w, req)
+			return
+		}
+		_ = h.collector.DeleteThreshold(req.Context(), name[0])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *handler) serveGroups(w http.ResponseWriter, req *http.Request, name []string) {
+	groups, _ := h.collector.Groups(req.Context())
+	if len(name) == 0 {
+		writeJSON(w, groups)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		group, ok := groups[name[0]]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		writeJSON(w, group)
+	case http.MethodPut:
+		group := sflowrt.Groups{}
+		if err := json.NewDecoder(req.Body).Decode(&group); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := h.collector.PutGroup(req.Context(), name[0], group); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if _, ok := groups[name[0]]; !ok {
+			http.NotFound(w, req)
+			return
+		}
+		_ = h.collector.DeleteGroup(req.Context(), name[0])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *handler) serveActiveFlows(w http.ResponseWriter, req *http.Request, agent, name string) {
+	maxFlows, err := intParam(req.URL.Query(), "maxFlows")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flows, _ := h.collector.ActiveFlows(req.Context(), agent, name, maxFlows)
+	if flows == nil {
+		flows = []sflowrt.ActiveFlow{}
+	}
+	writeJSON(w, flows)
+}
+
+func (h *handler) serveEvents(w http.ResponseWriter, req *http.Request) {
+	params := req.URL.Query()
+	eventID, err := intParam(params, "eventID")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	maxEvents, err := intParam(params, "maxEvents")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	timeout, err := intParam(params, "timeout")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.collector.Events(req.Context(), sflowrt.EventsQuery{
+		EventID:   int32(eventID),
+		MaxEvents: maxEvents,
+		Timeout:   time.Duration(timeout) * time.Second,
+	})
+	if err != nil {
+		// the client went away
+		return
+	}
+	if events == nil {
+		events = []v1alpha1.Event{}
+	}
+	writeJSON(w, events)
+}
+
+// intParam returns the integer query parameter <name>, it is zero if it is not set.
+func intParam(params url.Values, name string) (int, error) {
+	if len(params.Get(name)) == 0 {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(params.Get(name))
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", name, err)
+	}
+	return n, nil
+}
+
+// readDefinition decodes the flow or threshold of the body of <req> into <definition>, it answers bad requests.
+func readDefinition(w http.ResponseWriter, req *http.Request, definition interface{}) bool {
+	body, err := ioutil.ReadAll(req.Body)
+	if err == nil {
+		err = sflowrt.UnmarshalDefinition(body, definition)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, object interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(object)
+}
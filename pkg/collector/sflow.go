@@ -0,0 +1,311 @@
+package collector
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	sFlowV5 = 5
+
+	addressTypeIPv4 = 1
+	addressTypeIPv6 = 2
+
+	// sample formats of the standard enterprise
+	sampleFlow             = 1
+	sampleCounters         = 2
+	sampleExpandedFlow     = 3
+	sampleExpandedCounters = 4
+
+	// flow record formats of the standard enterprise
+	recordRawPacketHeader = 1
+	recordIPv4            = 3
+	recordIPv6            = 4
+	recordExtendedSwitch  = 1001
+
+	// counter record formats of the standard enterprise
+	recordGenericInterface = 1
+
+	headerProtocolEthernet = 1
+
+	etherTypeIPv4 = 0x0800
+	etherTypeIPv6 = 0x86dd
+	etherTypeVLAN = 0x8100
+
+	// interfaceUnknown marks an unknown input or output interface of a flow sample
+	interfaceUnknown = 0x3fffffff
+)
+
+// isSFlow returns true if <data> is an sFlow v5 datagram, its version is a 32 bit integer unlike the one of NetFlow.
+func isSFlow(data []byte) bool {
+	return len(data) >= 4 && binary.BigEndian.Uint32(data) == sFlowV5
+}
+
+// xdr reads the big endian fields of sFlow datagrams, reading beyond the end of the data fails all later reads.
+type xdr struct {
+	data []byte
+	err  error
+}
+
+func (x *xdr) next(n int) []byte {
+	if x.err != nil {
+		return nil
+	}
+	if n < 0 || len(x.data) < n {
+		x.err = fmt.Errorf("truncated sFlow datagram")
+		x.data = nil
+		return nil
+	}
+	value := x.data[:n]
+	x.data = x.data[n:]
+	return value
+}
+
+func (x *xdr) uint32() uint32 {
+	if value := x.next(4); value != nil {
+		return binary.BigEndian.Uint32(value)
+	}
+	return 0
+}
+
+func (x *xdr) uint64() uint64 {
+	if value := x.next(8); value != nil {
+		return binary.BigEndian.Uint64(value)
+	}
+	return 0
+}
+
+// opaque reads <n> bytes which are padded to a multiple of four bytes.
+func (x *xdr) opaque(n int) []byte {
+	value := x.next(n)
+	x.next((4 - n%4) % 4)
+	return value
+}
+
+// sub returns a reader of the next <n> bytes, e.g. of a sample, and skips them.
+func (x *xdr) sub(n uint32) *xdr {
+	value := x.next(int(n))
+	if value == nil {
+		return &xdr{err: x.err}
+	}
+	return &xdr{data: value}
+}
+
+// decodeSFlow returns the flow records and interface counters of the sFlow v5 datagram <data>. The counters of flow
+// records are the ones of the sampled packet scaled by the sampling rate.
+func decodeSFlow(data []byte) ([]Record, []Counters, error) {
+	x := &xdr{data: data}
+	if version := x.uint32(); version != sFlowV5 {
+		return nil, nil, fmt.Errorf("unsupported sFlow version %d", version)
+	}
+
+	var agent string
+	switch addressType := x.uint32(); addressType {
+	case addressTypeIPv4:
+		agent = net.IP(x.next(net.IPv4len)).String()
+	case addressTypeIPv6:
+		agent = net.IP(x.next(net.IPv6len)).String()
+	default:
+		return nil, nil, fmt.Errorf("unsupported sFlow agent address type %d", addressType)
+	}
+	x.uint32() // sub agent
+	x.uint32() // sequence number
+	x.uint32() // uptime
+
+	var (
+		records  []Record
+		counters []Counters
+	)
+	for samples := x.uint32(); samples > 0 && x.err == nil; samples-- {
+		format := x.uint32()
+		sample := x.sub(x.uint32())
+		if format>>12 != 0 {
+			// samples of other enterprises are not decoded
+			continue
+		}
+		switch format {
+		case sampleFlow, sampleExpandedFlow:
+			records = append(records, decodeFlowSample(agent, sample, format == sampleExpandedFlow))
+		case sampleCounters, sampleExpandedCounters:
+			counters = append(counters, decodeCounterSample(agent, sample, format == sampleExpandedCounters)...)
+		}
+	}
+	if x.err != nil {
+		return records, counters, x.err
+	}
+	return records, counters, nil
+}
+
+func decodeFlowSample(agent string, x *xdr, expanded bool) Record {
+	var (
+		sourceIndex, samplingRate    uint32
+		input, output                uint32
+		inputKnown, outputKnown      bool
+		record                       = Record{Agent: agent, Fields: map[string]string{}}
+		recordedBytes, decodedHeader bool
+	)
+	x.uint32() // sequence number
+	if expanded {
+		x.uint32() // source ID type
+		sourceIndex = x.uint32()
+	} else {
+		sourceIndex = x.uint32() & 0xffffff
+	}
+	samplingRate = x.uint32()
+	x.uint32() // sample pool
+	x.uint32() // drops
+	if expanded {
+		inputKnown, input = x.uint32() == 0, x.uint32()
+		outputKnown, output = x.uint32() == 0, x.uint32()
+	} else {
+		value := x.uint32()
+		inputKnown, input = value>>30 == 0, value&interfaceUnknown
+		value = x.uint32()
+		outputKnown, output = value>>30 == 0, value&interfaceUnknown
+	}
+
+	record.DataSource = strconv.FormatUint(uint64(sourceIndex), 10)
+	record.Frames = uint64(samplingRate)
+	if inputKnown && input != 0 && input != interfaceUnknown {
+		record.Fields[KeyInputIfIndex] = strconv.FormatUint(uint64(input), 10)
+	}
+	if outputKnown && output != 0 && output != interfaceUnknown {
+		record.Fields[KeyOutputIfIndex] = strconv.FormatUint(uint64(output), 10)
+	}
+
+	for count := x.uint32(); count > 0 && x.err == nil; count-- {
+		format := x.uint32()
+		data := x.sub(x.uint32())
+		switch format {
+		case recordRawPacketHeader:
+			protocol := data.uint32()
+			frameLength := data.uint32()
+			data.uint32() // stripped
+			header := data.opaque(int(data.uint32()))
+			if data.err != nil {
+				continue
+			}
+			record.Bytes, recordedBytes = uint64(frameLength)*uint64(samplingRate), true
+			if protocol == headerProtocolEthernet {
+				decodeEthernet(header, record.Fields)
+				decodedHeader = true
+			}
+		case recordIPv4, recordIPv6:
+			length := data.uint32()
+			protocol := data.uint32()
+			addressLength := net.IPv4len
+			sourceKey, destinationKey := KeyIPSource, KeyIPDestination
+			if format == recordIPv6 {
+				addressLength, sourceKey, destinationKey = net.IPv6len, KeyIP6Source, KeyIP6Destination
+			}
+			source, destination := data.next(addressLength), data.next(addressLength)
+			sourcePort, destinationPort := data.uint32(), data.uint32()
+			if data.err != nil || decodedHeader {
+				continue
+			}
+			if !recordedBytes {
+				record.Bytes = uint64(length) * uint64(samplingRate)
+			}
+			record.Fields[KeyIPProtocol] = strconv.FormatUint(uint64(protocol), 10)
+			record.Fields[sourceKey] = net.IP(source).String()
+			record.Fields[destinationKey] = net.IP(destination).String()
+			setTransportPorts(record.Fields, uint8(protocol), uint16(sourcePort), uint16(destinationPort))
+		case recordExtendedSwitch:
+			vlan := data.uint32()
+			if _, ok := record.Fields[KeyVLAN]; !ok && data.err == nil {
+				record.Fields[KeyVLAN] = strconv.FormatUint(uint64(vlan), 10)
+			}
+		}
+	}
+	return record
+}
+
+// decodeEthernet adds the keys of the Ethernet frame <header> to <fields>.
+func decodeEthernet(header []byte, fields map[string]string) {
+	if len(header) < 14 {
+		return
+	}
+	fields[KeyMACDestination] = strings.ToUpper(hex.EncodeToString(header[0:6]))
+	fields[KeyMACSource] = strings.ToUpper(hex.EncodeToString(header[6:12]))
+	etherType := binary.BigEndian.Uint16(header[12:])
+	header = header[14:]
+	if etherType == etherTypeVLAN && len(header) >= 4 {
+		fields[KeyVLAN] = strconv.Itoa(int(binary.BigEndian.Uint16(header) & 0x0fff))
+		etherType = binary.BigEndian.Uint16(header[2:])
+		header = header[4:]
+	}
+	fields[KeyEthernetProtocol] = strconv.Itoa(int(etherType))
+
+	switch {
+	case etherType == etherTypeIPv4 && len(header) >= 20:
+		headerLength := int(header[0]&0x0f) * 4
+		protocol := header[9]
+		fields[KeyIPProtocol] = strconv.Itoa(int(protocol))
+		fields[KeyIPSource] = net.IP(header[12:16]).String()
+		fields[KeyIPDestination] = net.IP(header[16:20]).String()
+		// only the first fragment carries the ports
+		if binary.BigEndian.Uint16(header[6:])&0x1fff == 0 && len(header) >= headerLength+4 {
+			transport := header[headerLength:]
+			setTransportPorts(fields, protocol, binary.BigEndian.Uint16(transport), binary.BigEndian.Uint16(transport[2:]))
+		}
+	case etherType == etherTypeIPv6 && len(header) >= 40:
+		protocol := header[6]
+		fields[KeyIPProtocol] = strconv.Itoa(int(protocol))
+		fields[KeyIP6Source] = net.IP(header[8:24]).String()
+		fields[KeyIP6Destination] = net.IP(header[24:40]).String()
+		if len(header) >= 44 {
+			transport := header[40:]
+			setTransportPorts(fields, protocol, binary.BigEndian.Uint16(transport), binary.BigEndian.Uint16(transport[2:]))
+		}
+	}
+}
+
+func setTransportPorts(fields map[string]string, protocol uint8, source, destination uint16) {
+	switch strconv.Itoa(int(protocol)) {
+	case protocolTCP:
+		setPorts(fields, KeyTCPSourcePort, strconv.Itoa(int(source)), KeyTCPDestinationPort, strconv.Itoa(int(destination)))
+	case protocolUDP:
+		setPorts(fields, KeyUDPSourcePort, strconv.Itoa(int(source)), KeyUDPDestinationPort, strconv.Itoa(int(destination)))
+	}
+}
+
+func decodeCounterSample(agent string, x *xdr, expanded bool) []Counters {
+	x.uint32() // sequence number
+	if expanded {
+		x.uint32() // source ID type
+	}
+	x.uint32() // source ID
+
+	var counters []Counters
+	for count := x.uint32(); count > 0 && x.err == nil; count-- {
+		format := x.uint32()
+		data := x.sub(x.uint32())
+		if format != recordGenericInterface {
+			continue
+		}
+
+		c := Counters{Agent: agent}
+		c.DataSource = strconv.FormatUint(uint64(data.uint32()), 10)
+		data.uint32() // type
+		c.Speed = data.uint64()
+		data.uint32() // direction
+		data.uint32() // status
+		c.InOctets = data.uint64()
+		c.InPackets = uint64(data.uint32()) + uint64(data.uint32()) + uint64(data.uint32())
+		c.InDiscards = uint64(data.uint32())
+		c.InErrors = uint64(data.uint32())
+		data.uint32() // unknown protocols
+		c.OutOctets = data.uint64()
+		c.OutPackets = uint64(data.uint32()) + uint64(data.uint32()) + uint64(data.uint32())
+		c.OutDiscards = uint64(data.uint32())
+		c.OutErrors = uint64(data.uint32())
+		if data.err == nil {
+			counters = append(counters, c)
+		}
+	}
+	return counters
+}
@@ -0,0 +1,218 @@
+package collector
+
+import (
+	"bufio"
+	"encoding/hex"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+)
+
+// readDatagrams reads the hex encoded datagrams of a capture in testdata.
+func readDatagrams(t *testing.T, name string) [][]byte {
+	file, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var datagrams [][]byte
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		datagram, err := hex.DecodeString(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		datagrams = append(datagrams, datagram)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return datagrams
+}
+
+func TestDecodeSFlow(t *testing.T) {
+	datagrams := readDatagrams(t, "sflow-v5.hex")
+
+	records, counters, err := decodeSFlow(datagrams[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || len(counters) != 1 {
+		t.Fatalf("expected three records and one counter sample, got %+v and %+v", records, counters)
+	}
+	expected := Record{
+		Agent:      "192.168.1.10",
+		DataSource: "3",
+		Fields: map[string]string{
+			KeyInputIfIndex: "3", KeyOutputIfIndex: "4", KeyMACSource: "020000000001", KeyMACDestination: "020000000002",
+			KeyEthernetProtocol: "2048", KeyIPProtocol: "6", KeyIPSource: "10.0.1.1", KeyIPDestination: "10.0.2.2",
+			KeyTCPSourcePort: "40000", KeyTCPDestinationPort: "443",
+		},
+		Bytes:  151400,
+		Frames: 100,
+	}
+	if !reflect.DeepEqual(records[0], expected) {
+		t.Errorf("expected record %+v, got %+v", expected, records[0])
+	}
+	if records[1].Fields[KeyUDPDestinationPort] != "53" || records[2].Fields[KeyVLAN] != "100" || records[2].DataSource != "5" {
+		t.Errorf("unexpected UDP or expanded records %+v, %+v", records[1], records[2])
+	}
+	if c := counters[0]; c.Agent != "192.168.1.10" || c.DataSource != "3" || c.Speed != 1000000000 || c.InOctets != 1000000 || c.OutPackets != 2000 {
+		t.Errorf("unexpected counters %+v", c)
+	}
+
+	records, _, err = decodeSFlow(datagrams[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1].Fields[KeyIP6Source] != "2001:db8::1" || records[1].Fields[KeyTCPDestinationPort] != "443" {
+		t.Errorf("expected the IPv6 record and the sample of the other enterprise to be skipped, got %+v", records)
+	}
+
+	if _, _, err := decodeSFlow(datagrams[0][:100]); err == nil {
+		t.Error("expected truncated datagrams to be rejected")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	record := &Record{Agent: "192.168.1.10", Fields: map[string]string{
+		KeyIPSource: "10.0.1.1", KeyIPProtocol: "6", KeyTCPDestinationPort: "443", KeyMACSource: "020000000001",
+	}}
+	for expression, expected := range map[string]bool{
+		"ipprotocol=6":                                 true,
+		"ipprotocol=17":                                false,
+		"ipprotocol!=17":                               true,
+		"tcpdestinationport=80,443":                    true,
+		"ipsource=10.0.0.0/16":                         true,
+		"ipsource=10.1.0.0/16,10.0.1.1":                true,
+		"ipprotocol=6&tcpdestinationport=80":           false,
+		"ipprotocol=17|tcpdestinationport=443":         true,
+		"!(ipprotocol=17|udpdestinationport=53)":       true,
+		"vlan=100":                                     false,
+		"vlan!=100":                                    false,
+		"agent=192.168.1.10 & macsource=020000000001":  true,
+		"macsource=020000000001&(vlan=1|ipprotocol=6)": true,
+	} {
+		f, err := parseFilter(expression, nil)
+		if err != nil {
+			t.Errorf("could not parse %q: %v", expression, err)
+			continue
+		}
+		if f(record) != expected {
+			t.Errorf("expected %q to be %t", expression, expected)
+		}
+	}
+
+	for _, expression := range []string{"", "ipprotocol", "ipprotocol=", "dnsqname=x", "(ipprotocol=6", "ipprotocol=6)", "ipsource=10.0.0.0/33", "group:ipsource:namespaces=payments"} {
+		if _, err := parseFilter(expression, nil); err == nil {
+			t.Errorf("expected %q to be rejected", expression)
+		}
+	}
+}
+
+// TestReplay replays a capture to a collector configured through its REST API with the sFlow-RT client.
+func TestReplay(t *testing.T) {
+	c, fakeClock := newTestCollector()
+	server := httptest.NewServer(NewHandler(c))
+	defer server.Close()
+	client, err := sflowrt.New(server.URL, &sflowrt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.PutGroup(ctx, "namespaces", sflowrt.Groups{"payments": {"10.0.1.0/24"}, "frontend": {"10.0.1.5"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, flow := range []v1alpha1.Flow{
+		{Name: "https", Keys: "ipsource,ipdestination", Value: "bytes", Filter: "tcpdestinationport=443&ethernetprotocol=2048"},
+		{Name: "vlans", Keys: "vlan", Value: "frames"},
+		{Name: "payments", Keys: "ipsource", Value: "frames", Filter: "group:ipsource:namespaces=payments"},
+	} {
+		if err := client.PutFlow(ctx, flow); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, threshold := range []v1alpha1.Threshold{
+		{Name: "elephant", Metric: "https", Value: 10000, ByFlow: "true"},
+		{Name: "saturated", Metric: MetricInUtilization, Value: 40},
+	} {
+		if err := client.PutThreshold(ctx, threshold); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := client.PutFlow(ctx, v1alpha1.Flow{Name: "invalid", Keys: "ipsource", Value: "bytes", Filter: "ipsource=x/y"}); err == nil {
+		t.Error("expected an invalid filter to be rejected")
+	}
+	if threshold, err := client.Threshold(ctx, "elephant"); err != nil || threshold.ByFlow != "true" || threshold.Value != 10000 {
+		t.Errorf("expected the threshold by flow, got %+v, %v", threshold, err)
+	}
+
+	for _, datagram := range readDatagrams(t, "sflow-v5.hex") {
+		if err := c.HandleDatagram("192.168.0.1", datagram); err != nil {
+			t.Fatal(err)
+		}
+		fakeClock.Step(10 * time.Second)
+	}
+
+	events, err := client.Events(ctx, sflowrt.EventsQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary []string
+	for _, event := range events {
+		summary = append(summary, event.ThresholdID+"/"+event.FlowKey+"/"+event.DataSource)
+	}
+	// 1514 bytes sampled 1 in 100 are a rate of 15140 bytes per second with a smoothing of 10 seconds, it decays
+	// below the threshold until the second datagram raises the first flow again. The UDP and IPv6 flows are filtered.
+	expected := []string{"saturated//3", "elephant/10.0.1.1,10.0.2.2/3", "elephant/10.0.1.5,10.0.2.2/5", "elephant/10.0.1.1,10.0.2.2/3"}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("expected events %v, got %v", expected, summary)
+	}
+	if events[0].Agent != "192.168.1.10" || events[0].Value != 50 {
+		t.Errorf("expected the utilization event of the agent, got %+v", events[0])
+	}
+
+	metrics, err := client.Metrics(ctx, "ALL", "ifinutilization,sum:ifinoctets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metrics) != 2 || metrics[0].MetricValue != 50 || metrics[0].DataSource != "3" || metrics[1].MetricValue != 62500000 {
+		t.Errorf("expected the utilization and received bytes of ifIndex 3, got %+v", metrics)
+	}
+
+	flows, err := client.ActiveFlows(ctx, "ALL", "https", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flows) != 1 || flows[0].Key != "10.0.1.1,10.0.2.2" || flows[0].Agent != "192.168.1.10" {
+		t.Errorf("expected the largest key of the flow, got %+v", flows)
+	}
+	if flows, err := client.ActiveFlows(ctx, "ALL", "vlans", 0); err != nil || len(flows) != 1 || flows[0].Key != "100" {
+		t.Errorf("expected the key of the tagged record, got %+v, %v", flows, err)
+	}
+	// the longest prefix of the address groups wins
+	if flows, err := client.ActiveFlows(ctx, "ALL", "payments", 0); err != nil || len(flows) != 1 || flows[0].Key != "10.0.1.1" {
+		t.Errorf("expected the key of the address group, got %+v, %v", flows, err)
+	}
+
+	for _, name := range []string{"https", "https"} {
+		if err := client.DeleteFlow(ctx, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if flows, err := client.Flows(ctx); err != nil || len(flows) != 2 {
+		t.Errorf("expected the flow to be deleted, got %v, %v", flows, err)
+	}
+}
@@ -2,8 +2,11 @@ package controllers
 
 import (
 	"github.com/networkmachinery/networkmachinery-operators/pkg/controllers/networkmonitor/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 func init() {
-	AddToManagerFuncs = append(AddToManagerFuncs, controller.Add)
+	AddToManagerFuncs = append(AddToManagerFuncs, func(mgr manager.Manager, options *OperatorOptions) error {
+		return controller.Add(mgr, options.Collector, options.SFlowRT, options.Notify, options.Export)
+	})
 }
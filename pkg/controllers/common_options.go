@@ -1,5 +1,16 @@
 package controllers
 
+import (
+	"github.com/networkmachinery/networkmachinery-operators/pkg/collector"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/controllers/networkmonitor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/notify"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/audit"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/guard"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/target"
+	"github.com/spf13/pflag"
+)
+
 type LeaderElectionOptions struct {
 	LeaderElection          bool
 	LeaderElectionNamespace string
@@ -9,3 +20,47 @@ type LeaderElectionOptions struct {
 type ControllerOptions struct {
 	MaxConcurrentReconciles int
 }
+
+// OperatorOptions are the options shared by all controllers and webhooks of a process.
+type OperatorOptions struct {
+	Audit      *audit.Options
+	NodeHelper *target.NodeHelperOptions
+	Guard      *guard.Options
+	SFlowRT    *sflowrt.Options
+	Notify     *notify.Options
+	Collector  *collector.Options
+	Export     *networkmonitor.ExportOptions
+}
+
+// NewOperatorOptions returns operator options initialized with copies of the default options.
+func NewOperatorOptions() *OperatorOptions {
+	var (
+		auditOptions      = *audit.DefaultOptions
+		nodeHelperOptions = *target.DefaultNodeHelperOptions
+		guardOptions      = *guard.DefaultOptions
+		sflowrtOptions    = *sflowrt.DefaultOptions
+		notifyOptions     = *notify.DefaultOptions
+		collectorOptions  = *collector.DefaultOptions
+		exportOptions     = *networkmonitor.DefaultExportOptions
+	)
+	return &OperatorOptions{
+		Audit:      &auditOptions,
+		NodeHelper: &nodeHelperOptions,
+		Guard:      &guardOptions,
+		SFlowRT:    &sflowrtOptions,
+		Notify:     &notifyOptions,
+		Collector:  &collectorOptions,
+		Export:     &exportOptions,
+	}
+}
+
+// AddFlags adds the flags of all operator options to <flags>.
+func (o *OperatorOptions) AddFlags(flags *pflag.FlagSet) {
+	o.Audit.AddFlags(flags)
+	o.NodeHelper.AddFlags(flags)
+	o.Guard.AddFlags(flags)
+	o.SFlowRT.AddFlags(flags)
+	o.Notify.AddFlags(flags)
+	o.Collector.AddFlags(flags)
+	o.Export.AddFlags(flags)
+}
@@ -26,11 +26,14 @@ var log = logf.Log.WithName("example-controller")
 const (
 	layerValidationServerPath       = "/validate-layer-v1alpha1-networkconnectivitytest"
 	destinationValidationServerPath = "/validate-destination-v1alpha1-networkconnectivitytest"
+	requesterMutationServerPath     = "/mutate-requester-v1alpha1"
+	shaperValidationServerPath      = "/validate-v1alpha1-networktrafficshaper"
+	monitorValidationServerPath     = "/validate-v1alpha1-networkmonitor"
 
 	webhookServerPort = 9876
 )
 
-func NewNetworkConnectivityTestCmd(ctx context.Context) *cobra.Command {
+func NewNetworkConnectivityTestCmd(ctx context.Context, operatorOptions *controllers.OperatorOptions) *cobra.Command {
 	var (
 		entryLog                       = log.WithName("networkconnectivity-test-cmd")
 		retryDuration                  = 100 * time.Millisecond
@@ -42,6 +45,7 @@ func NewNetworkConnectivityTestCmd(ctx context.Context) *cobra.Command {
 				LeaderElectionID:        utils.LeaderElectionNameID(controller.Name),
 			},
 			leaderLelectionRetryPeriod: &retryDuration,
+			OperatorOptions:            operatorOptions,
 		}
 	)
 
@@ -66,8 +70,11 @@ func NewNetworkConnectivityTestCmd(ctx context.Context) *cobra.Command {
 			entryLog.Info("registering webhooks to the webhook server")
 			admissionServer.Register(layerValidationServerPath, &webhook.Admission{Handler: &networkmachineryhandlers.LayerValidator{}})
 			admissionServer.Register(destinationValidationServerPath, &webhook.Admission{Handler: &networkmachineryhandlers.DestinationValidator{}})
+			admissionServer.Register(shaperValidationServerPath, &webhook.Admission{Handler: &networkmachineryhandlers.ShaperValidator{GuardOptions: networkConnectivityTestCmdOpts.OperatorOptions.Guard}})
+			admissionServer.Register(monitorValidationServerPath, &webhook.Admission{Handler: &networkmachineryhandlers.MonitorValidator{}})
+			admissionServer.Register(requesterMutationServerPath, &webhook.Admission{Handler: &networkmachineryhandlers.RequesterAnnotator{}})
 
-			if err := controllers.AddToManager(mgr); err != nil {
+			if err := controllers.AddToManager(mgr, networkConnectivityTestCmdOpts.OperatorOptions); err != nil {
 				utils.LogErrAndExit(err, "Could not add controller to manager")
 			}
 
@@ -19,6 +19,8 @@ type NetworkConnectivityTestCmdOpts struct {
 	leaderLelectionRetryPeriod    *time.Duration
 	*genericclioptions.ConfigFlags
 	controllers.LeaderElectionOptions
+	// OperatorOptions are set by the flags of the hyper command.
+	OperatorOptions *controllers.OperatorOptions
 }
 
 func (nct *NetworkConnectivityTestCmdOpts) InjectLeaderElectionOpts(mgrOpts *manager.Options) *manager.Options {
@@ -2,6 +2,11 @@ package controller
 
 import (
 	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/audit"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/policy"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/target"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -12,12 +17,14 @@ import (
 )
 
 // newReconciler returns a new reconcile.Reconciler.
-func newReconciler(mgr manager.Manager) *ReconcileNetworkConnectivityTest {
+func newReconciler(mgr manager.Manager, podExecutor executor.PodExecutor, nodeHelperOptions *target.NodeHelperOptions) *ReconcileNetworkConnectivityTest {
 	return &ReconcileNetworkConnectivityTest{
-		logger:   log.Log.WithName("networkconnectivity-test-controller"),
-		client:   mgr.GetClient(),
-		scheme:   mgr.GetScheme(),
-		recorder: mgr.GetEventRecorderFor(Name)}
+		logger:            log.Log.WithName("networkconnectivity-test-controller"),
+		executor:          podExecutor,
+		nodeHelperOptions: nodeHelperOptions,
+		client:            mgr.GetClient(),
+		scheme:            mgr.GetScheme(),
+		recorder:          mgr.GetEventRecorderFor(Name)}
 }
 
 // DefaultPredicates returns the default predicates for an infrastructure reconciler.
@@ -26,8 +33,14 @@ func DefaultPredicates() []predicate.Predicate {
 }
 
 // Add creates a new NetworkMonitor Controller and adds it to the Manager
-func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr), DefaultPredicates())
+func Add(mgr manager.Manager, auditOptions *audit.Options, nodeHelperOptions *target.NodeHelperOptions) error {
+	sink, err := audit.SinkFor(mgr, auditOptions)
+	if err != nil {
+		return err
+	}
+	// executions are audited before they are authorized, denied executions are recorded but never reach the pods
+	podExecutor := audit.NewExecutor(policy.NewExecutor(utils.NewExecutor(mgr.GetConfig()), mgr.GetClient()), sink)
+	return add(mgr, newReconciler(mgr, podExecutor, nodeHelperOptions), DefaultPredicates())
 }
 
 func add(mgr manager.Manager, r reconcile.Reconciler, predicates []predicate.Predicate) error {
@@ -1,4 +1,3 @@
 package controller
 
-
 const Name = "networkconnectivity-test-controller"
@@ -5,49 +5,29 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/apimachinery"
-
 	networkmachineryv1alpha1 "github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
-	"k8s.io/client-go/rest"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/target"
 )
 
-func Ping(ctx context.Context, config *rest.Config, source networkmachineryv1alpha1.NetworkSourceEndpoint, host string) (*PingOutput, error) {
+// Ping pings <host> from the source pod. A ping which ran but failed is not considered an error, its details
+// are returned as part of the PingOutput instead.
+func Ping(ctx context.Context, podExecutor executor.PodExecutor, source *target.Target, host string) (*PingOutput, error) {
 	var (
 		stdOut, stdErr bytes.Buffer
-		execOpts       = executor.PodExecOptions{
-			Namespace: source.Namespace,
-			Name:      source.Name,
-			Command:   fmt.Sprintf("ping -c3 %s", host),
-			Container: source.Container,
-			StandardCmdOpts: executor.StandardCmdOpts{
-				StdErr: &stdErr,
-				StdOut: &stdOut,
-			},
-		}
+		execOpts       = source.ExecOptions(fmt.Sprintf("ping -c3 %s", host))
 	)
+	execOpts.StdOut = &stdOut
+	execOpts.StdErr = &stdErr
 
-	useEphemeralContainers, err := utils.ShouldUseEphemeralContainers(config)
+	result, err := podExecutor.Execute(ctx, execOpts)
 	if err != nil {
-		return nil, err
-	}
-
-	if useEphemeralContainers {
-		debugContainerName := "net-debug"
-		if err := apimachinery.CreateOrUpdateEphemeralContainer(config, source.Namespace, source.Name, debugContainerName); err != nil {
-			return nil, err
-		}
-		execOpts.Container = debugContainerName
-
-		if err := apimachinery.EphemeralContainerInStatus(ctx, config, &source); err != nil {
-			return nil, err
+		out := &PingOutput{state: networkmachineryv1alpha1.FailedPing, exec: result}
+		if executor.IsExecError(err) {
+			return out, nil
 		}
-	}
-
-	err = utils.PodExec(ctx, config, execOpts)
-	if err != nil {
-		return &PingOutput{state: networkmachineryv1alpha1.FailedPing}, err
+		return out, err
 	}
 
 	ping := &utils.Ping{}
@@ -58,44 +38,27 @@ func Ping(ctx context.Context, config *rest.Config, source networkmachineryv1alp
 		min:   ping.Min(),
 		max:   ping.Max(),
 		avg:   ping.Average(),
+		exec:  result,
 	}, nil
 }
 
-func NetCat(ctx context.Context, config *rest.Config, source networkmachineryv1alpha1.NetworkSourceEndpoint, host, port string) (*NetcatOutput, error) {
+// NetCat checks whether <host>:<port> is reachable from the source pod. A netcat which ran but failed is not
+// considered an error, its details are returned as part of the NetcatOutput instead.
+func NetCat(ctx context.Context, podExecutor executor.PodExecutor, source *target.Target, host, port string) (*NetcatOutput, error) {
 	var (
 		stdOut, stdErr bytes.Buffer
-		execOpts       = executor.PodExecOptions{
-			Namespace: source.Namespace,
-			Name:      source.Name,
-			Command:   fmt.Sprintf("nc -z -v %s %s 2>&1", host, port),
-			Container: source.Container,
-			StandardCmdOpts: executor.StandardCmdOpts{
-				StdErr: &stdErr,
-				StdOut: &stdOut,
-			},
-		}
+		execOpts       = source.ExecOptions(fmt.Sprintf("nc -z -v %s %s 2>&1", host, port))
 	)
+	execOpts.StdOut = &stdOut
+	execOpts.StdErr = &stdErr
 
-	useEphemeralContainers, err := utils.ShouldUseEphemeralContainers(config)
+	result, err := podExecutor.Execute(ctx, execOpts)
 	if err != nil {
-		return nil, err
-	}
-
-	if useEphemeralContainers {
-		debugContainerName := "net-debug"
-		if err := apimachinery.CreateOrUpdateEphemeralContainer(config, source.Namespace, source.Name, debugContainerName); err != nil {
-			return nil, err
-		}
-		execOpts.Container = debugContainerName
-
-		if err := apimachinery.EphemeralContainerInStatus(ctx, config, &source); err != nil {
-			return nil, err
+		out := &NetcatOutput{state: networkmachineryv1alpha1.Refused, exec: result}
+		if executor.IsExecError(err) {
+			return out, nil
 		}
-	}
-
-	err = utils.PodExec(ctx, config, execOpts)
-	if err != nil {
-		return &NetcatOutput{state: networkmachineryv1alpha1.Refused}, err
+		return out, err
 	}
 
 	netcat := &utils.Netcat{}
@@ -103,5 +66,19 @@ func NetCat(ctx context.Context, config *rest.Config, source networkmachineryv1a
 
 	return &NetcatOutput{
 		state: netcat.State(),
+		exec:  result,
 	}, nil
 }
+
+// execStatus returns the status of a failed execution, <err> is used to describe failures which happened
+// before the command could run.
+func execStatus(result *executor.ExecResult, err error) *networkmachineryv1alpha1.ExecStatus {
+	status := utils.ToExecStatus(result)
+	if status == nil {
+		status = &networkmachineryv1alpha1.ExecStatus{ExitCode: -1}
+	}
+	if err != nil {
+		status.Message = err.Error()
+	}
+	return status
+}
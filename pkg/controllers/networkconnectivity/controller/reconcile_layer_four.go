@@ -3,10 +3,13 @@ package controller
 import (
 	"context"
 	"fmt"
+	"net"
 	"strconv"
 
 	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/apimachinery"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/target"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -22,27 +25,39 @@ var NetcatStatusTypeMeta = metav1.TypeMeta{
 
 type NetcatOutput struct {
 	state v1alpha1.NetcatResultState
+	exec  *executor.ExecResult
 }
 
-func (r *ReconcileNetworkConnectivityTest) IPNetcat(ctx context.Context, status *v1alpha1.NetcatStatus, source *v1alpha1.NetworkSourceEndpoint, destination *v1alpha1.NetworkDestinationEndpoint) error {
-	netcatOut, err := NetCat(ctx, r.config, *source, destination.IP, destination.Port)
+// netcat runs netcat against <host>:<port> and returns the result reported in the status. The returned error
+// indicates a netcat which could not be run at all, in which case the result describes the failure.
+func (r *ReconcileNetworkConnectivityTest) netcat(ctx context.Context, networkConnectivityTest *v1alpha1.NetworkConnectivityTest, source *target.Target, host, port string) (v1alpha1.NetcatResult, error) {
+	netcatOut, err := NetCat(ctx, r.executor, source, host, port)
+	result := v1alpha1.NetcatResult{
+		State: netcatOut.state,
+	}
+	if err != nil || netcatOut.state != v1alpha1.Succeeded {
+		result.Exec = execStatus(netcatOut.exec, err)
+		r.logger.Info("failed to netcat endpoint", "destination", net.JoinHostPort(host, port), "reason", result.Exec.Message)
+		r.recordCommandFailure(networkConnectivityTest, "netcat", net.JoinHostPort(host, port), result.Exec)
+	}
+	return result, err
+}
+
+func (r *ReconcileNetworkConnectivityTest) IPNetcat(ctx context.Context, status *v1alpha1.NetcatStatus, networkConnectivityTest *v1alpha1.NetworkConnectivityTest, source *target.Target, destination *v1alpha1.NetworkDestinationEndpoint) error {
+	netcatResult, err := r.netcat(ctx, networkConnectivityTest, source, destination.IP, destination.Port)
 	if err != nil {
 		return err
 	}
 
-	if netcatOut != nil {
-		status.NetcatIPEndpoints = append(status.NetcatIPEndpoints, v1alpha1.NetcatIPEndpoint{
-			IP:   destination.IP,
-			Port: destination.Port,
-			NetcatResult: v1alpha1.NetcatResult{
-				State: netcatOut.state,
-			},
-		})
-	}
+	status.NetcatIPEndpoints = append(status.NetcatIPEndpoints, v1alpha1.NetcatIPEndpoint{
+		IP:           destination.IP,
+		Port:         destination.Port,
+		NetcatResult: netcatResult,
+	})
 	return nil
 }
 
-func (r *ReconcileNetworkConnectivityTest) PodNetcat(ctx context.Context, status *v1alpha1.NetcatStatus, source *v1alpha1.NetworkSourceEndpoint, destination *v1alpha1.NetworkDestinationEndpoint) error {
+func (r *ReconcileNetworkConnectivityTest) PodNetcat(ctx context.Context, status *v1alpha1.NetcatStatus, networkConnectivityTest *v1alpha1.NetworkConnectivityTest, source *target.Target, destination *v1alpha1.NetworkDestinationEndpoint) error {
 	destinationPod := &corev1.Pod{}
 	err := r.client.Get(ctx, client.ObjectKey{Namespace: destination.Namespace, Name: destination.Name}, destinationPod)
 	if err != nil {
@@ -54,7 +69,7 @@ func (r *ReconcileNetworkConnectivityTest) PodNetcat(ctx context.Context, status
 		return fmt.Errorf("could not find pod IP to netcat")
 	}
 
-	netcatOut, err := NetCat(ctx, r.config, *source, podIP, destination.Port)
+	netcatResult, err := r.netcat(ctx, networkConnectivityTest, source, podIP, destination.Port)
 	if err != nil {
 		return err
 	}
@@ -67,15 +82,13 @@ func (r *ReconcileNetworkConnectivityTest) PodNetcat(ctx context.Context, status
 				IP:        podIP,
 				Port:      destination.Port,
 			},
-			NetcatResult: v1alpha1.NetcatResult{
-				State: netcatOut.state,
-			},
+			NetcatResult: netcatResult,
 		})
 
 	return nil
 }
 
-func (r *ReconcileNetworkConnectivityTest) ServiceNetcat(ctx context.Context, status *v1alpha1.NetcatStatus, source *v1alpha1.NetworkSourceEndpoint, destination *v1alpha1.NetworkDestinationEndpoint) error {
+func (r *ReconcileNetworkConnectivityTest) ServiceNetcat(ctx context.Context, status *v1alpha1.NetcatStatus, networkConnectivityTest *v1alpha1.NetworkConnectivityTest, source *target.Target, destination *v1alpha1.NetworkDestinationEndpoint) error {
 	objectKey := client.ObjectKey{Namespace: destination.Namespace, Name: destination.Name}
 
 	service := &corev1.Service{}
@@ -94,19 +107,15 @@ func (r *ReconcileNetworkConnectivityTest) ServiceNetcat(ctx context.Context, st
 	var netcatIPEndpoints []v1alpha1.NetcatIPEndpoint
 	for _, endpoint := range endpoints.Subsets[0].Addresses {
 		endPointPort := strconv.Itoa(int(endpoints.Subsets[0].Ports[0].Port))
-		netcatOut, _ := NetCat(ctx, r.config, *source, endpoint.IP, endPointPort)
-		if netcatOut != nil {
-			netcatIPEndpoints = append(netcatIPEndpoints, v1alpha1.NetcatIPEndpoint{
-				IP:   endpoint.IP,
-				Port: endPointPort,
-				NetcatResult: v1alpha1.NetcatResult{
-					State: netcatOut.state,
-				},
-			})
-		}
+		netcatResult, _ := r.netcat(ctx, networkConnectivityTest, source, endpoint.IP, endPointPort)
+		netcatIPEndpoints = append(netcatIPEndpoints, v1alpha1.NetcatIPEndpoint{
+			IP:           endpoint.IP,
+			Port:         endPointPort,
+			NetcatResult: netcatResult,
+		})
 	}
 	// This goes directly to the service IP and port
-	directServiceNetCatOut, _ := NetCat(ctx, r.config, *source, service.Spec.ClusterIP, destination.Port)
+	directServiceNetcatResult, _ := r.netcat(ctx, networkConnectivityTest, source, service.Spec.ClusterIP, destination.Port)
 	status.NetcatServiceEndpoints = append(status.NetcatServiceEndpoints, v1alpha1.NetcatServiceEndpoint{
 		ServiceParams: v1alpha1.Params{
 			IP:        service.Spec.ClusterIP,
@@ -116,17 +125,15 @@ func (r *ReconcileNetworkConnectivityTest) ServiceNetcat(ctx context.Context, st
 		},
 		ServiceResults: netcatIPEndpoints,
 		ServiceResultsDirect: v1alpha1.NetcatIPEndpoint{
-			IP:   service.Spec.ClusterIP,
-			Port: destination.Port,
-			NetcatResult: v1alpha1.NetcatResult{
-				State: directServiceNetCatOut.state,
-			},
+			IP:           service.Spec.ClusterIP,
+			Port:         destination.Port,
+			NetcatResult: directServiceNetcatResult,
 		},
 	})
 	return nil
 }
 
-func (r *ReconcileNetworkConnectivityTest) reconcileLayerFour(ctx context.Context, networkConnectivityTest *v1alpha1.NetworkConnectivityTest) (reconcile.Result, error) {
+func (r *ReconcileNetworkConnectivityTest) reconcileLayerFour(ctx context.Context, networkConnectivityTest *v1alpha1.NetworkConnectivityTest, source *target.Target) (reconcile.Result, error) {
 	var (
 		status = &v1alpha1.NetcatStatus{
 			TypeMeta: NetcatStatusTypeMeta,
@@ -136,17 +143,17 @@ func (r *ReconcileNetworkConnectivityTest) reconcileLayerFour(ctx context.Contex
 		switch destination.Kind {
 		case v1alpha1.IP:
 			r.logger.Info("checking connectivity against %s", destination)
-			if err := r.IPNetcat(ctx, status, &networkConnectivityTest.Spec.Source, &destination); err != nil {
+			if err := r.IPNetcat(ctx, status, networkConnectivityTest, source, &destination); err != nil {
 				return apimachinery.ReconcileErr(err)
 			}
 
 		case v1alpha1.Pod:
-			if err := r.PodNetcat(ctx, status, &networkConnectivityTest.Spec.Source, &destination); err != nil {
+			if err := r.PodNetcat(ctx, status, networkConnectivityTest, source, &destination); err != nil {
 				return apimachinery.ReconcileErr(err)
 			}
 
 		case v1alpha1.Service:
-			if err := r.ServiceNetcat(ctx, status, &networkConnectivityTest.Spec.Source, &destination); err != nil {
+			if err := r.ServiceNetcat(ctx, status, networkConnectivityTest, source, &destination); err != nil {
 				return apimachinery.ReconcileErr(err)
 			}
 		}
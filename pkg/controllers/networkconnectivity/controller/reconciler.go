@@ -7,6 +7,10 @@ import (
 	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/apimachinery"
+	apimachineryerror "github.com/networkmachinery/networkmachinery-operators/pkg/utils/apimachinery/error"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/policy"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/target"
 	authorizationv1 "k8s.io/api/authorization/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -25,11 +29,14 @@ const (
 // ReconcileMachineDeployment reconciles a MachineDeployment object.
 type ReconcileNetworkConnectivityTest struct {
 	config   *rest.Config
+	executor executor.PodExecutor
 	logger   logr.Logger
 	client   client.Client
 	ctx      context.Context
 	scheme   *runtime.Scheme
 	recorder record.EventRecorder
+
+	nodeHelperOptions *target.NodeHelperOptions
 }
 
 // InjectConfig implements inject.Config.
@@ -57,14 +64,17 @@ func (r *ReconcileNetworkConnectivityTest) Reconcile(request reconcile.Request)
 		return apimachinery.ReconcileErr(err)
 	}
 
+	// executions are authorized on behalf of the user who requested the NetworkConnectivityTest
+	ctx := executor.WithOrigin(r.ctx, policy.NewOrigin(networkConnectivityTest, "NetworkConnectivityTest", v1alpha1.ExecToolProbe))
+
 	if networkConnectivityTest.DeletionTimestamp != nil {
-		return r.delete(r.ctx, networkConnectivityTest)
+		return r.delete(ctx, networkConnectivityTest)
 	}
 
 	r.logger.Info("Reconciling Network Connectivity Test", "Name", networkConnectivityTest.Name)
 	r.recorder.Event(networkConnectivityTest, v1alpha1.EventTypeNormal, v1alpha1.EventTypeReconciliation, "Reconciling NetworkConnectivityTest")
 
-	return r.reconcile(r.ctx, networkConnectivityTest)
+	return r.reconcile(ctx, networkConnectivityTest)
 }
 
 func (r *ReconcileNetworkConnectivityTest) reconcile(ctx context.Context, networkConnectivityTest *v1alpha1.NetworkConnectivityTest) (reconcile.Result, error) {
@@ -87,9 +97,17 @@ func (r *ReconcileNetworkConnectivityTest) reconcile(ctx context.Context, networ
 
 	switch networkConnectivityTest.Spec.Layer {
 	case "3":
-		return r.reconcileLayerThree(ctx, networkConnectivityTest)
+		source, err := r.resolveSource(ctx, networkConnectivityTest, "ping")
+		if err != nil {
+			return apimachinery.ReconcileErr(err)
+		}
+		return r.reconcileLayerThree(ctx, networkConnectivityTest, source)
 	case "4":
-		return r.reconcileLayerFour(ctx, networkConnectivityTest)
+		source, err := r.resolveSource(ctx, networkConnectivityTest, "nc")
+		if err != nil {
+			return apimachinery.ReconcileErr(err)
+		}
+		return r.reconcileLayerFour(ctx, networkConnectivityTest, source)
 	}
 	return reconcile.Result{
 		RequeueAfter: reconcilePeriod,
@@ -121,3 +139,39 @@ func (r *ReconcileNetworkConnectivityTest) delete(ctx context.Context, networkCo
 	r.recorder.Event(networkConnectivityTest, v1alpha1.EventTypeNormal, v1alpha1.EventTypeDeletion, "Network Connectivity Test Deleted!")
 	return reconcile.Result{}, nil
 }
+
+// resolveSource resolves the pod and container the test is executed in, the source pod is either named or the
+// first eligible pod matching the source selector. Sources which are not eligible yet are retried later.
+func (r *ReconcileNetworkConnectivityTest) resolveSource(ctx context.Context, networkConnectivityTest *v1alpha1.NetworkConnectivityTest, binaries ...string) (*target.Target, error) {
+	var (
+		source   = networkConnectivityTest.Spec.Source
+		resolver = target.NewResolver(r.client, r.executor, r.nodeHelperOptions)
+		options  = target.Options{
+			Container:        source.Container,
+			Binaries:         binaries,
+			AllowHostNetwork: source.AllowHostNetwork,
+		}
+		sourceTarget *target.Target
+		err          error
+	)
+
+	if len(source.Name) > 0 {
+		sourceTarget, err = resolver.Pod(ctx, source.Namespace, source.Name, options)
+	} else {
+		sourceTarget, err = resolver.First(ctx, source.Namespace, source.SourceSelector, options)
+	}
+	if err != nil {
+		if errors.IsNotFound(err) || target.IsNotEligible(err) {
+			r.recorder.Eventf(networkConnectivityTest, v1alpha1.EventTypeWarning, v1alpha1.EventTypeTargetNotEligible, "Could not select source pod: %v", err)
+			return nil, &apimachineryerror.RequeueAfterError{Cause: err, RequeueAfter: reconcilePeriod}
+		}
+		return nil, err
+	}
+	return sourceTarget, nil
+}
+
+// recordCommandFailure emits a warning event for a probe which failed inside the source pod.
+func (r *ReconcileNetworkConnectivityTest) recordCommandFailure(networkConnectivityTest *v1alpha1.NetworkConnectivityTest, probe, destination string, status *v1alpha1.ExecStatus) {
+	r.recorder.Eventf(networkConnectivityTest, v1alpha1.EventTypeWarning, v1alpha1.EventTypeCommandFailed,
+		"%s to %s failed (exit code %d, container %q, %s): %s", probe, destination, status.ExitCode, status.Container, status.Strategy, status.Message)
+}
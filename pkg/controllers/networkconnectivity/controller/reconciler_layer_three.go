@@ -7,6 +7,8 @@ import (
 
 	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/apimachinery"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/target"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -27,32 +29,44 @@ var (
 type PingOutput struct {
 	state         v1alpha1.PingResultState
 	min, avg, max string
+	exec          *executor.ExecResult
 }
 
-func (r *ReconcileNetworkConnectivityTest) IPPing(ctx context.Context, status *v1alpha1.PingStatus, source *v1alpha1.NetworkSourceEndpoint, destination string) {
-	pingOut, err := Ping(ctx, r.config, *source, destination)
-	if err != nil {
-		status.PingIPEndpoints = append(status.PingIPEndpoints, v1alpha1.PingIPEndpoint{
-			IP: destination,
-			PingResult: v1alpha1.PingResult{
-				State: v1alpha1.FailedPing,
-			},
-		})
-		r.logger.Error(err, "failed to ping endpoint", "destination", destination)
-	} else {
-		status.PingIPEndpoints = append(status.PingIPEndpoints, v1alpha1.PingIPEndpoint{
-			IP: destination,
-			PingResult: v1alpha1.PingResult{
-				State:   v1alpha1.SuccessPing,
-				Average: pingOut.avg,
-				Max:     pingOut.max,
-				Min:     pingOut.min,
-			},
-		})
+// pingResult converts the output of a ping into the result reported in the status, <err> describes a ping
+// which could not be run at all.
+func pingResult(out *PingOutput, err error) v1alpha1.PingResult {
+	if err != nil || out.state == v1alpha1.FailedPing {
+		return v1alpha1.PingResult{
+			State: v1alpha1.FailedPing,
+			Exec:  execStatus(out.exec, err),
+		}
+	}
+	return v1alpha1.PingResult{
+		State:   v1alpha1.SuccessPing,
+		Average: out.avg,
+		Max:     out.max,
+		Min:     out.min,
 	}
 }
 
-func (r *ReconcileNetworkConnectivityTest) PodPing(ctx context.Context, status *v1alpha1.PingStatus, source *v1alpha1.NetworkSourceEndpoint, destination *v1alpha1.NetworkDestinationEndpoint) error {
+func (r *ReconcileNetworkConnectivityTest) ping(ctx context.Context, networkConnectivityTest *v1alpha1.NetworkConnectivityTest, source *target.Target, destination string) v1alpha1.PingResult {
+	pingOut, err := Ping(ctx, r.executor, source, destination)
+	result := pingResult(pingOut, err)
+	if result.State == v1alpha1.FailedPing {
+		r.logger.Info("failed to ping endpoint", "destination", destination, "reason", result.Exec.Message)
+		r.recordCommandFailure(networkConnectivityTest, "ping", destination, result.Exec)
+	}
+	return result
+}
+
+func (r *ReconcileNetworkConnectivityTest) IPPing(ctx context.Context, status *v1alpha1.PingStatus, networkConnectivityTest *v1alpha1.NetworkConnectivityTest, source *target.Target, destination string) {
+	status.PingIPEndpoints = append(status.PingIPEndpoints, v1alpha1.PingIPEndpoint{
+		IP:         destination,
+		PingResult: r.ping(ctx, networkConnectivityTest, source, destination),
+	})
+}
+
+func (r *ReconcileNetworkConnectivityTest) PodPing(ctx context.Context, status *v1alpha1.PingStatus, networkConnectivityTest *v1alpha1.NetworkConnectivityTest, source *target.Target, destination *v1alpha1.NetworkDestinationEndpoint) error {
 	destinationPod := &corev1.Pod{}
 	if err := r.client.Get(ctx, client.ObjectKey{Namespace: destination.Namespace, Name: destination.Name}, destinationPod); err != nil {
 		status.PingPodEndpoints = append(status.PingPodEndpoints, v1alpha1.PingPodEndpoint{
@@ -72,37 +86,18 @@ func (r *ReconcileNetworkConnectivityTest) PodPing(ctx context.Context, status *
 		return fmt.Errorf("could not find pod IP to ping")
 	}
 
-	pingOut, err := Ping(ctx, r.config, *source, podIP)
-	if err != nil {
-		status.PingPodEndpoints = append(status.PingPodEndpoints, v1alpha1.PingPodEndpoint{
-			PodParams: v1alpha1.Params{
-				Namespace: destination.Namespace,
-				Name:      destination.Name,
-				IP:        podIP,
-			},
-			PingResult: v1alpha1.PingResult{
-				State: v1alpha1.FailedPing,
-			},
-		})
-	} else {
-		status.PingPodEndpoints = append(status.PingPodEndpoints, v1alpha1.PingPodEndpoint{
-			PodParams: v1alpha1.Params{
-				Namespace: destination.Namespace,
-				Name:      destination.Name,
-				IP:        podIP,
-			},
-			PingResult: v1alpha1.PingResult{
-				State:   v1alpha1.SuccessPing,
-				Average: pingOut.avg,
-				Max:     pingOut.max,
-				Min:     pingOut.min,
-			},
-		})
-	}
+	status.PingPodEndpoints = append(status.PingPodEndpoints, v1alpha1.PingPodEndpoint{
+		PodParams: v1alpha1.Params{
+			Namespace: destination.Namespace,
+			Name:      destination.Name,
+			IP:        podIP,
+		},
+		PingResult: r.ping(ctx, networkConnectivityTest, source, podIP),
+	})
 	return nil
 }
 
-func (r *ReconcileNetworkConnectivityTest) ServicePing(ctx context.Context, status *v1alpha1.PingStatus, source *v1alpha1.NetworkSourceEndpoint, destination *v1alpha1.NetworkDestinationEndpoint) error {
+func (r *ReconcileNetworkConnectivityTest) ServicePing(ctx context.Context, status *v1alpha1.PingStatus, networkConnectivityTest *v1alpha1.NetworkConnectivityTest, source *target.Target, destination *v1alpha1.NetworkDestinationEndpoint) error {
 	endpoints := &corev1.Endpoints{}
 	err := r.client.Get(ctx, client.ObjectKey{Namespace: destination.Namespace, Name: destination.Name}, endpoints)
 	if err != nil {
@@ -114,25 +109,10 @@ func (r *ReconcileNetworkConnectivityTest) ServicePing(ctx context.Context, stat
 
 	if endpoints != nil && endpoints.Subsets != nil {
 		for _, endpoint := range endpoints.Subsets[0].Addresses {
-			pingOut, err := Ping(ctx, r.config, *source, endpoint.IP)
-			if err != nil {
-				pingIPEndpoints = append(pingIPEndpoints, v1alpha1.PingIPEndpoint{
-					IP: endpoint.IP,
-					PingResult: v1alpha1.PingResult{
-						State: v1alpha1.FailedPing,
-					},
-				})
-			} else {
-				pingIPEndpoints = append(pingIPEndpoints, v1alpha1.PingIPEndpoint{
-					IP: endpoint.IP,
-					PingResult: v1alpha1.PingResult{
-						State:   v1alpha1.SuccessPing,
-						Average: pingOut.avg,
-						Max:     pingOut.max,
-						Min:     pingOut.min,
-					},
-				})
-			}
+			pingIPEndpoints = append(pingIPEndpoints, v1alpha1.PingIPEndpoint{
+				IP:         endpoint.IP,
+				PingResult: r.ping(ctx, networkConnectivityTest, source, endpoint.IP),
+			})
 		}
 	}
 
@@ -154,7 +134,7 @@ func (r *ReconcileNetworkConnectivityTest) ServicePing(ctx context.Context, stat
 	return nil
 }
 
-func (r *ReconcileNetworkConnectivityTest) reconcileLayerThree(ctx context.Context, networkConnectivityTest *v1alpha1.NetworkConnectivityTest) (reconcile.Result, error) {
+func (r *ReconcileNetworkConnectivityTest) reconcileLayerThree(ctx context.Context, networkConnectivityTest *v1alpha1.NetworkConnectivityTest, source *target.Target) (reconcile.Result, error) {
 	var (
 		status = &v1alpha1.PingStatus{
 			TypeMeta: PingStatusTypeMeta,
@@ -164,14 +144,14 @@ func (r *ReconcileNetworkConnectivityTest) reconcileLayerThree(ctx context.Conte
 	for _, destination := range networkConnectivityTest.Spec.Destinations {
 		switch destination.Kind {
 		case v1alpha1.IP:
-			r.IPPing(ctx, status, &networkConnectivityTest.Spec.Source, destination.IP)
+			r.IPPing(ctx, status, networkConnectivityTest, source, destination.IP)
 		case v1alpha1.Pod:
-			err := r.PodPing(ctx, status, &networkConnectivityTest.Spec.Source, &destination)
+			err := r.PodPing(ctx, status, networkConnectivityTest, source, &destination)
 			if err != nil {
 				return apimachinery.ReconcileErr(err)
 			}
 		case v1alpha1.Service:
-			err := r.ServicePing(ctx, status, &networkConnectivityTest.Spec.Source, &destination)
+			err := r.ServicePing(ctx, status, networkConnectivityTest, source, &destination)
 			if err != nil {
 				return apimachinery.ReconcileErr(err)
 			}
@@ -0,0 +1,214 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor/fake"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var testSource = v1alpha1.NetworkSourceEndpoint{
+	Namespace: "default",
+	Name:      "source",
+	Container: "app",
+}
+
+func newTestReconciler(podExecutor *fake.Executor, c *test.Client) (*ReconcileNetworkConnectivityTest, *record.FakeRecorder) {
+	recorder := record.NewFakeRecorder(100)
+	return &ReconcileNetworkConnectivityTest{
+		logger:   log.Log.WithName(Name),
+		executor: podExecutor,
+		client:   c,
+		ctx:      context.TODO(),
+		scheme:   test.Scheme(),
+		recorder: recorder,
+	}, recorder
+}
+
+var sourcePod = test.NewRunningPod("default", "source", nil, "app")
+
+func newConnectivityTest(layer string, destinations ...v1alpha1.NetworkDestinationEndpoint) *v1alpha1.NetworkConnectivityTest {
+	return &v1alpha1.NetworkConnectivityTest{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: v1alpha1.NetworkConnectivityTestSpec{
+			Layer:        layer,
+			Source:       testSource,
+			Destinations: destinations,
+		},
+	}
+}
+
+func reconcileTest(t *testing.T, r *ReconcileNetworkConnectivityTest) *v1alpha1.NetworkConnectivityTest {
+	t.Helper()
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}}
+	result, err := r.Reconcile(request)
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if result.RequeueAfter != reconcilePeriod {
+		t.Errorf("expected requeue after %s, got %s", reconcilePeriod, result.RequeueAfter)
+	}
+
+	networkConnectivityTest := &v1alpha1.NetworkConnectivityTest{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, networkConnectivityTest); err != nil {
+		t.Fatalf("could not get NetworkConnectivityTest: %v", err)
+	}
+	return networkConnectivityTest
+}
+
+func decodeStatus(t *testing.T, networkConnectivityTest *v1alpha1.NetworkConnectivityTest, status interface{}) {
+	t.Helper()
+	if networkConnectivityTest.Status.TestStatus == nil {
+		t.Fatal("expected test status to be set")
+	}
+	if err := json.Unmarshal(networkConnectivityTest.Status.TestStatus.Raw, status); err != nil {
+		t.Fatalf("could not decode test status: %v", err)
+	}
+}
+
+func expectEvent(t *testing.T, recorder *record.FakeRecorder, substring string) {
+	t.Helper()
+	for {
+		select {
+		case event := <-recorder.Events:
+			if strings.Contains(event, substring) {
+				return
+			}
+		default:
+			t.Fatalf("expected an event containing %q", substring)
+		}
+	}
+}
+
+func TestReconcileLayerThree(t *testing.T) {
+	destinationPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "destination"},
+		Status:     corev1.PodStatus{PodIP: "10.0.0.2"},
+	}
+	podExecutor := fake.NewExecutor().
+		On(`^ping -c3 10\.0\.0\.1$`, fake.PingSucceeded("10.0.0.1", 1.1, 2.2, 3.3)).
+		On(`^ping -c3 10\.0\.0\.2$`, fake.PingFailed("ping: permission denied (are you root?)"))
+	r, recorder := newTestReconciler(podExecutor, test.NewFakeClient(sourcePod, destinationPod, newConnectivityTest("3",
+		v1alpha1.NetworkDestinationEndpoint{Kind: v1alpha1.IP, IP: "10.0.0.1"},
+		v1alpha1.NetworkDestinationEndpoint{Kind: v1alpha1.Pod, Namespace: "default", Name: "destination"},
+	)))
+
+	networkConnectivityTest := reconcileTest(t, r)
+
+	status := &v1alpha1.PingStatus{}
+	decodeStatus(t, networkConnectivityTest, status)
+	if len(status.PingIPEndpoints) != 1 || len(status.PingPodEndpoints) != 1 {
+		t.Fatalf("expected one IP and one pod endpoint, got %+v", status)
+	}
+
+	ipResult := status.PingIPEndpoints[0].PingResult
+	if ipResult.State != v1alpha1.SuccessPing || ipResult.Min != "1.1ms" || ipResult.Average != "2.2ms" || ipResult.Max != "3.3ms" {
+		t.Errorf("unexpected result for IP ping: %+v", ipResult)
+	}
+	if ipResult.Exec != nil {
+		t.Errorf("expected no exec details for a successful ping, got %+v", ipResult.Exec)
+	}
+
+	podResult := status.PingPodEndpoints[0].PingResult
+	if podResult.State != v1alpha1.FailedPing {
+		t.Fatalf("expected pod ping to fail, got %+v", podResult)
+	}
+	if podResult.Exec == nil || podResult.Exec.ExitCode != 1 || podResult.Exec.Message != "ping: permission denied (are you root?)" || podResult.Exec.Container != "app" {
+		t.Errorf("unexpected exec details for failed ping: %+v", podResult.Exec)
+	}
+	expectEvent(t, recorder, "ping to 10.0.0.2 failed (exit code 1")
+
+	for _, execution := range podExecutor.Executions() {
+		if execution.Namespace != testSource.Namespace || execution.Name != testSource.Name || execution.Container != testSource.Container {
+			t.Errorf("expected command to run in the source pod, got %s/%s (%s)", execution.Namespace, execution.Name, execution.Container)
+		}
+	}
+	if finalizers := networkConnectivityTest.GetFinalizers(); len(finalizers) != 1 || finalizers[0] != FinalizerName {
+		t.Errorf("expected finalizer %q, got %v", FinalizerName, finalizers)
+	}
+}
+
+func TestReconcileLayerFour(t *testing.T) {
+	podExecutor := fake.NewExecutor().
+		On(`^nc -z -v 10\.0\.0\.1 80 `, fake.NetcatSucceeded("10.0.0.1", "80")).
+		On(`^nc -z -v 10\.0\.0\.1 81 `, fake.NetcatRefused("10.0.0.1", "81"))
+	r, recorder := newTestReconciler(podExecutor, test.NewFakeClient(sourcePod, newConnectivityTest("4",
+		v1alpha1.NetworkDestinationEndpoint{Kind: v1alpha1.IP, IP: "10.0.0.1", Port: "80"},
+		v1alpha1.NetworkDestinationEndpoint{Kind: v1alpha1.IP, IP: "10.0.0.1", Port: "81"},
+	)))
+
+	networkConnectivityTest := reconcileTest(t, r)
+
+	status := &v1alpha1.NetcatStatus{}
+	decodeStatus(t, networkConnectivityTest, status)
+	if len(status.NetcatIPEndpoints) != 2 {
+		t.Fatalf("expected two IP endpoints, got %+v", status)
+	}
+	if result := status.NetcatIPEndpoints[0].NetcatResult; result.State != v1alpha1.Succeeded || result.Exec != nil {
+		t.Errorf("unexpected result for open port: %+v", result)
+	}
+	if result := status.NetcatIPEndpoints[1].NetcatResult; result.State != v1alpha1.Refused || result.Exec == nil || result.Exec.ExitCode != 1 {
+		t.Errorf("unexpected result for closed port: %+v", result)
+	}
+	expectEvent(t, recorder, "netcat to 10.0.0.1:81 failed")
+}
+
+func TestReconcileTransportError(t *testing.T) {
+	podExecutor := fake.NewExecutor().
+		On(`^nc `, fake.Response{Err: context.DeadlineExceeded})
+	r, _ := newTestReconciler(podExecutor, test.NewFakeClient(sourcePod, newConnectivityTest("4",
+		v1alpha1.NetworkDestinationEndpoint{Kind: v1alpha1.IP, IP: "10.0.0.1", Port: "80"},
+	)))
+
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}}); err == nil {
+		t.Fatal("expected reconcile to fail if netcat could not be executed")
+	}
+}
+
+func TestReconcileForbidden(t *testing.T) {
+	podExecutor := fake.NewExecutor()
+	c := test.NewFakeClient(newConnectivityTest("3", v1alpha1.NetworkDestinationEndpoint{Kind: v1alpha1.IP, IP: "10.0.0.1"}))
+	c.Denied = "RBAC: access denied"
+	r, _ := newTestReconciler(podExecutor, c)
+
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}}); err == nil {
+		t.Fatal("expected reconcile to fail without exec permissions")
+	}
+	if commands := podExecutor.Commands(); len(commands) != 0 {
+		t.Errorf("expected no commands to be executed, got %v", commands)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	now := metav1.Now()
+	networkConnectivityTest := newConnectivityTest("3")
+	networkConnectivityTest.Finalizers = []string{FinalizerName}
+	networkConnectivityTest.DeletionTimestamp = &now
+	podExecutor := fake.NewExecutor()
+	r, _ := newTestReconciler(podExecutor, test.NewFakeClient(networkConnectivityTest))
+
+	if _, err := r.Reconcile(reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}}); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	deleted := &v1alpha1.NetworkConnectivityTest{}
+	if err := r.client.Get(r.ctx, types.NamespacedName{Name: "test"}, deleted); err != nil {
+		t.Fatalf("could not get NetworkConnectivityTest: %v", err)
+	}
+	if len(deleted.Finalizers) != 0 {
+		t.Errorf("expected finalizer to be removed, got %v", deleted.Finalizers)
+	}
+	if commands := podExecutor.Commands(); len(commands) != 0 {
+		t.Errorf("expected no commands to be executed, got %v", commands)
+	}
+}
@@ -0,0 +1,221 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/collector"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/controllers/networkmonitor"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/validate-v1alpha1-networkmonitor,mutating=false,failurePolicy=fail,groups="networkmachinery.io",resources=networkmonitors,verbs=create;update,versions=v1alpha1,name=networkmonitor.networkmachinery.io
+
+var (
+	// namePattern matches the names of flows and thresholds, they are part of the paths of the sFlow-RT REST API
+	namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+	// keyPattern matches a key or value of a flow, e.g. ipsource, group:ipsource:zones or mask:ipsource:24
+	keyPattern = regexp.MustCompile(`^[A-Za-z0-9_]+(:[A-Za-z0-9_./-]+)*$`)
+	// metricPattern matches the metric of a threshold, e.g. the name of a flow or max:ifinutilization
+	metricPattern = regexp.MustCompile(`^((max|min|sum|avg):)?[A-Za-z0-9_.-]+$`)
+
+	severities = sets.NewString("info", "warning", "critical")
+)
+
+// MonitorValidator validates the flows and thresholds of NetworkMonitors.
+type MonitorValidator struct {
+	decoder *admission.Decoder
+}
+
+// InjectDecoder injects the decoder.
+func (v *MonitorValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle rejects NetworkMonitors whose flows or thresholds could not be installed or whose thresholds refer to flows
+// which do not exist.
+func (v *MonitorValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	networkMonitor := &v1alpha1.NetworkMonitor{}
+	if err := v.decoder.Decode(req, networkMonitor); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if errs := validateNetworkMonitor(networkMonitor); len(errs) > 0 {
+		return admission.ValidationResponse(false, errs.ToAggregate().Error())
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+func validateNetworkMonitor(networkMonitor *v1alpha1.NetworkMonitor) field.ErrorList {
+	var (
+		allErrs  field.ErrorList
+		specPath = field.NewPath("spec")
+		spec     = networkMonitor.Spec
+	)
+	switch spec.Backend {
+	case "", v1alpha1.BackendTypeSFlowRT:
+		allErrs = append(allErrs, validateMonitoringEndpoint(spec.MonitoringEndpoint, specPath.Child("monitoringEndpoint"))...)
+	case v1alpha1.BackendTypeEmbedded:
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("backend"), spec.Backend, []string{string(v1alpha1.BackendTypeSFlowRT), string(v1alpha1.BackendTypeEmbedded)}))
+	}
+
+	flows := sets.NewString()
+	for i, flow := range spec.Flows {
+		flowPath := specPath.Child("flows").Index(i)
+		if flows.Has(flow.Name) {
+			allErrs = append(allErrs, field.Duplicate(flowPath.Child("name"), flow.Name))
+		}
+		flows.Insert(flow.Name)
+		allErrs = append(allErrs, validateFlow(flow, spec.Backend, flowPath)...)
+	}
+
+	thresholds := sets.NewString()
+	for i, threshold := range spec.Thresholds {
+		thresholdPath := specPath.Child("thresholds").Index(i)
+		if thresholds.Has(threshold.Name) {
+			allErrs = append(allErrs, field.Duplicate(thresholdPath.Child("name"), threshold.Name))
+		}
+		thresholds.Insert(threshold.Name)
+		allErrs = append(allErrs, validateThreshold(threshold, flows, thresholdPath)...)
+	}
+
+	eventsPath := specPath.Child("eventsConfig")
+	allErrs = append(allErrs, validateCount(spec.EventsConfig.MaxEvents, eventsPath.Child("maxEvents"))...)
+	allErrs = append(allErrs, validateCount(spec.EventsConfig.Timeout, eventsPath.Child("timeout"))...)
+
+	retentionPath := specPath.Child("retention")
+	if spec.Retention.MaxNotifications < 0 {
+		allErrs = append(allErrs, field.Invalid(retentionPath.Child("maxNotifications"), spec.Retention.MaxNotifications, "the maximum number of notifications must not be negative"))
+	}
+	if spec.Retention.TTL != nil && spec.Retention.TTL.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(retentionPath.Child("ttl"), spec.Retention.TTL.Duration.String(), "the ttl must not be negative"))
+	}
+	return allErrs
+}
+
+func validateMonitoringEndpoint(endpoint v1alpha1.MonitoringEndpoint, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(endpoint.IP) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("ip"), "the address of sFlow-RT has to be given"))
+	}
+	if port, err := strconv.Atoi(endpoint.Port); err != nil || port < 1 || port > 65535 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("port"), endpoint.Port, "the port of sFlow-RT has to be between 1 and 65535"))
+	}
+	switch endpoint.Scheme {
+	case "", "http", "https":
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("scheme"), endpoint.Scheme, []string{"http", "https"}))
+	}
+	return allErrs
+}
+
+func validateFlow(flow v1alpha1.Flow, backend v1alpha1.BackendType, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if !namePattern.MatchString(flow.Name) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), flow.Name, "the name must consist of alphanumeric characters, '_', '.' or '-'"))
+	}
+	if len(flow.Keys) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("keys"), "at least one key has to be given"))
+	}
+	for _, key := range strings.Split(flow.Keys, ",") {
+		if len(flow.Keys) > 0 && !keyPattern.MatchString(strings.TrimSpace(key)) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("keys"), flow.Keys, "invalid key "+strconv.Quote(key)))
+		}
+	}
+	if !keyPattern.MatchString(flow.Value) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("value"), flow.Value, "the value has to be a single key, e.g. bytes or frames"))
+	}
+	if strings.Count(flow.Filter, "(") != strings.Count(flow.Filter, ")") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("filter"), flow.Filter, "unbalanced parentheses"))
+	}
+	if len(flow.ActiveTimeout) > 0 {
+		if timeout, err := strconv.Atoi(flow.ActiveTimeout); err != nil || timeout <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("activeTimeout"), flow.ActiveTimeout, "the active timeout has to be a positive number of seconds"))
+		}
+	}
+	allErrs = append(allErrs, validateFlag(flow.Log, fldPath.Child("log"))...)
+	allErrs = append(allErrs, validateFlag(flow.FlowStart, fldPath.Child("flowStart"))...)
+	if flow.MaxExportedKeys < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("maxExportedKeys"), flow.MaxExportedKeys, "the maximum number of exported keys must not be negative"))
+	}
+
+	// the embedded collector supports fewer keys and filters than sFlow-RT, they are checked by parsing the flow
+	if backend == v1alpha1.BackendTypeEmbedded && len(allErrs) == 0 {
+		flow.Filter, _ = networkmonitor.KubernetesFilter(flow.Filter)
+		if err := collector.ValidateFlow(flow); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath, flow.Name, "the embedded collector does not support the flow: "+err.Error()))
+		}
+	}
+	return allErrs
+}
+
+func validateThreshold(threshold v1alpha1.Threshold, flows sets.String, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if !namePattern.MatchString(threshold.Name) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), threshold.Name, "the name must consist of alphanumeric characters, '_', '.' or '-'"))
+	}
+	if !metricPattern.MatchString(threshold.Metric) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("metric"), threshold.Metric, "the metric has to be the name of a flow or of a counter metric, e.g. max:ifinutilization"))
+	}
+	if !flows.Has(threshold.FlowName) {
+		allErrs = append(allErrs, field.NotFound(fldPath.Child("flowName"), threshold.FlowName))
+	}
+	allErrs = append(allErrs, validateFlag(threshold.ByFlow, fldPath.Child("byFlow"))...)
+
+	byFlow, _ := strconv.ParseBool(threshold.ByFlow)
+	switch threshold.Direction {
+	case "", v1alpha1.ThresholdDirectionAbove:
+		if threshold.Value < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("value"), threshold.Value, "the value must not be negative"))
+		}
+		if threshold.Hysteresis > threshold.Value {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("hysteresis"), threshold.Hysteresis, "the hysteresis must not exceed the value of an upper bound"))
+		}
+	case v1alpha1.ThresholdDirectionBelow:
+		if threshold.Value <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("value"), threshold.Value, "the value of a lower bound has to be positive"))
+		}
+		if !flows.Has(threshold.Metric) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("metric"), threshold.Metric, "lower bounds are only supported for the flows of the NetworkMonitor"))
+		}
+		if byFlow {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("byFlow"), "lower bounds can not be evaluated by flow"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("direction"), threshold.Direction, []string{string(v1alpha1.ThresholdDirectionAbove), string(v1alpha1.ThresholdDirectionBelow)}))
+	}
+	if threshold.Hysteresis < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("hysteresis"), threshold.Hysteresis, "the hysteresis must not be negative"))
+	}
+	if threshold.Timeout < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("timeout"), threshold.Timeout, "the timeout must not be negative"))
+	}
+	if len(threshold.Severity) > 0 && !severities.Has(threshold.Severity) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("severity"), threshold.Severity, severities.List()))
+	}
+	return allErrs
+}
+
+// validateFlag validates a boolean option of sFlow-RT, options which are not set are false.
+func validateFlag(value string, fldPath *field.Path) field.ErrorList {
+	if _, err := strconv.ParseBool(value); len(value) > 0 && err != nil {
+		return field.ErrorList{field.Invalid(fldPath, value, "has to be true or false")}
+	}
+	return nil
+}
+
+// validateCount validates a non-negative number which is given as string.
+func validateCount(value string, fldPath *field.Path) field.ErrorList {
+	if n, err := strconv.Atoi(value); len(value) > 0 && (err != nil || n < 0) {
+		return field.ErrorList{field.Invalid(fldPath, value, "has to be a non-negative number")}
+	}
+	return nil
+}
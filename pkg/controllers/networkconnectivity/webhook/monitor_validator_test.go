@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/test"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newMonitor(mutate func(*v1alpha1.NetworkMonitorSpec)) *v1alpha1.NetworkMonitor {
+	monitor := &v1alpha1.NetworkMonitor{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "networkmachinery.io/v1alpha1", Kind: "NetworkMonitor"},
+		ObjectMeta: metav1.ObjectMeta{Name: "monitor"},
+		Spec: v1alpha1.NetworkMonitorSpec{
+			MonitoringEndpoint: v1alpha1.MonitoringEndpoint{IP: "sflow-rt", Port: "8008"},
+			Flows: []v1alpha1.Flow{
+				{Name: "tcp-flow", Keys: "ipsource,ipdestination,tcpdestinationport", Value: "bytes", Filter: "namespace=payments&ipprotocol=6"},
+			},
+			Thresholds: []v1alpha1.Threshold{
+				{Name: "elephant", Metric: "tcp-flow", Value: 1000000, FlowName: "tcp-flow", ByFlow: "true", Hysteresis: 100000, Timeout: 30, Severity: "critical"},
+			},
+			EventsConfig: v1alpha1.EventsConfig{MaxEvents: "10", Timeout: "5"},
+		},
+	}
+	if mutate != nil {
+		mutate(&monitor.Spec)
+	}
+	return monitor
+}
+
+func TestMonitorValidator(t *testing.T) {
+	decoder, err := admission.NewDecoder(test.Scheme())
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := &MonitorValidator{}
+	_ = validator.InjectDecoder(decoder)
+
+	tests := []struct {
+		name    string
+		obj     *v1alpha1.NetworkMonitor
+		allowed bool
+		reason  string
+	}{
+		{name: "valid", obj: newMonitor(nil), allowed: true},
+		{name: "embedded", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) {
+			spec.Backend, spec.MonitoringEndpoint = v1alpha1.BackendTypeEmbedded, v1alpha1.MonitoringEndpoint{}
+		}), allowed: true},
+		{name: "unknown backend", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.Backend = "netflow" }), reason: "spec.backend"},
+		{name: "invalid port", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.MonitoringEndpoint.Port = "http" }), reason: "spec.monitoringEndpoint.port"},
+		{name: "duplicate flow", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.Flows = append(spec.Flows, spec.Flows[0]) }), reason: "spec.flows[1].name"},
+		{name: "empty key", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.Flows[0].Keys = "ipsource,,ipdestination" }), reason: "spec.flows[0].keys"},
+		{name: "key function", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.Flows[0].Keys = "mask:ipsource:24,null:vlan:0" }), allowed: true},
+		{name: "unbalanced filter", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.Flows[0].Filter = "(ipprotocol=6" }), reason: "spec.flows[0].filter"},
+		{name: "key not supported by the embedded collector", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) {
+			spec.Backend = v1alpha1.BackendTypeEmbedded
+			spec.Flows[0].Keys = "dnsqname"
+		}), reason: "spec.flows[0]: Invalid value: \"tcp-flow\""},
+		{name: "invalid flag", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.Flows[0].Log = "yes" }), reason: "spec.flows[0].log"},
+		{name: "missing flow", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.Thresholds[0].FlowName = "udp-flow" }), reason: "spec.thresholds[0].flowName"},
+		{name: "invalid byFlow", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.Thresholds[0].ByFlow = "flow" }), reason: "spec.thresholds[0].byFlow"},
+		{name: "invalid metric", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.Thresholds[0].Metric = "tcp flow" }), reason: "spec.thresholds[0].metric"},
+		{name: "hysteresis above value", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.Thresholds[0].Hysteresis = 2000000 }), reason: "spec.thresholds[0].hysteresis"},
+		{name: "negative timeout", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.Thresholds[0].Timeout = -1 }), reason: "spec.thresholds[0].timeout"},
+		{name: "unknown severity", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.Thresholds[0].Severity = "page" }), reason: "spec.thresholds[0].severity"},
+		{name: "lower bound", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) {
+			spec.Thresholds[0].Direction, spec.Thresholds[0].ByFlow = v1alpha1.ThresholdDirectionBelow, ""
+		}), allowed: true},
+		{name: "lower bound by flow", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) {
+			spec.Thresholds[0].Direction = v1alpha1.ThresholdDirectionBelow
+		}), reason: "spec.thresholds[0].byFlow"},
+		{name: "lower bound of a counter metric", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) {
+			spec.Thresholds[0].Direction, spec.Thresholds[0].ByFlow, spec.Thresholds[0].Metric = v1alpha1.ThresholdDirectionBelow, "", "ifinutilization"
+		}), reason: "spec.thresholds[0].metric"},
+		{name: "unknown direction", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.Thresholds[0].Direction = "outside" }), reason: "spec.thresholds[0].direction"},
+		{name: "invalid maxEvents", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.EventsConfig.MaxEvents = "ten" }), reason: "spec.eventsConfig.maxEvents"},
+		{name: "negative retention", obj: newMonitor(func(spec *v1alpha1.NetworkMonitorSpec) { spec.Retention.MaxNotifications = -1 }), reason: "spec.retention.maxNotifications"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := validator.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+				Operation: admissionv1beta1.Create,
+				Object:    raw(t, tt.obj),
+			}})
+			if response.Allowed != tt.allowed {
+				t.Fatalf("expected allowed to be %t, got %+v", tt.allowed, response.Result)
+			}
+			if !tt.allowed && !strings.Contains(string(response.Result.Reason), tt.reason) {
+				t.Errorf("expected reason to mention %s, got %q", tt.reason, response.Result.Reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/policy"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/mutate-requester-v1alpha1,mutating=true,failurePolicy=fail,groups="networkmachinery.io",resources=networkconnectivitytests;networktrafficshapers,verbs=create;update,versions=v1alpha1,name=requester.networkmachinery.io
+
+// RequesterAnnotator records the user who created or last changed the spec of an object in the
+// policy.RequesterAnnotation, the controllers execute tools on behalf of this user.
+type RequesterAnnotator struct {
+	decoder *admission.Decoder
+}
+
+// InjectDecoder injects the decoder.
+func (a *RequesterAnnotator) InjectDecoder(d *admission.Decoder) error {
+	a.decoder = d
+	return nil
+}
+
+// Handle sets the requester annotation. Updates which leave the spec untouched, e.g. the controllers adding their
+// finalizers, keep the previous requester and users can not forge the annotation.
+func (a *RequesterAnnotator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj := &unstructured.Unstructured{}
+	if err := a.decoder.Decode(req, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1beta1.Update {
+		oldObj := &unstructured.Unstructured{}
+		if err := a.decoder.DecodeRaw(req.OldObject, oldObj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if reflect.DeepEqual(obj.Object["spec"], oldObj.Object["spec"]) {
+			return a.keepRequester(req, obj, oldObj)
+		}
+	}
+
+	if err := policy.SetRequester(obj, req.UserInfo); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return patch(req, obj)
+}
+
+func (a *RequesterAnnotator) keepRequester(req admission.Request, obj, oldObj *unstructured.Unstructured) admission.Response {
+	annotations := obj.GetAnnotations()
+	oldValue, ok := oldObj.GetAnnotations()[policy.RequesterAnnotation]
+	if annotations[policy.RequesterAnnotation] == oldValue {
+		return admission.Allowed("")
+	}
+
+	if ok {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[policy.RequesterAnnotation] = oldValue
+	} else {
+		delete(annotations, policy.RequesterAnnotation)
+	}
+	obj.SetAnnotations(annotations)
+	return patch(req, obj)
+}
+
+func patch(req admission.Request, obj *unstructured.Unstructured) admission.Response {
+	marshaled, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
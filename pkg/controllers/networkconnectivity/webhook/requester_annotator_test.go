@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/policy"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/test"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newAnnotator(t *testing.T) *RequesterAnnotator {
+	decoder, err := admission.NewDecoder(test.Scheme())
+	if err != nil {
+		t.Fatal(err)
+	}
+	annotator := &RequesterAnnotator{}
+	_ = annotator.InjectDecoder(decoder)
+	return annotator
+}
+
+func raw(t *testing.T, obj interface{}) runtime.RawExtension {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runtime.RawExtension{Raw: data}
+}
+
+func newShaper(requester string, value string) *v1alpha1.NetworkTrafficShaper {
+	shaper := &v1alpha1.NetworkTrafficShaper{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "networkmachinery.io/v1alpha1", Kind: "NetworkTrafficShaper"},
+		ObjectMeta: metav1.ObjectMeta{Name: "shaper"},
+		Spec: v1alpha1.NetworkTrafficShaperSpec{Targets: []v1alpha1.ShaperTarget{
+			{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: v1alpha1.ShaperConfiguration{Type: v1alpha1.Delay, Device: "eth0", Value: value}},
+		}},
+	}
+	if len(requester) > 0 {
+		_ = policy.SetRequester(shaper, authenticationv1.UserInfo{Username: requester})
+	}
+	return shaper
+}
+
+func handle(t *testing.T, operation admissionv1beta1.Operation, user string, obj, oldObj *v1alpha1.NetworkTrafficShaper) admission.Response {
+	req := admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+		Operation: operation,
+		UserInfo:  authenticationv1.UserInfo{Username: user},
+		Object:    raw(t, obj),
+	}}
+	if oldObj != nil {
+		req.OldObject = raw(t, oldObj)
+	}
+	return newAnnotator(t).Handle(context.TODO(), req)
+}
+
+func TestRequesterAnnotator(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation admissionv1beta1.Operation
+		obj       *v1alpha1.NetworkTrafficShaper
+		oldObj    *v1alpha1.NetworkTrafficShaper
+		patched   bool
+	}{
+		{name: "create", operation: admissionv1beta1.Create, obj: newShaper("", "100ms"), patched: true},
+		{name: "forged on create", operation: admissionv1beta1.Create, obj: newShaper("admin", "100ms"), patched: true},
+		{name: "spec changed", operation: admissionv1beta1.Update, obj: newShaper("bob", "200ms"), oldObj: newShaper("bob", "100ms"), patched: true},
+		{name: "spec unchanged", operation: admissionv1beta1.Update, obj: newShaper("bob", "100ms"), oldObj: newShaper("bob", "100ms")},
+		{name: "forged on unchanged spec", operation: admissionv1beta1.Update, obj: newShaper("alice", "100ms"), oldObj: newShaper("bob", "100ms"), patched: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := handle(t, tt.operation, "alice", tt.obj, tt.oldObj)
+			if !response.Allowed {
+				t.Fatalf("expected request to be allowed, got %+v", response.Result)
+			}
+			if patched := len(response.Patches) > 0; patched != tt.patched {
+				t.Errorf("expected patched to be %t, got patches %v", tt.patched, response.Patches)
+			}
+			for _, patch := range response.Patches {
+				value, ok := patch.Value.(string)
+				if annotations, isMap := patch.Value.(map[string]interface{}); isMap {
+					value, ok = annotations[policy.RequesterAnnotation].(string)
+				}
+				if !ok {
+					t.Fatalf("unexpected patch %+v", patch)
+				}
+				requester := &authenticationv1.UserInfo{}
+				if err := json.Unmarshal([]byte(value), requester); err != nil {
+					t.Fatalf("could not decode requester %q: %v", value, err)
+				}
+				expected := "alice"
+				if tt.oldObj != nil && tt.obj.Spec.Targets[0].ShaperConfig.Value == tt.oldObj.Spec.Targets[0].ShaperConfig.Value {
+					expected = "bob"
+				}
+				if requester.Username != expected {
+					t.Errorf("expected requester %q, got %q", expected, requester.Username)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/guard"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/schedule"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/target"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/tc"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/validate-v1alpha1-networktrafficshaper,mutating=false,failurePolicy=fail,groups="networkmachinery.io",resources=networktrafficshapers,verbs=create;update,versions=v1alpha1,name=networktrafficshaper.networkmachinery.io
+
+// ShaperValidator validates the targets and shaping configurations of NetworkTrafficShapers.
+type ShaperValidator struct {
+	// GuardOptions are the safety guards NetworkTrafficShapers have to respect.
+	GuardOptions *guard.Options
+
+	decoder *admission.Decoder
+}
+
+// InjectDecoder injects the decoder.
+func (v *ShaperValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle rejects NetworkTrafficShapers whose configuration could not be applied by tc.
+func (v *ShaperValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	networkTrafficShaper := &v1alpha1.NetworkTrafficShaper{}
+	if err := v.decoder.Decode(req, networkTrafficShaper); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if errs := validateNetworkTrafficShaper(networkTrafficShaper, v.GuardOptions); len(errs) > 0 {
+		return admission.ValidationResponse(false, errs.ToAggregate().Error())
+	}
+	return admission.ValidationResponse(true, "")
+}
+
+func validateNetworkTrafficShaper(networkTrafficShaper *v1alpha1.NetworkTrafficShaper, guardOptions *guard.Options) field.ErrorList {
+	var (
+		allErrs     field.ErrorList
+		targetsPath = field.NewPath("spec", "targets")
+	)
+	if len(networkTrafficShaper.Spec.Targets) == 0 {
+		allErrs = append(allErrs, field.Required(targetsPath, "at least one target has to be given"))
+	}
+
+	for i, shaperTarget := range networkTrafficShaper.Spec.Targets {
+		targetPath := targetsPath.Index(i)
+		if len(shaperTarget.Namespace) == 0 && shaperTarget.Kind != v1alpha1.Node {
+			allErrs = append(allErrs, field.Required(targetPath.Child("namespace"), "the namespace of the target has to be given"))
+		}
+		switch shaperTarget.Kind {
+		case v1alpha1.Pod:
+			if len(shaperTarget.Name) == 0 {
+				allErrs = append(allErrs, field.Required(targetPath.Child("name"), "a pod target needs a name"))
+			}
+		case v1alpha1.Selector:
+			if shaperTarget.SourceSelector == nil {
+				allErrs = append(allErrs, field.Required(targetPath.Child("targetSelector"), "a selector target needs a selector"))
+				break
+			}
+			allErrs = append(allErrs, metav1validation.ValidateLabelSelector(shaperTarget.SourceSelector, targetPath.Child("targetSelector"))...)
+		case v1alpha1.Node:
+			if len(shaperTarget.Namespace) > 0 {
+				allErrs = append(allErrs, field.Forbidden(targetPath.Child("namespace"), "nodes are not namespaced"))
+			}
+			if (len(shaperTarget.Name) == 0) == (shaperTarget.SourceSelector == nil) {
+				allErrs = append(allErrs, field.Required(targetPath.Child("name"), "a node target needs either a name or a selector"))
+				break
+			}
+			if shaperTarget.SourceSelector != nil {
+				allErrs = append(allErrs, metav1validation.ValidateLabelSelector(shaperTarget.SourceSelector, targetPath.Child("targetSelector"))...)
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(targetPath.Child("kind"), shaperTarget.Kind, []string{string(v1alpha1.Pod), string(v1alpha1.Selector), string(v1alpha1.Node)}))
+		}
+
+		if selection, err := target.ParseSelection(shaperTarget.Mode, shaperTarget.Value); err != nil {
+			allErrs = append(allErrs, field.Invalid(targetPath.Child("value"), shaperTarget.Value, err.Error()))
+		} else if !selection.All() && shaperTarget.Kind != v1alpha1.Selector {
+			allErrs = append(allErrs, field.Forbidden(targetPath.Child("mode"), "only pods matched by a selector can be selected"))
+		}
+
+		_, errs := tc.ShapingFor(shaperTarget.ShaperConfig, targetPath.Child("configuration"))
+		allErrs = append(allErrs, errs...)
+	}
+
+	allErrs = append(allErrs, validateShapingWindow(networkTrafficShaper.Spec, field.NewPath("spec"))...)
+	allErrs = append(allErrs, guardOptions.Validate(networkTrafficShaper)...)
+	return allErrs
+}
+
+func validateShapingWindow(spec v1alpha1.NetworkTrafficShaperSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.Duration != nil && spec.Duration.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("duration"), spec.Duration.Duration.String(), "the duration has to be positive"))
+	}
+	if len(spec.Schedule) > 0 {
+		if spec.Duration == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("duration"), "a schedule needs the duration of its windows"))
+		}
+		if _, err := schedule.Parse(spec.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("schedule"), spec.Schedule, err.Error()))
+		}
+	}
+	return allErrs
+}
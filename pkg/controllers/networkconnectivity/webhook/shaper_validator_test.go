@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/guard"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/test"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestShaperValidator(t *testing.T) {
+	decoder, err := admission.NewDecoder(test.Scheme())
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := &ShaperValidator{GuardOptions: &guard.Options{ProtectedNamespaces: []string{"kube-system"}, MaxPodsPerShaper: 50}}
+	_ = validator.InjectDecoder(decoder)
+
+	netem := func(netem *v1alpha1.NetemConfiguration) *v1alpha1.NetworkTrafficShaper {
+		shaper := newShaper("", "")
+		shaper.Spec.Targets[0].ShaperConfig = v1alpha1.ShaperConfiguration{Device: "eth0", Netem: netem}
+		return shaper
+	}
+	noName := newShaper("", "100ms")
+	noName.Spec.Targets[0].Name = ""
+	selected := func(requirement metav1.LabelSelectorRequirement) *v1alpha1.NetworkTrafficShaper {
+		shaper := newShaper("", "100ms")
+		shaper.Spec.Targets[0].Kind = v1alpha1.Selector
+		shaper.Spec.Targets[0].SourceSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{requirement}}
+		return shaper
+	}
+	sampled := func(mode v1alpha1.SelectionMode, value string) *v1alpha1.NetworkTrafficShaper {
+		shaper := selected(metav1.LabelSelectorRequirement{Key: "app", Operator: metav1.LabelSelectorOpExists})
+		shaper.Spec.Targets[0].Mode, shaper.Spec.Targets[0].Value = mode, value
+		return shaper
+	}
+	node := func(name, namespace string) *v1alpha1.NetworkTrafficShaper {
+		shaper := newShaper("", "100ms")
+		shaper.Spec.Targets[0].Kind, shaper.Spec.Targets[0].Name, shaper.Spec.Targets[0].Namespace = v1alpha1.Node, name, namespace
+		shaper.Annotations = map[string]string{guard.ConfirmAnnotation: "true"}
+		return shaper
+	}
+	scheduled := func(schedule string, duration *metav1.Duration) *v1alpha1.NetworkTrafficShaper {
+		shaper := newShaper("", "100ms")
+		shaper.Spec.Schedule, shaper.Spec.Duration = schedule, duration
+		return shaper
+	}
+
+	tests := []struct {
+		name    string
+		obj     *v1alpha1.NetworkTrafficShaper
+		allowed bool
+		reason  string
+	}{
+		{name: "delay value", obj: newShaper("", "100ms 10ms"), allowed: true},
+		{name: "invalid delay value", obj: newShaper("", "100"), reason: "spec.targets[0].configuration.value"},
+		{name: "pod without name", obj: noName, reason: "spec.targets[0].name"},
+		{
+			name: "netem",
+			obj: netem(&v1alpha1.NetemConfiguration{
+				Delay:     &v1alpha1.NetemDelay{Time: metav1.Duration{Duration: 50 * time.Millisecond}},
+				Loss:      &v1alpha1.NetemLoss{Percentage: "1%", Correlation: "25%"},
+				Duplicate: &v1alpha1.NetemProbability{Percentage: "0.1%"},
+				Slot:      &v1alpha1.NetemSlot{MinDelay: metav1.Duration{Duration: time.Millisecond}},
+			}),
+			allowed: true,
+		},
+		{
+			name:   "correlation without jitter",
+			obj:    netem(&v1alpha1.NetemConfiguration{Delay: &v1alpha1.NetemDelay{Time: metav1.Duration{Duration: 50 * time.Millisecond}, Correlation: "25%"}}),
+			reason: "spec.targets[0].configuration.netem.delay.correlation",
+		},
+		{name: "selector", obj: selected(metav1.LabelSelectorRequirement{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"web"}}), allowed: true},
+		{name: "invalid selector", obj: selected(metav1.LabelSelectorRequirement{Key: "app", Operator: metav1.LabelSelectorOpExists, Values: []string{"web"}}), reason: "spec.targets[0].targetSelector.matchExpressions[0].values"},
+		{name: "percent", obj: sampled(v1alpha1.SelectionModePercent, "30%"), allowed: true},
+		{name: "invalid percentage", obj: sampled(v1alpha1.SelectionModePercent, "120%"), reason: "spec.targets[0].value"},
+		{name: "unsupported mode", obj: sampled("random", ""), reason: "spec.targets[0].value"},
+		{name: "mode of pod target", obj: func() *v1alpha1.NetworkTrafficShaper {
+			shaper := newShaper("", "100ms")
+			shaper.Spec.Targets[0].Mode = v1alpha1.SelectionModeOne
+			return shaper
+		}(), reason: "spec.targets[0].mode"},
+		{name: "node", obj: node("worker-1", ""), allowed: true},
+		{name: "namespaced node", obj: node("worker-1", "default"), reason: "spec.targets[0].namespace"},
+		{name: "node without name", obj: node("", ""), reason: "spec.targets[0].name"},
+		{name: "unconfirmed node", obj: func() *v1alpha1.NetworkTrafficShaper {
+			shaper := node("worker-1", "")
+			shaper.Annotations = nil
+			return shaper
+		}(), reason: guard.ConfirmAnnotation},
+		{name: "protected namespace", obj: func() *v1alpha1.NetworkTrafficShaper {
+			shaper := newShaper("", "100ms")
+			shaper.Spec.Targets[0].Namespace = "kube-system"
+			return shaper
+		}(), reason: "spec.targets[0].namespace"},
+		{name: "fixed above limit", obj: sampled(v1alpha1.SelectionModeFixed, "1000"), reason: "spec.targets[0].value"},
+		{name: "schedule", obj: scheduled("*/30 9-17 * * mon-fri", &metav1.Duration{Duration: 5 * time.Minute}), allowed: true},
+		{name: "schedule without duration", obj: scheduled("@hourly", nil), reason: "spec.duration"},
+		{name: "invalid schedule", obj: scheduled("0 25 * * *", &metav1.Duration{Duration: time.Minute}), reason: "spec.schedule"},
+		{name: "negative duration", obj: scheduled("", &metav1.Duration{Duration: -time.Minute}), reason: "spec.duration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := validator.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+				Operation: admissionv1beta1.Create,
+				Object:    raw(t, tt.obj),
+			}})
+			if response.Allowed != tt.allowed {
+				t.Fatalf("expected allowed to be %t, got %+v", tt.allowed, response.Result)
+			}
+			if !tt.allowed && !strings.Contains(string(response.Result.Reason), tt.reason) {
+				t.Errorf("expected reason to mention %s, got %q", tt.reason, response.Result.Reason)
+			}
+		})
+	}
+}
@@ -15,7 +15,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
-func NewNetworkContrlCmd(ctx context.Context) *cobra.Command {
+func NewNetworkContrlCmd(ctx context.Context, operatorOptions *controllers.OperatorOptions) *cobra.Command {
 	networkMonitorCmdOpts := NetworkControlCmdOptions{
 		ConfigFlags: genericclioptions.NewConfigFlags(true),
 		LeaderElectionOptions: controllers.LeaderElectionOptions{
@@ -26,6 +26,7 @@ func NewNetworkContrlCmd(ctx context.Context) *cobra.Command {
 		ControllerOptions: controllers.ControllerOptions{
 			MaxConcurrentReconciles: 5,
 		},
+		OperatorOptions: operatorOptions,
 	}
 
 	cmd := &cobra.Command{
@@ -40,7 +41,7 @@ func NewNetworkContrlCmd(ctx context.Context) *cobra.Command {
 				utils.LogErrAndExit(err, "Could not update manager scheme")
 			}
 
-			if err := controllers.AddToManager(mgr); err != nil {
+			if err := controllers.AddToManager(mgr, networkMonitorCmdOpts.OperatorOptions); err != nil {
 				utils.LogErrAndExit(err, "Could not add controller to manager")
 			}
 
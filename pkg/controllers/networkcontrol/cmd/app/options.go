@@ -15,6 +15,8 @@ type NetworkControlCmdOptions struct {
 	*genericclioptions.ConfigFlags
 	controllers.LeaderElectionOptions
 	controllers.ControllerOptions
+	// OperatorOptions are set by the flags of the hyper command.
+	OperatorOptions *controllers.OperatorOptions
 }
 
 func (nm *NetworkControlCmdOptions) ApplyLeaderElection(mgr *manager.Options) *manager.Options {
@@ -2,6 +2,8 @@ package controller
 
 import (
 	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/notify"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -12,12 +14,17 @@ import (
 )
 
 // newReconciler returns a new reconcile.Reconciler.
-func newReconciler(mgr manager.Manager) *ReconcileNetworkController {
+func newReconciler(mgr manager.Manager, options *notify.Options) *ReconcileNetworkController {
 	return &ReconcileNetworkController{
 		logger:   log.Log.WithName("network-control-controller"),
 		client:   mgr.GetClient(),
 		scheme:   mgr.GetScheme(),
-		recorder: mgr.GetEventRecorderFor(Name)}
+		recorder: mgr.GetEventRecorderFor(Name),
+		clock:    clock.RealClock{},
+		sender:   notify.NewSender(options),
+		limiters: notify.NewLimiters(),
+		options:  options,
+	}
 }
 
 // DefaultPredicates returns the default predicates for an infrastructure reconciler.
@@ -26,8 +33,8 @@ func DefaultPredicates() []predicate.Predicate {
 }
 
 // Add creates a new NetworkMonitor Controller and adds it to the Manager
-func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr), DefaultPredicates())
+func Add(mgr manager.Manager, options *notify.Options) error {
+	return add(mgr, newReconciler(mgr, options), DefaultPredicates())
 }
 
 func add(mgr manager.Manager, r reconcile.Reconciler, predicates []predicate.Predicate) error {
@@ -40,5 +47,11 @@ func add(mgr manager.Manager, r reconcile.Reconciler, predicates []predicate.Pre
 		return err
 	}
 
+	// the notifications of new or changed routes are delivered right away
+	mapper := &routeMapper{client: mgr.GetClient(), logger: log.Log.WithName(Name)}
+	if err := ctrl.Watch(&source.Kind{Type: &v1alpha1.NetworkNotificationRoute{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapper}, predicates...); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// routeMapper maps NetworkNotificationRoutes to the NetworkNotifications of their namespace.
+type routeMapper struct {
+	client client.Client
+	logger logr.Logger
+}
+
+// Map implements handler.Mapper.
+func (m *routeMapper) Map(obj handler.MapObject) []reconcile.Request {
+	networkNotifications := &v1alpha1.NetworkNotificationList{}
+	if err := m.client.List(context.TODO(), networkNotifications, client.InNamespace(obj.Meta.GetNamespace())); err != nil {
+		m.logger.Error(err, "Could not list NetworkNotifications to map a route event")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(networkNotifications.Items))
+	for _, networkNotification := range networkNotifications.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: networkNotification.Namespace, Name: networkNotification.Name}})
+	}
+	return requests
+}
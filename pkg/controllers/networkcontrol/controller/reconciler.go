@@ -2,16 +2,24 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/networkmachinery/networkmachinery-operators/pkg/notify"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/apimachinery"
 
 	"github.com/go-logr/logr"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -31,6 +39,10 @@ type ReconcileNetworkController struct {
 	ctx      context.Context
 	scheme   *runtime.Scheme
 	recorder record.EventRecorder
+	clock    clock.Clock
+	sender   *notify.Sender
+	limiters *notify.Limiters
+	options  *notify.Options
 }
 
 func (r *ReconcileNetworkController) InjectClient(client client.Client) error {
@@ -53,17 +65,159 @@ func (r *ReconcileNetworkController) Reconcile(request reconcile.Request) (recon
 	}
 
 	r.logger.Info("Received a Network Notification", "Name", networkNotification.Name)
-	r.recorder.Event(networkNotification, v1alpha1.EventTypeNormal, v1alpha1.EventTypeReconciliation, "Received a Network Notification")
 
 	return r.reconcile(r.ctx, networkNotification)
 }
 
-// TODO: implement real actions
+// reconcile delivers <networkNotification> to the receivers of the routes matching it. Occurrences are delivered once
+// per receiver, failed deliveries are retried with a backoff and deliveries above the rate limit of a route are delayed.
+// Firing notifications are sent to Alertmanagers again until they are resolved.
 func (r *ReconcileNetworkController) reconcile(ctx context.Context, networkNotification *v1alpha1.NetworkNotification) (reconcile.Result, error) {
-	r.logger.Info("Who DARES disrupt my Network, YOU SHALL NOT PASS", "Name", networkNotification.Name)
-	r.logger.Info("I CAN Use OpenFlow now, I WILL FIND YOU AND I WILL KILL YOU")
-	r.logger.Info("I Know where you live", "Your SWITCH", networkNotification.Spec.Event.Event.Agent, "Your PORT", networkNotification.Spec.Event.Event.DataSource)
-	return reconcile.Result{
-		RequeueAfter: 30 * time.Second,
-	}, nil
+	routes := &v1alpha1.NetworkNotificationRouteList{}
+	if err := r.client.List(ctx, routes, client.InNamespace(networkNotification.Namespace)); err != nil {
+		return apimachinery.ReconcileErr(err)
+	}
+	sort.Slice(routes.Items, func(i, j int) bool { return routes.Items[i].Name < routes.Items[j].Name })
+
+	var (
+		now          = r.clock.Now()
+		deliveries   = append([]v1alpha1.NotificationDelivery(nil), networkNotification.Status.Deliveries...)
+		requeueAfter time.Duration
+	)
+	for i := range routes.Items {
+		route := &routes.Items[i]
+		matches, err := notify.Matches(route, networkNotification)
+		if err != nil {
+			r.recorder.Eventf(route, v1alpha1.EventTypeWarning, v1alpha1.EventTypeInvalidConfiguration, "Invalid selector: %v", err)
+			continue
+		}
+		if !matches {
+			continue
+		}
+
+		for _, receiver := range route.Spec.Receivers {
+			index := deliveryIndex(deliveries, route.Name, receiver.Name)
+			if index < 0 {
+				deliveries = append(deliveries, v1alpha1.NotificationDelivery{Route: route.Name, Receiver: receiver.Name})
+				index = len(deliveries) - 1
+			}
+			if wait := r.deliver(ctx, route, receiver, networkNotification, &deliveries[index], now); wait > 0 && (requeueAfter == 0 || wait < requeueAfter) {
+				requeueAfter = wait
+			}
+		}
+	}
+
+	if err := apimachinery.TryUpdateStatus(ctx, retry.DefaultBackoff, r.client, networkNotification, func() error {
+		networkNotification.Status.Deliveries = deliveries
+		return nil
+	}); err != nil {
+		return apimachinery.ReconcileErr(err)
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// deliver delivers the occurrences or the resolution of <networkNotification> to <receiver> of <route> unless
+// <delivery> records that they were delivered or failed before. It returns the time after which a pending delivery is attempted again.
+func (r *ReconcileNetworkController) deliver(ctx context.Context, route *v1alpha1.NetworkNotificationRoute, receiver v1alpha1.NotificationReceiver, networkNotification *v1alpha1.NetworkNotification, delivery *v1alpha1.NotificationDelivery, now time.Time) time.Duration {
+	occurrences := networkNotification.Spec.Occurrences
+	resolved := networkNotification.Spec.State == v1alpha1.NotificationStateResolved
+	resend := false
+	if delivery.Occurrences >= occurrences && delivery.Resolved == resolved && len(delivery.State) > 0 && delivery.State != v1alpha1.DeliveryStatePending {
+		// Alertmanager resolves firing alerts which are not sent again within its resolve_timeout
+		if resolved || receiver.Type != v1alpha1.ReceiverTypeAlertmanager || delivery.State != v1alpha1.DeliveryStateDelivered ||
+			delivery.LastAttemptTime == nil || r.options.AlertResendInterval <= 0 {
+			return 0
+		}
+		if next := delivery.LastAttemptTime.Add(r.options.AlertResendInterval); next.After(now) {
+			return next.Sub(now)
+		}
+		resend = true
+	}
+	// new occurrences and resolutions are attempted again even if the previous ones failed
+	if delivery.State != v1alpha1.DeliveryStatePending {
+		delivery.State = v1alpha1.DeliveryStatePending
+		delivery.Attempts = 0
+	}
+	delivery.Occurrences = occurrences
+	delivery.Resolved = resolved
+
+	if delivery.Attempts > 0 && delivery.LastAttemptTime != nil {
+		if next := delivery.LastAttemptTime.Add(r.options.Backoff(delivery.Attempts)); next.After(now) {
+			return next.Sub(now)
+		}
+	}
+	// sending a firing alert again is not a new delivery and not rate limited
+	if !resend {
+		if delay := r.limiters.Reserve(route, receiver.Name, now); delay > 0 {
+			delivery.LastError = "rate limited"
+			return delay
+		}
+	}
+
+	attemptTime := metav1.NewTime(now)
+	delivery.LastAttemptTime = &attemptTime
+	err := r.send(ctx, route, receiver, networkNotification)
+	if err == nil {
+		delivery.State = v1alpha1.DeliveryStateDelivered
+		delivery.Attempts = 0
+		delivery.LastError = ""
+		r.logger.Info("Delivered NetworkNotification", "Name", networkNotification.Name, "Route", route.Name, "Receiver", receiver.Name)
+		if receiver.Type == v1alpha1.ReceiverTypeAlertmanager && !resolved && r.options.AlertResendInterval > 0 {
+			return r.options.AlertResendInterval
+		}
+		return 0
+	}
+
+	delivery.Attempts++
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= r.options.MaxAttempts {
+		delivery.State = v1alpha1.DeliveryStateFailed
+		r.recorder.Eventf(networkNotification, v1alpha1.EventTypeWarning, v1alpha1.EventTypeDeliveryFailed, "Could not deliver to receiver %s of route %s after %d attempts: %v", receiver.Name, route.Name, delivery.Attempts, err)
+		return 0
+	}
+	r.logger.Info("Could not deliver NetworkNotification, retrying", "Name", networkNotification.Name, "Route", route.Name, "Receiver", receiver.Name, "Error", err.Error())
+	return r.options.Backoff(delivery.Attempts)
+}
+
+// send renders the message of <networkNotification> and sends it to <receiver>.
+func (r *ReconcileNetworkController) send(ctx context.Context, route *v1alpha1.NetworkNotificationRoute, receiver v1alpha1.NotificationReceiver, networkNotification *v1alpha1.NetworkNotification) error {
+	url, err := r.receiverURL(ctx, route, receiver)
+	if err != nil {
+		return err
+	}
+	message, err := notify.Message(route.Spec.Template, networkNotification)
+	if err != nil {
+		return fmt.Errorf("could not render the message: %v", err)
+	}
+	return r.sender.Send(ctx, route.Name, receiver, url, networkNotification, message)
+}
+
+// receiverURL returns the URL of <receiver>, it is read from the referenced secret in the namespace of <route> if set.
+func (r *ReconcileNetworkController) receiverURL(ctx context.Context, route *v1alpha1.NetworkNotificationRoute, receiver v1alpha1.NotificationReceiver) (string, error) {
+	ref := receiver.URLSecretRef
+	if ref == nil {
+		if len(receiver.URL) == 0 {
+			return "", fmt.Errorf("receiver %s has no URL", receiver.Name)
+		}
+		return receiver.URL, nil
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: route.Namespace, Name: ref.Name}, secret); err != nil {
+		return "", fmt.Errorf("could not read the URL of receiver %s: %v", receiver.Name, err)
+	}
+	url, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %s for the URL of receiver %s", ref.Name, ref.Key, receiver.Name)
+	}
+	return strings.TrimSpace(string(url)), nil
+}
+
+func deliveryIndex(deliveries []v1alpha1.NotificationDelivery, route, receiver string) int {
+	for i, delivery := range deliveries {
+		if delivery.Route == route && delivery.Receiver == receiver {
+			return i
+		}
+	}
+	return -1
 }
@@ -0,0 +1,276 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/notify"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var (
+	now     = time.Date(2020, 1, 1, 12, 0, 0, 0, time.Local)
+	options = &notify.Options{Timeout: 5 * time.Second, RetryBackoff: 10 * time.Second, MaxRetryBackoff: time.Minute, MaxAttempts: 2, AlertResendInterval: time.Minute}
+)
+
+// receiver counts the deliveries it receives and answers them with its status.
+type receiver struct {
+	mu         sync.Mutex
+	deliveries int
+	status     int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries++
+	w.WriteHeader(r.status)
+}
+
+func newReceiver() (*receiver, *httptest.Server) {
+	r := &receiver{status: http.StatusOK}
+	return r, httptest.NewServer(r)
+}
+
+func newTestReconciler(objects ...runtime.Object) (*ReconcileNetworkController, *record.FakeRecorder, *clock.FakeClock) {
+	recorder := record.NewFakeRecorder(10)
+	fakeClock := clock.NewFakeClock(now)
+	return &ReconcileNetworkController{
+		logger:   log.Log.WithName(Name),
+		client:   test.NewFakeClient(objects...),
+		ctx:      context.TODO(),
+		scheme:   test.Scheme(),
+		recorder: recorder,
+		clock:    fakeClock,
+		sender:   notify.NewSender(options),
+		limiters: notify.NewLimiters(),
+		options:  options,
+	}, recorder, fakeClock
+}
+
+func newNotification(name string, occurrences int32) *v1alpha1.NetworkNotification {
+	return &v1alpha1.NetworkNotification{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: v1alpha1.NetworkNotificationSpec{
+			Event:       v1alpha1.NetworkEvent{Event: v1alpha1.Event{EventID: 1, ThresholdID: "elephant", Agent: "10.0.0.1", DataSource: "2"}},
+			Severity:    "warning",
+			Occurrences: occurrences,
+		},
+	}
+}
+
+func newRoute(url string) *v1alpha1.NetworkNotificationRoute {
+	return &v1alpha1.NetworkNotificationRoute{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "route", UID: "route"},
+		Spec: v1alpha1.NetworkNotificationRouteSpec{
+			Match:     v1alpha1.NotificationMatch{Threshold: "elephant"},
+			Receivers: []v1alpha1.NotificationReceiver{{Name: "hook", Type: v1alpha1.ReceiverTypeWebhook, URL: url}},
+		},
+	}
+}
+
+func requestFor(name string) reconcile.Request {
+	return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}}
+}
+
+func deliveryOf(t *testing.T, r *ReconcileNetworkController, name string) v1alpha1.NotificationDelivery {
+	notification := &v1alpha1.NetworkNotification{}
+	if err := r.client.Get(r.ctx, requestFor(name).NamespacedName, notification); err != nil {
+		t.Fatalf("could not get NetworkNotification: %v", err)
+	}
+	if len(notification.Status.Deliveries) != 1 {
+		t.Fatalf("expected one delivery, got %+v", notification.Status.Deliveries)
+	}
+	return notification.Status.Deliveries[0]
+}
+
+func TestReconcile(t *testing.T) {
+	hook, server := newReceiver()
+	defer server.Close()
+	unmatched := newRoute(server.URL)
+	unmatched.Name = "unmatched"
+	unmatched.Spec.Match.Threshold = "mouse"
+	r, _, _ := newTestReconciler(newNotification("notification", 1), newRoute(server.URL), unmatched)
+
+	for i := 0; i < 2; i++ {
+		result, err := r.Reconcile(requestFor("notification"))
+		if err != nil {
+			t.Fatalf("reconcile failed: %v", err)
+		}
+		if result.RequeueAfter != 0 {
+			t.Errorf("expected no requeue, got %s", result.RequeueAfter)
+		}
+	}
+	if hook.deliveries != 1 {
+		t.Errorf("expected the occurrence to be delivered once, got %d deliveries", hook.deliveries)
+	}
+	if delivery := deliveryOf(t, r, "notification"); delivery.Route != "route" || delivery.Receiver != "hook" || delivery.State != v1alpha1.DeliveryStateDelivered || delivery.Occurrences != 1 {
+		t.Errorf("unexpected delivery %+v", delivery)
+	}
+
+	// new occurrences are delivered again
+	notification := &v1alpha1.NetworkNotification{}
+	if err := r.client.Get(r.ctx, requestFor("notification").NamespacedName, notification); err != nil {
+		t.Fatalf("could not get NetworkNotification: %v", err)
+	}
+	notification.Spec.Occurrences = 2
+	if err := r.client.Update(r.ctx, notification); err != nil {
+		t.Fatalf("could not update NetworkNotification: %v", err)
+	}
+	if _, err := r.Reconcile(requestFor("notification")); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if hook.deliveries != 2 || deliveryOf(t, r, "notification").Occurrences != 2 {
+		t.Errorf("expected the new occurrence to be delivered, got %d deliveries", hook.deliveries)
+	}
+
+	// the resolution is delivered once
+	if err := r.client.Get(r.ctx, requestFor("notification").NamespacedName, notification); err != nil {
+		t.Fatalf("could not get NetworkNotification: %v", err)
+	}
+	notification.Spec.State = v1alpha1.NotificationStateResolved
+	if err := r.client.Update(r.ctx, notification); err != nil {
+		t.Fatalf("could not update NetworkNotification: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(requestFor("notification")); err != nil {
+			t.Fatalf("reconcile failed: %v", err)
+		}
+	}
+	if delivery := deliveryOf(t, r, "notification"); hook.deliveries != 3 || !delivery.Resolved || delivery.State != v1alpha1.DeliveryStateDelivered {
+		t.Errorf("expected the resolution to be delivered once, got %d deliveries and %+v", hook.deliveries, delivery)
+	}
+}
+
+func TestReconcileRetries(t *testing.T) {
+	hook, server := newReceiver()
+	defer server.Close()
+	hook.status = http.StatusInternalServerError
+	r, recorder, fakeClock := newTestReconciler(newNotification("notification", 1), newRoute(server.URL))
+
+	result, err := r.Reconcile(requestFor("notification"))
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if result.RequeueAfter != 10*time.Second {
+		t.Errorf("expected a retry after 10s, got %s", result.RequeueAfter)
+	}
+	if delivery := deliveryOf(t, r, "notification"); delivery.State != v1alpha1.DeliveryStatePending || delivery.Attempts != 1 || len(delivery.LastError) == 0 {
+		t.Errorf("expected a pending delivery, got %+v", delivery)
+	}
+
+	// no attempt is made before the backoff passed
+	fakeClock.Step(5 * time.Second)
+	if result, err := r.Reconcile(requestFor("notification")); err != nil || result.RequeueAfter != 5*time.Second || hook.deliveries != 1 {
+		t.Errorf("expected the retry to wait for the backoff, got %+v, %v after %d deliveries", result, err, hook.deliveries)
+	}
+
+	fakeClock.Step(5 * time.Second)
+	if result, err := r.Reconcile(requestFor("notification")); err != nil || result.RequeueAfter != 0 {
+		t.Errorf("expected the delivery to fail after the last attempt, got %+v, %v", result, err)
+	}
+	if delivery := deliveryOf(t, r, "notification"); delivery.State != v1alpha1.DeliveryStateFailed || delivery.Attempts != 2 {
+		t.Errorf("expected a failed delivery, got %+v", delivery)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected an event for the failed delivery, got %d", len(recorder.Events))
+	}
+}
+
+func TestReconcileSecretURLAndRateLimit(t *testing.T) {
+	hook, server := newReceiver()
+	defer server.Close()
+	route := newRoute("")
+	route.Spec.Receivers[0].URLSecretRef = &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "hook"}, Key: "url"}
+	route.Spec.RateLimit = &v1alpha1.NotificationRateLimit{Deliveries: 1, Period: metav1.Duration{Duration: time.Minute}}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "hook"},
+		Data:       map[string][]byte{"url": []byte(server.URL + "\n")},
+	}
+	r, _, _ := newTestReconciler(newNotification("first", 1), newNotification("second", 1), route, secret)
+
+	if _, err := r.Reconcile(requestFor("first")); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	result, err := r.Reconcile(requestFor("second"))
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if hook.deliveries != 1 {
+		t.Errorf("expected one delivery within the rate limit, got %d", hook.deliveries)
+	}
+	if result.RequeueAfter != time.Minute {
+		t.Errorf("expected the second delivery to be delayed by a minute, got %s", result.RequeueAfter)
+	}
+	if delivery := deliveryOf(t, r, "second"); delivery.State != v1alpha1.DeliveryStatePending || delivery.Attempts != 0 {
+		t.Errorf("expected the rate limited delivery to be pending, got %+v", delivery)
+	}
+}
+
+func TestReconcileResendsFiringAlerts(t *testing.T) {
+	alertmanager, server := newReceiver()
+	defer server.Close()
+	route := newRoute(server.URL)
+	route.Spec.Receivers[0].Type = v1alpha1.ReceiverTypeAlertmanager
+	r, _, fakeClock := newTestReconciler(newNotification("notification", 1), route)
+
+	result, err := r.Reconcile(requestFor("notification"))
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if result.RequeueAfter != time.Minute {
+		t.Errorf("expected the firing alert to be sent again after a minute, got %s", result.RequeueAfter)
+	}
+
+	// the alert is not sent again before the interval passed
+	fakeClock.Step(30 * time.Second)
+	if result, err := r.Reconcile(requestFor("notification")); err != nil || result.RequeueAfter != 30*time.Second || alertmanager.deliveries != 1 {
+		t.Errorf("expected the alert to be sent again after 30s, got %+v, %v after %d deliveries", result, err, alertmanager.deliveries)
+	}
+	fakeClock.Step(30 * time.Second)
+	if result, err := r.Reconcile(requestFor("notification")); err != nil || result.RequeueAfter != time.Minute || alertmanager.deliveries != 2 {
+		t.Errorf("expected the alert to be sent again, got %+v, %v after %d deliveries", result, err, alertmanager.deliveries)
+	}
+
+	// resolved alerts are sent once
+	notification := &v1alpha1.NetworkNotification{}
+	if err := r.client.Get(r.ctx, requestFor("notification").NamespacedName, notification); err != nil {
+		t.Fatalf("could not get NetworkNotification: %v", err)
+	}
+	resolvedAt := metav1.NewTime(fakeClock.Now())
+	notification.Spec.State, notification.Spec.ResolvedAt = v1alpha1.NotificationStateResolved, &resolvedAt
+	if err := r.client.Update(r.ctx, notification); err != nil {
+		t.Fatalf("could not update NetworkNotification: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		fakeClock.Step(time.Minute)
+		if result, err := r.Reconcile(requestFor("notification")); err != nil || result.RequeueAfter != 0 {
+			t.Errorf("expected no requeue of the resolved alert, got %+v, %v", result, err)
+		}
+	}
+	if alertmanager.deliveries != 3 {
+		t.Errorf("expected the resolution to be sent once, got %d deliveries", alertmanager.deliveries)
+	}
+}
+
+func TestReconcileNotFound(t *testing.T) {
+	r, _, _ := newTestReconciler()
+
+	result, err := r.Reconcile(requestFor("missing"))
+	if err != nil || result.Requeue || result.RequeueAfter != 0 {
+		t.Errorf("expected missing notification to be ignored, got %+v, %v", result, err)
+	}
+}
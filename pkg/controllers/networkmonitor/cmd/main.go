@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/networkmachinery/networkmachinery-operators/pkg/controllers"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/controllers/networkmonitor/cmd/app"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils"
 
@@ -9,7 +10,10 @@ import (
 
 func main() {
 	log.SetLogger(log.ZapLogger(false))
-	cmd := app.NewNetworkMonitorCmd(utils.SetupSignalHandlerContext())
+	// the standalone controller takes the flags of the hyper command itself
+	operatorOptions := controllers.NewOperatorOptions()
+	cmd := app.NewNetworkMonitorCmd(utils.SetupSignalHandlerContext(), operatorOptions)
+	operatorOptions.AddFlags(cmd.Flags())
 
 	if err := cmd.Execute(); err != nil {
 		utils.LogErrAndExit(err, "error executing the main controller command")
@@ -2,6 +2,12 @@ package controller
 
 import (
 	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/collector"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/controllers/networkmonitor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/notify"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/identity"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -12,12 +18,19 @@ import (
 )
 
 // newReconciler returns a new reconcile.Reconciler.
-func newReconciler(mgr manager.Manager) *ReconcileNetworkMonitor {
+func newReconciler(mgr manager.Manager, collectorOptions *collector.Options, sflowrtOptions *sflowrt.Options, notifyOptions *notify.Options, exportOptions *networkmonitor.ExportOptions) *ReconcileNetworkMonitor {
 	return &ReconcileNetworkMonitor{
-		logger:   log.Log.WithName("networkmonitor-controller"),
-		client:   mgr.GetClient(),
-		scheme:   mgr.GetScheme(),
-		recorder: mgr.GetEventRecorderFor(Name)}
+		logger:                log.Log.WithName("networkmonitor-controller"),
+		client:                mgr.GetClient(),
+		scheme:                mgr.GetScheme(),
+		recorder:              mgr.GetEventRecorderFor(Name),
+		clock:                 clock.RealClock{},
+		collector:             collector.New(collectorOptions),
+		sflowClients:          sflowrt.NewClients(sflowrtOptions),
+		notificationNamespace: notifyOptions.Namespace,
+		exportOptions:         exportOptions,
+		index:                 identity.NewIndex(),
+	}
 }
 
 // DefaultPredicates returns the default predicates for an infrastructure reconciler.
@@ -26,12 +39,21 @@ func DefaultPredicates() []predicate.Predicate {
 }
 
 // Add creates a new NetworkMonitor Controller and adds it to the Manager
-func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr), DefaultPredicates())
+func Add(mgr manager.Manager, collectorOptions *collector.Options, sflowrtOptions *sflowrt.Options, notifyOptions *notify.Options, exportOptions *networkmonitor.ExportOptions) error {
+	r := newReconciler(mgr, collectorOptions, sflowrtOptions, notifyOptions, exportOptions)
+	// the embedded collector receives datagrams in the background
+	if err := mgr.Add(r.collector); err != nil {
+		return err
+	}
+	if err := r.index.AddToInformers(mgr.GetCache()); err != nil {
+		return err
+	}
+	return add(mgr, r, DefaultPredicates())
 }
 
 func add(mgr manager.Manager, r reconcile.Reconciler, predicates []predicate.Predicate) error {
-	ctrl, err := controller.New(Name, mgr, controller.Options{Reconciler: r})
+	// the events of every NetworkMonitor are long-polled, a single worker would wait for them one after another
+	ctrl, err := controller.New(Name, mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: 5})
 	if err != nil {
 		return err
 	}
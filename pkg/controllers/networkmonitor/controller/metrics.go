@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"sync"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	eventsSeen = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "networkmachinery_networkmonitor_events_seen_total",
+		Help: "Number of sFlow-RT events returned to a NetworkMonitor.",
+	}, []string{"namespace", "networkmonitor"})
+	eventsFiltered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "networkmachinery_networkmonitor_events_filtered_total",
+		Help: "Number of sFlow-RT events which were dropped as they don't belong to a threshold of a NetworkMonitor.",
+	}, []string{"namespace", "networkmonitor"})
+	eventsNotified = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "networkmachinery_networkmonitor_events_notified_total",
+		Help: "Number of sFlow-RT events a NetworkNotification was created for.",
+	}, []string{"namespace", "networkmonitor"})
+	notificationsResolved = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "networkmachinery_networkmonitor_notifications_resolved_total",
+		Help: "Number of NetworkNotifications which were resolved as their values cleared the thresholds.",
+	}, []string{"namespace", "networkmonitor"})
+	flowValue = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "networkmachinery_networkmonitor_flow_value",
+		Help: "Value of a key of an exported flow of a NetworkMonitor, e.g. the bytes per second between two addresses.",
+	}, []string{"namespace", "networkmonitor", "flow", "key"})
+	flowKeysDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "networkmachinery_networkmonitor_flow_keys_dropped_total",
+		Help: "Number of keys of exported flows which were not exported as the maximum number of series was reached.",
+	}, []string{"namespace", "networkmonitor"})
+
+	// exported holds the flow value series of all NetworkMonitors
+	exported = &exportedSeries{series: map[types.NamespacedName]map[[4]string]struct{}{}}
+)
+
+func init() {
+	metrics.Registry.MustRegister(eventsSeen, eventsFiltered, eventsNotified, notificationsResolved, flowValue, flowKeysDropped)
+}
+
+// deleteMetrics deletes the metrics of <networkMonitor>.
+func deleteMetrics(networkMonitor *v1alpha1.NetworkMonitor) {
+	eventsSeen.DeleteLabelValues(networkMonitor.Namespace, networkMonitor.Name)
+	eventsFiltered.DeleteLabelValues(networkMonitor.Namespace, networkMonitor.Name)
+	eventsNotified.DeleteLabelValues(networkMonitor.Namespace, networkMonitor.Name)
+	notificationsResolved.DeleteLabelValues(networkMonitor.Namespace, networkMonitor.Name)
+	flowKeysDropped.DeleteLabelValues(networkMonitor.Namespace, networkMonitor.Name)
+	exported.delete(networkMonitor)
+}
+
+// exportedSeries tracks the label values of the flow value series by NetworkMonitor, so that series of keys which are
+// no longer reported are deleted and the series of all NetworkMonitors can be limited.
+type exportedSeries struct {
+	mu     sync.Mutex
+	series map[types.NamespacedName]map[[4]string]struct{}
+}
+
+// set replaces the flow value series of <networkMonitor> by the <values> of its exported flows, by the names of the
+// flows. Keys are dropped once <maxSeries> series are exported for all NetworkMonitors, it returns the number of
+// dropped keys.
+func (e *exportedSeries) set(networkMonitor *v1alpha1.NetworkMonitor, values map[string][]sflowrt.ActiveFlow, maxSeries int) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	name := types.NamespacedName{Namespace: networkMonitor.Namespace, Name: networkMonitor.Name}
+	others := 0
+	for n, series := range e.series {
+		if n != name {
+			others += len(series)
+		}
+	}
+
+	desired := map[[4]string]float64{}
+	dropped := 0
+	// keys are dropped in the order of the flows in the spec
+	for _, flow := range networkMonitor.Spec.Flows {
+		for _, value := range values[flow.Name] {
+			labels := [4]string{name.Namespace, name.Name, flow.Name, value.Key}
+			if _, ok := desired[labels]; !ok && maxSeries > 0 && others+len(desired) >= maxSeries {
+				dropped++
+				continue
+			}
+			desired[labels] = value.Value
+		}
+	}
+
+	for labels := range e.series[name] {
+		if _, ok := desired[labels]; !ok {
+			flowValue.DeleteLabelValues(labels[:]...)
+		}
+	}
+	series := make(map[[4]string]struct{}, len(desired))
+	for labels, value := range desired {
+		flowValue.WithLabelValues(labels[:]...).Set(value)
+		series[labels] = struct{}{}
+	}
+	e.series[name] = series
+	return dropped
+}
+
+// delete deletes the flow value series of <networkMonitor>.
+func (e *exportedSeries) delete(networkMonitor *v1alpha1.NetworkMonitor) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	name := types.NamespacedName{Namespace: networkMonitor.Namespace, Name: networkMonitor.Name}
+	for labels := range e.series[name] {
+		flowValue.DeleteLabelValues(labels[:]...)
+	}
+	delete(e.series, name)
+}
@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// MonitorUIDLabel labels NetworkNotifications with the UID of the NetworkMonitor they belong to.
+	MonitorUIDLabel = "networkmachinery.io/networkmonitor-uid"
+
+	defaultMaxNotifications = 100
+	defaultSeverity         = "warning"
+)
+
+// occurrences are the events of a threshold and flow key.
+type occurrences struct {
+	// oldest and newest are the first and last of the events
+	oldest, newest v1alpha1.Event
+	count          int32
+}
+
+// notificationName returns the name of the NetworkNotification of the events of <threshold> for <flowKey>. The name of
+// the NetworkMonitor is truncated to keep the name valid, the hash then covers the complete name.
+func notificationName(networkMonitor *v1alpha1.NetworkMonitor, threshold, flowKey string) string {
+	prefix, key := networkMonitor.Name, threshold+"/"+flowKey
+	if maxLength := validation.DNS1123SubdomainMaxLength - 11; len(prefix) > maxLength {
+		prefix, key = strings.TrimRight(prefix[:maxLength], ".-"), prefix+"/"+key
+	}
+	hash := sha256.Sum256([]byte(key))
+	return prefix + "-" + hex.EncodeToString(hash[:])[:10]
+}
+
+// eventTime returns the time <event> occurred at, <now> if sFlow-RT did not report it.
+func eventTime(event v1alpha1.Event, now time.Time) metav1.Time {
+	if event.TimeStamp == 0 {
+		return metav1.NewTime(now)
+	}
+	return metav1.NewTime(time.Unix(0, event.TimeStamp*int64(time.Millisecond)))
+}
+
+// groupEvents groups <events> by the NetworkNotification they are counted in.
+func groupEvents(networkMonitor *v1alpha1.NetworkMonitor, events []v1alpha1.Event) (map[string]*occurrences, []string) {
+	var (
+		groups = map[string]*occurrences{}
+		names  []string
+	)
+	for _, event := range events {
+		name := notificationName(networkMonitor, event.ThresholdID, event.FlowKey)
+		group, ok := groups[name]
+		if !ok {
+			groups[name] = &occurrences{oldest: event, newest: event, count: 1}
+			names = append(names, name)
+			continue
+		}
+		group.count++
+		if later(event, group.newest) {
+			group.newest = event
+		}
+		if later(group.oldest, event) {
+			group.oldest = event
+		}
+	}
+	sort.Strings(names)
+	return groups, names
+}
+
+func later(e1, e2 v1alpha1.Event) bool {
+	if e1.TimeStamp != e2.TimeStamp {
+		return e1.TimeStamp > e2.TimeStamp
+	}
+	return e1.EventID > e2.EventID
+}
+
+// notify counts <events> in the NetworkNotifications of <networkMonitor>. Events of the same threshold and flow key
+// share one NetworkNotification in the notification namespace which is owned by the NetworkMonitor.
+func (r *ReconcileNetworkMonitor) notify(ctx context.Context, networkMonitor *v1alpha1.NetworkMonitor, events []v1alpha1.Event) error {
+	groups, names := groupEvents(networkMonitor, events)
+	for _, name := range names {
+		if err := r.notifyOccurrences(ctx, networkMonitor, name, groups[name]); err != nil {
+			return err
+		}
+		eventsNotified.WithLabelValues(networkMonitor.Namespace, networkMonitor.Name).Add(float64(groups[name].count))
+	}
+	return nil
+}
+
+func (r *ReconcileNetworkMonitor) notifyOccurrences(ctx context.Context, networkMonitor *v1alpha1.NetworkMonitor, name string, group *occurrences) error {
+	eventThreshold, err := getThresholdForName(group.newest.ThresholdID, networkMonitor.Spec.Thresholds)
+	if err != nil {
+		return err
+	}
+	// the flow is only descriptive, events are notified without it rather than blocking the cursor
+	eventFlow, err := getFlowForName(eventThreshold.FlowName, networkMonitor.Spec.Flows)
+	if err != nil {
+		r.logger.Info("The flow of a threshold does not exist", "NetworkMonitor", networkMonitor.Name, "Threshold", eventThreshold.Name, "Flow", eventThreshold.FlowName)
+		eventFlow = &v1alpha1.Flow{}
+	}
+
+	now := r.clock.Now()
+	lastSeen := eventTime(group.newest, now)
+	event := v1alpha1.NetworkEvent{Event: group.newest, Flow: *eventFlow}
+	r.enrich(&event)
+	severity := eventThreshold.Severity
+	if len(severity) == 0 {
+		severity = defaultSeverity
+	}
+
+	networkNotification := &v1alpha1.NetworkNotification{}
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: r.notificationNamespace, Name: name}, networkNotification); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+
+		firstSeen := eventTime(group.oldest, now)
+		networkNotification = &v1alpha1.NetworkNotification{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: r.notificationNamespace,
+				Labels:    notificationLabels(networkMonitor),
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(networkMonitor, v1alpha1.SchemeGroupVersion.WithKind("NetworkMonitor")),
+				},
+			},
+			Spec: v1alpha1.NetworkNotificationSpec{
+				Event:       event,
+				Severity:    severity,
+				Occurrences: group.count,
+				FirstSeen:   &firstSeen,
+				LastSeen:    &lastSeen,
+				State:       v1alpha1.NotificationStateFiring,
+			},
+		}
+		return r.client.Create(ctx, networkNotification)
+	}
+
+	// the events were counted before if the cursor could not be moved after notifying them
+	previous := networkNotification.Spec.Event.Event
+	if previous.EventID == group.newest.EventID && previous.TimeStamp == group.newest.TimeStamp {
+		return nil
+	}
+
+	networkNotification.Labels = notificationLabels(networkMonitor)
+	networkNotification.Spec.Event = event
+	networkNotification.Spec.Severity = severity
+	networkNotification.Spec.Occurrences += group.count
+	networkNotification.Spec.LastSeen = &lastSeen
+	// a new occurrence fires a resolved NetworkNotification again
+	networkNotification.Spec.State = v1alpha1.NotificationStateFiring
+	networkNotification.Spec.ClearSince = nil
+	networkNotification.Spec.ResolvedAt = nil
+	return r.client.Update(ctx, networkNotification)
+}
+
+// enrich attaches the Kubernetes identity of the addresses of the flow key and of the agent to <event>.
+func (r *ReconcileNetworkMonitor) enrich(event *v1alpha1.NetworkEvent) {
+	if r.index == nil {
+		return
+	}
+	event.Agent = r.index.Lookup(event.Event.Agent)
+
+	keys, values := strings.Split(event.Flow.Keys, ","), strings.Split(event.Event.FlowKey, ",")
+	if len(event.Event.FlowKey) == 0 || len(keys) != len(values) {
+		return
+	}
+	for i, key := range keys {
+		switch strings.TrimSpace(key) {
+		case "ipsource", "ip6source":
+			event.Source = r.index.Lookup(values[i])
+		case "ipdestination", "ip6destination":
+			event.Destination = r.index.Lookup(values[i])
+		}
+	}
+}
+
+// notificationLabels returns the labels of the NetworkNotifications of <networkMonitor>, they carry its labels so that
+// routes can select them.
+func notificationLabels(networkMonitor *v1alpha1.NetworkMonitor) map[string]string {
+	labels := map[string]string{}
+	for key, value := range networkMonitor.Labels {
+		labels[key] = value
+	}
+	labels[MonitorUIDLabel] = string(networkMonitor.UID)
+	return labels
+}
+
+// pruneNotifications deletes the NetworkNotifications of <networkMonitor> which were last seen before its TTL and
+// the least recently seen ones exceeding its maximum number of notifications.
+func (r *ReconcileNetworkMonitor) pruneNotifications(ctx context.Context, networkMonitor *v1alpha1.NetworkMonitor) error {
+	networkNotifications := &v1alpha1.NetworkNotificationList{}
+	if err := r.client.List(ctx, networkNotifications, client.InNamespace(r.notificationNamespace), client.MatchingLabels{MonitorUIDLabel: string(networkMonitor.UID)}); err != nil {
+		return err
+	}
+
+	items := networkNotifications.Items
+	sort.Slice(items, func(i, j int) bool { return lastSeen(&items[i]).After(lastSeen(&items[j])) })
+
+	maxNotifications := int(networkMonitor.Spec.Retention.MaxNotifications)
+	if maxNotifications <= 0 {
+		maxNotifications = defaultMaxNotifications
+	}
+	var expiry time.Time
+	if ttl := networkMonitor.Spec.Retention.TTL; ttl != nil && ttl.Duration > 0 {
+		expiry = r.clock.Now().Add(-ttl.Duration)
+	}
+
+	for i := range items {
+		if i < maxNotifications && !lastSeen(&items[i]).Before(expiry) {
+			continue
+		}
+		if err := r.client.Delete(ctx, &items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		r.logger.Info("Deleted NetworkNotification due to the retention", "NetworkMonitor", networkMonitor.Name, "NetworkNotification", items[i].Name)
+	}
+	return nil
+}
+
+func lastSeen(networkNotification *v1alpha1.NetworkNotification) time.Time {
+	if networkNotification.Spec.LastSeen == nil {
+		return networkNotification.CreationTimestamp.Time
+	}
+	return networkNotification.Spec.LastSeen.Time
+}
@@ -2,37 +2,36 @@ package controller
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"net"
-	"net/http"
-	"strconv"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
-	apimachineryerror "github.com/networkmachinery/networkmachinery-operators/pkg/utils/apimachinery/error"
-
-	"gopkg.in/resty.v1"
-
+	"github.com/networkmachinery/networkmachinery-operators/pkg/collector"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/controllers/networkmonitor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/apimachinery"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/identity"
 
 	"github.com/go-logr/logr"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
 	// FinalizerName is the controlplane controller finalizer.
-	FinalizerName       = "networkmachinery.io/networkmonitor"
-	NetworkNotification = "network-notification-"
-	ContentType         = "Content-Type"
-	ApplicationJSON     = "application/json"
+	FinalizerName = "networkmachinery.io/networkmonitor"
+
+	// pollInterval is the time between two queries of the events of a NetworkMonitor.
+	pollInterval = 5 * time.Second
+	// maxEventsTimeout bounds the wait for new events, the workers of the controller are blocked while they wait.
+	maxEventsTimeout = pollInterval
 )
 
 // ReconcileMachineDeployment reconciles a MachineDeployment object.
@@ -42,6 +41,15 @@ type ReconcileNetworkMonitor struct {
 	ctx      context.Context
 	scheme   *runtime.Scheme
 	recorder record.EventRecorder
+	clock    clock.Clock
+	// collector is the embedded backend, it is shared by all NetworkMonitors using it
+	collector    *collector.Collector
+	sflowClients *sflowrt.Clients
+	// notificationNamespace is the namespace NetworkNotifications are created in
+	notificationNamespace string
+	exportOptions         *networkmonitor.ExportOptions
+	// index maps addresses to pods, services and nodes, it is kept up to date by the informers of the manager
+	index *identity.Index
 }
 
 func (r *ReconcileNetworkMonitor) InjectClient(client client.Client) error {
@@ -87,12 +95,17 @@ func (r *ReconcileNetworkMonitor) delete(ctx context.Context, networkmonitor *v1
 	r.logger.Info("Starting the deletion of network monitor", "NetworkMonitor", networkmonitor.Name)
 	r.recorder.Event(networkmonitor, v1alpha1.EventTypeNormal, v1alpha1.EventTypeDeletion, "Deleting the Network Monitor")
 
-	err = r.DeleteFlows(ctx, networkmonitor)
+	backend, err := r.backendFor(networkmonitor)
+	if err != nil {
+		return apimachinery.ReconcileErr(err)
+	}
+
+	err = r.DeleteFlows(ctx, backend, networkmonitor)
 	if err != nil {
 		return apimachinery.ReconcileErr(err)
 	}
 
-	err = r.DeleteThresholds(ctx, networkmonitor)
+	err = r.DeleteThresholds(ctx, backend, networkmonitor)
 	if err != nil {
 		return apimachinery.ReconcileErr(err)
 	}
@@ -104,6 +117,7 @@ func (r *ReconcileNetworkMonitor) delete(ctx context.Context, networkmonitor *v1
 		r.logger.Error(err, "Error removing finalizer from the NetworkMonitor resource", "Network Monitor", networkmonitor.Name)
 		return apimachinery.ReconcileErr(err)
 	}
+	deleteMetrics(networkmonitor)
 
 	return reconcile.Result{}, nil
 }
@@ -113,267 +127,316 @@ func (r *ReconcileNetworkMonitor) reconcile(ctx context.Context, networkMonitor
 		return apimachinery.ReconcileErr(err)
 	}
 
-	err := r.InstallFlows(ctx, networkMonitor)
+	backend, err := r.backendFor(networkMonitor)
 	if err != nil {
 		return apimachinery.ReconcileErr(err)
 	}
 
-	err = r.InstallThresholds(ctx, networkMonitor)
+	if err := r.InstallGroups(ctx, backend, networkMonitor); err != nil {
+		return apimachinery.ReconcileErr(err)
+	}
+
+	flows, err := r.InstallFlows(ctx, backend, networkMonitor)
 	if err != nil {
 		return apimachinery.ReconcileErr(err)
 	}
 
-	events, err := r.CheckEvents(ctx, networkMonitor)
+	thresholds, err := r.InstallThresholds(ctx, backend, networkMonitor)
 	if err != nil {
-		switch err.(type) {
-		case *apimachineryerror.ZeroEventsError:
-			r.logger.Info(err.Error(), "NetworkMonitor", networkMonitor.Name)
-			return reconcile.Result{}, nil
-		default:
-			return apimachinery.ReconcileErr(err)
-		}
+		return apimachinery.ReconcileErr(err)
 	}
 
-	for _, event := range events {
-		err := r.createNetworkNotification(ctx, &event, networkMonitor)
-		if err != nil {
-			return apimachinery.ReconcileErr(err)
-		}
+	if err := apimachinery.TryUpdateStatus(ctx, retry.DefaultBackoff, r.client, networkMonitor, func() error {
+		networkMonitor.Status.InstalledFlows = flows
+		networkMonitor.Status.InstalledThresholds = thresholds
+		return nil
+	}); err != nil {
+		return apimachinery.ReconcileErr(err)
 	}
-	// this is to allow for polling fresh events out of sFlow
-	return reconcile.Result{
-		RequeueAfter: 5 * time.Second,
-	}, nil
-}
 
-func (r *ReconcileNetworkMonitor) InstallFlows(ctx context.Context, networkMonitor *v1alpha1.NetworkMonitor) error {
-	var sflowAddress = net.JoinHostPort(networkMonitor.Spec.MonitoringEndpoint.IP, networkMonitor.Spec.MonitoringEndpoint.Port)
+	events, eventID, err := r.CheckEvents(ctx, backend, networkMonitor)
+	if err != nil {
+		return apimachinery.ReconcileErr(err)
+	}
 
-	flowExists := func(flowName string) (bool, error) {
-		resp, err := resty.R().SetContext(ctx).Get("http://" + sflowAddress + fmt.Sprintf("/flow/%s/json", flowName))
-		if err != nil {
-			return false, err
-		}
+	values := newFlowValues(backend, networkMonitor)
+	lowerBounds, err := r.CheckLowerBounds(ctx, values, networkMonitor)
+	if err != nil {
+		return apimachinery.ReconcileErr(err)
+	}
 
-		// TODO: check other 20X codes
-		if resp.StatusCode() != http.StatusOK {
-			return false, nil
-		}
-		return true, nil
+	if err := r.notify(ctx, networkMonitor, append(events, lowerBounds...)); err != nil {
+		return apimachinery.ReconcileErr(err)
 	}
 
-	installFlow := func(flow v1alpha1.Flow, nm *v1alpha1.NetworkMonitor) error {
-		jsonFlow, err := json.Marshal(flow)
-		if err != nil {
-			return err
-		}
-		url := fmt.Sprintf("http://%s/flow/%s/json", net.JoinHostPort(nm.Spec.MonitoringEndpoint.IP, nm.Spec.MonitoringEndpoint.Port), flow.Name)
-		_, err = resty.R().
-			SetContext(ctx).
-			SetHeader(ContentType, ApplicationJSON).
-			SetBody(jsonFlow).
-			Put(url)
+	// the cursor is only moved once all events are notified, failed notifications are retried with the same events
+	if err := apimachinery.TryUpdateStatus(ctx, retry.DefaultBackoff, r.client, networkMonitor, func() error {
+		networkMonitor.Status.LastEventID = eventID
+		return nil
+	}); err != nil {
+		return apimachinery.ReconcileErr(err)
+	}
 
-		return err
+	if err := r.resolveNotifications(ctx, values, networkMonitor); err != nil {
+		return apimachinery.ReconcileErr(err)
 	}
 
-	for _, flow := range networkMonitor.Spec.Flows {
-		exists, err := flowExists(flow.Name)
-		if err != nil {
-			return err
-		}
-		if !exists {
-			if err := installFlow(flow, networkMonitor); err != nil {
-				return err
-			}
-			r.logger.Info("Installed flow successfully", "NetworkMonitor", flow.Name)
-			r.recorder.Event(networkMonitor, v1alpha1.EventTypeNormal, v1alpha1.EventTypeReconciliation, fmt.Sprintf("Installed flow %s", flow.Name))
-		}
+	if err := r.pruneNotifications(ctx, networkMonitor); err != nil {
+		return apimachinery.ReconcileErr(err)
 	}
 
-	return nil
+	if err := r.ExportFlows(ctx, backend, networkMonitor); err != nil {
+		return apimachinery.ReconcileErr(err)
+	}
+
+	// this is to allow for polling fresh events out of the backend, regardless of whether there were events
+	return reconcile.Result{
+		RequeueAfter: pollInterval,
+	}, nil
 }
 
-func (r *ReconcileNetworkMonitor) DeleteFlows(ctx context.Context, networkMonitor *v1alpha1.NetworkMonitor) error {
-	var sflowAddress = net.JoinHostPort(networkMonitor.Spec.MonitoringEndpoint.IP, networkMonitor.Spec.MonitoringEndpoint.Port)
-	deleteFlow := func(flowName string, nm *v1alpha1.NetworkMonitor) error {
-		resp, err := resty.R().SetContext(ctx).Delete("http://" + sflowAddress + fmt.Sprintf("/flow/%s/json", flowName))
+// backendFor returns the backend the flows and thresholds of <networkMonitor> are installed in.
+func (r *ReconcileNetworkMonitor) backendFor(networkMonitor *v1alpha1.NetworkMonitor) (networkmonitor.Backend, error) {
+	switch networkMonitor.Spec.Backend {
+	case "", v1alpha1.BackendTypeSFlowRT:
+		sflow, err := r.sflowClients.ForEndpoint(networkMonitor.Spec.MonitoringEndpoint)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		// TODO: check other 20X codes
-		if resp.StatusCode() != http.StatusOK {
-			return nil
+		return sflow, nil
+	case v1alpha1.BackendTypeEmbedded:
+		if r.collector == nil {
+			return nil, fmt.Errorf("the embedded collector is not available")
 		}
-		return nil
+		return r.collector, nil
 	}
+	return nil, fmt.Errorf("unknown backend %q", networkMonitor.Spec.Backend)
+}
 
-	for _, flow := range networkMonitor.Spec.Flows {
-		err := deleteFlow(flow.Name, networkMonitor)
-		if err != nil {
+// InstallGroups defines the address groups the Kubernetes filters of the flows of <networkMonitor> refer to in its
+// backend. They are defined on every reconciliation to follow the addresses of pods, services and nodes.
+func (r *ReconcileNetworkMonitor) InstallGroups(ctx context.Context, backend networkmonitor.Backend, networkMonitor *v1alpha1.NetworkMonitor) error {
+	for _, name := range usedGroups(networkMonitor) {
+		if r.index == nil {
+			return fmt.Errorf("the address index for Kubernetes filters is not available")
+		}
+		if err := backend.PutGroup(ctx, name, r.index.Groups(name)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (r *ReconcileNetworkMonitor) InstallThresholds(ctx context.Context, networkMonitor *v1alpha1.NetworkMonitor) error {
-	var sflowAddress = net.JoinHostPort(networkMonitor.Spec.MonitoringEndpoint.IP, networkMonitor.Spec.MonitoringEndpoint.Port)
-	thresholdExists := func(thresholdName string) (bool, error) {
-		resp, err := resty.R().SetContext(ctx).Get("http://" + sflowAddress + fmt.Sprintf("/threshold/%s/json", thresholdName))
-		if err != nil {
-			return false, err
+// InstallFlows installs the flows of <networkMonitor> in its backend, updates flows which drifted from the spec and
+// deletes flows which were removed from it. It returns the names of the installed flows.
+func (r *ReconcileNetworkMonitor) InstallFlows(ctx context.Context, backend networkmonitor.Backend, networkMonitor *v1alpha1.NetworkMonitor) ([]string, error) {
+	installed, err := backend.Flows(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := sets.NewString()
+	for _, flow := range desiredFlows(networkMonitor) {
+		desired.Insert(flow.Name)
+		current, ok := installed[flow.Name]
+		if ok && !flowDrifted(&flow, &current) {
+			continue
 		}
-		// TODO: check other 20X codes
-		if resp.StatusCode() != http.StatusOK {
-			return false, nil
+		if err := backend.PutFlow(ctx, flow); err != nil {
+			return nil, err
 		}
 
-		var threshold v1alpha1.Threshold
-		err = json.Unmarshal(resp.Body(), &threshold)
-		if err != nil {
-			return false, err
+		action := "Installed"
+		if ok {
+			action = "Updated"
 		}
+		r.logger.Info(action+" flow successfully", "NetworkMonitor", networkMonitor.Name, "Flow", flow.Name)
+		r.recorder.Event(networkMonitor, v1alpha1.EventTypeNormal, v1alpha1.EventTypeReconciliation, fmt.Sprintf("%s flow %s", action, flow.Name))
+	}
 
-		specThreshold, err := getThresholdForName(thresholdName, networkMonitor.Spec.Thresholds)
-		if err != nil {
-			return false, err
+	for _, name := range staleNames(networkMonitor, sets.StringKeySet(installed).List(), networkMonitor.Status.InstalledFlows, desired) {
+		if err := backend.DeleteFlow(ctx, name); err != nil {
+			return nil, err
 		}
+		r.logger.Info("Deleted flow successfully", "NetworkMonitor", networkMonitor.Name, "Flow", name)
+		r.recorder.Event(networkMonitor, v1alpha1.EventTypeNormal, v1alpha1.EventTypeReconciliation, fmt.Sprintf("Deleted flow %s", name))
+	}
+
+	return desired.List(), nil
+}
 
-		if !Equal(&threshold, specThreshold) {
-			return false, nil
+// DeleteFlows deletes all flows of <networkMonitor> from its backend.
+func (r *ReconcileNetworkMonitor) DeleteFlows(ctx context.Context, backend networkmonitor.Backend, networkMonitor *v1alpha1.NetworkMonitor) error {
+	installed, err := backend.Flows(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range staleNames(networkMonitor, sets.StringKeySet(installed).List(), networkMonitor.Status.InstalledFlows, sets.NewString()) {
+		if err := backend.DeleteFlow(ctx, name); err != nil {
+			return err
 		}
-		return true, nil
 	}
+	return nil
+}
 
-	installThreshold := func(threshold v1alpha1.Threshold, nm *v1alpha1.NetworkMonitor) error {
-		jsonThreshold, err := json.Marshal(threshold)
-		if err != nil {
-			return err
+// InstallThresholds installs the thresholds of <networkMonitor> in its backend, updates thresholds which drifted from the
+// spec and deletes thresholds which were removed from it. It returns the names of the installed thresholds.
+func (r *ReconcileNetworkMonitor) InstallThresholds(ctx context.Context, backend networkmonitor.Backend, networkMonitor *v1alpha1.NetworkMonitor) ([]string, error) {
+	installed, err := backend.Thresholds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := sets.NewString()
+	for _, threshold := range desiredThresholds(networkMonitor) {
+		desired.Insert(threshold.Name)
+		current, ok := installed[threshold.Name]
+		if ok && Equal(&threshold, &current) {
+			continue
+		}
+		if err := backend.PutThreshold(ctx, threshold); err != nil {
+			return nil, err
 		}
 
-		url := fmt.Sprintf("http://%s/threshold/%s/json", sflowAddress, threshold.Name)
-		if len(threshold.ByFlow) > 0 {
-			_, err = resty.R().
-				SetContext(ctx).
-				SetHeader(ContentType, ApplicationJSON).
-				SetQueryParam("byFlow", "true").
-				SetBody(jsonThreshold).
-				Put(url)
-		} else {
-			_, err = resty.R().
-				SetContext(ctx).
-				SetHeader(ContentType, ApplicationJSON).
-				SetBody(jsonThreshold).
-				Put(url)
+		action := "Installed"
+		if ok {
+			action = "Updated"
 		}
+		r.logger.Info(action+" threshold successfully", "NetworkMonitor", networkMonitor.Name, "Threshold", threshold.Name)
+		r.recorder.Event(networkMonitor, v1alpha1.EventTypeNormal, v1alpha1.EventTypeReconciliation, fmt.Sprintf("%s threshold %s", action, threshold.Name))
+	}
 
-		return err
+	for _, name := range staleNames(networkMonitor, sets.StringKeySet(installed).List(), networkMonitor.Status.InstalledThresholds, desired) {
+		if err := backend.DeleteThreshold(ctx, name); err != nil {
+			return nil, err
+		}
+		r.logger.Info("Deleted threshold successfully", "NetworkMonitor", networkMonitor.Name, "Threshold", name)
+		r.recorder.Event(networkMonitor, v1alpha1.EventTypeNormal, v1alpha1.EventTypeReconciliation, fmt.Sprintf("Deleted threshold %s", name))
 	}
 
-	for _, threshold := range networkMonitor.Spec.Thresholds {
-		exists, err := thresholdExists(threshold.Name)
-		if err != nil {
+	return desired.List(), nil
+}
+
+// DeleteThresholds deletes all thresholds of <networkMonitor> from its backend.
+func (r *ReconcileNetworkMonitor) DeleteThresholds(ctx context.Context, backend networkmonitor.Backend, networkMonitor *v1alpha1.NetworkMonitor) error {
+	installed, err := backend.Thresholds(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range staleNames(networkMonitor, sets.StringKeySet(installed).List(), networkMonitor.Status.InstalledThresholds, sets.NewString()) {
+		if err := backend.DeleteThreshold(ctx, name); err != nil {
 			return err
 		}
-		if !exists {
-			if err := installThreshold(threshold, networkMonitor); err != nil {
-				return err
-			}
-			r.logger.Info("Installed thresholds successfully", "NetworkMonitor", threshold.Name)
-			r.recorder.Event(networkMonitor, v1alpha1.EventTypeNormal, v1alpha1.EventTypeReconciliation, fmt.Sprintf("Installed threshold %s", threshold.Name))
-		}
 	}
 	return nil
 }
 
-func (r *ReconcileNetworkMonitor) DeleteThresholds(ctx context.Context, networkMonitor *v1alpha1.NetworkMonitor) error {
-	var sflowAddress = net.JoinHostPort(networkMonitor.Spec.MonitoringEndpoint.IP, networkMonitor.Spec.MonitoringEndpoint.Port)
-	deleteThreshold := func(thresholdName string, nm *v1alpha1.NetworkMonitor) error {
-		resp, err := resty.R().SetContext(ctx).Delete("http://" + sflowAddress + fmt.Sprintf("/threshold/%s/json", thresholdName))
+// ExportFlows sets the flow value gauges of the exported flows of <networkMonitor> to the values of their largest keys in
+// its backend. Gauges of keys which are no longer among the largest and of flows which are no longer exported are
+// deleted.
+func (r *ReconcileNetworkMonitor) ExportFlows(ctx context.Context, backend networkmonitor.Backend, networkMonitor *v1alpha1.NetworkMonitor) error {
+	values := map[string][]sflowrt.ActiveFlow{}
+	for _, flow := range networkMonitor.Spec.Flows {
+		if !flow.Export {
+			continue
+		}
+		maxKeys := int(flow.MaxExportedKeys)
+		if maxKeys <= 0 {
+			maxKeys = r.exportOptions.MaxKeys
+		}
+		flows, err := backend.ActiveFlows(ctx, "ALL", installedName(networkMonitor, flow.Name), maxKeys)
 		if err != nil {
 			return err
 		}
-		// TODO: check other 20X codes
-		if resp.StatusCode() != http.StatusOK {
-			return nil
+		if len(flows) > maxKeys {
+			flows = flows[:maxKeys]
 		}
-		return nil
+		values[flow.Name] = flows
 	}
 
-	for _, threshold := range networkMonitor.Spec.Thresholds {
-		err := deleteThreshold(threshold.Name, networkMonitor)
-		if err != nil {
-			return err
-		}
+	if dropped := exported.set(networkMonitor, values, r.exportOptions.MaxSeries); dropped > 0 {
+		r.logger.Info("Flow keys were not exported as the maximum number of series was reached", "NetworkMonitor", networkMonitor.Name, "Dropped", dropped)
+		flowKeysDropped.WithLabelValues(networkMonitor.Namespace, networkMonitor.Name).Add(float64(dropped))
 	}
 	return nil
 }
 
-func (r *ReconcileNetworkMonitor) CheckEvents(ctx context.Context, networkMonitor *v1alpha1.NetworkMonitor) ([]v1alpha1.Event, error) {
-	var (
-		sflowAddress = net.JoinHostPort(networkMonitor.Spec.MonitoringEndpoint.IP, networkMonitor.Spec.MonitoringEndpoint.Port)
-		url          = fmt.Sprintf("http://%s/events/json", sflowAddress)
-	)
-	resp, err := resty.R().SetContext(ctx).SetQueryParams(map[string]string{
-		"maxEvents": networkMonitor.Spec.EventsConfig.MaxEvents,
-		"timeout":   networkMonitor.Spec.EventsConfig.Timeout,
-	}).Get(url)
+// CheckEvents returns the events of the thresholds of <networkMonitor> after its last processed event, the names of
+// the thresholds and metrics of the events are the ones of the spec. It waits up to the timeout of the events config,
+// but at most maxEventsTimeout, for new events and returns the ID of the most recent event as new cursor. The event IDs restart when the backend
+// restarts, the cursor is reset if it is beyond the most recent event of the backend.
+func (r *ReconcileNetworkMonitor) CheckEvents(ctx context.Context, backend networkmonitor.Backend, networkMonitor *v1alpha1.NetworkMonitor) ([]v1alpha1.Event, int32, error) {
+	cursor := networkMonitor.Status.LastEventID
+	query, err := eventsQuery(networkMonitor.Spec.EventsConfig)
 	if err != nil {
-		return nil, err
+		return nil, cursor, err
 	}
+	query.EventID = cursor
 
-	if resp.StatusCode() != http.StatusOK {
-		return nil, fmt.Errorf("request returned with non-ok status code %v, reason %v", resp.StatusCode(), resp.Error())
+	events, err := backend.Events(ctx, query)
+	if err != nil {
+		return nil, cursor, err
+	}
+	if cursor > 0 && len(events) == 0 {
+		reset, err := eventsReset(ctx, backend, cursor)
+		if err != nil {
+			return nil, cursor, err
+		}
+		if reset {
+			r.logger.Info("The event IDs of the backend were reset, reading its events from the start", "NetworkMonitor", networkMonitor.Name, "LastEventID", cursor)
+			cursor, query.EventID, query.Timeout = 0, 0, 0
+			if events, err = backend.Events(ctx, query); err != nil {
+				return nil, cursor, err
+			}
+		}
 	}
 
-	var events []v1alpha1.Event
-	err = json.Unmarshal(resp.Body(), &events)
-	if err != nil {
-		return nil, err
+	eventID := cursor
+	oldest := int32(0)
+	for _, event := range events {
+		if event.EventID > eventID {
+			eventID = event.EventID
+		}
+		if oldest == 0 || event.EventID < oldest {
+			oldest = event.EventID
+		}
+	}
+	if cursor > 0 && query.MaxEvents > 0 && len(events) == query.MaxEvents && oldest > cursor+1 {
+		r.logger.Info("More events occurred than maxEvents, older events were skipped", "NetworkMonitor", networkMonitor.Name, "Skipped", oldest-cursor-1)
 	}
+	eventsSeen.WithLabelValues(networkMonitor.Namespace, networkMonitor.Name).Add(float64(len(events)))
 
 	// we don't care about events that we didn't define thresholds for
-	events = filterEvents(events, networkMonitor, func(e v1alpha1.Event, monitor *v1alpha1.NetworkMonitor) bool {
-		var definedThresholds []string
-		for _, threshold := range monitor.Spec.Thresholds {
-			definedThresholds = append(definedThresholds, threshold.Name)
+	related := filterEvents(events, networkMonitor, func(e v1alpha1.Event, monitor *v1alpha1.NetworkMonitor) bool {
+		name, ok := specName(monitor, e.ThresholdID)
+		if !ok {
+			return false
 		}
-		if in(e.ThresholdID, definedThresholds) {
-			return true
-		}
-		return false
+		_, err := getThresholdForName(name, monitor.Spec.Thresholds)
+		return err == nil
 	})
+	eventsFiltered.WithLabelValues(networkMonitor.Namespace, networkMonitor.Name).Add(float64(len(events) - len(related)))
 
-	if len(events) == 0 {
-		return nil, apimachineryerror.NewZeroEventsError("no related events for defined flows / thresholds found to return")
+	for i := range related {
+		related[i].ThresholdID, _ = specName(networkMonitor, related[i].ThresholdID)
+		if metric, ok := specName(networkMonitor, related[i].Metric); ok {
+			related[i].Metric = metric
+		}
 	}
-
-	return events, err
+	return related, eventID, nil
 }
 
-func (r *ReconcileNetworkMonitor) createNetworkNotification(ctx context.Context, event *v1alpha1.Event, networkMonitor *v1alpha1.NetworkMonitor) error {
-	eventThreshold, err := getThresholdForName(event.ThresholdID, networkMonitor.Spec.Thresholds)
+// eventsReset returns true if the most recent event of <backend> is older than <cursor>, i.e. if its event IDs were
+// reset by a restart.
+func eventsReset(ctx context.Context, backend networkmonitor.Backend, cursor int32) (bool, error) {
+	latest, err := backend.Events(ctx, sflowrt.EventsQuery{MaxEvents: 1})
 	if err != nil {
-		return err
+		return false, err
 	}
-
-	eventFlow, err := getFlowForName(eventThreshold.FlowName, networkMonitor.Spec.Flows)
-	if err != nil {
-		return err
-	}
-
-	networkNotification := &v1alpha1.NetworkNotification{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: NetworkNotification + strconv.Itoa(int(event.EventID)),
-		},
-		Spec: v1alpha1.NetworkNotificationSpec{
-			Event: v1alpha1.NetworkEvent{
-				Event: *event,
-				Flow:  *eventFlow,
-			},
-		},
+	for _, event := range latest {
+		if event.EventID < cursor {
+			return true, nil
+		}
 	}
-
-	return apimachinery.CreateOrUpdate(ctx, r.client, networkNotification, nil)
+	return false, nil
 }
@@ -0,0 +1,718 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/collector"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/controllers/networkmonitor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt/fake"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/identity"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var now = time.Date(2020, 1, 1, 12, 0, 0, 0, time.Local)
+
+var request = reconcile.Request{NamespacedName: types.NamespacedName{Name: "monitor"}}
+
+func newTestReconciler(objects ...runtime.Object) *ReconcileNetworkMonitor {
+	return &ReconcileNetworkMonitor{
+		logger:                log.Log.WithName(Name),
+		client:                test.NewFakeClient(objects...),
+		ctx:                   context.TODO(),
+		scheme:                test.Scheme(),
+		recorder:              record.NewFakeRecorder(100),
+		clock:                 clock.NewFakeClock(now),
+		collector:             collector.New(&collector.Options{Smoothing: 10 * time.Second, FlowTimeout: time.Minute, MaxEvents: 10}),
+		sflowClients:          sflowrt.NewClients(&sflowrt.Options{Timeout: 5 * time.Second}),
+		notificationNamespace: "default",
+		exportOptions:         &networkmonitor.ExportOptions{MaxKeys: 2, MaxSeries: 3},
+		index:                 identity.NewIndex(),
+	}
+}
+
+func newMonitor(endpoint v1alpha1.MonitoringEndpoint) *v1alpha1.NetworkMonitor {
+	return &v1alpha1.NetworkMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "monitor", UID: "uid"},
+		Spec: v1alpha1.NetworkMonitorSpec{
+			MonitoringEndpoint: endpoint,
+			Flows:              []v1alpha1.Flow{{Name: "tcp", Keys: "ipsource,ipdestination", Value: "bytes"}},
+			Thresholds:         []v1alpha1.Threshold{{Name: "elephant", Metric: "tcp", Value: 1000, FlowName: "tcp"}},
+			EventsConfig:       v1alpha1.EventsConfig{MaxEvents: "10", Timeout: "1"},
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddEvents(
+		v1alpha1.Event{EventID: 7, ThresholdID: "uid-elephant", Metric: "uid-tcp", Agent: "10.0.0.1", DataSource: "2", Value: 2000},
+		v1alpha1.Event{EventID: 8, ThresholdID: "unrelated"},
+		v1alpha1.Event{EventID: 9, ThresholdID: "elephant"},
+	)
+	monitor := newMonitor(server.Endpoint())
+	monitor.Labels = map[string]string{"team": "network"}
+	r := newTestReconciler(monitor)
+
+	result, err := r.Reconcile(request)
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if result.RequeueAfter != 5*time.Second {
+		t.Errorf("expected requeue after 5s, got %s", result.RequeueAfter)
+	}
+
+	if _, ok := server.Flows()["uid-tcp"]; !ok {
+		t.Errorf("expected flow uid-tcp to be installed, got %v", server.Flows())
+	}
+	if threshold, ok := server.Thresholds()["uid-elephant"]; !ok || threshold.Metric != "uid-tcp" {
+		t.Errorf("expected threshold uid-elephant of the installed flow, got %v", server.Thresholds())
+	}
+
+	notifications := &v1alpha1.NetworkNotificationList{}
+	if err := r.client.List(r.ctx, notifications); err != nil {
+		t.Fatalf("could not list NetworkNotifications: %v", err)
+	}
+	if len(notifications.Items) != 1 {
+		t.Fatalf("expected one NetworkNotification, got %d", len(notifications.Items))
+	}
+	notification := notifications.Items[0]
+	if notification.Name != notificationName(monitor, "elephant", "") || notification.Namespace != "default" ||
+		notification.Spec.Event.Event.Agent != "10.0.0.1" || notification.Spec.Event.Flow.Name != "tcp" ||
+		notification.Spec.Event.Event.ThresholdID != "elephant" || notification.Spec.Event.Event.Metric != "tcp" {
+		t.Errorf("unexpected NetworkNotification %+v", notification)
+	}
+	if owner := metav1.GetControllerOf(&notification); owner == nil || owner.UID != monitor.UID || notification.Labels[MonitorUIDLabel] != "uid" {
+		t.Errorf("expected the NetworkNotification to be owned by the NetworkMonitor, got %+v", notification.ObjectMeta)
+	}
+	if notification.Labels["team"] != "network" || notification.Spec.Severity != "warning" {
+		t.Errorf("expected the labels of the NetworkMonitor and the default severity, got %v, %q", notification.Labels, notification.Spec.Severity)
+	}
+
+	updated := &v1alpha1.NetworkMonitor{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, updated); err != nil {
+		t.Fatalf("could not get NetworkMonitor: %v", err)
+	}
+	if updated.Status.LastEventID != 9 {
+		t.Errorf("expected the cursor to move to the most recent event 9, got %d", updated.Status.LastEventID)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.PutFlow(v1alpha1.Flow{Name: "uid-tcp"})
+	server.PutFlow(v1alpha1.Flow{Name: "uid-removed"})
+	server.PutFlow(v1alpha1.Flow{Name: "other-tcp"})
+	server.PutThreshold(v1alpha1.Threshold{Name: "uid-elephant"})
+
+	now := metav1.Now()
+	monitor := newMonitor(server.Endpoint())
+	monitor.Finalizers = []string{FinalizerName}
+	monitor.DeletionTimestamp = &now
+	r := newTestReconciler(monitor)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if flows := server.Flows(); len(flows) != 1 || len(server.Thresholds()) != 0 {
+		t.Errorf("expected flows and thresholds of the NetworkMonitor to be deleted, got %v and %v", flows, server.Thresholds())
+	}
+
+	deleted := &v1alpha1.NetworkMonitor{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, deleted); err != nil {
+		t.Fatalf("could not get NetworkMonitor: %v", err)
+	}
+	if len(deleted.Finalizers) != 0 {
+		t.Errorf("expected finalizer to be removed, got %v", deleted.Finalizers)
+	}
+}
+
+func TestDeleteFailsOnServerErrors(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.PutFlow(v1alpha1.Flow{Name: "uid-tcp"})
+	server.FailNext(400, 1)
+
+	now := metav1.Now()
+	monitor := newMonitor(server.Endpoint())
+	monitor.Finalizers = []string{FinalizerName}
+	monitor.DeletionTimestamp = &now
+	r := newTestReconciler(monitor)
+
+	if _, err := r.Reconcile(request); err == nil {
+		t.Fatal("expected the rejected deletion to fail the reconciliation")
+	}
+
+	deleted := &v1alpha1.NetworkMonitor{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, deleted); err != nil {
+		t.Fatalf("could not get NetworkMonitor: %v", err)
+	}
+	if len(deleted.Finalizers) != 1 {
+		t.Errorf("expected finalizer to be kept, got %v", deleted.Finalizers)
+	}
+}
+
+func TestReconcileEmbeddedBackend(t *testing.T) {
+	monitor := newMonitor(v1alpha1.MonitoringEndpoint{})
+	monitor.Spec.Backend = v1alpha1.BackendTypeEmbedded
+	monitor.Spec.Thresholds[0].ByFlow = "true"
+	r := newTestReconciler(monitor)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if flows, _ := r.collector.Flows(r.ctx); len(flows) != 1 || flows["uid-tcp"].Keys != "ipsource,ipdestination" {
+		t.Errorf("expected flow uid-tcp to be installed in the embedded collector, got %v", flows)
+	}
+
+	r.collector.Ingest(collector.Record{
+		Agent:  "10.0.0.254",
+		Fields: map[string]string{collector.KeyIPSource: "10.0.0.1", collector.KeyIPDestination: "10.0.0.2"},
+		Bytes:  100000,
+	})
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	notifications := &v1alpha1.NetworkNotificationList{}
+	if err := r.client.List(r.ctx, notifications); err != nil {
+		t.Fatalf("could not list NetworkNotifications: %v", err)
+	}
+	if len(notifications.Items) != 1 || notifications.Items[0].Spec.Event.Event.FlowKey != "10.0.0.1,10.0.0.2" || notifications.Items[0].Spec.Event.Event.ThresholdID != "elephant" {
+		t.Errorf("expected a NetworkNotification of the collected flow, got %+v", notifications.Items)
+	}
+
+	monitor = &v1alpha1.NetworkMonitor{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, monitor); err != nil {
+		t.Fatalf("could not get NetworkMonitor: %v", err)
+	}
+	monitor.Spec.Backend = "unknown"
+	if err := r.client.Update(r.ctx, monitor); err != nil {
+		t.Fatalf("could not update NetworkMonitor: %v", err)
+	}
+	if _, err := r.Reconcile(request); err == nil {
+		t.Error("expected an unknown backend to fail the reconciliation")
+	}
+}
+
+func TestReconcileUpdatesAndRemovesDefinitions(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.PutFlow(v1alpha1.Flow{Name: "uid-tcp", Keys: "ipsource", Value: "bytes"})
+	server.PutFlow(v1alpha1.Flow{Name: "uid-removed", Keys: "ipsource", Value: "bytes"})
+	server.PutFlow(v1alpha1.Flow{Name: "other-tcp", Keys: "ipsource", Value: "bytes"})
+	server.PutThreshold(v1alpha1.Threshold{Name: "uid-elephant", Metric: "uid-tcp", Value: 500})
+	server.PutThreshold(v1alpha1.Threshold{Name: "recorded", Metric: "uid-tcp", Value: 500})
+
+	monitor := newMonitor(server.Endpoint())
+	monitor.Spec.Flows[0].Filter = "ipprotocol=6"
+	monitor.Status.InstalledThresholds = []string{"uid-elephant", "recorded"}
+	r := newTestReconciler(monitor)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	flows := server.Flows()
+	if flow := flows["uid-tcp"]; flow.Keys != "ipsource,ipdestination" || flow.Filter != "ipprotocol=6" {
+		t.Errorf("expected the drifted flow to be updated, got %+v", flow)
+	}
+	if _, ok := flows["uid-removed"]; ok {
+		t.Error("expected the removed flow to be deleted")
+	}
+	if _, ok := flows["other-tcp"]; !ok {
+		t.Error("expected the flow of another NetworkMonitor to be kept")
+	}
+	thresholds := server.Thresholds()
+	if threshold := thresholds["uid-elephant"]; threshold.Value != 1000 {
+		t.Errorf("expected the drifted threshold to be updated, got %+v", threshold)
+	}
+	if _, ok := thresholds["recorded"]; ok {
+		t.Error("expected the recorded threshold to be deleted")
+	}
+
+	updated := &v1alpha1.NetworkMonitor{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, updated); err != nil {
+		t.Fatalf("could not get NetworkMonitor: %v", err)
+	}
+	if !reflect.DeepEqual(updated.Status.InstalledFlows, []string{"uid-tcp"}) || !reflect.DeepEqual(updated.Status.InstalledThresholds, []string{"uid-elephant"}) {
+		t.Errorf("expected the installed flows and thresholds in the status, got %v and %v", updated.Status.InstalledFlows, updated.Status.InstalledThresholds)
+	}
+}
+
+func TestReconcileEventCursor(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddEvents(
+		v1alpha1.Event{EventID: 7, ThresholdID: "uid-elephant", Metric: "uid-tcp"},
+		v1alpha1.Event{EventID: 8, ThresholdID: "uid-elephant", Metric: "uid-tcp"},
+	)
+
+	monitor := newMonitor(server.Endpoint())
+	monitor.Spec.EventsConfig.Timeout = "0"
+	monitor.Status.LastEventID = 7
+	r := newTestReconciler(monitor)
+
+	for i := 0; i < 2; i++ {
+		result, err := r.Reconcile(request)
+		if err != nil {
+			t.Fatalf("reconcile failed: %v", err)
+		}
+		// polling continues without new events
+		if result.RequeueAfter != pollInterval {
+			t.Errorf("expected requeue after %s, got %s", pollInterval, result.RequeueAfter)
+		}
+	}
+
+	notifications := &v1alpha1.NetworkNotificationList{}
+	if err := r.client.List(r.ctx, notifications); err != nil {
+		t.Fatalf("could not list NetworkNotifications: %v", err)
+	}
+	if len(notifications.Items) != 1 || notifications.Items[0].Spec.Event.Event.EventID != 8 || notifications.Items[0].Spec.Occurrences != 1 {
+		t.Errorf("expected a NetworkNotification for the event after the cursor only, got %+v", notifications.Items)
+	}
+
+	var queries []string
+	for _, request := range server.Requests() {
+		if request == "GET /events/json" {
+			queries = append(queries, request)
+		}
+	}
+	// the empty poll checks whether the event IDs were reset
+	if len(queries) != 3 {
+		t.Errorf("expected two event queries and a check for a reset, got %v", server.Requests())
+	}
+}
+
+func TestReconcileEventCursorReset(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddEvents(v1alpha1.Event{EventID: 7, ThresholdID: "uid-elephant", Metric: "uid-tcp"})
+
+	monitor := newMonitor(server.Endpoint())
+	monitor.Spec.EventsConfig.Timeout = "0"
+	monitor.Status.LastEventID = 7
+	r := newTestReconciler(monitor)
+
+	// the restarted backend numbers its events from 1 again
+	server.ResetEvents()
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	server.AddEvents(
+		v1alpha1.Event{ThresholdID: "uid-elephant", Metric: "uid-tcp", FlowKey: "10.0.0.1,10.0.0.2"},
+		v1alpha1.Event{ThresholdID: "uid-elephant", Metric: "uid-tcp", FlowKey: "10.0.0.1,10.0.0.3"},
+	)
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	notifications := &v1alpha1.NetworkNotificationList{}
+	if err := r.client.List(r.ctx, notifications); err != nil {
+		t.Fatalf("could not list NetworkNotifications: %v", err)
+	}
+	if len(notifications.Items) != 2 {
+		t.Errorf("expected a NetworkNotification for each event after the reset, got %+v", notifications.Items)
+	}
+	updated := &v1alpha1.NetworkMonitor{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, updated); err != nil {
+		t.Fatalf("could not get NetworkMonitor: %v", err)
+	}
+	if updated.Status.LastEventID != 2 {
+		t.Errorf("expected the cursor to be reset to the most recent event 2, got %d", updated.Status.LastEventID)
+	}
+}
+
+func TestReconcileDeduplicatesNotifications(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	first, second := now.Add(-time.Minute), now.Add(-time.Second)
+	server.AddEvents(
+		v1alpha1.Event{EventID: 1, ThresholdID: "uid-elephant", FlowKey: "10.0.0.1,10.0.0.2", TimeStamp: first.UnixNano() / int64(time.Millisecond)},
+		v1alpha1.Event{EventID: 2, ThresholdID: "uid-elephant", FlowKey: "10.0.0.1,10.0.0.3"},
+		v1alpha1.Event{EventID: 3, ThresholdID: "uid-elephant", FlowKey: "10.0.0.1,10.0.0.2", TimeStamp: second.UnixNano() / int64(time.Millisecond)},
+	)
+
+	monitor := newMonitor(server.Endpoint())
+	monitor.Spec.EventsConfig.Timeout = "0"
+	r := newTestReconciler(monitor)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	// events which were notified but not recorded in the cursor are not counted twice
+	reset := &v1alpha1.NetworkMonitor{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, reset); err != nil {
+		t.Fatalf("could not get NetworkMonitor: %v", err)
+	}
+	reset.Status.LastEventID = 0
+	if err := r.client.Status().Update(r.ctx, reset); err != nil {
+		t.Fatalf("could not reset the cursor: %v", err)
+	}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	notifications := &v1alpha1.NetworkNotificationList{}
+	if err := r.client.List(r.ctx, notifications); err != nil {
+		t.Fatalf("could not list NetworkNotifications: %v", err)
+	}
+	if len(notifications.Items) != 2 {
+		t.Fatalf("expected a NetworkNotification per flow key, got %d", len(notifications.Items))
+	}
+
+	notification := &v1alpha1.NetworkNotification{}
+	key := client.ObjectKey{Namespace: "default", Name: notificationName(monitor, "elephant", "10.0.0.1,10.0.0.2")}
+	if err := r.client.Get(r.ctx, key, notification); err != nil {
+		t.Fatalf("could not get NetworkNotification: %v", err)
+	}
+	if notification.Spec.Occurrences != 2 || notification.Spec.Event.Event.EventID != 3 {
+		t.Errorf("expected two occurrences up to event 3, got %d up to %d", notification.Spec.Occurrences, notification.Spec.Event.Event.EventID)
+	}
+	if !notification.Spec.FirstSeen.Time.Equal(first.Truncate(time.Millisecond)) || !notification.Spec.LastSeen.Time.Equal(second.Truncate(time.Millisecond)) {
+		t.Errorf("expected the notification to be seen from %s to %s, got %s to %s", first, second, notification.Spec.FirstSeen, notification.Spec.LastSeen)
+	}
+}
+
+func TestReconcileNotificationRetention(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+
+	monitor := newMonitor(server.Endpoint())
+	monitor.Spec.EventsConfig.Timeout = "0"
+	monitor.Spec.Retention = v1alpha1.NotificationRetention{MaxNotifications: 2, TTL: &metav1.Duration{Duration: time.Hour}}
+
+	objects := []runtime.Object{monitor}
+	for i, age := range []time.Duration{2 * time.Hour, time.Minute, 2 * time.Minute, 3 * time.Minute} {
+		lastSeen := metav1.NewTime(now.Add(-age))
+		objects = append(objects, &v1alpha1.NetworkNotification{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("notification-%d", i), Labels: map[string]string{MonitorUIDLabel: "uid"}},
+			Spec:       v1alpha1.NetworkNotificationSpec{LastSeen: &lastSeen},
+		})
+	}
+	objects = append(objects, &v1alpha1.NetworkNotification{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other", Labels: map[string]string{MonitorUIDLabel: "other"}},
+	})
+	r := newTestReconciler(objects...)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	notifications := &v1alpha1.NetworkNotificationList{}
+	if err := r.client.List(r.ctx, notifications); err != nil {
+		t.Fatalf("could not list NetworkNotifications: %v", err)
+	}
+	var names []string
+	for _, notification := range notifications.Items {
+		names = append(names, notification.Name)
+	}
+	sort.Strings(names)
+	if expected := []string{"notification-1", "notification-2", "other"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected NetworkNotifications %v to be kept, got %v", expected, names)
+	}
+}
+
+// exportedValues returns the exported flow values of the NetworkMonitor of the test request by flow and key.
+func exportedValues(t *testing.T) map[string]float64 {
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("could not gather metrics: %v", err)
+	}
+	values := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "networkmachinery_networkmonitor_flow_value" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == request.Namespace && labels["networkmonitor"] == request.Name {
+				values[labels["flow"]+"/"+labels["key"]] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+	return values
+}
+
+func TestReconcileExportsFlows(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.SetActiveFlows("ALL", "uid-tcp",
+		sflowrt.ActiveFlow{Key: "10.0.0.1,10.0.0.2", Value: 3000},
+		sflowrt.ActiveFlow{Key: "10.0.0.1,10.0.0.3", Value: 2000},
+		sflowrt.ActiveFlow{Key: "10.0.0.1,10.0.0.4", Value: 1000},
+	)
+	server.SetActiveFlows("ALL", "uid-udp", sflowrt.ActiveFlow{Key: "10.0.0.1", Value: 500}, sflowrt.ActiveFlow{Key: "10.0.0.2", Value: 400})
+
+	monitor := newMonitor(server.Endpoint())
+	monitor.Spec.EventsConfig.Timeout = "0"
+	monitor.Spec.Flows[0].Export = true
+	monitor.Spec.Flows = append(monitor.Spec.Flows,
+		v1alpha1.Flow{Name: "udp", Keys: "ipsource", Value: "bytes", Export: true, MaxExportedKeys: 5},
+		v1alpha1.Flow{Name: "icmp", Keys: "ipsource", Value: "frames"},
+	)
+	r := newTestReconciler(monitor)
+	defer deleteMetrics(monitor)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if flow := server.Flows()["uid-udp"]; flow.Export || flow.MaxExportedKeys != 0 {
+		t.Errorf("expected the export options not to be installed, got %+v", flow)
+	}
+	// the two largest keys of tcp are exported, the series limit leaves room for one key of udp
+	expected := map[string]float64{"tcp/10.0.0.1,10.0.0.2": 3000, "tcp/10.0.0.1,10.0.0.3": 2000, "udp/10.0.0.1": 500}
+	if values := exportedValues(t); !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected exported values %v, got %v", expected, values)
+	}
+
+	updated := &v1alpha1.NetworkMonitor{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, updated); err != nil {
+		t.Fatalf("could not get NetworkMonitor: %v", err)
+	}
+	updated.Spec.Flows[1].Export = false
+	if err := r.client.Update(r.ctx, updated); err != nil {
+		t.Fatalf("could not update NetworkMonitor: %v", err)
+	}
+	server.SetActiveFlows("ALL", "uid-tcp", sflowrt.ActiveFlow{Key: "10.0.0.1,10.0.0.3", Value: 2500})
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	expected = map[string]float64{"tcp/10.0.0.1,10.0.0.3": 2500}
+	if values := exportedValues(t); !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected the series of keys and flows which are no longer exported to be deleted, got %v", values)
+	}
+
+	deleteMetrics(updated)
+	if values := exportedValues(t); len(values) != 0 {
+		t.Errorf("expected the series of the NetworkMonitor to be deleted, got %v", values)
+	}
+}
+
+func TestDeleteMetricsOfNamespace(t *testing.T) {
+	first, second := newMonitor(v1alpha1.MonitoringEndpoint{}), newMonitor(v1alpha1.MonitoringEndpoint{})
+	second.Namespace = "staging"
+	defer deleteMetrics(second)
+	eventsSeen.WithLabelValues(first.Namespace, first.Name).Add(1)
+	eventsSeen.WithLabelValues(second.Namespace, second.Name).Add(2)
+
+	// the counters of a NetworkMonitor with the same name in another namespace are kept
+	deleteMetrics(first)
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("could not gather metrics: %v", err)
+	}
+	seen := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "networkmachinery_networkmonitor_events_seen_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["networkmonitor"] == first.Name {
+				seen[labels["namespace"]+"/"+labels["networkmonitor"]] = metric.GetCounter().GetValue()
+			}
+		}
+	}
+	if expected := map[string]float64{"staging/monitor": 2}; !reflect.DeepEqual(seen, expected) {
+		t.Errorf("expected events seen %v, got %v", expected, seen)
+	}
+}
+
+func TestReconcileKubernetesIdentity(t *testing.T) {
+	monitor := newMonitor(v1alpha1.MonitoringEndpoint{})
+	monitor.Spec.Backend = v1alpha1.BackendTypeEmbedded
+	monitor.Spec.Flows[0].Filter = "sourcenamespace=payments"
+	monitor.Spec.Thresholds[0].ByFlow = "true"
+	r := newTestReconciler(monitor)
+
+	controller := true
+	r.index.OnAdd(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "payments",
+			Name:            "api-1",
+			Labels:          map[string]string{"app": "api"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "api", Controller: &controller}},
+		},
+		Spec:   corev1.PodSpec{NodeName: "worker-1"},
+		Status: corev1.PodStatus{PodIP: "10.244.1.5"},
+	})
+	r.index.OnAdd(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "orders", Name: "orders"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.20"},
+	})
+	r.index.OnAdd(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+		Status:     corev1.NodeStatus{Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "192.168.1.10"}}},
+	})
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if groups, _ := r.collector.Groups(r.ctx); len(groups[identity.NamespaceGroups]["payments"]) != 1 {
+		t.Errorf("expected the namespace groups to be installed, got %v", groups)
+	}
+
+	r.collector.Ingest(
+		collector.Record{Agent: "192.168.1.10", Fields: map[string]string{collector.KeyIPSource: "10.244.1.5", collector.KeyIPDestination: "10.96.0.20"}, Bytes: 100000},
+		collector.Record{Agent: "192.168.1.10", Fields: map[string]string{collector.KeyIPSource: "10.96.0.20", collector.KeyIPDestination: "10.244.1.5"}, Bytes: 100000},
+	)
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	notifications := &v1alpha1.NetworkNotificationList{}
+	if err := r.client.List(r.ctx, notifications); err != nil {
+		t.Fatalf("could not list NetworkNotifications: %v", err)
+	}
+	if len(notifications.Items) != 1 {
+		t.Fatalf("expected a NetworkNotification of the flow from the namespace only, got %+v", notifications.Items)
+	}
+	event := notifications.Items[0].Spec.Event
+	expected := &v1alpha1.Endpoint{IP: "10.244.1.5", Kind: "Pod", Name: "api-1", Namespace: "payments", Workload: "StatefulSet/api", Labels: map[string]string{"app": "api"}, Node: "worker-1"}
+	if !reflect.DeepEqual(event.Source, expected) {
+		t.Errorf("expected source %+v, got %+v", expected, event.Source)
+	}
+	if event.Destination == nil || event.Destination.Kind != "Service" || event.Destination.Namespace != "orders" {
+		t.Errorf("expected the service as destination, got %+v", event.Destination)
+	}
+	if event.Agent == nil || event.Agent.Kind != "Node" || event.Agent.Name != "worker-1" {
+		t.Errorf("expected the node as agent, got %+v", event.Agent)
+	}
+}
+
+func TestReconcileResolvesNotifications(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddEvents(v1alpha1.Event{EventID: 1, ThresholdID: "uid-elephant", Metric: "uid-tcp", FlowKey: "10.0.0.1,10.0.0.2", Value: 2000})
+	server.SetActiveFlows("ALL", "uid-tcp", sflowrt.ActiveFlow{Key: "10.0.0.1,10.0.0.2", Value: 900})
+
+	monitor := newMonitor(server.Endpoint())
+	monitor.Spec.EventsConfig.Timeout = "0"
+	monitor.Spec.Thresholds = []v1alpha1.Threshold{
+		{Name: "elephant", Metric: "tcp", Value: 1000, FlowName: "tcp", ByFlow: "true", Hysteresis: 200, Timeout: 10},
+		{Name: "idle", Metric: "tcp", Value: 100, FlowName: "tcp", Direction: v1alpha1.ThresholdDirectionBelow},
+	}
+	r := newTestReconciler(monitor)
+	fakeClock := r.clock.(*clock.FakeClock)
+	defer deleteMetrics(monitor)
+
+	elephant, idle := notificationName(monitor, "elephant", "10.0.0.1,10.0.0.2"), notificationName(monitor, "idle", "")
+	notificationOf := func(name string) *v1alpha1.NetworkNotification {
+		notification := &v1alpha1.NetworkNotification{}
+		if err := r.client.Get(r.ctx, client.ObjectKey{Namespace: "default", Name: name}, notification); err != nil {
+			t.Fatalf("could not get NetworkNotification %s: %v", name, err)
+		}
+		return notification
+	}
+	reconcileAfter := func(step time.Duration) {
+		fakeClock.Step(step)
+		if _, err := r.Reconcile(request); err != nil {
+			t.Fatalf("reconcile failed: %v", err)
+		}
+	}
+
+	// the value is within the hysteresis of the threshold
+	reconcileAfter(0)
+	if thresholds := server.Thresholds(); len(thresholds) != 1 || thresholds["uid-elephant"].Hysteresis != 0 || thresholds["uid-elephant"].Timeout != 0 {
+		t.Errorf("expected only the upper bound to be installed without the options of the controller, got %v", thresholds)
+	}
+	if notification := notificationOf(elephant); notification.Spec.State != v1alpha1.NotificationStateFiring || notification.Spec.ClearSince != nil {
+		t.Errorf("expected a firing NetworkNotification, got %+v", notification.Spec)
+	}
+
+	// the value cleared the upper bound and fell below the lower bound
+	server.SetActiveFlows("ALL", "uid-tcp", sflowrt.ActiveFlow{Key: "10.0.0.1,10.0.0.2", Value: 50, Agent: "10.0.0.254"})
+	reconcileAfter(5 * time.Second)
+	if notification := notificationOf(elephant); notification.Spec.State != v1alpha1.NotificationStateFiring || notification.Spec.ClearSince == nil {
+		t.Errorf("expected the NetworkNotification to fire until the timeout, got %+v", notification.Spec)
+	}
+	if notification := notificationOf(idle); notification.Spec.State != v1alpha1.NotificationStateFiring ||
+		notification.Spec.Event.Event.Value != 50 || notification.Spec.Event.Event.Agent != "10.0.0.254" {
+		t.Errorf("expected a firing NetworkNotification of the lower bound, got %+v", notification.Spec)
+	}
+
+	reconcileAfter(10 * time.Second)
+	if notification := notificationOf(elephant); notification.Spec.State != v1alpha1.NotificationStateResolved || notification.Spec.ResolvedAt == nil || notification.Spec.ClearSince != nil {
+		t.Errorf("expected the NetworkNotification to be resolved after the timeout, got %+v", notification.Spec)
+	}
+	if notification := notificationOf(idle); notification.Spec.State != v1alpha1.NotificationStateFiring || notification.Spec.Occurrences != 1 {
+		t.Errorf("expected the lower bound to fire once, got %+v", notification.Spec)
+	}
+
+	// the value is back above the lower bound and exceeds the upper bound again
+	server.SetActiveFlows("ALL", "uid-tcp", sflowrt.ActiveFlow{Key: "10.0.0.1,10.0.0.2", Value: 1500})
+	server.AddEvents(v1alpha1.Event{EventID: 2, TimeStamp: 1, ThresholdID: "uid-elephant", Metric: "uid-tcp", FlowKey: "10.0.0.1,10.0.0.2", Value: 1500})
+	reconcileAfter(5 * time.Second)
+	if notification := notificationOf(idle); notification.Spec.State != v1alpha1.NotificationStateResolved {
+		t.Errorf("expected the lower bound to be resolved, got %+v", notification.Spec)
+	}
+	if notification := notificationOf(elephant); notification.Spec.State != v1alpha1.NotificationStateFiring || notification.Spec.ResolvedAt != nil || notification.Spec.Occurrences != 2 {
+		t.Errorf("expected the NetworkNotification to fire again, got %+v", notification.Spec)
+	}
+}
+
+func TestEventsQuery(t *testing.T) {
+	tests := []struct {
+		timeout  string
+		expected time.Duration
+	}{
+		{timeout: "", expected: 0},
+		{timeout: "2", expected: 2 * time.Second},
+		{timeout: "60", expected: maxEventsTimeout},
+	}
+	for _, tt := range tests {
+		query, err := eventsQuery(v1alpha1.EventsConfig{Timeout: tt.timeout})
+		if err != nil {
+			t.Fatalf("unexpected error for timeout %q: %v", tt.timeout, err)
+		}
+		if query.Timeout != tt.expected {
+			t.Errorf("expected a timeout of %s for %q, got %s", tt.expected, tt.timeout, query.Timeout)
+		}
+	}
+}
+
+func TestNotificationName(t *testing.T) {
+	monitor := newMonitor(v1alpha1.MonitoringEndpoint{})
+	if name := notificationName(monitor, "elephant", ""); !strings.HasPrefix(name, "monitor-") || len(name) != len("monitor-")+10 {
+		t.Errorf("expected the name of the monitor and a hash, got %q", name)
+	}
+
+	// the truncated part of long names ends with a dot
+	first, second := newMonitor(v1alpha1.MonitoringEndpoint{}), newMonitor(v1alpha1.MonitoringEndpoint{})
+	first.Name = strings.Repeat("a", 241) + ".first"
+	second.Name = strings.Repeat("a", 241) + ".second"
+	firstName, secondName := notificationName(first, "elephant", ""), notificationName(second, "elephant", "")
+	for _, name := range []string{firstName, secondName} {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			t.Errorf("expected a valid name, got %q: %v", name, errs)
+		}
+	}
+	if firstName == secondName {
+		t.Errorf("expected monitors with the same truncated name to get different names, got %q", firstName)
+	}
+}
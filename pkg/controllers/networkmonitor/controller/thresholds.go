@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/controllers/networkmonitor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// flowValues reads the values of the keys of the flows of a NetworkMonitor from its backend, each flow is read once per
+// reconciliation.
+type flowValues struct {
+	backend        networkmonitor.Backend
+	networkMonitor *v1alpha1.NetworkMonitor
+	flows          map[string][]sflowrt.ActiveFlow
+}
+
+func newFlowValues(backend networkmonitor.Backend, networkMonitor *v1alpha1.NetworkMonitor) *flowValues {
+	return &flowValues{backend: backend, networkMonitor: networkMonitor, flows: map[string][]sflowrt.ActiveFlow{}}
+}
+
+// of returns the values of the keys of the flow <name>.
+func (v *flowValues) of(ctx context.Context, name string) ([]sflowrt.ActiveFlow, error) {
+	if flows, ok := v.flows[name]; ok {
+		return flows, nil
+	}
+	flows, err := v.backend.ActiveFlows(ctx, "ALL", installedName(v.networkMonitor, name), 0)
+	if err != nil {
+		return nil, err
+	}
+	v.flows[name] = flows
+	return flows, nil
+}
+
+// value returns the value of the metric of <threshold> for <flowKey>, the largest value of all keys if the threshold
+// is not by flow. Keys which are not reported have no traffic. It returns false if the metric is not a flow of the
+// NetworkMonitor.
+func (v *flowValues) value(ctx context.Context, threshold *v1alpha1.Threshold, flowKey string) (sflowrt.ActiveFlow, bool, error) {
+	if _, err := getFlowForName(threshold.Metric, v.networkMonitor.Spec.Flows); err != nil {
+		return sflowrt.ActiveFlow{}, false, nil
+	}
+	flows, err := v.of(ctx, threshold.Metric)
+	if err != nil {
+		return sflowrt.ActiveFlow{}, false, err
+	}
+
+	var value sflowrt.ActiveFlow
+	for _, flow := range flows {
+		if flag(threshold.ByFlow) {
+			if flow.Key == flowKey {
+				return flow, true, nil
+			}
+			continue
+		}
+		if flow.Value > value.Value {
+			value = flow
+		}
+	}
+	return value, true, nil
+}
+
+// violates returns true if <value> violates the bound of <threshold>.
+func violates(threshold *v1alpha1.Threshold, value float64) bool {
+	if threshold.Direction == v1alpha1.ThresholdDirectionBelow {
+		return value < float64(threshold.Value)
+	}
+	return value > float64(threshold.Value)
+}
+
+// clears returns true if <value> moved back from the bound of <threshold> by at least its hysteresis.
+func clears(threshold *v1alpha1.Threshold, value float64) bool {
+	if threshold.Direction == v1alpha1.ThresholdDirectionBelow {
+		return value >= float64(threshold.Value+threshold.Hysteresis)
+	}
+	return value <= float64(threshold.Value-threshold.Hysteresis)
+}
+
+// CheckLowerBounds returns events for the lower bound thresholds of <networkMonitor> whose flows fell below them. No
+// event is raised while the NetworkNotification of a threshold is firing.
+func (r *ReconcileNetworkMonitor) CheckLowerBounds(ctx context.Context, values *flowValues, networkMonitor *v1alpha1.NetworkMonitor) ([]v1alpha1.Event, error) {
+	var events []v1alpha1.Event
+	for i := range networkMonitor.Spec.Thresholds {
+		threshold := &networkMonitor.Spec.Thresholds[i]
+		if threshold.Direction != v1alpha1.ThresholdDirectionBelow || flag(threshold.ByFlow) {
+			continue
+		}
+		value, ok, err := values.value(ctx, threshold, "")
+		if err != nil {
+			return nil, err
+		}
+		if !ok || !violates(threshold, value.Value) {
+			continue
+		}
+
+		networkNotification := &v1alpha1.NetworkNotification{}
+		err = r.client.Get(ctx, client.ObjectKey{Namespace: r.notificationNamespace, Name: notificationName(networkMonitor, threshold.Name, "")}, networkNotification)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil && networkNotification.Spec.State != v1alpha1.NotificationStateResolved {
+			continue
+		}
+
+		events = append(events, v1alpha1.Event{
+			Threshold:   threshold.Value,
+			TimeStamp:   r.clock.Now().UnixNano() / int64(time.Millisecond),
+			Value:       float32(value.Value),
+			Metric:      threshold.Metric,
+			ThresholdID: threshold.Name,
+			Agent:       value.Agent,
+			DataSource:  value.DataSource,
+		})
+	}
+	return events, nil
+}
+
+// resolveNotifications resolves the firing NetworkNotifications of <networkMonitor> whose values stayed clear of their
+// thresholds for the timeout of the threshold. NetworkNotifications of metrics which are not flows of the NetworkMonitor
+// are resolved once no event occurred for the timeout, the ones of thresholds which were removed right away.
+func (r *ReconcileNetworkMonitor) resolveNotifications(ctx context.Context, values *flowValues, networkMonitor *v1alpha1.NetworkMonitor) error {
+	networkNotifications := &v1alpha1.NetworkNotificationList{}
+	if err := r.client.List(ctx, networkNotifications, client.InNamespace(r.notificationNamespace), client.MatchingLabels{MonitorUIDLabel: string(networkMonitor.UID)}); err != nil {
+		return err
+	}
+
+	now := r.clock.Now()
+	for i := range networkNotifications.Items {
+		networkNotification := &networkNotifications.Items[i]
+		if networkNotification.Spec.State == v1alpha1.NotificationStateResolved {
+			continue
+		}
+
+		var (
+			event   = networkNotification.Spec.Event.Event
+			cleared = true
+			since   = now
+			timeout time.Duration
+		)
+		if threshold, err := getThresholdForName(event.ThresholdID, networkMonitor.Spec.Thresholds); err == nil {
+			timeout = time.Duration(threshold.Timeout) * time.Second
+			value, ok, err := values.value(ctx, threshold, event.FlowKey)
+			if err != nil {
+				return err
+			}
+			switch {
+			case ok:
+				cleared = clears(threshold, value.Value)
+				if cleared && networkNotification.Spec.ClearSince != nil {
+					since = networkNotification.Spec.ClearSince.Time
+				}
+			default:
+				cleared = threshold.Timeout > 0
+				since = lastSeen(networkNotification)
+			}
+		}
+
+		switch {
+		case !cleared && networkNotification.Spec.ClearSince == nil:
+			continue
+		case !cleared:
+			networkNotification.Spec.ClearSince = nil
+		case now.Sub(since) >= timeout:
+			resolvedAt := metav1.NewTime(now)
+			networkNotification.Spec.State = v1alpha1.NotificationStateResolved
+			networkNotification.Spec.ClearSince = nil
+			networkNotification.Spec.ResolvedAt = &resolvedAt
+		case networkNotification.Spec.ClearSince == nil:
+			clearSince := metav1.NewTime(since)
+			networkNotification.Spec.ClearSince = &clearSince
+		default:
+			continue
+		}
+
+		if err := r.client.Update(ctx, networkNotification); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		if networkNotification.Spec.State == v1alpha1.NotificationStateResolved {
+			r.logger.Info("Resolved NetworkNotification", "NetworkMonitor", networkMonitor.Name, "NetworkNotification", networkNotification.Name)
+			notificationsResolved.WithLabelValues(networkMonitor.Namespace, networkMonitor.Name).Inc()
+		}
+	}
+	return nil
+}
@@ -2,12 +2,111 @@ package controller
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/controllers/networkmonitor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 func Equal(t1 *v1alpha1.Threshold, t2 *v1alpha1.Threshold) bool {
-	return t1.Metric == t2.Metric && t1.Value == t2.Value
+	return t1.Metric == t2.Metric && t1.Value == t2.Value && flag(t1.ByFlow) == flag(t2.ByFlow)
+}
+
+// flowDrifted returns true if the <installed> flow differs from the <desired> one. sFlow-RT reports a default for an
+// active timeout which is not set, it is therefore only compared if it is desired.
+func flowDrifted(desired, installed *v1alpha1.Flow) bool {
+	return desired.Keys != installed.Keys ||
+		desired.Value != installed.Value ||
+		desired.Filter != installed.Filter ||
+		flag(desired.Log) != flag(installed.Log) ||
+		flag(desired.FlowStart) != flag(installed.FlowStart) ||
+		(len(desired.ActiveTimeout) > 0 && desired.ActiveTimeout != installed.ActiveTimeout)
+}
+
+// flag returns the value of a boolean option of sFlow-RT, options which are not set are false.
+func flag(value string) bool {
+	enabled, _ := strconv.ParseBool(value)
+	return enabled
+}
+
+// installedName returns the name of the flow or threshold <name> of <networkMonitor> in sFlow-RT. Names are prefixed
+// with the UID of the NetworkMonitor to avoid collisions between NetworkMonitors.
+func installedName(networkMonitor *v1alpha1.NetworkMonitor, name string) string {
+	return string(networkMonitor.UID) + "-" + name
+}
+
+// specName returns the name in the spec of <networkMonitor> of the flow or threshold <installed> in sFlow-RT, it
+// returns false if the flow or threshold is not installed by the NetworkMonitor.
+func specName(networkMonitor *v1alpha1.NetworkMonitor, installed string) (string, bool) {
+	prefix := installedName(networkMonitor, "")
+	if !strings.HasPrefix(installed, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(installed, prefix), true
+}
+
+// desiredFlows returns the flows of <networkMonitor> as they are installed in sFlow-RT.
+func desiredFlows(networkMonitor *v1alpha1.NetworkMonitor) []v1alpha1.Flow {
+	flows := make([]v1alpha1.Flow, 0, len(networkMonitor.Spec.Flows))
+	for _, flow := range networkMonitor.Spec.Flows {
+		flow.Name = installedName(networkMonitor, flow.Name)
+		// the export options are handled by the controller
+		flow.Export, flow.MaxExportedKeys = false, 0
+		flow.Filter, _ = networkmonitor.KubernetesFilter(flow.Filter)
+		flows = append(flows, flow)
+	}
+	return flows
+}
+
+// usedGroups returns the names of the address groups the Kubernetes filters of the flows of <networkMonitor> refer to.
+func usedGroups(networkMonitor *v1alpha1.NetworkMonitor) []string {
+	groups := sets.NewString()
+	for _, flow := range networkMonitor.Spec.Flows {
+		_, used := networkmonitor.KubernetesFilter(flow.Filter)
+		groups.Insert(used...)
+	}
+	return groups.List()
+}
+
+// desiredThresholds returns the thresholds of <networkMonitor> as they are installed in sFlow-RT. Thresholds of the
+// metric of a flow of the NetworkMonitor refer to the installed flow, lower bounds are evaluated by the controller and
+// not installed.
+func desiredThresholds(networkMonitor *v1alpha1.NetworkMonitor) []v1alpha1.Threshold {
+	thresholds := make([]v1alpha1.Threshold, 0, len(networkMonitor.Spec.Thresholds))
+	for _, threshold := range networkMonitor.Spec.Thresholds {
+		if threshold.Direction == v1alpha1.ThresholdDirectionBelow {
+			continue
+		}
+		threshold.Name = installedName(networkMonitor, threshold.Name)
+		// the clearing of thresholds is handled by the controller
+		threshold.Direction, threshold.Hysteresis, threshold.Timeout = "", 0, 0
+		if _, err := getFlowForName(threshold.Metric, networkMonitor.Spec.Flows); err == nil {
+			threshold.Metric = installedName(networkMonitor, threshold.Metric)
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds
+}
+
+// staleNames returns the names of the <installed> and <recorded> flows or thresholds of <networkMonitor> which are not
+// <desired>.
+func staleNames(networkMonitor *v1alpha1.NetworkMonitor, installed, recorded []string, desired sets.String) []string {
+	stale := sets.NewString()
+	for _, name := range installed {
+		if _, ok := specName(networkMonitor, name); ok && !desired.Has(name) {
+			stale.Insert(name)
+		}
+	}
+	for _, name := range recorded {
+		if !desired.Has(name) {
+			stale.Insert(name)
+		}
+	}
+	return stale.List()
 }
 
 func filterEvents(events []v1alpha1.Event, monitor *v1alpha1.NetworkMonitor, test func(v1alpha1.Event, *v1alpha1.NetworkMonitor) bool) (ret []v1alpha1.Event) {
@@ -45,3 +144,27 @@ func getFlowForName(flowName string, flows []v1alpha1.Flow) (*v1alpha1.Flow, err
 	}
 	return nil, fmt.Errorf("flow with key %s does not exist", flowName)
 }
+
+// eventsQuery returns the query of the events of <config>, the timeout is given in seconds and capped at
+// maxEventsTimeout.
+func eventsQuery(config v1alpha1.EventsConfig) (sflowrt.EventsQuery, error) {
+	var query sflowrt.EventsQuery
+	if len(config.MaxEvents) > 0 {
+		maxEvents, err := strconv.Atoi(config.MaxEvents)
+		if err != nil {
+			return query, fmt.Errorf("invalid maxEvents %q: %v", config.MaxEvents, err)
+		}
+		query.MaxEvents = maxEvents
+	}
+	if len(config.Timeout) > 0 {
+		timeout, err := strconv.Atoi(config.Timeout)
+		if err != nil {
+			return query, fmt.Errorf("invalid timeout %q: %v", config.Timeout, err)
+		}
+		query.Timeout = time.Duration(timeout) * time.Second
+		if query.Timeout > maxEventsTimeout {
+			query.Timeout = maxEventsTimeout
+		}
+	}
+	return query, nil
+}
@@ -0,0 +1,47 @@
+package networkmonitor
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/identity"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// kubernetesFilters are the keys of Kubernetes filters and the address groups and address keys they are translated to.
+var kubernetesFilters = map[string]struct {
+	groups string
+	keys   []string
+}{
+	"namespace":            {identity.NamespaceGroups, []string{"ipsource", "ip6source", "ipdestination", "ip6destination"}},
+	"sourcenamespace":      {identity.NamespaceGroups, []string{"ipsource", "ip6source"}},
+	"destinationnamespace": {identity.NamespaceGroups, []string{"ipdestination", "ip6destination"}},
+	"node":                 {identity.NodeGroups, []string{"ipsource", "ip6source", "ipdestination", "ip6destination"}},
+	"sourcenode":           {identity.NodeGroups, []string{"ipsource", "ip6source"}},
+	"destinationnode":      {identity.NodeGroups, []string{"ipdestination", "ip6destination"}},
+}
+
+var kubernetesFilterPattern = regexp.MustCompile(`(^|[&|(!\s])\s*(sourcenamespace|destinationnamespace|namespace|sourcenode|destinationnode|node)\s*(!?=)\s*([^&|()]+)`)
+
+// KubernetesFilter translates the Kubernetes filters of <filter> to filters of the address groups of sFlow-RT, e.g.
+// "namespace=payments" to "(group:ipsource:networkmachinery-namespaces=payments|...)". It returns the translated filter
+// and the names of the address groups it refers to.
+func KubernetesFilter(filter string) (string, []string) {
+	groups := sets.NewString()
+	translated := kubernetesFilterPattern.ReplaceAllStringFunc(filter, func(term string) string {
+		match := kubernetesFilterPattern.FindStringSubmatch(term)
+		f := kubernetesFilters[match[2]]
+		groups.Insert(f.groups)
+
+		comparisons := make([]string, 0, len(f.keys))
+		for _, key := range f.keys {
+			comparisons = append(comparisons, "group:"+key+":"+f.groups+"="+strings.TrimSpace(match[4]))
+		}
+		expression := "(" + strings.Join(comparisons, "|") + ")"
+		if match[3] == "!=" {
+			expression = "!" + expression
+		}
+		return match[1] + expression
+	})
+	return translated, groups.List()
+}
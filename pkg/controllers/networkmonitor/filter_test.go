@@ -0,0 +1,35 @@
+package networkmonitor
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/identity"
+)
+
+func TestKubernetesFilter(t *testing.T) {
+	tests := []struct {
+		filter, expected string
+		groups           []string
+	}{
+		{"ipprotocol=6", "ipprotocol=6", []string{}},
+		{
+			"sourcenamespace=payments,orders&tcpdestinationport=443",
+			"(group:ipsource:networkmachinery-namespaces=payments,orders|group:ip6source:networkmachinery-namespaces=payments,orders)&tcpdestinationport=443",
+			[]string{identity.NamespaceGroups},
+		},
+		{
+			"ipprotocol=6&(destinationnode!=worker-1|namespace = kube-system)",
+			"ipprotocol=6&(!(group:ipdestination:networkmachinery-nodes=worker-1|group:ip6destination:networkmachinery-nodes=worker-1)|" +
+				"(group:ipsource:networkmachinery-namespaces=kube-system|group:ip6source:networkmachinery-namespaces=kube-system|" +
+				"group:ipdestination:networkmachinery-namespaces=kube-system|group:ip6destination:networkmachinery-namespaces=kube-system))",
+			[]string{identity.NamespaceGroups, identity.NodeGroups},
+		},
+	}
+	for _, test := range tests {
+		filter, groups := KubernetesFilter(test.filter)
+		if filter != test.expected || !reflect.DeepEqual(groups, test.groups) {
+			t.Errorf("expected %q to be translated to %q with groups %v, got %q with %v", test.filter, test.expected, test.groups, filter, groups)
+		}
+	}
+}
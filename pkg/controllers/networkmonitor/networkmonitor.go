@@ -4,12 +4,19 @@ import (
 	"context"
 
 	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
 )
 
-type NetworkMonitorer interface {
-	InstallFlows(context.Context, *v1alpha1.NetworkMonitor) error
-	InstallThresholds(context.Context, *v1alpha1.NetworkMonitor) error
-	DeleteFlows(context.Context, *v1alpha1.NetworkMonitor) error
-	DeleteThresholds(context.Context, *v1alpha1.NetworkMonitor) error
-	CheckEvents(context.Context, *v1alpha1.NetworkMonitor) ([]v1alpha1.Event, error)
+// Backend is a flow telemetry system the flows and thresholds of NetworkMonitors are installed in and their threshold
+// events and flow values are read from. The sFlow-RT client and the embedded collector implement it.
+type Backend interface {
+	Flows(context.Context) (map[string]v1alpha1.Flow, error)
+	PutFlow(context.Context, v1alpha1.Flow) error
+	DeleteFlow(context.Context, string) error
+	ActiveFlows(ctx context.Context, agent, name string, maxFlows int) ([]sflowrt.ActiveFlow, error)
+	Thresholds(context.Context) (map[string]v1alpha1.Threshold, error)
+	PutThreshold(context.Context, v1alpha1.Threshold) error
+	DeleteThreshold(context.Context, string) error
+	PutGroup(ctx context.Context, name string, groups sflowrt.Groups) error
+	Events(context.Context, sflowrt.EventsQuery) ([]v1alpha1.Event, error)
 }
@@ -0,0 +1,25 @@
+package networkmonitor
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// ExportOptions limit the Prometheus series of the flows NetworkMonitors export.
+type ExportOptions struct {
+	// MaxKeys is the number of keys with the largest values exported for a flow which doesn't set maxExportedKeys.
+	MaxKeys int
+	// MaxSeries is the number of series exported for all NetworkMonitors, keys beyond it are dropped.
+	MaxSeries int
+}
+
+// DefaultExportOptions are the default export limits of the NetworkMonitor controller.
+var DefaultExportOptions = &ExportOptions{
+	MaxKeys:   20,
+	MaxSeries: 1000,
+}
+
+// AddFlags adds the export flags to <flags>.
+func (o *ExportOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.IntVar(&o.MaxKeys, "networkmonitor-export-max-keys", o.MaxKeys, "number of keys with the largest values exported for a flow of a NetworkMonitor which doesn't set maxExportedKeys")
+	flags.IntVar(&o.MaxSeries, "networkmonitor-export-max-series", o.MaxSeries, "number of flow value series exported for all NetworkMonitors, further keys are dropped")
+}
@@ -14,6 +14,8 @@ type NetworkTrafficShaperCmdOptions struct {
 	*genericclioptions.ConfigFlags
 	controllers.LeaderElectionOptions
 	controllers.ControllerOptions
+	// OperatorOptions are set by the flags of the hyper command.
+	OperatorOptions *controllers.OperatorOptions
 }
 
 func (nm *NetworkTrafficShaperCmdOptions) ApplyLeaderElection(mgr *manager.Options) *manager.Options {
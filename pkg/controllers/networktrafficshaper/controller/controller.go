@@ -2,6 +2,14 @@ package controller
 
 import (
 	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/audit"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/guard"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/policy"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/target"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -12,12 +20,16 @@ import (
 )
 
 // newReconciler returns a new reconcile.Reconciler.
-func newReconciler(mgr manager.Manager) *ReconcileNetworkTrafficShaper {
+func newReconciler(mgr manager.Manager, podExecutor executor.PodExecutor, guardOptions *guard.Options, nodeHelperOptions *target.NodeHelperOptions) *ReconcileNetworkTrafficShaper {
 	return &ReconcileNetworkTrafficShaper{
-		logger:   log.Log.WithName(Name),
-		client:   mgr.GetClient(),
-		scheme:   mgr.GetScheme(),
-		recorder: mgr.GetEventRecorderFor(Name)}
+		logger:            log.Log.WithName(Name),
+		executor:          podExecutor,
+		clock:             clock.RealClock{},
+		guardOptions:      guardOptions,
+		nodeHelperOptions: nodeHelperOptions,
+		client:            mgr.GetClient(),
+		scheme:            mgr.GetScheme(),
+		recorder:          mgr.GetEventRecorderFor(Name)}
 }
 
 // DefaultPredicates returns the default predicates for an infrastructure reconciler.
@@ -26,11 +38,17 @@ func DefaultPredicates() []predicate.Predicate {
 }
 
 // Add creates a new NetworkMonitor Controller and adds it to the Manager
-func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr), DefaultPredicates())
+func Add(mgr manager.Manager, auditOptions *audit.Options, guardOptions *guard.Options, nodeHelperOptions *target.NodeHelperOptions) error {
+	sink, err := audit.SinkFor(mgr, auditOptions)
+	if err != nil {
+		return err
+	}
+	// executions are audited before they are authorized, denied executions are recorded but never reach the pods
+	podExecutor := audit.NewExecutor(policy.NewExecutor(utils.NewExecutor(mgr.GetConfig()), mgr.GetClient()), sink)
+	return add(mgr, newReconciler(mgr, podExecutor, guardOptions, nodeHelperOptions), guardOptions, DefaultPredicates())
 }
 
-func add(mgr manager.Manager, r reconcile.Reconciler, predicates []predicate.Predicate) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, guardOptions *guard.Options, predicates []predicate.Predicate) error {
 	ctrl, err := controller.New(Name, mgr, controller.Options{Reconciler: r, MaxConcurrentReconciles: 5})
 	if err != nil {
 		return err
@@ -40,5 +58,17 @@ func add(mgr manager.Manager, r reconcile.Reconciler, predicates []predicate.Pre
 		return err
 	}
 
+	// new replicas of selector targets are shaped and pods which are no longer targeted are unshaped right away
+	mapper := &podMapper{client: mgr.GetClient(), logger: log.Log.WithName(Name)}
+	if err := ctrl.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: mapper}, PodTargetChangedPredicate()); err != nil {
+		return err
+	}
+
+	// all shaping is rolled back right away when the kill switch is engaged
+	killSwitch := &killSwitchMapper{client: mgr.GetClient(), logger: log.Log.WithName(Name)}
+	if err := ctrl.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{ToRequests: killSwitch}, KillSwitchPredicate(guardOptions)); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// podMapper maps pods to the NetworkTrafficShapers targeting them, which shaped them before or own them as node
+// helpers.
+type podMapper struct {
+	client client.Client
+	logger logr.Logger
+}
+
+// Map implements handler.Mapper.
+func (m *podMapper) Map(obj handler.MapObject) []reconcile.Request {
+	networkTrafficShapers := &v1alpha1.NetworkTrafficShaperList{}
+	if err := m.client.List(context.TODO(), networkTrafficShapers); err != nil {
+		m.logger.Error(err, "Could not list NetworkTrafficShapers to map a pod event")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range networkTrafficShapers.Items {
+		networkTrafficShaper := &networkTrafficShapers.Items[i]
+		if targetsPod(networkTrafficShaper, obj.Meta) || recordsPod(networkTrafficShaper, obj.Meta) || ownsPod(networkTrafficShaper, obj.Meta) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: networkTrafficShaper.Name}})
+		}
+	}
+	return requests
+}
+
+// killSwitchMapper maps the kill switch ConfigMap to all NetworkTrafficShapers, they are rolled back as soon as
+// it is created and shape the traffic again once it is deleted.
+type killSwitchMapper struct {
+	client client.Client
+	logger logr.Logger
+}
+
+// Map implements handler.Mapper.
+func (m *killSwitchMapper) Map(obj handler.MapObject) []reconcile.Request {
+	networkTrafficShapers := &v1alpha1.NetworkTrafficShaperList{}
+	if err := m.client.List(context.TODO(), networkTrafficShapers); err != nil {
+		m.logger.Error(err, "Could not list NetworkTrafficShapers to map a kill switch event")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(networkTrafficShapers.Items))
+	for _, networkTrafficShaper := range networkTrafficShapers.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: networkTrafficShaper.Name}})
+	}
+	return requests
+}
+
+// targetsPod returns true if a target of <networkTrafficShaper> matches <pod>.
+func targetsPod(networkTrafficShaper *v1alpha1.NetworkTrafficShaper, pod metav1.Object) bool {
+	for _, shaperTarget := range networkTrafficShaper.Spec.Targets {
+		if shaperTarget.Namespace != pod.GetNamespace() {
+			continue
+		}
+		switch shaperTarget.Kind {
+		case v1alpha1.Pod:
+			if shaperTarget.Name == pod.GetName() {
+				return true
+			}
+		case v1alpha1.Selector:
+			selector, err := metav1.LabelSelectorAsSelector(shaperTarget.SourceSelector)
+			if err == nil && selector.Matches(labels.Set(pod.GetLabels())) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recordsPod returns true if <pod> is recorded as shaped in the status of <networkTrafficShaper>.
+func recordsPod(networkTrafficShaper *v1alpha1.NetworkTrafficShaper, pod metav1.Object) bool {
+	for _, p := range networkTrafficShaper.Status.Pods {
+		if p.Namespace == pod.GetNamespace() && p.Name == pod.GetName() {
+			return true
+		}
+	}
+	return false
+}
+
+// ownsPod returns true if <networkTrafficShaper> is an owner of <pod>, e.g. of the helper pod of a node target.
+func ownsPod(networkTrafficShaper *v1alpha1.NetworkTrafficShaper, pod metav1.Object) bool {
+	for _, owner := range pod.GetOwnerReferences() {
+		if owner.UID == networkTrafficShaper.UID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/test"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestPodMapper(t *testing.T) {
+	named := func(name string, targets ...v1alpha1.ShaperTarget) *v1alpha1.NetworkTrafficShaper {
+		shaper := newShaper(targets...)
+		shaper.Name = name
+		return shaper
+	}
+	selecting := named("selecting", v1alpha1.ShaperTarget{Kind: v1alpha1.Selector, Namespace: "default", SourceSelector: &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"db"}}},
+	}})
+	naming := named("naming", v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "db"})
+	recording := named("recording", v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "other"})
+	recording.Status.Pods = []v1alpha1.ShapedPod{{Namespace: "default", Name: "web", Device: "eth0"}}
+	otherNamespace := named("other-namespace", v1alpha1.ShaperTarget{Kind: v1alpha1.Selector, Namespace: "staging", SourceSelector: &metav1.LabelSelector{}})
+
+	mapper := &podMapper{client: test.NewFakeClient(selecting, naming, recording, otherNamespace), logger: log.Log.WithName(Name)}
+	for pod, expected := range map[string][]string{
+		"web": {"recording", "selecting"},
+		"db":  {"naming"},
+	} {
+		p := newPod(pod, map[string]string{"app": pod})
+		var actual []string
+		for _, request := range mapper.Map(handler.MapObject{Meta: p, Object: p}) {
+			actual = append(actual, request.Name)
+		}
+		sort.Strings(actual)
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected pod %s to be mapped to %v, got %v", pod, expected, actual)
+		}
+	}
+}
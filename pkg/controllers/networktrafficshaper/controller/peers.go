@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/tc"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resolvePeers adds the IPs of the destinations of <match> to the peers of the <shaping>. Destinations which do
+// not exist (yet) do not have any IPs.
+func (r *ReconcileNetworkTrafficShaper) resolvePeers(ctx context.Context, shaping *tc.Shaping, match *v1alpha1.TrafficMatch) error {
+	if shaping.Match == nil || match == nil {
+		return nil
+	}
+
+	for _, destination := range match.Destinations {
+		ips, err := r.destinationIPs(ctx, destination)
+		if err != nil {
+			return err
+		}
+		for _, ip := range ips {
+			peer, err := tc.ParsePeer(ip)
+			if err != nil {
+				r.logger.Info("Ignoring invalid destination IP", "destination", destination.Namespace+"/"+destination.Name, "ip", ip)
+				continue
+			}
+			shaping.Match.Peers = append(shaping.Match.Peers, peer)
+		}
+	}
+	return nil
+}
+
+func (r *ReconcileNetworkTrafficShaper) destinationIPs(ctx context.Context, destination v1alpha1.ShaperDestination) ([]string, error) {
+	key := client.ObjectKey{Namespace: destination.Namespace, Name: destination.Name}
+	switch destination.Kind {
+	case v1alpha1.Pod:
+		pod := &corev1.Pod{}
+		if err := r.client.Get(ctx, key, pod); err != nil {
+			if errors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return podIPs(pod), nil
+	case v1alpha1.Service:
+		return r.serviceIPs(ctx, key)
+	case v1alpha1.Selector:
+		selector, err := metav1.LabelSelectorAsSelector(destination.Selector)
+		if err != nil {
+			return nil, err
+		}
+		pods := &corev1.PodList{}
+		if err := r.client.List(ctx, pods, client.InNamespace(destination.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+		var ips []string
+		for i := range pods.Items {
+			ips = append(ips, podIPs(&pods.Items[i])...)
+		}
+		return ips, nil
+	}
+	return nil, nil
+}
+
+// serviceIPs returns the cluster IP of a service and the IPs of its endpoints, the traffic of clients reaching
+// the endpoints directly is matched as well.
+func (r *ReconcileNetworkTrafficShaper) serviceIPs(ctx context.Context, key client.ObjectKey) ([]string, error) {
+	var ips []string
+	service := &corev1.Service{}
+	if err := r.client.Get(ctx, key, service); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(service.Spec.ClusterIP) > 0 && service.Spec.ClusterIP != corev1.ClusterIPNone {
+		ips = append(ips, service.Spec.ClusterIP)
+	}
+
+	endpoints := &corev1.Endpoints{}
+	if err := r.client.Get(ctx, key, endpoints); err != nil {
+		if errors.IsNotFound(err) {
+			return ips, nil
+		}
+		return nil, err
+	}
+	for _, subset := range endpoints.Subsets {
+		for _, address := range subset.Addresses {
+			ips = append(ips, address.IP)
+		}
+	}
+	return ips, nil
+}
+
+func podIPs(pod *corev1.Pod) []string {
+	if len(pod.Status.PodIPs) == 0 && len(pod.Status.PodIP) > 0 {
+		return []string{pod.Status.PodIP}
+	}
+	var ips []string
+	for _, podIP := range pod.Status.PodIPs {
+		ips = append(ips, podIP.IP)
+	}
+	return ips
+}
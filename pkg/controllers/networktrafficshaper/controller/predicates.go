@@ -1,6 +1,11 @@
 package controller
 
 import (
+	"reflect"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/guard"
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
@@ -15,3 +20,43 @@ var generationChangedPredicate = predicate.Funcs{
 func GenerationChangedPredicate() predicate.Predicate {
 	return generationChangedPredicate
 }
+
+var podTargetChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldPod, ok := e.ObjectOld.(*corev1.Pod)
+		if !ok {
+			return false
+		}
+		newPod, ok := e.ObjectNew.(*corev1.Pod)
+		if !ok {
+			return false
+		}
+		return !reflect.DeepEqual(oldPod.Labels, newPod.Labels) ||
+			utils.IsPodReady(oldPod) != utils.IsPodReady(newPod) ||
+			(oldPod.DeletionTimestamp == nil) != (newPod.DeletionTimestamp == nil)
+	},
+}
+
+// PodTargetChangedPredicate is a predicate for pod changes which may change the targets of a NetworkTrafficShaper,
+// i.e. created and deleted pods, changed labels and pods becoming ready or terminating.
+func PodTargetChangedPredicate() predicate.Predicate {
+	return podTargetChangedPredicate
+}
+
+// KillSwitchPredicate is a predicate for the creation and deletion of the kill switch ConfigMap of <options>.
+func KillSwitchPredicate(options *guard.Options) predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return options.IsKillSwitch(e.Meta)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return options.IsKillSwitch(e.Meta)
+		},
+		UpdateFunc: func(event.UpdateEvent) bool {
+			return false
+		},
+		GenericFunc: func(event.GenericEvent) bool {
+			return false
+		},
+	}
+}
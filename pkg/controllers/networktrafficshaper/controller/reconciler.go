@@ -2,36 +2,68 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"k8s.io/client-go/rest"
 
-	"k8s.io/apimachinery/pkg/labels"
-
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/apimachinery"
 
 	"github.com/go-logr/logr"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/guard"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/policy"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/target"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/tc"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
-	// FinalizerName is the controlplane controller finalizer.
-	FinalizerName = "networkmachinery.io/networkmonitor"
-	LogKey        = "network-traffic-shaper"
+	// FinalizerName is the finalizer of the NetworkTrafficShaper controller.
+	FinalizerName = "networkmachinery.io/networktrafficshaper"
+	// legacyFinalizerName was set by former versions of the controller, it is replaced by FinalizerName.
+	legacyFinalizerName = "networkmachinery.io/networkmonitor"
+	LogKey              = "network-traffic-shaper"
+
+	// AbandonAnnotation set to "true" on a deleted NetworkTrafficShaper leaves the recorded pods whose shaping can not
+	// be undone, e.g. because a NetworkExecPolicy denies it, shaped and removes the finalizer.
+	AbandonAnnotation = "networkmachinery.io/abandon-shaping"
+
+	// blockedRequeueAfter is the interval in which blocked NetworkTrafficShapers are reconciled.
+	blockedRequeueAfter = 30 * time.Second
 )
 
+// shapingPlan are the targets which are shaped with the same configuration.
+type shapingPlan struct {
+	// index is the index of the target in the spec.
+	index   int
+	device  string
+	shaping *tc.Shaping
+	targets []target.Target
+}
+
 // ReconcileMachineDeployment reconciles a MachineDeployment object.
 type ReconcileNetworkTrafficShaper struct {
-	logger logr.Logger
-	client client.Client
-	config *rest.Config
+	logger   logr.Logger
+	client   client.Client
+	config   *rest.Config
+	executor executor.PodExecutor
+	clock    clock.Clock
+
+	guardOptions      *guard.Options
+	nodeHelperOptions *target.NodeHelperOptions
 
 	ctx      context.Context
 	scheme   *runtime.Scheme
@@ -62,12 +94,15 @@ func (r *ReconcileNetworkTrafficShaper) Reconcile(request reconcile.Request) (re
 		return apimachinery.ReconcileErr(err)
 	}
 
+	// executions are authorized on behalf of the user who requested the NetworkTrafficShaper
+	ctx := executor.WithOrigin(r.ctx, policy.NewOrigin(networkTrafficShaper, "NetworkTrafficShaper", v1alpha1.ExecToolMutation))
+
 	if networkTrafficShaper.DeletionTimestamp != nil {
-		return r.delete(r.ctx, networkTrafficShaper)
+		return r.delete(ctx, networkTrafficShaper)
 	}
 
 	r.logger.Info("Reconciling NetworkTrafficShaper", "Name", networkTrafficShaper.Name)
-	return r.reconcile(r.ctx, networkTrafficShaper)
+	return r.reconcile(ctx, networkTrafficShaper)
 }
 
 func (r *ReconcileNetworkTrafficShaper) reconcile(ctx context.Context, networkTrafficShaper *v1alpha1.NetworkTrafficShaper) (reconcile.Result, error) {
@@ -75,46 +110,359 @@ func (r *ReconcileNetworkTrafficShaper) reconcile(ctx context.Context, networkTr
 	if err := apimachinery.EnsureFinalizer(ctx, r.client, FinalizerName, networkTrafficShaper); err != nil {
 		return apimachinery.ReconcileErr(err)
 	}
+	if err := apimachinery.DeleteFinalizer(ctx, r.client, legacyFinalizerName, networkTrafficShaper); err != nil {
+		return apimachinery.ReconcileErr(err)
+	}
+
+	now := r.clock.Now()
+	w, err := shapingWindow(networkTrafficShaper, now)
+	if err != nil {
+		r.recorder.Eventf(networkTrafficShaper, v1alpha1.EventTypeWarning, v1alpha1.EventTypeInvalidConfiguration, "Invalid shaping window: %v", err)
+		return r.failed(ctx, networkTrafficShaper, 0, nil, err)
+	}
+	if w.phase != v1alpha1.ShaperPhaseActive {
+		return r.rollback(ctx, networkTrafficShaper, w, now)
+	}
+	reason, err := r.guardViolation(ctx, networkTrafficShaper)
+	if err != nil {
+		return r.failed(ctx, networkTrafficShaper, 0, nil, err)
+	}
+	if len(reason) > 0 {
+		return r.block(ctx, networkTrafficShaper, w, now, reason)
+	}
 
-	shapePodList := func(ctx context.Context, pods *corev1.PodList, device, value, shapeType string) error {
-		for _, pod := range pods.Items {
-			if err := shapeTraffic(ctx, r.config, pod.Namespace, pod.Name, device, value, shapeType); err != nil {
-				return err
+	var (
+		shapedPods []v1alpha1.ShapedPod
+		selections []v1alpha1.TargetSelection
+		plans      []shapingPlan
+		shaped     int
+		statuses   = make([]v1alpha1.ShaperTargetStatus, len(networkTrafficShaper.Spec.Targets))
+	)
+	// failed records the error of a target before the plans are applied, no pod has been shaped yet
+	failed := func(i, progress int, err error) (reconcile.Result, error) {
+		statuses[i].LastError = err.Error()
+		return r.failed(ctx, networkTrafficShaper, progress, statuses, err)
+	}
+	for i, shaperTarget := range networkTrafficShaper.Spec.Targets {
+		statuses[i].Target = i
+		config := shaperTarget.ShaperConfig
+		shaping, errs := tc.ShapingFor(config, field.NewPath("spec", "targets").Index(i).Child("configuration"))
+		if len(errs) > 0 {
+			err := errs.ToAggregate()
+			r.recorder.Eventf(networkTrafficShaper, v1alpha1.EventTypeWarning, v1alpha1.EventTypeInvalidConfiguration, "Invalid shaper configuration: %v", err)
+			return failed(i, 0, err)
+		}
+		selection, err := target.ParseSelection(shaperTarget.Mode, shaperTarget.Value)
+		if err != nil {
+			r.recorder.Eventf(networkTrafficShaper, v1alpha1.EventTypeWarning, v1alpha1.EventTypeInvalidConfiguration, "Invalid selection of target %d: %v", i, err)
+			return failed(i, 0, err)
+		}
+		// changed destination IPs change the shaping, the filters are rebuilt then
+		if err := r.resolvePeers(ctx, shaping, config.Match); err != nil {
+			return failed(i, 0, err)
+		}
+
+		targets, skipped, err := r.resolveTargets(ctx, networkTrafficShaper, shaperTarget, target.Options{Binaries: shaping.Binaries()})
+		if err != nil {
+			return failed(i, 0, err)
+		}
+		statuses[i].Skipped = len(skipped)
+		if len(skipped) > 0 {
+			statuses[i].LastError = skipped[len(skipped)-1].Reason.Error()
+		}
+		selected := func(string) bool { return true }
+		if !selection.All() {
+			var matched, names []string
+			for _, t := range targets {
+				matched = append(matched, t.Pod.Name)
+			}
+			for _, s := range skipped {
+				_, name := skippedPod(shaperTarget, s)
+				matched = append(matched, name)
+			}
+			targets, names = selection.Select(string(networkTrafficShaper.UID), previousSelection(networkTrafficShaper, i), matched, targets)
+			selections = append(selections, v1alpha1.TargetSelection{Target: i, Pods: names})
+			selected = func(name string) bool { return containsString(names, name) }
+		}
+		// selected pods which are still targeted but not eligible, e.g. because the shaping made them unready, stay
+		// shaped
+		for _, s := range skipped {
+			namespace, name := skippedPod(shaperTarget, s)
+			if !selected(name) || errors.IsNotFound(s.Reason) {
+				continue
+			}
+			for _, previous := range networkTrafficShaper.Status.Pods {
+				if previous.Namespace == namespace && previous.Name == name && previous.Device == config.Device {
+					shapedPods = append(shapedPods, previous)
+					statuses[i].Shaped++
+				}
 			}
 		}
+		plans = append(plans, shapingPlan{index: i, device: config.Device, shaping: shaping, targets: targets})
+		shaped += len(targets)
+	}
+
+	// the pod limits are checked before any pod is shaped
+	others, err := r.podsShapedByOthers(ctx, networkTrafficShaper)
+	if err != nil {
+		return r.failed(ctx, networkTrafficShaper, 0, statuses, err)
+	}
+	if err := r.guardOptions.CheckPods(shaped+len(shapedPods), others); err != nil {
+		return r.block(ctx, networkTrafficShaper, w, now, err.Error())
+	}
+
+	// the window is persisted before the traffic is shaped, the shaping is rolled back even if the controller
+	// was not running when the window ended
+	if err := apimachinery.TryUpdateStatus(ctx, retry.DefaultBackoff, r.client, networkTrafficShaper, func() error {
+		setWindow(networkTrafficShaper, w)
 		return nil
+	}); err != nil {
+		return apimachinery.ReconcileErr(err)
 	}
 
-	for _, target := range networkTrafficShaper.Spec.Targets {
-		switch target.Kind {
-		case v1alpha1.Selector:
-			podList, err := utils.GetPodsByLabels(ctx, r.client, labels.SelectorFromSet(target.SourceSelector.MatchLabels), target.Namespace)
+	for _, plan := range plans {
+		for j := range plan.targets {
+			// not every option is reported by tc, a changed configuration is therefore always applied
+			changed := changedQdisc(networkTrafficShaper, &plan.targets[j], plan.device, plan.shaping)
+			applied, state, result, err := shapeTraffic(ctx, r.executor, &plan.targets[j], plan.device, plan.shaping, changed)
 			if err != nil {
-				return apimachinery.ReconcileErr(err)
-			}
-			if err := shapePodList(ctx, podList, target.ShaperConfig.Device, target.ShaperConfig.Value, string(target.ShaperConfig.Type)); err != nil {
-				return apimachinery.ReconcileErr(err)
-			}
-		case v1alpha1.Pod:
-			pod := &corev1.Pod{}
-			if err := r.client.Get(ctx, client.ObjectKey{Namespace: target.Namespace, Name: target.Name}, pod); err != nil {
-				return apimachinery.ReconcileErr(err)
-			}
-			if err := shapeTraffic(ctx, r.config, pod.Namespace, pod.Name, target.ShaperConfig.Device, target.ShaperConfig.Value, string(target.ShaperConfig.Type)); err != nil {
-				return apimachinery.ReconcileErr(err)
+				r.recordCommandFailure(networkTrafficShaper, plan.targets[j].Pod, result, err)
+				statuses[plan.index].LastError = err.Error()
+				// the failed pod may be shaped partially, it is recorded to be undone like the pods shaped before
+				shapedPods = append(shapedPods, r.shapedPod(networkTrafficShaper, &plan.targets[j], plan.device, plan.shaping, nil, applied))
+				return r.failedShaping(ctx, networkTrafficShaper, 100*plan.index/len(plans), statuses, shapedPods, err)
 			}
+			shapedPods = append(shapedPods, r.shapedPod(networkTrafficShaper, &plan.targets[j], plan.device, plan.shaping, state, applied))
+			statuses[plan.index].Shaped++
+		}
+	}
+
+	// pods which are no longer targeted are unshaped, they are kept in the status until that succeeded
+	for _, previous := range networkTrafficShaper.Status.Pods {
+		if containsShapedPod(shapedPods, previous) {
+			continue
+		}
+		if err := r.undoShapedPod(ctx, networkTrafficShaper, previous); err != nil {
+			r.logger.Error(err, "Could not undo the shaping of a pod which is no longer targeted", LogKey, networkTrafficShaper.Name, "pod", previous.Namespace+"/"+previous.Name)
+			shapedPods = append(shapedPods, previous)
+			continue
+		}
+		r.recorder.Eventf(networkTrafficShaper, v1alpha1.EventTypeNormal, v1alpha1.EventTypeRolledBack,
+			"Shaping of device %s in pod %s/%s has been undone, the pod is no longer targeted", previous.Device, previous.Namespace, previous.Name)
+	}
+
+	if err := apimachinery.TryUpdateStatus(ctx, retry.DefaultBackoff, r.client, networkTrafficShaper, func() error {
+		setWindow(networkTrafficShaper, w)
+		setLastOperation(networkTrafficShaper, v1alpha1.LastOperationStateSucceeded, 100, fmt.Sprintf("Traffic of %d pods is shaped", len(shapedPods)), now)
+		networkTrafficShaper.Status.Pods = shapedPods
+		networkTrafficShaper.Status.Selections = selections
+		networkTrafficShaper.Status.Targets = statuses
+		return nil
+	}); err != nil {
+		return apimachinery.ReconcileErr(err)
+	}
+
+	requeueAfter := 10 * time.Second
+	if end := w.requeueAfter(networkTrafficShaper, now); end > 0 && end < requeueAfter {
+		requeueAfter = end
+	}
+	return reconcile.Result{
+		RequeueAfter: requeueAfter,
+	}, nil
+}
+
+// rollback undoes the shaping outside of the shaping window and requeues the NetworkTrafficShaper when the
+// window changes. The shaping is only undone if the status records it may have been applied.
+func (r *ReconcileNetworkTrafficShaper) rollback(ctx context.Context, networkTrafficShaper *v1alpha1.NetworkTrafficShaper, w *window, now time.Time) (reconcile.Result, error) {
+	if networkTrafficShaper.Status.Phase == v1alpha1.ShaperPhaseActive || len(networkTrafficShaper.Status.Pods) > 0 {
+		if err := r.undoShaping(ctx, networkTrafficShaper); err != nil {
+			return r.failed(ctx, networkTrafficShaper, 0, nil, err)
+		}
+		r.recorder.Eventf(networkTrafficShaper, v1alpha1.EventTypeNormal, v1alpha1.EventTypeRolledBack, "Shaping has been undone, the NetworkTrafficShaper is %s", w.phase)
+	}
+
+	if err := apimachinery.TryUpdateStatus(ctx, retry.DefaultBackoff, r.client, networkTrafficShaper, func() error {
+		setWindow(networkTrafficShaper, w)
+		if w.phase == v1alpha1.ShaperPhaseBlocked {
+			setLastOperation(networkTrafficShaper, v1alpha1.LastOperationStatePending, 0, "Shaping is blocked: "+w.reason, now)
+		} else {
+			setLastOperation(networkTrafficShaper, v1alpha1.LastOperationStateSucceeded, 100, fmt.Sprintf("No traffic is shaped, the NetworkTrafficShaper is %s", w.phase), now)
 		}
+		networkTrafficShaper.Status.Pods = nil
+		networkTrafficShaper.Status.Targets = nil
+		return nil
+	}); err != nil {
+		return apimachinery.ReconcileErr(err)
 	}
+
 	return reconcile.Result{
-		RequeueAfter: 10 * time.Second,
+		RequeueAfter: w.requeueAfter(networkTrafficShaper, now),
 	}, nil
 }
 
-func (r *ReconcileNetworkTrafficShaper) delete(ctx context.Context, networkTrafficShaper *v1alpha1.NetworkTrafficShaper) (reconcile.Result, error) {
-	hasFinalizer, err := apimachinery.HasFinalizer(networkTrafficShaper, FinalizerName)
+// guardViolation returns the safety guard which prevents the shaping of <networkTrafficShaper>, it is empty if
+// the shaping is allowed. The guards of the webhook are checked as well since the guard options may have changed
+// since the NetworkTrafficShaper was admitted.
+func (r *ReconcileNetworkTrafficShaper) guardViolation(ctx context.Context, networkTrafficShaper *v1alpha1.NetworkTrafficShaper) (string, error) {
+	engaged, err := r.guardOptions.KillSwitchEngaged(ctx, r.client)
 	if err != nil {
-		r.logger.Error(err, "Could not instantiate finalizer deletion")
-		return apimachinery.ReconcileErr(err)
+		return "", err
+	}
+	if engaged {
+		return fmt.Sprintf("the kill switch ConfigMap %s/%s exists", r.guardOptions.KillSwitchNamespace, r.guardOptions.KillSwitchName), nil
+	}
+	if errs := r.guardOptions.Validate(networkTrafficShaper); len(errs) > 0 {
+		return errs.ToAggregate().Error(), nil
+	}
+	return "", nil
+}
+
+// block rolls back the shaping of <networkTrafficShaper> which is prevented by a safety guard. The guard may be
+// lifted by changes of other objects, e.g. other NetworkTrafficShapers unshaping their pods, the
+// NetworkTrafficShaper is therefore requeued periodically.
+func (r *ReconcileNetworkTrafficShaper) block(ctx context.Context, networkTrafficShaper *v1alpha1.NetworkTrafficShaper, w *window, now time.Time, reason string) (reconcile.Result, error) {
+	if networkTrafficShaper.Status.Phase != v1alpha1.ShaperPhaseBlocked || networkTrafficShaper.Status.BlockedReason != reason {
+		r.recorder.Eventf(networkTrafficShaper, v1alpha1.EventTypeWarning, v1alpha1.EventTypeBlocked, "Shaping is blocked: %s", reason)
+	}
+
+	blocked := *w
+	blocked.phase, blocked.reason = v1alpha1.ShaperPhaseBlocked, reason
+	if len(networkTrafficShaper.Spec.Schedule) == 0 && networkTrafficShaper.Status.StartTime == nil {
+		// the duration starts once the shaping is no longer blocked
+		blocked.start, blocked.end = nil, nil
+	}
+	result, err := r.rollback(ctx, networkTrafficShaper, &blocked, now)
+	if err != nil {
+		return result, err
+	}
+	if result.RequeueAfter == 0 || result.RequeueAfter > blockedRequeueAfter {
+		result.RequeueAfter = blockedRequeueAfter
+	}
+	return result, nil
+}
+
+// podsShapedByOthers returns the number of pods shaped by the NetworkTrafficShapers other than
+// <networkTrafficShaper>.
+func (r *ReconcileNetworkTrafficShaper) podsShapedByOthers(ctx context.Context, networkTrafficShaper *v1alpha1.NetworkTrafficShaper) (int, error) {
+	networkTrafficShapers := &v1alpha1.NetworkTrafficShaperList{}
+	if err := r.client.List(ctx, networkTrafficShapers); err != nil {
+		return 0, err
+	}
+	var pods int
+	for _, other := range networkTrafficShapers.Items {
+		if other.UID != networkTrafficShaper.UID {
+			pods += len(other.Status.Pods)
+		}
+	}
+	return pods, nil
+}
+
+// shapedPod returns the status of a shaped target. If the qdisc was applied to a pod which was already shaped
+// with the same configuration, it has been changed or removed by someone else in the meantime.
+func (r *ReconcileNetworkTrafficShaper) shapedPod(networkTrafficShaper *v1alpha1.NetworkTrafficShaper, shapeTarget *target.Target, device string, shaping *tc.Shaping, state *tc.State, applied bool) v1alpha1.ShapedPod {
+	shapedPod := v1alpha1.ShapedPod{
+		Namespace: shapeTarget.Pod.Namespace,
+		Name:      shapeTarget.Pod.Name,
+		Container: shapeTarget.Container,
+		Device:    device,
+		Qdisc:     shaping.String(),
+		AppliedAt: metav1.NewTime(r.clock.Now()),
+	}
+	if shaping.Ingress {
+		shapedPod.IFBDevice = shaping.IFBDevice
+	}
+	if len(shapeTarget.Node) > 0 {
+		shapedPod.Node = shapeTarget.Node
+	}
+	if bandwidth := shaping.AchievedBandwidth(state); bandwidth != nil {
+		shapedPod.Bandwidth = &v1alpha1.ShapedBandwidth{
+			Algorithm: v1alpha1.BandwidthAlgorithm(bandwidth.Algorithm),
+			Device:    shaping.ShapedDevice(device),
+			Rate:      tc.FormatRate(bandwidth.Rate),
+			Burst:     tc.FormatSize(bandwidth.Burst),
+		}
+		if bandwidth.Algorithm == tc.HTB {
+			shapedPod.Bandwidth.Ceil = tc.FormatRate(bandwidth.Ceil)
+		}
+	}
+
+	for _, previous := range networkTrafficShaper.Status.Pods {
+		if previous.Namespace != shapedPod.Namespace || previous.Name != shapedPod.Name || previous.Device != device {
+			continue
+		}
+		if previous.Qdisc != shapedPod.Qdisc {
+			break
+		}
+		if !applied {
+			shapedPod.AppliedAt = previous.AppliedAt
+			break
+		}
+		r.recorder.Eventf(networkTrafficShaper, v1alpha1.EventTypeWarning, v1alpha1.EventTypeDrift,
+			"Qdisc of device %s in pod %s drifted from %q, it has been reapplied", device, shapeTarget.Key(), shapedPod.Qdisc)
+	}
+	return shapedPod
+}
+
+// previousSelection returns the names of the pods selected for the target with <index> before.
+func previousSelection(networkTrafficShaper *v1alpha1.NetworkTrafficShaper, index int) []string {
+	for _, selection := range networkTrafficShaper.Status.Selections {
+		if selection.Target == index {
+			return selection.Pods
+		}
+	}
+	return nil
+}
+
+// skippedPod returns the namespace and name of a skipped pod of <shaperTarget>, the pod is not set for skipped pod
+// and node targets.
+func skippedPod(shaperTarget v1alpha1.ShaperTarget, skipped target.Skipped) (string, string) {
+	if skipped.Pod != nil {
+		return skipped.Pod.Namespace, skipped.Pod.Name
+	}
+	if err, ok := skipped.Reason.(*target.NotEligibleError); ok {
+		return err.Namespace, err.Name
+	}
+	return shaperTarget.Namespace, shaperTarget.Name
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsShapedPod returns true if the device of <shapedPod> is in <shapedPods>.
+func containsShapedPod(shapedPods []v1alpha1.ShapedPod, shapedPod v1alpha1.ShapedPod) bool {
+	for _, p := range shapedPods {
+		if p.Namespace == shapedPod.Namespace && p.Name == shapedPod.Name && p.Device == shapedPod.Device {
+			return true
+		}
+	}
+	return false
+}
+
+// changedQdisc returns true if the target was shaped differently than <shaping> before.
+func changedQdisc(networkTrafficShaper *v1alpha1.NetworkTrafficShaper, shapeTarget *target.Target, device string, shaping *tc.Shaping) bool {
+	for _, previous := range networkTrafficShaper.Status.Pods {
+		if previous.Namespace == shapeTarget.Pod.Namespace && previous.Name == shapeTarget.Pod.Name && previous.Device == device {
+			return previous.Qdisc != shaping.String()
+		}
+	}
+	return false
+}
+
+func (r *ReconcileNetworkTrafficShaper) delete(ctx context.Context, networkTrafficShaper *v1alpha1.NetworkTrafficShaper) (reconcile.Result, error) {
+	var hasFinalizer bool
+	for _, finalizer := range []string{FinalizerName, legacyFinalizerName} {
+		has, err := apimachinery.HasFinalizer(networkTrafficShaper, finalizer)
+		if err != nil {
+			r.logger.Error(err, "Could not instantiate finalizer deletion")
+			return apimachinery.ReconcileErr(err)
+		}
+		hasFinalizer = hasFinalizer || has
 	}
 	if !hasFinalizer {
 		r.logger.Info("Deleting NetworkTrafficShaper causes a no-op as there is no finalizer.", LogKey, networkTrafficShaper.Name)
@@ -124,40 +472,221 @@ func (r *ReconcileNetworkTrafficShaper) delete(ctx context.Context, networkTraff
 	r.logger.Info("Starting the deletion of the network connectivity test ", LogKey, networkTrafficShaper.Name)
 	r.recorder.Event(networkTrafficShaper, v1alpha1.EventTypeNormal, v1alpha1.EventTypeDeletion, "Deleting the NetworkTrafficShaper")
 
-	undoShapePodList := func(ctx context.Context, pods *corev1.PodList, device string) error {
-		for _, pod := range pods.Items {
-			if err := undoShape(ctx, r.config, pod.Namespace, pod.Name, device); err != nil {
-				return err
+	// the finalizer is kept until the shaping of all pods which still exist has been undone
+	if err := r.undoShaping(ctx, networkTrafficShaper); err != nil {
+		return r.failed(ctx, networkTrafficShaper, 0, nil, err)
+	}
+
+	for _, finalizer := range []string{FinalizerName, legacyFinalizerName} {
+		if err := apimachinery.DeleteFinalizer(ctx, r.client, finalizer, networkTrafficShaper); err != nil {
+			r.logger.Error(err, "Error removing finalizer from the NetworkTrafficShaper resource", LogKey, networkTrafficShaper.Name)
+			return apimachinery.ReconcileErr(err)
+		}
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// undoShaping removes the shaping from all targets of <networkTrafficShaper> and all pods recorded as shaped in its
+// status, which may no longer be targeted. Pods which are gone are not shaped anymore, the shaping of all other pods
+// is undone even if it fails for some of them.
+func (r *ReconcileNetworkTrafficShaper) undoShaping(ctx context.Context, networkTrafficShaper *v1alpha1.NetworkTrafficShaper) error {
+	var (
+		undone   []v1alpha1.ShapedPod
+		failures []error
+	)
+	for _, shaperTarget := range networkTrafficShaper.Spec.Targets {
+		shaping, errs := tc.ShapingFor(shaperTarget.ShaperConfig, field.NewPath("configuration"))
+		if len(errs) > 0 {
+			// invalid configurations have never been applied
+			continue
+		}
+		if selection, err := target.ParseSelection(shaperTarget.Mode, shaperTarget.Value); err != nil || !selection.All() {
+			// only the selected pods of the matched ones are shaped, they are recorded in the status
+			continue
+		}
+		if shaperTarget.Kind == v1alpha1.Node {
+			// node helpers are not created to undo a shaping, the shaped ones are recorded in the status
+			continue
+		}
+
+		// pods which became unready in the meantime are still shaped
+		targets, _, err := r.resolveTargets(ctx, networkTrafficShaper, shaperTarget, target.Options{Binaries: shaping.Binaries(), AllowNotReady: true})
+		if err != nil {
+			failures = append(failures, err)
+			continue
+		}
+		for i := range targets {
+			// failed pods are not retried from the status
+			undone = append(undone, v1alpha1.ShapedPod{Namespace: targets[i].Pod.Namespace, Name: targets[i].Pod.Name, Device: shaperTarget.ShaperConfig.Device})
+			if result, err := undoShape(ctx, r.executor, &targets[i], shaperTarget.ShaperConfig.Device, shaping); err != nil && !r.podGone(ctx, targets[i].Pod) {
+				r.recordCommandFailure(networkTrafficShaper, targets[i].Pod, result, err)
+				failures = append(failures, err)
 			}
 		}
+	}
+
+	for _, shapedPod := range networkTrafficShaper.Status.Pods {
+		if containsShapedPod(undone, shapedPod) {
+			continue
+		}
+		if err := r.undoShapedPod(ctx, networkTrafficShaper, shapedPod); err != nil {
+			failures = append(failures, err)
+		}
+	}
+	return utilerrors.NewAggregate(failures)
+}
+
+// undoShapedPod removes the shaping recorded in the status from a pod. Pods which are gone, terminating or
+// completed are not shaped anymore. The shaping of all other pods has to be undone, pods which are not eligible,
+// e.g. because a NetworkExecPolicy denies it, are kept shaped and an error is returned unless the deleted
+// NetworkTrafficShaper abandons them. The requester is not authorized again, the shaping was applied on its behalf.
+func (r *ReconcileNetworkTrafficShaper) undoShapedPod(ctx context.Context, networkTrafficShaper *v1alpha1.NetworkTrafficShaper, shapedPod v1alpha1.ShapedPod) error {
+	pod := &corev1.Pod{}
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: shapedPod.Namespace, Name: shapedPod.Name}, pod); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		// the network namespace of the pod is removed together with the shaping
 		return nil
 	}
 
-	for _, target := range networkTrafficShaper.Spec.Targets {
-		switch target.Kind {
-		case v1alpha1.Selector:
-			podList, err := utils.GetPodsByLabels(ctx, r.client, labels.SelectorFromSet(labels.Set(target.SourceSelector.MatchLabels)), target.Namespace)
-			if err != nil {
-				return apimachinery.ReconcileErr(err)
-			}
-			if err := undoShapePodList(ctx, podList, target.ShaperConfig.Device); err != nil {
-				return apimachinery.ReconcileErr(err)
+	origin, _ := executor.OriginFrom(ctx)
+	origin.Undo = true
+	ctx = executor.WithOrigin(ctx, origin)
+
+	shaping := &tc.Shaping{Ingress: len(shapedPod.IFBDevice) > 0, IFBDevice: shapedPod.IFBDevice}
+	shapeTarget, err := target.NewResolver(r.client, r.executor, r.nodeHelperOptions).Resolve(ctx, pod, target.Options{
+		Container:        shapedPod.Container,
+		Binaries:         shaping.Binaries(),
+		AllowHostNetwork: true,
+		AllowNotReady:    true,
+		Node:             shapedPod.Node,
+	})
+	if target.IsNotEligible(err) {
+		if networkTrafficShaper.DeletionTimestamp != nil && networkTrafficShaper.Annotations[AbandonAnnotation] == "true" {
+			r.recorder.Eventf(networkTrafficShaper, v1alpha1.EventTypeWarning, v1alpha1.EventTypeDeletion,
+				"Shaping of device %s in pod %s/%s is abandoned: %v", shapedPod.Device, shapedPod.Namespace, shapedPod.Name, err)
+			return nil
+		}
+		return fmt.Errorf("%v, annotate the NetworkTrafficShaper with %s=true to delete it without undoing the shaping", err, AbandonAnnotation)
+	}
+	if err != nil {
+		return err
+	}
+	if result, err := undoRecordedShape(ctx, r.executor, shapeTarget, shapedPod.Device, shaping); err != nil && !r.podGone(ctx, pod) {
+		r.recordCommandFailure(networkTrafficShaper, pod, result, err)
+		return err
+	}
+	return nil
+}
+
+// podGone returns true if <pod> has been deleted in the meantime, e.g. while tc was executed in it. A pod which was
+// recreated with the same name is a different pod.
+func (r *ReconcileNetworkTrafficShaper) podGone(ctx context.Context, pod *corev1.Pod) bool {
+	current := &corev1.Pod{}
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: pod.Namespace, Name: pod.Name}, current); err != nil {
+		return errors.IsNotFound(err)
+	}
+	return current.UID != pod.UID
+}
+
+// resolveTargets resolves the pods and containers of the given target, pods which are not eligible or missing are
+// skipped and reported as events. The pod of skipped pod targets is not set.
+func (r *ReconcileNetworkTrafficShaper) resolveTargets(ctx context.Context, networkTrafficShaper *v1alpha1.NetworkTrafficShaper, shaperTarget v1alpha1.ShaperTarget, options target.Options) ([]target.Target, []target.Skipped, error) {
+	var (
+		resolver = target.NewResolver(r.client, r.executor, r.nodeHelperOptions)
+		skipped  []target.Skipped
+		targets  []target.Target
+	)
+	options.Container = shaperTarget.Container
+	options.AllowHostNetwork = shaperTarget.AllowHostNetwork
+
+	switch shaperTarget.Kind {
+	case v1alpha1.Selector:
+		var err error
+		targets, skipped, err = resolver.Selector(ctx, shaperTarget.Namespace, shaperTarget.SourceSelector, options)
+		if err != nil {
+			return nil, nil, err
+		}
+	case v1alpha1.Pod:
+		podTarget, err := resolver.Pod(ctx, shaperTarget.Namespace, shaperTarget.Name, options)
+		if err != nil {
+			if !target.IsNotEligible(err) && !errors.IsNotFound(err) {
+				return nil, nil, err
 			}
-		case v1alpha1.Pod:
-			pod := &corev1.Pod{}
-			if err := r.client.Get(ctx, client.ObjectKey{Namespace: target.Namespace, Name: target.Name}, pod); err != nil {
-				return apimachinery.ReconcileErr(err)
+			skipped = append(skipped, target.Skipped{Reason: err})
+			break
+		}
+		targets = append(targets, *podTarget)
+	case v1alpha1.Node:
+		nodes, err := r.targetNodes(ctx, shaperTarget)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, nil, err
 			}
-			if err := undoShape(ctx, r.config, pod.Namespace, pod.Name, target.ShaperConfig.Device); err != nil {
-				return apimachinery.ReconcileErr(err)
+			skipped = append(skipped, target.Skipped{Reason: err})
+		}
+		owner := metav1.OwnerReference{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       "NetworkTrafficShaper",
+			Name:       networkTrafficShaper.Name,
+			UID:        networkTrafficShaper.UID,
+		}
+		for _, node := range nodes {
+			nodeTarget, err := resolver.Node(ctx, node, owner, options)
+			if err != nil {
+				if !target.IsNotEligible(err) {
+					return nil, nil, err
+				}
+				skipped = append(skipped, target.Skipped{Reason: err})
+				continue
 			}
+			targets = append(targets, *nodeTarget)
 		}
 	}
 
-	if err := apimachinery.DeleteFinalizer(ctx, r.client, FinalizerName, networkTrafficShaper); err != nil {
-		r.logger.Error(err, "Error removing finalizer from the NetworkTrafficShaper resource", LogKey, networkTrafficShaper.Name)
-		return apimachinery.ReconcileErr(err)
+	for _, s := range skipped {
+		r.logger.Info("Skipping pod", LogKey, networkTrafficShaper.Name, "reason", s.Reason.Error())
+		r.recorder.Eventf(networkTrafficShaper, v1alpha1.EventTypeWarning, v1alpha1.EventTypeTargetNotEligible, "Skipping target: %v", s.Reason)
 	}
+	return targets, skipped, nil
+}
 
-	return reconcile.Result{}, nil
+// targetNodes returns the names of the nodes of a node target.
+func (r *ReconcileNetworkTrafficShaper) targetNodes(ctx context.Context, shaperTarget v1alpha1.ShaperTarget) ([]string, error) {
+	if len(shaperTarget.Name) > 0 {
+		node := &corev1.Node{}
+		if err := r.client.Get(ctx, client.ObjectKey{Name: shaperTarget.Name}, node); err != nil {
+			return nil, err
+		}
+		return []string{node.Name}, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(shaperTarget.SourceSelector)
+	if err != nil {
+		return nil, err
+	}
+	nodes := &corev1.NodeList{}
+	if err := r.client.List(ctx, nodes, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, node := range nodes.Items {
+		names = append(names, node.Name)
+	}
+	return names, nil
+}
+
+// recordCommandFailure emits a warning event for a tc command which failed inside a target pod.
+func (r *ReconcileNetworkTrafficShaper) recordCommandFailure(networkTrafficShaper *v1alpha1.NetworkTrafficShaper, pod *corev1.Pod, result *executor.ExecResult, err error) {
+	message := err.Error()
+	if executor.IsExecError(err) {
+		message = result.Message()
+	}
+	r.recorder.Eventf(networkTrafficShaper, v1alpha1.EventTypeWarning, v1alpha1.EventTypeCommandFailed,
+		"tc failed on pod %s/%s: %s", pod.Namespace, pod.Name, message)
 }
@@ -0,0 +1,1146 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor/fake"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/guard"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/policy"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/target"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/test"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var (
+	request               = reconcile.Request{NamespacedName: types.NamespacedName{Name: "shaper"}}
+	testNodeHelperOptions = &target.NodeHelperOptions{Namespace: "node-helpers", Image: "helper:test"}
+)
+
+func newTestReconciler(podExecutor *fake.Executor, objects ...runtime.Object) (*ReconcileNetworkTrafficShaper, *record.FakeRecorder) {
+	recorder := record.NewFakeRecorder(100)
+	return &ReconcileNetworkTrafficShaper{
+		logger:   log.Log.WithName(Name),
+		executor: podExecutor,
+		clock:    clock.NewFakeClock(time.Now()),
+		guardOptions: &guard.Options{
+			ProtectedNamespaces: []string{"kube-system"},
+			KillSwitchNamespace: "default",
+			KillSwitchName:      "kill-switch",
+		},
+		nodeHelperOptions: testNodeHelperOptions,
+		client:            test.NewFakeClient(objects...),
+		ctx:               context.TODO(),
+		scheme:            test.Scheme(),
+		recorder:          recorder,
+	}, recorder
+}
+
+func newPod(name string, labels map[string]string) *corev1.Pod {
+	return test.NewRunningPod("default", name, labels)
+}
+
+func newShaper(targets ...v1alpha1.ShaperTarget) *v1alpha1.NetworkTrafficShaper {
+	return &v1alpha1.NetworkTrafficShaper{
+		ObjectMeta: metav1.ObjectMeta{Name: "shaper"},
+		Spec:       v1alpha1.NetworkTrafficShaperSpec{Targets: targets},
+	}
+}
+
+// newExecutor returns a fake executor for pods providing tc in all containers, their devices have the default
+// noqueue root qdisc.
+func newExecutor() *fake.Executor {
+	return fake.NewExecutor().
+		On(`^command -v tc `, fake.Succeeded()).
+		On(`^tc -j qdisc show `, fake.Response{Stdout: noqueue})
+}
+
+const (
+	noqueue = `[{"kind":"noqueue","handle":"0:","root":true,"refcnt":2,"options":{}}]`
+	netem   = `[{"kind":"netem","handle":"8001:","root":true,"refcnt":2,"options":{"limit":1000,"delay":{"delay":0.2,"jitter":0,"correlation":0},"ecn":false,"gap":0}}]`
+)
+
+var delay = v1alpha1.ShaperConfiguration{Type: v1alpha1.Delay, Device: "eth0", Value: "200ms"}
+
+func executedIn(podExecutor *fake.Executor) []string {
+	var pods []string
+	for _, execution := range podExecutor.Executions() {
+		if !strings.HasPrefix(execution.Command, "tc qdisc ") {
+			continue
+		}
+		pods = append(pods, execution.Namespace+"/"+execution.Name+": "+execution.Command)
+	}
+	sort.Strings(pods)
+	return pods
+}
+
+func TestReconcile(t *testing.T) {
+	podExecutor := newExecutor().On(`^tc qdisc `, fake.Succeeded())
+	r, _ := newTestReconciler(podExecutor,
+		newPod("pod", nil),
+		newPod("web-1", map[string]string{"app": "web"}),
+		newPod("web-2", map[string]string{"app": "web"}),
+		newPod("db", map[string]string{"app": "db"}),
+		newShaper(
+			v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: delay},
+			v1alpha1.ShaperTarget{Kind: v1alpha1.Selector, Namespace: "default", SourceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}, ShaperConfig: delay},
+		),
+	)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	expected := []string{
+		"default/pod: tc qdisc replace dev eth0 root netem delay 200ms",
+		"default/web-1: tc qdisc replace dev eth0 root netem delay 200ms",
+		"default/web-2: tc qdisc replace dev eth0 root netem delay 200ms",
+	}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+
+	shaper := &v1alpha1.NetworkTrafficShaper{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, shaper); err != nil {
+		t.Fatalf("could not get NetworkTrafficShaper: %v", err)
+	}
+	if len(shaper.Finalizers) != 1 || shaper.Finalizers[0] != FinalizerName {
+		t.Errorf("expected finalizer %q, got %v", FinalizerName, shaper.Finalizers)
+	}
+}
+
+func TestReconcileCommandFailed(t *testing.T) {
+	podExecutor := newExecutor().On(`^tc qdisc replace `, fake.Failed("RTNETLINK answers: Operation not permitted", 2))
+	r, recorder := newTestReconciler(podExecutor,
+		newPod("pod", nil),
+		newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: delay}),
+	)
+
+	if _, err := r.Reconcile(request); err == nil {
+		t.Fatal("expected reconcile to fail")
+	}
+
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "tc failed on pod default/pod: RTNETLINK answers: Operation not permitted") {
+			t.Errorf("unexpected event %q", event)
+		}
+	default:
+		t.Error("expected a warning event")
+	}
+
+	status := getShaper(t, r).Status
+	if status.LastOperation == nil || status.LastOperation.Type != v1alpha1.LastOperationTypeCreate || status.LastOperation.State != v1alpha1.LastOperationStateError {
+		t.Errorf("expected a failed create operation, got %+v", status.LastOperation)
+	}
+	if !strings.Contains(status.LastError.Description, "Operation not permitted") {
+		t.Errorf("expected the tc error to be recorded, got %q", status.LastError.Description)
+	}
+	if len(status.Targets) != 1 || !strings.Contains(status.Targets[0].LastError, "Operation not permitted") {
+		t.Errorf("expected the tc error to be recorded for the target, got %+v", status.Targets)
+	}
+}
+
+func TestReconcileRecordsPodsShapedBeforeFailure(t *testing.T) {
+	podExecutor := fake.NewExecutor().
+		OnPod("default/pod", `^tc qdisc replace `, fake.Failed("RTNETLINK answers: Operation not permitted", 2)).
+		On(`^command -v tc `, fake.Succeeded()).
+		On(`^tc -j qdisc show `, fake.Response{Stdout: noqueue}).
+		On(`^tc qdisc `, fake.Succeeded())
+	r, _ := newTestReconciler(podExecutor,
+		newPod("web", map[string]string{"app": "web"}),
+		newPod("pod", nil),
+		newShaper(
+			// selected pods are only undone from the status
+			v1alpha1.ShaperTarget{Kind: v1alpha1.Selector, Namespace: "default", SourceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}, ShaperConfig: delay, Mode: v1alpha1.SelectionModeFixed, Value: "1"},
+			v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: delay},
+		),
+	)
+
+	if _, err := r.Reconcile(request); err == nil {
+		t.Fatal("expected reconcile to fail")
+	}
+	shaper := getShaper(t, r)
+	var recorded []string
+	for _, p := range shaper.Status.Pods {
+		recorded = append(recorded, p.Name)
+	}
+	sort.Strings(recorded)
+	if expected := []string{"pod", "web"}; !reflect.DeepEqual(recorded, expected) {
+		t.Errorf("expected pods %v to be recorded, got %v", expected, recorded)
+	}
+	if len(shaper.Status.Targets) != 2 || shaper.Status.Targets[0].Shaped != 1 || !strings.Contains(shaper.Status.Targets[1].LastError, "Operation not permitted") {
+		t.Errorf("expected the outcome of both targets, got %+v", shaper.Status.Targets)
+	}
+
+	// the recorded pods are undone once the NetworkTrafficShaper is deleted
+	now := metav1.Now()
+	shaper.DeletionTimestamp = &now
+	if err := r.client.Update(r.ctx, shaper); err != nil {
+		t.Fatal(err)
+	}
+	podExecutor = newShapedExecutor()
+	r.executor = podExecutor
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	expected := []string{
+		"default/pod: tc qdisc del dev eth0 root",
+		"default/web: tc qdisc del dev eth0 root",
+	}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+}
+
+func TestReconcileStatus(t *testing.T) {
+	notReady := newPod("web-2", map[string]string{"app": "web"})
+	notReady.Status.Conditions = nil
+	r, _ := newTestReconciler(newExecutor().On(`^tc qdisc `, fake.Succeeded()),
+		newPod("web-1", map[string]string{"app": "web"}),
+		notReady,
+		newShaper(
+			v1alpha1.ShaperTarget{Kind: v1alpha1.Selector, Namespace: "default", SourceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}, ShaperConfig: delay},
+			v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "gone", ShaperConfig: delay},
+		),
+	)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	status := getShaper(t, r).Status
+	if operation := status.LastOperation; operation == nil || operation.Type != v1alpha1.LastOperationTypeCreate ||
+		operation.State != v1alpha1.LastOperationStateSucceeded || operation.Progress != 100 {
+		t.Errorf("expected a succeeded create operation, got %+v", operation)
+	}
+	if len(status.Targets) != 2 || status.Targets[0].Shaped != 1 || status.Targets[0].Skipped != 1 ||
+		status.Targets[1].Shaped != 0 || status.Targets[1].Skipped != 1 || !strings.Contains(status.Targets[1].LastError, "not found") {
+		t.Errorf("expected the outcome of both targets, got %+v", status.Targets)
+	}
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if operation := getShaper(t, r).Status.LastOperation; operation.Type != v1alpha1.LastOperationTypeReconcile {
+		t.Errorf("expected a reconcile operation, got %+v", operation)
+	}
+}
+
+func TestReconcileReplacesLegacyFinalizer(t *testing.T) {
+	shaper := newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: delay})
+	shaper.Finalizers = []string{legacyFinalizerName}
+	r, _ := newTestReconciler(newExecutor().On(`^tc qdisc `, fake.Succeeded()), newPod("pod", nil), shaper)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if finalizers := getShaper(t, r).Finalizers; !reflect.DeepEqual(finalizers, []string{FinalizerName}) {
+		t.Errorf("expected only finalizer %q, got %v", FinalizerName, finalizers)
+	}
+}
+
+func TestDeleteToleratesMissingPods(t *testing.T) {
+	now := metav1.Now()
+	shaper := newShaper(
+		v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "gone", ShaperConfig: delay},
+		v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: delay},
+	)
+	shaper.Finalizers = []string{legacyFinalizerName}
+	shaper.DeletionTimestamp = &now
+	shaper.Status.Pods = []v1alpha1.ShapedPod{
+		{Namespace: "default", Name: "gone", Device: "eth0", Qdisc: "netem delay 200ms"},
+		{Namespace: "default", Name: "pod", Device: "eth0", Qdisc: "netem delay 200ms"},
+		{Namespace: "default", Name: "former", Device: "eth0", Qdisc: "netem delay 200ms"},
+	}
+	podExecutor := newShapedExecutor()
+	r, _ := newTestReconciler(podExecutor, newPod("pod", nil), newPod("former", nil), shaper)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	expected := []string{
+		"default/former: tc qdisc del dev eth0 root",
+		"default/pod: tc qdisc del dev eth0 root",
+	}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+	if finalizers := getShaper(t, r).Finalizers; len(finalizers) != 0 {
+		t.Errorf("expected finalizer to be removed, got %v", finalizers)
+	}
+}
+
+func TestDeleteUndoesRemainingPods(t *testing.T) {
+	now := metav1.Now()
+	shaper := newShaper(
+		v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "failing", ShaperConfig: delay},
+		v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: delay},
+	)
+	shaper.Finalizers = []string{FinalizerName}
+	shaper.DeletionTimestamp = &now
+	podExecutor := fake.NewExecutor().
+		On(`^command -v tc `, fake.Succeeded()).
+		On(`^tc -j qdisc show `, fake.Response{Stdout: netem}).
+		OnPod("default/failing", `^tc qdisc del `, fake.Failed("RTNETLINK answers: Operation not permitted", 2)).
+		On(`^tc qdisc del `, fake.Succeeded())
+	r, _ := newTestReconciler(podExecutor, newPod("failing", nil), newPod("pod", nil), shaper)
+
+	if _, err := r.Reconcile(request); err == nil {
+		t.Fatal("expected reconcile to fail")
+	}
+
+	// both pods are attempted, the finalizer is kept for the failed one
+	expected := []string{
+		"default/failing: tc qdisc del dev eth0 root",
+		"default/pod: tc qdisc del dev eth0 root",
+	}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+	deleting := getShaper(t, r)
+	if !reflect.DeepEqual(deleting.Finalizers, []string{FinalizerName}) {
+		t.Errorf("expected finalizer %q to be kept, got %v", FinalizerName, deleting.Finalizers)
+	}
+	if operation := deleting.Status.LastOperation; operation == nil || operation.Type != v1alpha1.LastOperationTypeDelete || operation.State != v1alpha1.LastOperationStateError {
+		t.Errorf("expected a failed delete operation, got %+v", operation)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	now := metav1.Now()
+	shaper := newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: delay})
+	shaper.Finalizers = []string{FinalizerName}
+	shaper.DeletionTimestamp = &now
+	podExecutor := fake.NewExecutor().
+		On(`^command -v tc `, fake.Succeeded()).
+		On(`^tc -j qdisc show `, fake.Response{Stdout: netem}).
+		On(`^tc qdisc del `, fake.Succeeded())
+	r, _ := newTestReconciler(podExecutor, newPod("pod", nil), shaper)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	expected := []string{"default/pod: tc qdisc del dev eth0 root"}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+
+	deleted := &v1alpha1.NetworkTrafficShaper{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, deleted); err != nil {
+		t.Fatalf("could not get NetworkTrafficShaper: %v", err)
+	}
+	if len(deleted.Finalizers) != 0 {
+		t.Errorf("expected finalizer to be removed, got %v", deleted.Finalizers)
+	}
+}
+
+func TestReconcileSkipsIneligiblePods(t *testing.T) {
+	notReady := newPod("web-2", map[string]string{"app": "web"})
+	notReady.Status.Conditions = nil
+	hostNetwork := newPod("web-3", map[string]string{"app": "web"})
+	hostNetwork.Spec.HostNetwork = true
+	sidecar := test.NewRunningPod("default", "web-1", map[string]string{"app": "web"}, "proxy", "app")
+
+	podExecutor := fake.NewExecutor().
+		OnContainer("default/web-1", "app", `^command -v tc `, fake.Succeeded()).
+		On(`^tc -j qdisc show `, fake.Response{Stdout: noqueue}).
+		On(`^tc qdisc `, fake.Succeeded())
+	r, recorder := newTestReconciler(podExecutor, sidecar, notReady, hostNetwork,
+		newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Selector, Namespace: "default", SourceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}, ShaperConfig: delay}),
+	)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	var shaped []string
+	for _, execution := range podExecutor.Executions() {
+		if strings.HasPrefix(execution.Command, "tc qdisc ") {
+			shaped = append(shaped, execution.Namespace+"/"+execution.Name+"/"+execution.Container)
+		}
+	}
+	if expected := []string{"default/web-1/app"}; !reflect.DeepEqual(shaped, expected) {
+		t.Errorf("expected only %v to be shaped, got %v", expected, shaped)
+	}
+	if len(recorder.Events) != 2 {
+		t.Errorf("expected an event for each skipped pod, got %d", len(recorder.Events))
+	}
+}
+
+func TestReconcileSkipsDeniedPods(t *testing.T) {
+	podExecutor := newExecutor().On(`^tc qdisc `, fake.Succeeded())
+	r, recorder := newTestReconciler(podExecutor,
+		newPod("web-1", map[string]string{"app": "web"}),
+		test.NewRunningPod("staging", "web-2", map[string]string{"app": "web"}),
+		&v1alpha1.NetworkExecPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+			Spec: v1alpha1.NetworkExecPolicySpec{Allow: []v1alpha1.ExecPolicyRule{
+				{Namespaces: []string{"staging"}, Tools: []v1alpha1.ExecTool{v1alpha1.ExecToolMutation}},
+			}},
+		},
+		newShaper(
+			v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "web-1", ShaperConfig: delay},
+			v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "staging", Name: "web-2", ShaperConfig: delay},
+		),
+	)
+	r.executor = policy.NewExecutor(podExecutor, r.client)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	expected := []string{"staging/web-2: tc qdisc replace dev eth0 root netem delay 200ms"}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "no NetworkExecPolicy allows mutation tools") {
+			t.Errorf("unexpected event %q", event)
+		}
+	default:
+		t.Error("expected an event for the denied pod")
+	}
+}
+
+func TestReconcileIsIdempotent(t *testing.T) {
+	podExecutor := fake.NewExecutor().
+		On(`^command -v tc `, fake.Succeeded()).
+		On(`^tc -j qdisc show `, fake.Response{Stdout: netem})
+	r, recorder := newTestReconciler(podExecutor,
+		newPod("pod", nil),
+		newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: delay}),
+	)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if actual := executedIn(podExecutor); len(actual) != 0 {
+		t.Errorf("expected the matching qdisc to be left untouched, got %v", actual)
+	}
+	if len(recorder.Events) != 0 {
+		t.Errorf("expected no events, got %d", len(recorder.Events))
+	}
+
+	shaper := &v1alpha1.NetworkTrafficShaper{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, shaper); err != nil {
+		t.Fatalf("could not get NetworkTrafficShaper: %v", err)
+	}
+	expected := v1alpha1.ShapedPod{Namespace: "default", Name: "pod", Container: "app", Device: "eth0", Qdisc: "netem delay 200ms"}
+	if len(shaper.Status.Pods) != 1 {
+		t.Fatalf("expected one shaped pod in status, got %+v", shaper.Status.Pods)
+	}
+	if actual := shaper.Status.Pods[0]; actual.AppliedAt.IsZero() {
+		t.Errorf("expected applied time to be set, got %+v", actual)
+	} else if actual.AppliedAt = expected.AppliedAt; actual != expected {
+		t.Errorf("expected shaped pod %+v, got %+v", expected, actual)
+	}
+}
+
+func TestReconcileCorrectsDrift(t *testing.T) {
+	appliedAt := metav1.NewTime(metav1.Now().Add(-time.Hour))
+	shaper := newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: delay})
+	shaper.Status.Pods = []v1alpha1.ShapedPod{{Namespace: "default", Name: "pod", Container: "app", Device: "eth0", Qdisc: "netem delay 200ms", AppliedAt: appliedAt}}
+	podExecutor := newExecutor().On(`^tc qdisc replace `, fake.Succeeded())
+	r, recorder := newTestReconciler(podExecutor, newPod("pod", nil), shaper)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	expected := []string{"default/pod: tc qdisc replace dev eth0 root netem delay 200ms"}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "drifted") {
+			t.Errorf("unexpected event %q", event)
+		}
+	default:
+		t.Error("expected a drift event")
+	}
+
+	shaped := &v1alpha1.NetworkTrafficShaper{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, shaped); err != nil {
+		t.Fatalf("could not get NetworkTrafficShaper: %v", err)
+	}
+	if len(shaped.Status.Pods) != 1 || !shaped.Status.Pods[0].AppliedAt.After(appliedAt.Time) {
+		t.Errorf("expected applied time to be updated, got %+v", shaped.Status.Pods)
+	}
+}
+
+func TestReconcileInvalidConfiguration(t *testing.T) {
+	podExecutor := newExecutor()
+	r, recorder := newTestReconciler(podExecutor,
+		newPod("pod", nil),
+		newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod",
+			ShaperConfig: v1alpha1.ShaperConfiguration{Type: v1alpha1.Loss, Device: "eth0", Value: "lots"}}),
+	)
+
+	if _, err := r.Reconcile(request); err == nil {
+		t.Fatal("expected reconcile to fail")
+	}
+	if commands := podExecutor.Commands(); len(commands) != 0 {
+		t.Errorf("expected no commands to be executed, got %v", commands)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected an event for the invalid configuration, got %d", len(recorder.Events))
+	}
+}
+
+func TestReconcileBandwidth(t *testing.T) {
+	bandwidth := v1alpha1.ShaperConfiguration{Device: "eth0", Bandwidth: &v1alpha1.BandwidthConfiguration{Rate: "10mbit", Burst: "12500"}}
+	podExecutor := fake.NewExecutor().
+		On(`^command -v tc `, fake.Succeeded()).
+		On(`^tc -j qdisc show `, fake.Response{Stdout: `[{"kind":"tbf","handle":"8001:","root":true,"refcnt":2,"options":{"rate":1250000,"burst":12499}}]`})
+	r, _ := newTestReconciler(podExecutor,
+		newPod("pod", nil),
+		newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: bandwidth}),
+	)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if actual := executedIn(podExecutor); len(actual) != 0 {
+		t.Errorf("expected the matching tbf qdisc to be left untouched, got %v", actual)
+	}
+
+	shaper := &v1alpha1.NetworkTrafficShaper{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, shaper); err != nil {
+		t.Fatalf("could not get NetworkTrafficShaper: %v", err)
+	}
+	expected := &v1alpha1.ShapedBandwidth{Algorithm: v1alpha1.BandwidthTBF, Device: "eth0", Rate: "10mbit", Burst: "12499b"}
+	if len(shaper.Status.Pods) != 1 || !reflect.DeepEqual(shaper.Status.Pods[0].Bandwidth, expected) {
+		t.Errorf("expected achieved bandwidth %+v, got %+v", expected, shaper.Status.Pods)
+	}
+}
+
+func TestReconcileIngressBandwidth(t *testing.T) {
+	ingress := v1alpha1.ShaperConfiguration{Device: "eth0", Bandwidth: &v1alpha1.BandwidthConfiguration{Rate: "10mbit", Ingress: true}}
+	podExecutor := fake.NewExecutor().
+		On(`^command -v tc `, fake.Succeeded()).
+		On(`^tc -j qdisc show dev ifb-eth0`, fake.Failed(`Cannot find device "ifb-eth0"`, 1)).
+		On(`ip link add ifb-eth0 type ifb`, fake.Succeeded())
+	r, _ := newTestReconciler(podExecutor,
+		newPod("pod", nil),
+		newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: ingress}),
+	)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	var replaced bool
+	for _, command := range podExecutor.Commands() {
+		if strings.Contains(command, "mirred egress redirect dev ifb-eth0") && strings.HasSuffix(command, "tc qdisc replace dev ifb-eth0 root tbf rate 10000000bit burst 12500 latency 50ms") {
+			replaced = true
+		}
+	}
+	if !replaced {
+		t.Errorf("expected ingress traffic to be redirected and shaped, got %v", podExecutor.Commands())
+	}
+}
+
+func TestReconcileDestinationScoped(t *testing.T) {
+	db := newPod("db", map[string]string{"app": "db"})
+	db.Status.PodIP = "10.0.0.7"
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"}, Spec: corev1.ServiceSpec{ClusterIP: "10.96.0.10"}}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "api"},
+		Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.1.3"}}}},
+	}
+	config := v1alpha1.ShaperConfiguration{
+		Device: "eth0",
+		Netem:  &v1alpha1.NetemConfiguration{Delay: &v1alpha1.NetemDelay{Time: metav1.Duration{Duration: 200 * time.Millisecond}}},
+		Match: &v1alpha1.TrafficMatch{
+			Destinations: []v1alpha1.ShaperDestination{
+				{Kind: v1alpha1.Selector, Namespace: "default", Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}},
+				{Kind: v1alpha1.Service, Namespace: "default", Name: "api"},
+				{Kind: v1alpha1.Pod, Namespace: "default", Name: "missing"},
+			},
+			Ports:    []int32{5432},
+			Protocol: v1alpha1.ProtocolTCP,
+		},
+	}
+	shaper := newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: config})
+	// the peers changed since the last reconciliation, the filters have to be rebuilt
+	shaper.Status.Pods = []v1alpha1.ShapedPod{{Namespace: "default", Name: "pod", Container: "app", Device: "eth0",
+		Qdisc: "netem delay 200ms for peers 10.0.0.7/32 10.96.0.10/32 ports 5432 protocols tcp"}}
+	podExecutor := newExecutor().On(`tc qdisc add `, fake.Succeeded())
+	r, _ := newTestReconciler(podExecutor, newPod("pod", nil), db, service, endpoints, shaper)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	expected := "(tc qdisc del dev eth0 root 2>/dev/null || true) && " +
+		"tc qdisc add dev eth0 root handle 1: prio bands 4 && " +
+		"tc qdisc add dev eth0 parent 1:4 handle 10: netem delay 200ms && " +
+		"tc filter add dev eth0 parent 1: protocol ip prio 1 u32 match ip dst 10.0.0.7/32 match ip protocol 6 0xff match ip dport 5432 0xffff flowid 1:4 && " +
+		"tc filter add dev eth0 parent 1: protocol ip prio 1 u32 match ip dst 10.0.1.3/32 match ip protocol 6 0xff match ip dport 5432 0xffff flowid 1:4 && " +
+		"tc filter add dev eth0 parent 1: protocol ip prio 1 u32 match ip dst 10.96.0.10/32 match ip protocol 6 0xff match ip dport 5432 0xffff flowid 1:4"
+	var applied []string
+	for _, command := range podExecutor.Commands() {
+		if strings.Contains(command, "tc qdisc add ") {
+			applied = append(applied, command)
+		}
+	}
+	if !reflect.DeepEqual(applied, []string{expected}) {
+		t.Errorf("expected command %q, got %v", expected, applied)
+	}
+
+	shaped := &v1alpha1.NetworkTrafficShaper{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, shaped); err != nil {
+		t.Fatalf("could not get NetworkTrafficShaper: %v", err)
+	}
+	qdisc := "netem delay 200ms for peers 10.0.0.7/32 10.0.1.3/32 10.96.0.10/32 ports 5432 protocols tcp"
+	if len(shaped.Status.Pods) != 1 || shaped.Status.Pods[0].Qdisc != qdisc {
+		t.Errorf("expected shaped pod with qdisc %q, got %+v", qdisc, shaped.Status.Pods)
+	}
+}
+
+// newShapedExecutor returns a fake executor for pods whose devices are shaped with the delay configuration.
+func newShapedExecutor() *fake.Executor {
+	return fake.NewExecutor().
+		On(`^command -v tc `, fake.Succeeded()).
+		On(`^tc -j qdisc show `, fake.Response{Stdout: netem}).
+		On(`^tc qdisc `, fake.Succeeded())
+}
+
+func getShaper(t *testing.T, r *ReconcileNetworkTrafficShaper) *v1alpha1.NetworkTrafficShaper {
+	shaper := &v1alpha1.NetworkTrafficShaper{}
+	if err := r.client.Get(r.ctx, request.NamespacedName, shaper); err != nil {
+		t.Fatalf("could not get NetworkTrafficShaper: %v", err)
+	}
+	return shaper
+}
+
+func TestReconcileDuration(t *testing.T) {
+	var (
+		start       = time.Date(2019, 10, 1, 10, 0, 0, 0, time.UTC)
+		fakeClock   = clock.NewFakeClock(start)
+		podExecutor = newShapedExecutor()
+		shaper      = newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: delay})
+	)
+	shaper.Spec.Duration = &metav1.Duration{Duration: 5 * time.Second}
+	r, _ := newTestReconciler(podExecutor, newPod("pod", nil), shaper)
+	r.clock = fakeClock
+
+	result, err := r.Reconcile(request)
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if result.RequeueAfter != 5*time.Second {
+		t.Errorf("expected requeue at the end of the window, got %v", result.RequeueAfter)
+	}
+	active := getShaper(t, r)
+	if active.Status.Phase != v1alpha1.ShaperPhaseActive || len(active.Status.Pods) != 1 {
+		t.Fatalf("expected the pod to be shaped, got phase %q and pods %v", active.Status.Phase, active.Status.Pods)
+	}
+	if !active.Status.StartTime.Equal(&metav1.Time{Time: start}) || !active.Status.EndTime.Equal(&metav1.Time{Time: start.Add(5 * time.Second)}) {
+		t.Errorf("unexpected window %v - %v", active.Status.StartTime, active.Status.EndTime)
+	}
+
+	fakeClock.Step(5 * time.Second)
+	if result, err = r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if result.RequeueAfter != 0 {
+		t.Errorf("expected no requeue after the window ended, got %v", result.RequeueAfter)
+	}
+	expected := []string{"default/pod: tc qdisc del dev eth0 root"}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+	completed := getShaper(t, r)
+	if completed.Status.Phase != v1alpha1.ShaperPhaseCompleted || len(completed.Status.Pods) != 0 {
+		t.Errorf("expected the shaping to be completed, got phase %q and pods %v", completed.Status.Phase, completed.Status.Pods)
+	}
+
+	// the shaping is not undone again
+	if _, err = r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+}
+
+func TestReconcileRollbackAfterRestart(t *testing.T) {
+	// the window ended while the controller was not running
+	var (
+		start       = metav1.NewTime(time.Now().Add(-time.Hour))
+		end         = metav1.NewTime(start.Add(10 * time.Minute))
+		podExecutor = newShapedExecutor()
+		shaper      = newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: delay})
+	)
+	shaper.Spec.Duration = &metav1.Duration{Duration: 10 * time.Minute}
+	shaper.Status = v1alpha1.NetworkTrafficShaperStatus{Phase: v1alpha1.ShaperPhaseActive, StartTime: &start, EndTime: &end}
+	r, recorder := newTestReconciler(podExecutor, newPod("pod", nil), shaper)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	expected := []string{"default/pod: tc qdisc del dev eth0 root"}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+	if phase := getShaper(t, r).Status.Phase; phase != v1alpha1.ShaperPhaseCompleted {
+		t.Errorf("expected phase %q, got %q", v1alpha1.ShaperPhaseCompleted, phase)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected a rollback event, got %d events", len(recorder.Events))
+	}
+}
+
+func TestReconcileSchedule(t *testing.T) {
+	var (
+		fakeClock   = clock.NewFakeClock(time.Date(2019, 10, 1, 10, 50, 0, 0, time.UTC))
+		podExecutor = newShapedExecutor()
+		shaper      = newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: delay})
+	)
+	shaper.Spec.Schedule = "0 * * * *"
+	shaper.Spec.Duration = &metav1.Duration{Duration: 15 * time.Minute}
+	r, _ := newTestReconciler(podExecutor, newPod("pod", nil), shaper)
+	r.clock = fakeClock
+
+	for _, step := range []struct {
+		after        time.Duration
+		phase        v1alpha1.ShaperPhase
+		requeueAfter time.Duration
+		shaped       bool
+	}{
+		{0, v1alpha1.ShaperPhaseWaiting, 10 * time.Minute, false},
+		{10 * time.Minute, v1alpha1.ShaperPhaseActive, 10 * time.Second, true},
+		{14*time.Minute + 55*time.Second, v1alpha1.ShaperPhaseActive, 5 * time.Second, true},
+		{5 * time.Second, v1alpha1.ShaperPhaseWaiting, 45 * time.Minute, false},
+	} {
+		fakeClock.Step(step.after)
+		result, err := r.Reconcile(request)
+		if err != nil {
+			t.Fatalf("%v: reconcile failed: %v", fakeClock.Now(), err)
+		}
+		shaper := getShaper(t, r)
+		if shaper.Status.Phase != step.phase || result.RequeueAfter != step.requeueAfter || (len(shaper.Status.Pods) > 0) != step.shaped {
+			t.Errorf("%v: expected phase %q, requeue after %v and shaped %t, got %q, %v and %v",
+				fakeClock.Now(), step.phase, step.requeueAfter, step.shaped, shaper.Status.Phase, result.RequeueAfter, shaper.Status.Pods)
+		}
+	}
+
+	expected := []string{"default/pod: tc qdisc del dev eth0 root"}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+}
+
+func TestReconcilePaused(t *testing.T) {
+	var (
+		now         = time.Now().Truncate(time.Second)
+		pauseUntil  = metav1.NewTime(now.Add(time.Hour))
+		podExecutor = newShapedExecutor()
+		shaper      = newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: delay})
+	)
+	shaper.Spec.PauseUntil = &pauseUntil
+	shaper.Status.Pods = []v1alpha1.ShapedPod{{Namespace: "default", Name: "pod", Device: "eth0", Qdisc: "netem delay 200ms"}}
+	r, _ := newTestReconciler(podExecutor, newPod("pod", nil), shaper)
+	r.clock = clock.NewFakeClock(now)
+
+	result, err := r.Reconcile(request)
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if result.RequeueAfter != time.Hour {
+		t.Errorf("expected requeue when the pause ends, got %v", result.RequeueAfter)
+	}
+	expected := []string{"default/pod: tc qdisc del dev eth0 root"}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+	if phase := getShaper(t, r).Status.Phase; phase != v1alpha1.ShaperPhasePaused {
+		t.Errorf("expected phase %q, got %q", v1alpha1.ShaperPhasePaused, phase)
+	}
+}
+
+func TestReconcileUndoesUntargetedPods(t *testing.T) {
+	var (
+		podExecutor = newShapedExecutor()
+		notReady    = newPod("web-2", map[string]string{"app": "web"})
+		shaper      = newShaper(v1alpha1.ShaperTarget{
+			Kind:      v1alpha1.Selector,
+			Namespace: "default",
+			SourceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"web", "api"}},
+			}},
+			ShaperConfig: delay,
+		})
+	)
+	notReady.Status.Conditions = nil
+	shaper.Status.Pods = []v1alpha1.ShapedPod{
+		{Namespace: "default", Name: "web-2", Device: "eth0", Qdisc: "netem delay 200ms"},
+		{Namespace: "default", Name: "db", Device: "eth0", Qdisc: "netem delay 200ms"},
+		{Namespace: "default", Name: "gone", Device: "eth0", Qdisc: "netem delay 200ms"},
+	}
+	r, recorder := newTestReconciler(podExecutor,
+		newPod("web-1", map[string]string{"app": "web"}),
+		newPod("api", map[string]string{"app": "api"}),
+		newPod("db", map[string]string{"app": "db"}),
+		notReady,
+		shaper,
+	)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	// the pod which is no longer selected is unshaped, the unready one stays shaped
+	expected := []string{"default/db: tc qdisc del dev eth0 root"}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+	var shaped []string
+	for _, p := range getShaper(t, r).Status.Pods {
+		shaped = append(shaped, p.Name)
+	}
+	sort.Strings(shaped)
+	if expected := []string{"api", "web-1", "web-2"}; !reflect.DeepEqual(shaped, expected) {
+		t.Errorf("expected shaped pods %v, got %v", expected, shaped)
+	}
+
+	var rolledBack int
+	for len(recorder.Events) > 0 {
+		if event := <-recorder.Events; strings.Contains(event, v1alpha1.EventTypeRolledBack) {
+			rolledBack++
+		}
+	}
+	if rolledBack != 2 {
+		t.Errorf("expected a rollback event for the untargeted pods, got %d", rolledBack)
+	}
+}
+
+func TestDeleteUndoesRecordedPods(t *testing.T) {
+	now := metav1.Now()
+	shaper := newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Selector, Namespace: "default", SourceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}, ShaperConfig: delay})
+	shaper.Finalizers = []string{FinalizerName}
+	shaper.DeletionTimestamp = &now
+	// the pod was shaped with an ingress configuration before the selector changed
+	shaper.Status.Pods = []v1alpha1.ShapedPod{{Namespace: "default", Name: "db", Device: "eth0", IFBDevice: "ifb-eth0", Qdisc: "netem delay 200ms on ingress via ifb-eth0"}}
+	podExecutor := newShapedExecutor().On(`^if tc qdisc show `, fake.Succeeded())
+	r, _ := newTestReconciler(podExecutor, newPod("web", map[string]string{"app": "web"}), newPod("db", map[string]string{"app": "db"}), shaper)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	var undone []string
+	for _, execution := range podExecutor.Executions() {
+		if strings.HasPrefix(execution.Command, "tc qdisc del ") || strings.HasPrefix(execution.Command, "if tc qdisc show ") {
+			undone = append(undone, execution.Name+": "+execution.Command)
+		}
+	}
+	sort.Strings(undone)
+	expected := []string{
+		"db: if tc qdisc show dev eth0 ingress | grep -q ingress; then tc qdisc del dev eth0 ingress; fi && if ip link show dev ifb-eth0 >/dev/null 2>&1; then ip link del dev ifb-eth0; fi",
+		"web: tc qdisc del dev eth0 root",
+	}
+	if !reflect.DeepEqual(undone, expected) {
+		t.Errorf("expected commands %v, got %v", expected, undone)
+	}
+}
+
+func TestDeleteKeepsDeniedPods(t *testing.T) {
+	now := metav1.Now()
+	shaper := newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "other", ShaperConfig: delay})
+	shaper.Finalizers = []string{FinalizerName}
+	shaper.DeletionTimestamp = &now
+	shaper.Status.Pods = []v1alpha1.ShapedPod{{Namespace: "default", Name: "pod", Device: "eth0", Qdisc: "netem delay 200ms"}}
+	podExecutor := newShapedExecutor()
+	r, _ := newTestReconciler(podExecutor, newPod("pod", nil), shaper,
+		// a policy created after the pod was shaped denies undoing the shaping
+		&v1alpha1.NetworkExecPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+			Spec:       v1alpha1.NetworkExecPolicySpec{Deny: []v1alpha1.ExecPolicyRule{{Namespaces: []string{"default"}}}},
+		},
+	)
+	r.executor = policy.NewExecutor(podExecutor, r.client)
+
+	if _, err := r.Reconcile(request); err == nil {
+		t.Fatal("expected reconcile to fail")
+	}
+
+	if actual := executedIn(podExecutor); len(actual) != 0 {
+		t.Errorf("expected no commands, got %v", actual)
+	}
+	deleting := getShaper(t, r)
+	if !reflect.DeepEqual(deleting.Finalizers, []string{FinalizerName}) {
+		t.Errorf("expected finalizer %q to be kept, got %v", FinalizerName, deleting.Finalizers)
+	}
+	if pods := deleting.Status.Pods; len(pods) != 1 || pods[0].Name != "pod" {
+		t.Errorf("expected the denied pod to stay recorded, got %v", pods)
+	}
+	if !strings.Contains(deleting.Status.LastError.Description, AbandonAnnotation) {
+		t.Errorf("expected the error to name the override, got %q", deleting.Status.LastError.Description)
+	}
+
+	// the shaping is abandoned on request
+	deleting.Annotations = map[string]string{AbandonAnnotation: "true"}
+	if err := r.client.Update(r.ctx, deleting); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if actual := executedIn(podExecutor); len(actual) != 0 {
+		t.Errorf("expected no commands, got %v", actual)
+	}
+	if finalizers := getShaper(t, r).Finalizers; len(finalizers) != 0 {
+		t.Errorf("expected the finalizer to be removed, got %v", finalizers)
+	}
+}
+
+func TestDeleteUndoesPodsOfRevokedRequester(t *testing.T) {
+	now := metav1.Now()
+	shaper := newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "other", ShaperConfig: delay})
+	shaper.Finalizers = []string{FinalizerName}
+	shaper.DeletionTimestamp = &now
+	shaper.Status.Pods = []v1alpha1.ShapedPod{{Namespace: "default", Name: "pod", Device: "eth0", Qdisc: "netem delay 200ms"}}
+	if err := policy.SetRequester(shaper, authenticationv1.UserInfo{Username: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	podExecutor := newShapedExecutor()
+	r, _ := newTestReconciler(podExecutor, newPod("pod", nil), shaper)
+	c := r.client.(*test.Client)
+	// alice may no longer exec into the pod she had shaped
+	c.DeniedUsers = map[string]string{"alice": "role binding removed"}
+	r.executor = policy.NewExecutor(podExecutor, c)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	if expected, actual := []string{"default/pod: tc qdisc del dev eth0 root"}, executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+	if len(c.Reviews) != 0 {
+		t.Errorf("expected the requester not to be checked again, got %+v", c.Reviews)
+	}
+	if finalizers := getShaper(t, r).Finalizers; len(finalizers) != 0 {
+		t.Errorf("expected the finalizer to be removed, got %v", finalizers)
+	}
+}
+
+func TestReconcileSelection(t *testing.T) {
+	var (
+		podExecutor = newExecutor().On(`^tc qdisc `, fake.Succeeded())
+		shaper      = newShaper(v1alpha1.ShaperTarget{
+			Kind:           v1alpha1.Selector,
+			Namespace:      "default",
+			SourceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			ShaperConfig:   delay,
+			Mode:           v1alpha1.SelectionModePercent,
+			Value:          "50%",
+		})
+		objects = []runtime.Object{shaper}
+	)
+	shaper.UID = "4f5c4f2e-4e35-4bd8-b4a3-0ac5d2d1b6f1"
+	for _, name := range []string{"web-1", "web-2", "web-3", "web-4"} {
+		objects = append(objects, newPod(name, map[string]string{"app": "web"}))
+	}
+	r, _ := newTestReconciler(podExecutor, objects...)
+
+	selection := func() []string {
+		shaper := getShaper(t, r)
+		if len(shaper.Status.Selections) != 1 {
+			t.Fatalf("expected a selection, got %v", shaper.Status.Selections)
+		}
+		var shaped []string
+		for _, p := range shaper.Status.Pods {
+			shaped = append(shaped, p.Name)
+		}
+		sort.Strings(shaped)
+		if !reflect.DeepEqual(shaped, shaper.Status.Selections[0].Pods) {
+			t.Errorf("expected the selected pods %v to be shaped, got %v", shaper.Status.Selections[0].Pods, shaped)
+		}
+		return shaper.Status.Selections[0].Pods
+	}
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	first := selection()
+	if len(first) != 2 {
+		t.Fatalf("expected half of the pods to be selected, got %v", first)
+	}
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if again := selection(); !reflect.DeepEqual(first, again) {
+		t.Errorf("expected the selection %v to be kept, got %v", first, again)
+	}
+
+	// only the pod which disappeared is replaced
+	if err := r.client.Delete(r.ctx, newPod(first[0], nil)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	replaced := selection()
+	if len(replaced) != 2 || !containsString(replaced, first[1]) || containsString(replaced, first[0]) {
+		t.Errorf("expected %s to be replaced in %v, got %v", first[0], first, replaced)
+	}
+}
+
+func TestReconcileNode(t *testing.T) {
+	var (
+		podExecutor = newExecutor().On(`^tc qdisc `, fake.Succeeded())
+		config      = v1alpha1.ShaperConfiguration{Type: v1alpha1.Delay, Device: "vxlan.calico", Value: "200ms"}
+		helper      = test.NewRunningPod(testNodeHelperOptions.Namespace, target.NodeHelperName("worker-1"), map[string]string{target.NodeHelperLabel: "worker-1"}, target.NodeHelperContainer)
+	)
+	helper.Spec.HostNetwork = true
+	shaper := newShaper(
+		v1alpha1.ShaperTarget{Kind: v1alpha1.Node, SourceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "a"}}, ShaperConfig: config},
+		v1alpha1.ShaperTarget{Kind: v1alpha1.Node, Name: "worker-3", ShaperConfig: config},
+	)
+	shaper.Annotations = map[string]string{guard.ConfirmAnnotation: "true"}
+	r, recorder := newTestReconciler(podExecutor,
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Labels: map[string]string{"pool": "a"}}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-2", Labels: map[string]string{"pool": "b"}}},
+		helper,
+		shaper,
+	)
+
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+
+	expected := []string{"node-helpers/networkmachinery-node-helper-worker-1: tc qdisc replace dev vxlan.calico root netem delay 200ms"}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+	if pods := getShaper(t, r).Status.Pods; len(pods) != 1 || pods[0].Node != "worker-1" {
+		t.Errorf("expected the helper of worker-1 to be recorded, got %v", pods)
+	}
+	// the missing node is skipped
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected an event for the missing node, got %d events", len(recorder.Events))
+	}
+}
+
+func TestReconcileKillSwitch(t *testing.T) {
+	var (
+		podExecutor = newShapedExecutor()
+		killSwitch  = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kill-switch"}}
+		shaper      = newShaper(v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "pod", ShaperConfig: delay})
+	)
+	shaper.Status.Phase = v1alpha1.ShaperPhaseActive
+	shaper.Status.Pods = []v1alpha1.ShapedPod{{Namespace: "default", Name: "pod", Device: "eth0", Qdisc: "netem delay 200ms"}}
+	r, _ := newTestReconciler(podExecutor, newPod("pod", nil), shaper, killSwitch)
+
+	result, err := r.Reconcile(request)
+	if err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	expected := []string{"default/pod: tc qdisc del dev eth0 root"}
+	if actual := executedIn(podExecutor); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected commands %v, got %v", expected, actual)
+	}
+	if status := getShaper(t, r).Status; status.Phase != v1alpha1.ShaperPhaseBlocked || len(status.BlockedReason) == 0 || len(status.Pods) > 0 {
+		t.Errorf("expected a blocked NetworkTrafficShaper without shaped pods, got %+v", status)
+	}
+	if result.RequeueAfter != blockedRequeueAfter {
+		t.Errorf("expected a requeue after %s, got %s", blockedRequeueAfter, result.RequeueAfter)
+	}
+
+	// the traffic is shaped again once the kill switch is released
+	if err := r.client.Delete(r.ctx, killSwitch); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(request); err != nil {
+		t.Fatalf("reconcile failed: %v", err)
+	}
+	if status := getShaper(t, r).Status; status.Phase != v1alpha1.ShaperPhaseActive || len(status.BlockedReason) > 0 || len(status.Pods) != 1 {
+		t.Errorf("expected an active NetworkTrafficShaper with a shaped pod, got %+v", status)
+	}
+}
+
+func TestReconcileGuards(t *testing.T) {
+	web := v1alpha1.ShaperTarget{Kind: v1alpha1.Selector, Namespace: "default", SourceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}, ShaperConfig: delay}
+	other := &v1alpha1.NetworkTrafficShaper{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", UID: "other"},
+		Status: v1alpha1.NetworkTrafficShaperStatus{Pods: []v1alpha1.ShapedPod{
+			{Namespace: "default", Name: "db", Device: "eth0"},
+			{Namespace: "default", Name: "api", Device: "eth0"},
+		}},
+	}
+	protected := v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "kube-system", Name: "coredns", ShaperConfig: delay}
+	unconfirmed := v1alpha1.ShaperTarget{Kind: v1alpha1.Selector, Namespace: "default", SourceSelector: &metav1.LabelSelector{}, ShaperConfig: delay}
+
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		blocked bool
+	}{
+		{name: "within limits", objects: []runtime.Object{newShaper(web), newPod("web-1", map[string]string{"app": "web"}), newPod("web-2", map[string]string{"app": "web"})}},
+		{name: "shaper limit", blocked: true, objects: []runtime.Object{newShaper(web),
+			newPod("web-1", map[string]string{"app": "web"}), newPod("web-2", map[string]string{"app": "web"}), newPod("web-3", map[string]string{"app": "web"})}},
+		{name: "cluster limit", blocked: true, objects: []runtime.Object{newShaper(web), other,
+			newPod("web-1", map[string]string{"app": "web"}), newPod("web-2", map[string]string{"app": "web"})}},
+		{name: "protected namespace", blocked: true, objects: []runtime.Object{newShaper(protected), test.NewRunningPod("kube-system", "coredns", nil)}},
+		{name: "unconfirmed target", blocked: true, objects: []runtime.Object{newShaper(unconfirmed), newPod("web-1", nil)}},
+		{name: "confirmed target", objects: []runtime.Object{func() runtime.Object {
+			shaper := newShaper(unconfirmed)
+			shaper.Annotations = map[string]string{guard.ConfirmAnnotation: "true"}
+			return shaper
+		}(), newPod("web-1", nil)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podExecutor := newExecutor().On(`^tc qdisc `, fake.Succeeded())
+			r, recorder := newTestReconciler(podExecutor, tt.objects...)
+			r.guardOptions.MaxPodsPerShaper, r.guardOptions.MaxPodsInCluster = 2, 3
+
+			if _, err := r.Reconcile(request); err != nil {
+				t.Fatalf("reconcile failed: %v", err)
+			}
+			status := getShaper(t, r).Status
+			if blocked := status.Phase == v1alpha1.ShaperPhaseBlocked; blocked != tt.blocked {
+				t.Fatalf("expected blocked to be %t, got %+v", tt.blocked, status)
+			}
+			if !tt.blocked {
+				return
+			}
+			// blocked NetworkTrafficShapers do not shape any pod
+			if actual := executedIn(podExecutor); len(actual) > 0 {
+				t.Errorf("expected no commands, got %v", actual)
+			}
+			var blockedEvents int
+			for len(recorder.Events) > 0 {
+				if event := <-recorder.Events; strings.Contains(event, v1alpha1.EventTypeBlocked) {
+					blockedEvents++
+				}
+			}
+			if blockedEvents != 1 {
+				t.Errorf("expected a blocked event, got %d", blockedEvents)
+			}
+		})
+	}
+}
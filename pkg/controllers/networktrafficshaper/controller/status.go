@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/apimachinery"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// operationType returns the type of the operation on <networkTrafficShaper>, it is a Create until the first
+// reconciliation succeeded.
+func operationType(networkTrafficShaper *v1alpha1.NetworkTrafficShaper) v1alpha1.LastOperationType {
+	if networkTrafficShaper.DeletionTimestamp != nil {
+		return v1alpha1.LastOperationTypeDelete
+	}
+	last := networkTrafficShaper.Status.LastOperation
+	if last == nil || (last.Type == v1alpha1.LastOperationTypeCreate && last.State != v1alpha1.LastOperationStateSucceeded) {
+		return v1alpha1.LastOperationTypeCreate
+	}
+	return v1alpha1.LastOperationTypeReconcile
+}
+
+// setLastOperation records the last operation in the status of <networkTrafficShaper> and sets or clears its last
+// error. The update time only changes with the type or state of the operation, periodic reconciliations which
+// end the same way do not change the status.
+func setLastOperation(networkTrafficShaper *v1alpha1.NetworkTrafficShaper, state v1alpha1.LastOperationState, progress int, description string, now time.Time) {
+	var (
+		operation  = operationType(networkTrafficShaper)
+		last       = networkTrafficShaper.Status.LastOperation
+		updateTime = *timeRef(now)
+	)
+	if last != nil && last.Type == operation && last.State == state {
+		updateTime = last.LastUpdateTime
+	}
+	networkTrafficShaper.Status.LastOperation = &v1alpha1.LastOperation{
+		Type:           operation,
+		State:          state,
+		Progress:       progress,
+		Description:    description,
+		LastUpdateTime: updateTime,
+	}
+
+	switch state {
+	case v1alpha1.LastOperationStateError:
+		networkTrafficShaper.Status.LastError = v1alpha1.LastError{Description: description}
+	case v1alpha1.LastOperationStateSucceeded:
+		networkTrafficShaper.Status.LastError = v1alpha1.LastError{}
+	}
+}
+
+// failed records <err> as the last error of the operation on <networkTrafficShaper>, which failed after <progress>
+// percent, together with the outcome of its <targets> if they are given.
+func (r *ReconcileNetworkTrafficShaper) failed(ctx context.Context, networkTrafficShaper *v1alpha1.NetworkTrafficShaper, progress int, targets []v1alpha1.ShaperTargetStatus, err error) (reconcile.Result, error) {
+	if updateErr := apimachinery.TryUpdateStatus(ctx, retry.DefaultBackoff, r.client, networkTrafficShaper, func() error {
+		setLastOperation(networkTrafficShaper, v1alpha1.LastOperationStateError, progress, err.Error(), r.clock.Now())
+		if targets != nil {
+			networkTrafficShaper.Status.Targets = targets
+		}
+		return nil
+	}); updateErr != nil {
+		r.logger.Error(updateErr, "Could not record the failed operation", LogKey, networkTrafficShaper.Name)
+	}
+	return apimachinery.ReconcileErr(err)
+}
+
+// failedShaping records <err> like failed if the shaping of a pod failed. The <shapedPods> are recorded in the
+// status together with the pods shaped by previous reconciliations, all of them are undone once the
+// NetworkTrafficShaper is no longer active.
+func (r *ReconcileNetworkTrafficShaper) failedShaping(ctx context.Context, networkTrafficShaper *v1alpha1.NetworkTrafficShaper, progress int, targets []v1alpha1.ShaperTargetStatus, shapedPods []v1alpha1.ShapedPod, err error) (reconcile.Result, error) {
+	for _, previous := range networkTrafficShaper.Status.Pods {
+		if !containsShapedPod(shapedPods, previous) {
+			shapedPods = append(shapedPods, previous)
+		}
+	}
+	if updateErr := apimachinery.TryUpdateStatus(ctx, retry.DefaultBackoff, r.client, networkTrafficShaper, func() error {
+		setLastOperation(networkTrafficShaper, v1alpha1.LastOperationStateError, progress, err.Error(), r.clock.Now())
+		networkTrafficShaper.Status.Pods = shapedPods
+		networkTrafficShaper.Status.Targets = targets
+		return nil
+	}); updateErr != nil {
+		r.logger.Error(updateErr, "Could not record the failed operation", LogKey, networkTrafficShaper.Name)
+	}
+	return apimachinery.ReconcileErr(err)
+}
@@ -3,61 +3,93 @@ package controller
 import (
 	"bytes"
 	"context"
-	"fmt"
-
-	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
-
-	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/apimachinery"
 
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
-
-	"github.com/networkmachinery/networkmachinery-operators/pkg/utils"
-	"k8s.io/client-go/rest"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/target"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/tc"
 )
 
-func shapeTraffic(ctx context.Context, config *rest.Config, namespace, name, device, value, shapeType string) error {
-	command := fmt.Sprintf("tc qdisc add dev %s root netem %s %s", device, shapeType, value)
-	return shape(ctx, config, namespace, name, command)
-}
-func undoShape(ctx context.Context, config *rest.Config, namespace, name, device string) error {
-	command := fmt.Sprintf("tc qdisc del dev %s root", device)
-	return shape(ctx, config, namespace, name, command)
+// shapeTraffic makes sure <device> is shaped like <shaping>. The qdiscs are only replaced if the current ones
+// differ or <force> is set, the returned bool is true if they were replaced. The returned state are the qdiscs
+// of the shaped device after shaping, it is nil if they can not be shown.
+func shapeTraffic(ctx context.Context, podExecutor executor.PodExecutor, shapeTarget *target.Target, device string, shaping *tc.Shaping, force bool) (bool, *tc.State, *executor.ExecResult, error) {
+	if !force {
+		current, err := shapingState(ctx, podExecutor, shapeTarget, device, shaping)
+		if err != nil {
+			return false, nil, nil, err
+		}
+		if shaping.Matches(current) {
+			return false, current, nil, nil
+		}
+	}
+
+	if _, result, err := shape(ctx, podExecutor, shapeTarget, shaping.ReplaceCommand(device)); err != nil {
+		return false, nil, result, err
+	}
+	if shaping.Bandwidth == nil {
+		return true, nil, nil, nil
+	}
+	// the kernel rounds the bandwidth limit, the achieved one is reported
+	current, err := shapingState(ctx, podExecutor, shapeTarget, device, shaping)
+	return true, current, nil, err
 }
 
-func shape(ctx context.Context, config *rest.Config, namespace, name, command string) error {
-	var stdOut, stdErr bytes.Buffer
-	execOpts := executor.PodExecOptions{
-		Namespace: namespace,
-		Name:      name,
-		Command:   command,
-		Container: "", //TODO Fixme, get the right container value
-		StandardCmdOpts: executor.StandardCmdOpts{
-			StdErr: &stdErr,
-			StdOut: &stdOut,
-		},
-	}
-
-	useEphemeralContainers, err := utils.ShouldUseEphemeralContainers(config)
+// undoShape removes the shaping from <device>, devices shaped differently are left untouched.
+func undoShape(ctx context.Context, podExecutor executor.PodExecutor, shapeTarget *target.Target, device string, shaping *tc.Shaping) (*executor.ExecResult, error) {
+	current, err := shapingState(ctx, podExecutor, shapeTarget, device, shaping)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if !shaping.Shaped(current) {
+		return nil, nil
 	}
 
-	if useEphemeralContainers {
-		debugContainerName := "net-debug"
-		if err := apimachinery.CreateOrUpdateEphemeralContainer(config, namespace, name, debugContainerName); err != nil {
-			return err
+	_, result, err := shape(ctx, podExecutor, shapeTarget, shaping.DeleteCommand(device))
+	return result, err
+}
+
+// undoRecordedShape removes a shaping whose configuration is no longer known from <device>. Only the IFB device of
+// <shaping> is known, devices whose root qdisc is not installed by any shaping are left untouched.
+func undoRecordedShape(ctx context.Context, podExecutor executor.PodExecutor, shapeTarget *target.Target, device string, shaping *tc.Shaping) (*executor.ExecResult, error) {
+	if !shaping.Ingress {
+		current, err := shapingState(ctx, podExecutor, shapeTarget, device, shaping)
+		if err != nil {
+			return nil, err
+		}
+		if !tc.Installed(current) {
+			return nil, nil
 		}
-		execOpts.Container = debugContainerName
+	}
+
+	_, result, err := shape(ctx, podExecutor, shapeTarget, shaping.DeleteCommand(device))
+	return result, err
+}
 
-		if err := apimachinery.EphemeralContainerInStatus(ctx, config, &v1alpha1.NetworkSourceEndpoint{Namespace: namespace, Name: name}); err != nil {
-			return err
+// shapingState returns the qdiscs of the device shaped by <shaping>. It returns nil if the qdiscs can not be
+// shown, e.g. because tc does not support JSON output, in which case the qdiscs are always replaced.
+func shapingState(ctx context.Context, podExecutor executor.PodExecutor, shapeTarget *target.Target, device string, shaping *tc.Shaping) (*tc.State, error) {
+	stdout, _, err := shape(ctx, podExecutor, shapeTarget, shaping.ShowCommand(device))
+	if err != nil {
+		if executor.IsExecError(err) {
+			return nil, nil
 		}
+		return nil, err
 	}
 
-	// TODO: handle error if tc config already Exists
-	if err = utils.PodExec(ctx, config, execOpts); err != nil {
-		return err
+	state, err := tc.ParseState(stdout)
+	if err != nil {
+		return nil, nil
 	}
+	return state, nil
+}
+
+// shape executes the tc <command> in the target, it returns the complete standard output of the command.
+func shape(ctx context.Context, podExecutor executor.PodExecutor, shapeTarget *target.Target, command string) (string, *executor.ExecResult, error) {
+	var stdOut, stdErr bytes.Buffer
+	execOpts := shapeTarget.ExecOptions(command)
+	execOpts.StdOut = &stdOut
+	execOpts.StdErr = &stdErr
 
-	return nil
+	result, err := podExecutor.Execute(ctx, execOpts)
+	return stdOut.String(), result, err
 }
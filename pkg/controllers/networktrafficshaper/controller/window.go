@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/schedule"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// window is the shaping window of a NetworkTrafficShaper at a point in time.
+type window struct {
+	phase      v1alpha1.ShaperPhase
+	start, end *metav1.Time
+	next       *metav1.Time
+	// reason is the safety guard which prevents the shaping in the Blocked phase.
+	reason string
+}
+
+// shapingWindow returns the shaping window of <networkTrafficShaper> at <now>. Windows of a Duration without
+// Schedule start at the StartTime recorded in the status, or at <now> if the traffic has not been shaped yet.
+func shapingWindow(networkTrafficShaper *v1alpha1.NetworkTrafficShaper, now time.Time) (*window, error) {
+	var (
+		spec   = networkTrafficShaper.Spec
+		status = networkTrafficShaper.Status
+		w      = &window{phase: v1alpha1.ShaperPhaseActive, start: status.StartTime}
+		paused = spec.PauseUntil != nil && now.Before(spec.PauseUntil.Time)
+	)
+	if spec.Duration != nil && spec.Duration.Duration <= 0 {
+		return nil, fmt.Errorf("duration %s is not positive", spec.Duration.Duration)
+	}
+
+	switch {
+	case len(spec.Schedule) > 0:
+		if spec.Duration == nil {
+			return nil, fmt.Errorf("schedule %q requires a duration", spec.Schedule)
+		}
+		cron, err := schedule.Parse(spec.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		if next := cron.Next(now); !next.IsZero() {
+			w.next = timeRef(next)
+		}
+		start, ok := cron.Last(now, spec.Duration.Duration)
+		if !ok {
+			// the times of the last window are kept
+			w.phase, w.end = v1alpha1.ShaperPhaseWaiting, status.EndTime
+			break
+		}
+		w.start, w.end = timeRef(start), timeRef(start.Add(spec.Duration.Duration))
+	case spec.Duration != nil:
+		if w.start == nil {
+			if paused {
+				w.phase = v1alpha1.ShaperPhasePaused
+				break
+			}
+			w.start = timeRef(now)
+		}
+		w.end = timeRef(w.start.Add(spec.Duration.Duration))
+		if !now.Before(w.end.Time) {
+			w.phase = v1alpha1.ShaperPhaseCompleted
+		}
+	}
+
+	if w.phase == v1alpha1.ShaperPhaseActive {
+		if paused {
+			w.phase = v1alpha1.ShaperPhasePaused
+		} else if w.start == nil {
+			w.start = timeRef(now)
+		}
+	}
+	return w, nil
+}
+
+// requeueAfter returns the time until the phase of the window changes, zero if it never changes.
+func (w *window) requeueAfter(networkTrafficShaper *v1alpha1.NetworkTrafficShaper, now time.Time) time.Duration {
+	if w.phase == v1alpha1.ShaperPhaseCompleted {
+		return 0
+	}
+	var after time.Duration
+	for _, boundary := range []*metav1.Time{w.end, w.next, networkTrafficShaper.Spec.PauseUntil} {
+		if boundary == nil || !boundary.After(now) {
+			continue
+		}
+		if until := boundary.Sub(now); after == 0 || until < after {
+			after = until
+		}
+	}
+	return after
+}
+
+// setWindow records the window in the status of <networkTrafficShaper>.
+func setWindow(networkTrafficShaper *v1alpha1.NetworkTrafficShaper, w *window) {
+	networkTrafficShaper.Status.Phase = w.phase
+	networkTrafficShaper.Status.BlockedReason = w.reason
+	networkTrafficShaper.Status.StartTime = w.start
+	networkTrafficShaper.Status.EndTime = w.end
+	networkTrafficShaper.Status.NextStartTime = w.next
+}
+
+// timeRef returns a reference to <t> as it is read from the API server, i.e. truncated to seconds in local time.
+// Unchanged windows are not updated then.
+func timeRef(t time.Time) *metav1.Time {
+	truncated := metav1.NewTime(t.Local().Truncate(time.Second))
+	return &truncated
+}
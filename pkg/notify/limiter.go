@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"sync"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"golang.org/x/time/rate"
+)
+
+// Limiters enforce the rate limits of the receivers of routes.
+type Limiters struct {
+	mu       sync.Mutex
+	limiters map[string]*limiter
+}
+
+type limiter struct {
+	*rate.Limiter
+	limit v1alpha1.NotificationRateLimit
+}
+
+// NewLimiters returns limiters without any deliveries.
+func NewLimiters() *Limiters {
+	return &Limiters{limiters: map[string]*limiter{}}
+}
+
+// Reserve reserves a delivery to <receiver> of <route> at <now>. If the rate limit of the route is exceeded nothing is
+// reserved and the time until a delivery is allowed is returned.
+func (l *Limiters) Reserve(route *v1alpha1.NetworkNotificationRoute, receiver string, now time.Time) time.Duration {
+	limit := route.Spec.RateLimit
+	if limit == nil || limit.Deliveries <= 0 || limit.Period.Duration <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := string(route.UID) + "/" + receiver
+	lim, ok := l.limiters[key]
+	if !ok || lim.limit != *limit {
+		lim = &limiter{
+			Limiter: rate.NewLimiter(rate.Every(limit.Period.Duration/time.Duration(limit.Deliveries)), int(limit.Deliveries)),
+			limit:   *limit,
+		}
+		l.limiters[key] = lim
+	}
+
+	reservation := lim.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return delay
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// DefaultTemplate is the message of routes without a template.
+const DefaultTemplate = `{{ if eq .Spec.State "Resolved" }}[resolved] {{ end }}[{{ .Spec.Severity }}] NetworkMonitor {{ monitor . }}: threshold {{ .Spec.Event.Event.ThresholdID }} ` +
+	`was exceeded{{ with .Spec.Event.Event.FlowKey }} by {{ . }}{{ end }}` +
+	`{{ with .Spec.Event.Source }} from {{ endpoint . }}{{ end }}{{ with .Spec.Event.Destination }} to {{ endpoint . }}{{ end }} ` +
+	`with {{ .Spec.Event.Event.Value }} at agent {{ .Spec.Event.Event.Agent }} ({{ .Spec.Occurrences }} occurrences)`
+
+var funcs = template.FuncMap{"monitor": NetworkMonitor, "endpoint": Endpoint}
+
+// Message returns the message of <notification> rendered by the Go template <text>, the DefaultTemplate is used if it
+// is empty.
+func Message(text string, notification *v1alpha1.NetworkNotification) (string, error) {
+	if len(text) == 0 {
+		text = DefaultTemplate
+	}
+	tmpl, err := template.New("message").Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var message bytes.Buffer
+	if err := tmpl.Execute(&message, notification); err != nil {
+		return "", err
+	}
+	return message.String(), nil
+}
+
+// Endpoint returns a short description of <endpoint>, e.g. "pod payments/api-1 (Deployment/api)".
+func Endpoint(endpoint *v1alpha1.Endpoint) string {
+	description := strings.ToLower(endpoint.Kind) + " " + endpoint.Name
+	if len(endpoint.Namespace) > 0 {
+		description = strings.ToLower(endpoint.Kind) + " " + endpoint.Namespace + "/" + endpoint.Name
+	}
+	if len(endpoint.Workload) > 0 {
+		description += " (" + endpoint.Workload + ")"
+	}
+	return description
+}
+
+// NetworkMonitor returns the name of the NetworkMonitor which created <notification>.
+func NetworkMonitor(notification *v1alpha1.NetworkNotification) string {
+	if owner := metav1.GetControllerOf(notification); owner != nil {
+		return owner.Name
+	}
+	return ""
+}
+
+// Matches returns true if <notification> is selected by the match of <route>.
+func Matches(route *v1alpha1.NetworkNotificationRoute, notification *v1alpha1.NetworkNotification) (bool, error) {
+	match := route.Spec.Match
+	if len(match.NetworkMonitor) > 0 && match.NetworkMonitor != NetworkMonitor(notification) {
+		return false, nil
+	}
+	if len(match.Threshold) > 0 && match.Threshold != notification.Spec.Event.Event.ThresholdID {
+		return false, nil
+	}
+	if len(match.Severity) > 0 && match.Severity != notification.Spec.Severity {
+		return false, nil
+	}
+	if match.Selector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(match.Selector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(notification.Labels)), nil
+}
@@ -0,0 +1,190 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newNotification() *v1alpha1.NetworkNotification {
+	controller := true
+	firstSeen := metav1.NewTime(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+	return &v1alpha1.NetworkNotification{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "default",
+			Name:            "monitor-0123456789",
+			Labels:          map[string]string{"team": "network"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "NetworkMonitor", Name: "monitor", Controller: &controller}},
+		},
+		Spec: v1alpha1.NetworkNotificationSpec{
+			Event:       v1alpha1.NetworkEvent{Event: v1alpha1.Event{ThresholdID: "elephant", Agent: "10.0.0.1", Value: 2000, FlowKey: "10.0.0.1,10.0.0.2"}},
+			Severity:    "critical",
+			Occurrences: 3,
+			FirstSeen:   &firstSeen,
+		},
+	}
+}
+
+func TestMessage(t *testing.T) {
+	message, err := Message("", newNotification())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "[critical] NetworkMonitor monitor: threshold elephant was exceeded by 10.0.0.1,10.0.0.2 with 2000 at agent 10.0.0.1 (3 occurrences)"
+	if message != expected {
+		t.Errorf("expected message %q, got %q", expected, message)
+	}
+
+	enriched := newNotification()
+	enriched.Spec.Event.Source = &v1alpha1.Endpoint{IP: "10.0.0.1", Kind: "Pod", Name: "api-1", Namespace: "payments", Workload: "Deployment/api"}
+	enriched.Spec.Event.Destination = &v1alpha1.Endpoint{IP: "10.0.0.2", Kind: "Node", Name: "worker-1", Node: "worker-1"}
+	expected = "[critical] NetworkMonitor monitor: threshold elephant was exceeded by 10.0.0.1,10.0.0.2 from pod payments/api-1 (Deployment/api) to node worker-1 with 2000 at agent 10.0.0.1 (3 occurrences)"
+	if message, err := Message("", enriched); err != nil || message != expected {
+		t.Errorf("expected message %q, got %q, %v", expected, message, err)
+	}
+
+	resolved := newNotification()
+	resolved.Spec.State = v1alpha1.NotificationStateResolved
+	expected = "[resolved] [critical] NetworkMonitor monitor: threshold elephant was exceeded by 10.0.0.1,10.0.0.2 with 2000 at agent 10.0.0.1 (3 occurrences)"
+	if message, err := Message("", resolved); err != nil || message != expected {
+		t.Errorf("expected message %q, got %q, %v", expected, message, err)
+	}
+
+	if message, err := Message("{{ .Spec.Event.Event.ThresholdID }} on {{ monitor . }}", newNotification()); err != nil || message != "elephant on monitor" {
+		t.Errorf("expected the custom template to be rendered, got %q, %v", message, err)
+	}
+	if _, err := Message("{{ .Spec.Unknown }}", newNotification()); err == nil {
+		t.Error("expected an invalid template to fail")
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		match    v1alpha1.NotificationMatch
+		expected bool
+	}{
+		{"empty", v1alpha1.NotificationMatch{}, true},
+		{"all", v1alpha1.NotificationMatch{NetworkMonitor: "monitor", Threshold: "elephant", Severity: "critical"}, true},
+		{"monitor", v1alpha1.NotificationMatch{NetworkMonitor: "other"}, false},
+		{"threshold", v1alpha1.NotificationMatch{Threshold: "mouse"}, false},
+		{"severity", v1alpha1.NotificationMatch{Severity: "warning"}, false},
+		{"labels", v1alpha1.NotificationMatch{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "network"}}}, true},
+		{"other labels", v1alpha1.NotificationMatch{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "storage"}}}, false},
+	}
+	for _, test := range tests {
+		route := &v1alpha1.NetworkNotificationRoute{Spec: v1alpha1.NetworkNotificationRouteSpec{Match: test.match}}
+		if matches, err := Matches(route, newNotification()); err != nil || matches != test.expected {
+			t.Errorf("%s: expected %t, got %t, %v", test.name, test.expected, matches, err)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	var (
+		paths  []string
+		bodies []string
+		header string
+		status = http.StatusOK
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		paths = append(paths, req.URL.Path)
+		bodies = append(bodies, string(body))
+		header = req.Header.Get("X-Token")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sender := NewSender(&Options{Timeout: 5 * time.Second})
+	for _, receiverType := range []v1alpha1.ReceiverType{v1alpha1.ReceiverTypeWebhook, v1alpha1.ReceiverTypeSlack, v1alpha1.ReceiverTypeAlertmanager} {
+		receiver := v1alpha1.NotificationReceiver{Name: "receiver", Type: receiverType, Headers: map[string]string{"X-Token": "secret"}}
+		if err := sender.Send(context.TODO(), "route", receiver, server.URL+"/hook", newNotification(), "message"); err != nil {
+			t.Errorf("%s: expected the delivery to succeed, got %v", receiverType, err)
+		}
+	}
+	if header != "secret" {
+		t.Errorf("expected the headers of the receiver to be sent, got %q", header)
+	}
+
+	webhook := webhookPayload{}
+	if err := json.Unmarshal([]byte(bodies[0]), &webhook); err != nil || webhook.Message != "message" || webhook.Notification.Name != "monitor-0123456789" {
+		t.Errorf("unexpected webhook payload %s", bodies[0])
+	}
+	if bodies[1] != `{"text":"message"}` {
+		t.Errorf("unexpected Slack payload %s", bodies[1])
+	}
+	var alerts []alert
+	if err := json.Unmarshal([]byte(bodies[2]), &alerts); err != nil || len(alerts) != 1 || paths[2] != "/hook/api/v2/alerts" {
+		t.Fatalf("unexpected Alertmanager request %s %s", paths[2], bodies[2])
+	}
+	if a := alerts[0]; a.Labels["alertname"] != AlertName || a.Labels["networkmonitor"] != "monitor" || a.Labels["severity"] != "critical" ||
+		a.Annotations["flowKey"] != "10.0.0.1,10.0.0.2" || a.StartsAt != "2020-01-01T12:00:00Z" || a.Annotations["summary"] != "message" {
+		t.Errorf("unexpected alert %+v", a)
+	}
+	// the labels identify the alert, they do not change between occurrences
+	for label := range alerts[0].Labels {
+		switch label {
+		case "alertname", "namespace", "networkmonitor", "threshold", "severity":
+		default:
+			t.Errorf("unexpected label %s of alert %+v", label, alerts[0])
+		}
+	}
+
+	resolved := newNotification()
+	resolvedAt := metav1.NewTime(time.Date(2020, 1, 1, 12, 5, 0, 0, time.UTC))
+	resolved.Spec.State, resolved.Spec.ResolvedAt = v1alpha1.NotificationStateResolved, &resolvedAt
+	receiver := v1alpha1.NotificationReceiver{Name: "receiver", Type: v1alpha1.ReceiverTypeAlertmanager}
+	if err := sender.Send(context.TODO(), "route", receiver, server.URL, resolved, "message"); err != nil {
+		t.Fatalf("expected the delivery to succeed, got %v", err)
+	}
+	if err := json.Unmarshal([]byte(bodies[3]), &alerts); err != nil || len(alerts) != 1 || alerts[0].EndsAt != "2020-01-01T12:05:00Z" {
+		t.Errorf("expected the resolved alert to end, got %s", bodies[3])
+	}
+
+	status = http.StatusBadGateway
+	receiver = v1alpha1.NotificationReceiver{Name: "receiver", Type: v1alpha1.ReceiverTypeSlack}
+	if err := sender.Send(context.TODO(), "route", receiver, server.URL, newNotification(), "message"); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("expected the rejected delivery to fail, got %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	options := &Options{RetryBackoff: 10 * time.Second, MaxRetryBackoff: time.Minute}
+	for attempts, expected := range map[int32]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 3: 40 * time.Second, 4: time.Minute, 10: time.Minute} {
+		if backoff := options.Backoff(attempts); backoff != expected {
+			t.Errorf("expected a backoff of %s after %d attempts, got %s", expected, attempts, backoff)
+		}
+	}
+}
+
+func TestLimiters(t *testing.T) {
+	var (
+		now      = time.Now()
+		limiters = NewLimiters()
+		route    = &v1alpha1.NetworkNotificationRoute{
+			ObjectMeta: metav1.ObjectMeta{UID: "route"},
+			Spec:       v1alpha1.NetworkNotificationRouteSpec{RateLimit: &v1alpha1.NotificationRateLimit{Deliveries: 2, Period: metav1.Duration{Duration: time.Minute}}},
+		}
+	)
+	if limiters.Reserve(route, "receiver", now) != 0 || limiters.Reserve(route, "receiver", now) != 0 {
+		t.Error("expected the deliveries within the limit to be allowed")
+	}
+	if delay := limiters.Reserve(route, "receiver", now); delay != 30*time.Second {
+		t.Errorf("expected the delivery above the limit to be delayed by 30s, got %s", delay)
+	}
+	if limiters.Reserve(route, "other", now) != 0 {
+		t.Error("expected receivers to be limited separately")
+	}
+	if limiters.Reserve(route, "receiver", now.Add(30*time.Second)) != 0 {
+		t.Error("expected the delivery to be allowed after the delay")
+	}
+}
@@ -0,0 +1,58 @@
+// Package notify delivers NetworkNotifications to webhooks, Slack-compatible incoming webhooks and Alertmanagers.
+package notify
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Options configure the deliveries of NetworkNotifications.
+type Options struct {
+	// Timeout is the timeout of a single delivery.
+	Timeout time.Duration
+	// RetryBackoff is the time waited before the first retry of a failed delivery, it doubles with every retry up to
+	// MaxRetryBackoff.
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// MaxAttempts is the number of attempts after which a delivery fails until the next occurrence.
+	MaxAttempts int32
+	// AlertResendInterval is the interval firing alerts are sent to Alertmanagers again, it has to be below their
+	// resolve_timeout which resolves alerts that are not sent again.
+	AlertResendInterval time.Duration
+	// Namespace is the namespace the NetworkNotifications of the cluster-scoped NetworkMonitors are created in.
+	Namespace string
+}
+
+// DefaultOptions are the default options of the NetworkNotification controller.
+var DefaultOptions = &Options{
+	Timeout:         10 * time.Second,
+	RetryBackoff:    10 * time.Second,
+	MaxRetryBackoff: 5 * time.Minute,
+	MaxAttempts:     5,
+	// Alertmanager resolves alerts after a resolve_timeout of 5m by default
+	AlertResendInterval: time.Minute,
+	Namespace:           "default",
+}
+
+// AddFlags adds the notification flags to <flags>.
+func (o *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.DurationVar(&o.Timeout, "notification-timeout", o.Timeout, "timeout of a delivery of a NetworkNotification")
+	flags.DurationVar(&o.RetryBackoff, "notification-retry-backoff", o.RetryBackoff, "time to wait before the first retry of a failed delivery, it doubles with every retry")
+	flags.DurationVar(&o.MaxRetryBackoff, "notification-max-retry-backoff", o.MaxRetryBackoff, "maximum time to wait between retries of a failed delivery")
+	flags.Int32Var(&o.MaxAttempts, "notification-max-attempts", o.MaxAttempts, "number of attempts to deliver a NetworkNotification before the delivery fails until the next occurrence")
+	flags.StringVar(&o.Namespace, "notification-namespace", o.Namespace, "namespace the NetworkNotifications of NetworkMonitors are created in")
+	flags.DurationVar(&o.AlertResendInterval, "notification-alert-resend-interval", o.AlertResendInterval, "interval firing NetworkNotifications are sent to Alertmanagers again, it has to be below their resolve_timeout, 0 disables it")
+}
+
+// Backoff returns the time to wait before the next attempt after <attempts> failed attempts.
+func (o *Options) Backoff(attempts int32) time.Duration {
+	backoff := o.RetryBackoff
+	for i := int32(1); i < attempts && backoff < o.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if o.MaxRetryBackoff > 0 && backoff > o.MaxRetryBackoff {
+		return o.MaxRetryBackoff
+	}
+	return backoff
+}
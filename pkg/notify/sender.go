@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"gopkg.in/resty.v1"
+)
+
+// AlertName is the name of the alerts sent to Alertmanagers.
+const AlertName = "NetworkThresholdExceeded"
+
+// Sender delivers NetworkNotifications to receivers.
+type Sender struct {
+	http *resty.Client
+}
+
+// NewSender returns a sender configured by <options>.
+func NewSender(options *Options) *Sender {
+	return &Sender{http: resty.New().SetTimeout(options.Timeout)}
+}
+
+// webhookPayload is posted to generic webhooks.
+type webhookPayload struct {
+	Route        string                        `json:"route"`
+	Receiver     string                        `json:"receiver"`
+	Message      string                        `json:"message"`
+	Notification *v1alpha1.NetworkNotification `json:"notification"`
+}
+
+// slackPayload is posted to Slack-compatible incoming webhooks.
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+// alert is an alert of the Alertmanager v2 API.
+type alert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	StartsAt    string            `json:"startsAt,omitempty"`
+	EndsAt      string            `json:"endsAt,omitempty"`
+}
+
+// Send delivers <notification> with <message> to <receiver> of <route> at <url>.
+func (s *Sender) Send(ctx context.Context, route string, receiver v1alpha1.NotificationReceiver, url string, notification *v1alpha1.NetworkNotification, message string) error {
+	var body interface{}
+	switch receiver.Type {
+	case v1alpha1.ReceiverTypeWebhook:
+		body = webhookPayload{Route: route, Receiver: receiver.Name, Message: message, Notification: notification}
+	case v1alpha1.ReceiverTypeSlack:
+		body = slackPayload{Text: message}
+	case v1alpha1.ReceiverTypeAlertmanager:
+		url = strings.TrimSuffix(url, "/") + "/api/v2/alerts"
+		body = []alert{newAlert(notification, message)}
+	default:
+		return fmt.Errorf("unknown receiver type %q", receiver.Type)
+	}
+
+	response, err := s.http.R().
+		SetContext(ctx).
+		SetHeaders(receiver.Headers).
+		SetHeader("Content-Type", "application/json").
+		SetBody(body).
+		Post(url)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode() < 200 || response.StatusCode() >= 300 {
+		return fmt.Errorf("%s receiver %s responded with status %d: %s", receiver.Type, receiver.Name, response.StatusCode(), strings.TrimSpace(string(response.Body())))
+	}
+	return nil
+}
+
+// newAlert returns the alert of <notification>. Alertmanager identifies alerts by their labels, they are therefore
+// limited to the NetworkMonitor, threshold and severity. The agent, flow key and endpoints of the event change between
+// occurrences and are sent as annotations.
+func newAlert(notification *v1alpha1.NetworkNotification, message string) alert {
+	event := notification.Spec.Event.Event
+	a := alert{
+		Labels: map[string]string{
+			"alertname":      AlertName,
+			"namespace":      notification.Namespace,
+			"networkmonitor": NetworkMonitor(notification),
+			"threshold":      event.ThresholdID,
+			"severity":       notification.Spec.Severity,
+		},
+		Annotations: map[string]string{
+			"summary": message,
+			"value":   fmt.Sprintf("%v", event.Value),
+			"agent":   event.Agent,
+		},
+	}
+	if len(event.FlowKey) > 0 {
+		a.Annotations["flowKey"] = event.FlowKey
+	}
+	for prefix, endpoint := range map[string]*v1alpha1.Endpoint{"source": notification.Spec.Event.Source, "destination": notification.Spec.Event.Destination} {
+		if endpoint == nil {
+			continue
+		}
+		a.Annotations[prefix+"Kind"] = endpoint.Kind
+		a.Annotations[prefix+"Name"] = endpoint.Name
+		if len(endpoint.Namespace) > 0 {
+			a.Annotations[prefix+"Namespace"] = endpoint.Namespace
+		}
+		if len(endpoint.Workload) > 0 {
+			a.Annotations[prefix+"Workload"] = endpoint.Workload
+		}
+		if len(endpoint.Node) > 0 {
+			a.Annotations[prefix+"Node"] = endpoint.Node
+		}
+	}
+	if notification.Spec.FirstSeen != nil {
+		a.StartsAt = notification.Spec.FirstSeen.UTC().Format(time.RFC3339)
+	}
+	// Alertmanager resolves alerts which ended
+	if notification.Spec.State == v1alpha1.NotificationStateResolved && notification.Spec.ResolvedAt != nil {
+		a.EndsAt = notification.Spec.ResolvedAt.UTC().Format(time.RFC3339)
+	}
+	return a
+}
@@ -0,0 +1,357 @@
+// Package sflowrt is a client of the REST API of sFlow-RT.
+package sflowrt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"gopkg.in/resty.v1"
+)
+
+// Client talks to the REST API of a sFlow-RT instance.
+type Client struct {
+	baseURL   string
+	http      *resty.Client
+	timeout   time.Duration
+	retries   int
+	retryWait time.Duration
+}
+
+// New returns a client of the sFlow-RT REST API at <baseURL>, e.g. "http://10.0.0.1:8008". The credentials and
+// headers of <options> are sent with every request.
+func New(baseURL string, options *Options) (*Client, error) {
+	credentials, err := options.credentials()
+	if err != nil {
+		return nil, err
+	}
+	return newClient(baseURL, options, credentials)
+}
+
+func newClient(baseURL string, options *Options, credentials *credentials) (*Client, error) {
+	httpClient := resty.New()
+	if strings.HasPrefix(baseURL, "https://") {
+		config, err := options.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		httpClient.SetTLSClientConfig(config)
+	}
+
+	if credentials != nil {
+		httpClient.SetHeaders(options.Headers)
+		if len(credentials.token) > 0 {
+			httpClient.SetAuthToken(credentials.token)
+		}
+		if len(options.Username) > 0 {
+			httpClient.SetBasicAuth(options.Username, credentials.password)
+		}
+	}
+
+	return &Client{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		http:      httpClient,
+		timeout:   options.Timeout,
+		retries:   options.Retries,
+		retryWait: options.RetryWait,
+	}, nil
+}
+
+// Clients caches the clients of sFlow-RT endpoints, a client is created once per endpoint and reused. The
+// credentials and headers of the options are only sent to the endpoints they are configured for, other endpoints
+// are reached without them.
+type Clients struct {
+	options *Options
+
+	mu      sync.Mutex
+	clients map[string]*cachedClient
+}
+
+type cachedClient struct {
+	client      *Client
+	credentials *credentials
+}
+
+// NewClients returns Clients of the endpoints configured by <options>.
+func NewClients(options *Options) *Clients {
+	return &Clients{
+		options: options,
+		clients: map[string]*cachedClient{},
+	}
+}
+
+// ForEndpoint returns the client of the sFlow-RT instance at <endpoint>. The credentials are read again on every
+// call, the client is replaced if they changed.
+func (c *Clients) ForEndpoint(endpoint v1alpha1.MonitoringEndpoint) (*Client, error) {
+	baseURL := EndpointURL(endpoint)
+	var credentials *credentials
+	if c.options.sendsCredentials(baseURL) {
+		var err error
+		if credentials, err = c.options.credentials(); err != nil {
+			return nil, err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.clients[baseURL]; ok && reflect.DeepEqual(cached.credentials, credentials) {
+		return cached.client, nil
+	}
+	client, err := newClient(baseURL, c.options, credentials)
+	if err != nil {
+		return nil, err
+	}
+	c.clients[baseURL] = &cachedClient{client: client, credentials: credentials}
+	return client, nil
+}
+
+// EndpointURL returns the base URL of <endpoint>.
+func EndpointURL(endpoint v1alpha1.MonitoringEndpoint) string {
+	scheme := endpoint.Scheme
+	if len(scheme) == 0 {
+		scheme = "http"
+	}
+	return scheme + "://" + net.JoinHostPort(endpoint.IP, endpoint.Port)
+}
+
+// Flows returns the flows defined in sFlow-RT by their names.
+func (c *Client) Flows(ctx context.Context) (map[string]v1alpha1.Flow, error) {
+	definitions := map[string]json.RawMessage{}
+	if err := c.get(ctx, "/flow/json", nil, &definitions); err != nil {
+		return nil, err
+	}
+	flows := make(map[string]v1alpha1.Flow, len(definitions))
+	for name, definition := range definitions {
+		flow := v1alpha1.Flow{}
+		if err := UnmarshalDefinition(definition, &flow); err != nil {
+			return nil, fmt.Errorf("could not decode flow %s: %v", name, err)
+		}
+		flow.Name = name
+		flows[name] = flow
+	}
+	return flows, nil
+}
+
+// Flow returns the flow <name>, a not found error is returned if it is not defined.
+func (c *Client) Flow(ctx context.Context, name string) (*v1alpha1.Flow, error) {
+	var definition json.RawMessage
+	if err := c.get(ctx, path("flow", name, "json"), nil, &definition); err != nil {
+		return nil, err
+	}
+	flow := &v1alpha1.Flow{}
+	if err := UnmarshalDefinition(definition, flow); err != nil {
+		return nil, fmt.Errorf("could not decode flow %s: %v", name, err)
+	}
+	flow.Name = name
+	return flow, nil
+}
+
+// PutFlow defines <flow> or replaces its definition.
+func (c *Client) PutFlow(ctx context.Context, flow v1alpha1.Flow) error {
+	return c.do(ctx, http.MethodPut, path("flow", flow.Name, "json"), nil, flow, nil, 0)
+}
+
+// DeleteFlow deletes the flow <name>, flows which do not exist are ignored.
+func (c *Client) DeleteFlow(ctx context.Context, name string) error {
+	return IgnoreNotFound(c.do(ctx, http.MethodDelete, path("flow", name, "json"), nil, nil, nil, 0))
+}
+
+// ActiveFlows returns the largest <maxFlows> values of the flow <name> reported by <agent>, "ALL" for all agents.
+func (c *Client) ActiveFlows(ctx context.Context, agent, name string, maxFlows int) ([]ActiveFlow, error) {
+	var query map[string]string
+	if maxFlows > 0 {
+		query = map[string]string{"maxFlows": strconv.Itoa(maxFlows)}
+	}
+	var flows []ActiveFlow
+	if err := c.get(ctx, path("activeflows", agent, name, "json"), query, &flows); err != nil {
+		return nil, err
+	}
+	return flows, nil
+}
+
+// Thresholds returns the thresholds defined in sFlow-RT by their names.
+func (c *Client) Thresholds(ctx context.Context) (map[string]v1alpha1.Threshold, error) {
+	definitions := map[string]json.RawMessage{}
+	if err := c.get(ctx, "/threshold/json", nil, &definitions); err != nil {
+		return nil, err
+	}
+	thresholds := make(map[string]v1alpha1.Threshold, len(definitions))
+	for name, definition := range definitions {
+		threshold := v1alpha1.Threshold{}
+		if err := UnmarshalDefinition(definition, &threshold); err != nil {
+			return nil, fmt.Errorf("could not decode threshold %s: %v", name, err)
+		}
+		threshold.Name = name
+		thresholds[name] = threshold
+	}
+	return thresholds, nil
+}
+
+// Threshold returns the threshold <name>, a not found error is returned if it is not defined.
+func (c *Client) Threshold(ctx context.Context, name string) (*v1alpha1.Threshold, error) {
+	var definition json.RawMessage
+	if err := c.get(ctx, path("threshold", name, "json"), nil, &definition); err != nil {
+		return nil, err
+	}
+	threshold := &v1alpha1.Threshold{}
+	if err := UnmarshalDefinition(definition, threshold); err != nil {
+		return nil, fmt.Errorf("could not decode threshold %s: %v", name, err)
+	}
+	threshold.Name = name
+	return threshold, nil
+}
+
+// PutThreshold defines <threshold> or replaces its definition.
+func (c *Client) PutThreshold(ctx context.Context, threshold v1alpha1.Threshold) error {
+	var query map[string]string
+	if len(threshold.ByFlow) > 0 {
+		query = map[string]string{"byFlow": "true"}
+	}
+	return c.do(ctx, http.MethodPut, path("threshold", threshold.Name, "json"), query, threshold, nil, 0)
+}
+
+// DeleteThreshold deletes the threshold <name>, thresholds which do not exist are ignored.
+func (c *Client) DeleteThreshold(ctx context.Context, name string) error {
+	return IgnoreNotFound(c.do(ctx, http.MethodDelete, path("threshold", name, "json"), nil, nil, nil, 0))
+}
+
+// Events returns the threshold events selected by <query>, the most recent first. The request waits up to the
+// timeout of the query for new events if there are none.
+func (c *Client) Events(ctx context.Context, query EventsQuery) ([]v1alpha1.Event, error) {
+	params := map[string]string{}
+	if query.EventID > 0 {
+		params["eventID"] = strconv.Itoa(int(query.EventID))
+	}
+	if query.MaxEvents > 0 {
+		params["maxEvents"] = strconv.Itoa(query.MaxEvents)
+	}
+	if query.Timeout > 0 {
+		params["timeout"] = strconv.Itoa(int(query.Timeout.Seconds()))
+	}
+	var events []v1alpha1.Event
+	if err := c.do(ctx, http.MethodGet, "/events/json", params, nil, &events, query.Timeout); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Metrics returns the values of <metric> reported by <agent>, "ALL" for all agents. The metric may be a comma
+// separated list and use aggregations, e.g. "max:ifinutilization".
+func (c *Client) Metrics(ctx context.Context, agent, metric string) ([]Metric, error) {
+	var metrics []Metric
+	if err := c.get(ctx, path("metric", agent, metric, "json"), nil, &metrics); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// Topology returns the topology known to sFlow-RT.
+func (c *Client) Topology(ctx context.Context) (*Topology, error) {
+	topology := &Topology{}
+	if err := c.get(ctx, "/topology/json", nil, topology); err != nil {
+		return nil, err
+	}
+	return topology, nil
+}
+
+// PutTopology replaces the topology known to sFlow-RT.
+func (c *Client) PutTopology(ctx context.Context, topology *Topology) error {
+	return c.do(ctx, http.MethodPut, "/topology/json", nil, topology, nil, 0)
+}
+
+// Groups returns the address groups defined in sFlow-RT by their names.
+func (c *Client) Groups(ctx context.Context) (map[string]Groups, error) {
+	groups := map[string]Groups{}
+	if err := c.get(ctx, "/group/json", nil, &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Group returns the address groups <name>, a not found error is returned if they are not defined.
+func (c *Client) Group(ctx context.Context, name string) (Groups, error) {
+	groups := Groups{}
+	if err := c.get(ctx, path("group", name, "json"), nil, &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// PutGroup defines the address <groups> <name> or replaces their definition.
+func (c *Client) PutGroup(ctx context.Context, name string, groups Groups) error {
+	return c.do(ctx, http.MethodPut, path("group", name, "json"), nil, groups, nil, 0)
+}
+
+// DeleteGroup deletes the address groups <name>, groups which do not exist are ignored.
+func (c *Client) DeleteGroup(ctx context.Context, name string) error {
+	return IgnoreNotFound(c.do(ctx, http.MethodDelete, path("group", name, "json"), nil, nil, nil, 0))
+}
+
+func (c *Client) get(ctx context.Context, path string, query map[string]string, result interface{}) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, result, 0)
+}
+
+// do sends a request and decodes the response into <result>. Requests which failed with a connection or server
+// error are retried, every attempt times out after the timeout of the client plus <wait>.
+func (c *Client) do(ctx context.Context, method, path string, query map[string]string, body, result interface{}, wait time.Duration) error {
+	var err error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(time.Duration(attempt) * c.retryWait):
+			}
+		}
+		if err = c.attempt(ctx, method, path, query, body, result, wait); err == nil || !retriable(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (c *Client) attempt(ctx context.Context, method, path string, query map[string]string, body, result interface{}, wait time.Duration) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout+wait)
+		defer cancel()
+	}
+
+	request := c.http.R().SetContext(ctx).SetQueryParams(query)
+	if body != nil {
+		request.SetHeader("Content-Type", "application/json").SetBody(body)
+	}
+	response, err := request.Execute(method, c.baseURL+path)
+	if err != nil {
+		return &Error{Method: method, Path: path, Err: err}
+	}
+	if response.StatusCode() < 200 || response.StatusCode() >= 300 {
+		return &Error{Method: method, Path: path, StatusCode: response.StatusCode(), Message: strings.TrimSpace(string(response.Body()))}
+	}
+
+	if result == nil || len(response.Body()) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(response.Body(), result); err != nil {
+		return fmt.Errorf("could not decode the response of sFlow-RT request %s %s: %v", method, path, err)
+	}
+	return nil
+}
+
+// path joins the escaped <segments> of a path.
+func path(segments ...string) string {
+	escaped := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		escaped = append(escaped, url.PathEscape(segment))
+	}
+	return "/" + strings.Join(escaped, "/")
+}
@@ -0,0 +1,238 @@
+package sflowrt_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt/fake"
+)
+
+var ctx = context.TODO()
+
+func newClient(t *testing.T, server *fake.Server, options sflowrt.Options) *sflowrt.Client {
+	if options.Timeout == 0 {
+		options.Timeout = 5 * time.Second
+	}
+	client, err := sflowrt.New(sflowrt.EndpointURL(server.Endpoint()), &options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestFlows(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	client := newClient(t, server, sflowrt.Options{})
+
+	flow := v1alpha1.Flow{Name: "tcp", Keys: "ipsource,ipdestination", Value: "bytes"}
+	if err := client.PutFlow(ctx, flow); err != nil {
+		t.Fatal(err)
+	}
+	actual, err := client.Flow(ctx, "tcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*actual, flow) {
+		t.Errorf("expected flow %+v, got %+v", flow, *actual)
+	}
+	flows, err := client.Flows(ctx)
+	if err != nil || len(flows) != 1 {
+		t.Errorf("expected one flow, got %v, %v", flows, err)
+	}
+
+	if err := client.DeleteFlow(ctx, "tcp"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Flow(ctx, "tcp"); !sflowrt.IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	// deleting a missing flow succeeds
+	if err := client.DeleteFlow(ctx, "tcp"); err != nil {
+		t.Errorf("expected missing flows to be ignored, got %v", err)
+	}
+}
+
+func TestThresholdsAndGroups(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	client := newClient(t, server, sflowrt.Options{})
+
+	threshold := v1alpha1.Threshold{Name: "elephant", Metric: "tcp", Value: 1000, ByFlow: "true"}
+	if err := client.PutThreshold(ctx, threshold); err != nil {
+		t.Fatal(err)
+	}
+	if thresholds, err := client.Thresholds(ctx); err != nil || !reflect.DeepEqual(thresholds["elephant"], threshold) {
+		t.Errorf("expected threshold %+v, got %v, %v", threshold, thresholds, err)
+	}
+
+	groups := sflowrt.Groups{"pods": {"10.244.0.0/16"}, "services": {"10.96.0.0/12"}}
+	if err := client.PutGroup(ctx, "cluster", groups); err != nil {
+		t.Fatal(err)
+	}
+	if actual, err := client.Group(ctx, "cluster"); err != nil || !reflect.DeepEqual(actual, groups) {
+		t.Errorf("expected groups %v, got %v, %v", groups, actual, err)
+	}
+	if err := client.DeleteGroup(ctx, "cluster"); err != nil || len(server.Groups()) != 0 {
+		t.Errorf("expected groups to be deleted, got %v, %v", server.Groups(), err)
+	}
+}
+
+func TestMetricsAndTopology(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	client := newClient(t, server, sflowrt.Options{})
+
+	server.SetMetrics("ALL", "max:ifinutilization,max:ifoututilization", sflowrt.Metric{Agent: "10.0.0.1", MetricName: "max:ifinutilization", MetricValue: 12.5})
+	metrics, err := client.Metrics(ctx, "ALL", "max:ifinutilization,max:ifoututilization")
+	if err != nil || len(metrics) != 1 || metrics[0].MetricValue != 12.5 {
+		t.Errorf("expected the metric, got %v, %v", metrics, err)
+	}
+
+	server.SetActiveFlows("ALL", "tcp", sflowrt.ActiveFlow{Key: "10.0.0.1,10.0.0.2", Value: 2000}, sflowrt.ActiveFlow{Key: "10.0.0.1,10.0.0.3", Value: 1000})
+	if flows, err := client.ActiveFlows(ctx, "ALL", "tcp", 1); err != nil || len(flows) != 1 || flows[0].Value != 2000 {
+		t.Errorf("expected the largest flow, got %v, %v", flows, err)
+	}
+
+	topology := &sflowrt.Topology{
+		Nodes: map[string]sflowrt.TopologyNode{"leaf1": {Agent: "10.0.0.1", Ports: map[string]sflowrt.TopologyPort{"swp1": {IfIndex: "1"}}}},
+		Links: map[string]sflowrt.TopologyLink{"link1": {Node1: "leaf1", Port1: "swp1", Node2: "spine1", Port2: "swp1"}},
+	}
+	if err := client.PutTopology(ctx, topology); err != nil {
+		t.Fatal(err)
+	}
+	if actual, err := client.Topology(ctx); err != nil || !reflect.DeepEqual(actual, topology) {
+		t.Errorf("expected topology %+v, got %+v, %v", topology, actual, err)
+	}
+}
+
+func TestEvents(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	client := newClient(t, server, sflowrt.Options{})
+
+	server.AddEvents(v1alpha1.Event{ThresholdID: "elephant"}, v1alpha1.Event{ThresholdID: "elephant"}, v1alpha1.Event{ThresholdID: "mouse"})
+	events, err := client.Events(ctx, sflowrt.EventsQuery{EventID: 1, MaxEvents: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].EventID != 3 || events[1].EventID != 2 {
+		t.Errorf("expected the events after 1 with the most recent first, got %+v", events)
+	}
+
+	// the long-poll returns as soon as an event arrives
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		server.AddEvents(v1alpha1.Event{ThresholdID: "elephant"})
+	}()
+	start := time.Now()
+	events, err = client.Events(ctx, sflowrt.EventsQuery{EventID: 3, Timeout: 10 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].EventID != 4 || time.Since(start) > 5*time.Second {
+		t.Errorf("expected the new event before the timeout, got %+v after %s", events, time.Since(start))
+	}
+}
+
+func TestErrors(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+
+	// server errors are retried
+	client := newClient(t, server, sflowrt.Options{Retries: 2, RetryWait: time.Millisecond})
+	server.FailNext(503, 2)
+	if _, err := client.Flows(ctx); err != nil {
+		t.Errorf("expected the request to succeed after retries, got %v", err)
+	}
+	server.FailNext(500, 3)
+	if _, err := client.Flows(ctx); err == nil {
+		t.Error("expected the request to fail once the retries are exhausted")
+	}
+
+	// client errors are not retried
+	server.FailNext(400, 1)
+	if err := client.PutFlow(ctx, v1alpha1.Flow{Name: "tcp"}); err == nil {
+		t.Error("expected the bad request to fail")
+	}
+	if requests := len(server.Requests()); requests != 7 {
+		t.Errorf("expected 7 requests, got %d", requests)
+	}
+}
+
+func TestAuthAndTLS(t *testing.T) {
+	server := fake.NewTLSServer()
+	defer server.Close()
+	server.RequireToken("secret")
+
+	dir, err := ioutil.TempDir("", "sflowrt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newClient(t, server, sflowrt.Options{InsecureSkipVerify: true}).Flows(ctx); !sflowrt.IsUnauthorized(err) {
+		t.Errorf("expected an unauthorized error, got %v", err)
+	}
+	if _, err := newClient(t, server, sflowrt.Options{BearerTokenFile: tokenFile}).Flows(ctx); err == nil {
+		t.Error("expected the self-signed certificate to be rejected")
+	}
+	if _, err := newClient(t, server, sflowrt.Options{BearerTokenFile: tokenFile, InsecureSkipVerify: true}).Flows(ctx); err != nil {
+		t.Errorf("expected the authenticated request to succeed, got %v", err)
+	}
+}
+
+func TestClientsCredentials(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.RequireToken("secret")
+	other := fake.NewServer()
+	defer other.Close()
+	other.RequireToken("secret")
+
+	dir, err := ioutil.TempDir("", "sflowrt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	clients := sflowrt.NewClients(&sflowrt.Options{
+		Timeout:             5 * time.Second,
+		BearerTokenFile:     tokenFile,
+		CredentialEndpoints: []string{sflowrt.EndpointURL(server.Endpoint()) + "/"},
+	})
+	client, err := clients.ForEndpoint(server.Endpoint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Flows(ctx); err != nil {
+		t.Errorf("expected the credentials to be sent to the configured endpoint, got %v", err)
+	}
+	if again, err := clients.ForEndpoint(server.Endpoint()); err != nil || again != client {
+		t.Errorf("expected the client to be reused, got %v", err)
+	}
+
+	// endpoints which are not configured never receive the credentials
+	client, err = clients.ForEndpoint(other.Endpoint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Flows(ctx); !sflowrt.IsUnauthorized(err) {
+		t.Errorf("expected the token not to be sent to an endpoint which is not configured, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package sflowrt
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Error is returned for requests sFlow-RT could not be reached for or which it answered with an error.
+type Error struct {
+	Method string
+	Path   string
+	// StatusCode is the status code of the response, it is zero if no response was received.
+	StatusCode int
+	// Message is the body of the response.
+	Message string
+	// Err is the error which prevented a response.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("sFlow-RT request %s %s failed: %v", e.Method, e.Path, e.Err)
+	}
+	if len(e.Message) == 0 {
+		return fmt.Sprintf("sFlow-RT request %s %s failed with status %d", e.Method, e.Path, e.StatusCode)
+	}
+	return fmt.Sprintf("sFlow-RT request %s %s failed with status %d: %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// IsNotFound returns true if <err> indicates that the requested object does not exist.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized returns true if <err> indicates that the credentials were rejected.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized) || hasStatus(err, http.StatusForbidden)
+}
+
+// IgnoreNotFound returns nil if <err> indicates that the requested object does not exist.
+func IgnoreNotFound(err error) error {
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// retriable returns true if the request may succeed if it is repeated, i.e. if sFlow-RT could not be reached, is
+// overloaded or failed.
+func retriable(err error) bool {
+	e, ok := err.(*Error)
+	if !ok {
+		return false
+	}
+	return e.StatusCode == 0 || e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+func hasStatus(err error, statusCode int) bool {
+	e, ok := err.(*Error)
+	return ok && e.StatusCode == statusCode
+}
@@ -0,0 +1,379 @@
+// Package fake provides an in-process sFlow-RT serving the REST API of the sflowrt client from memory.
+package fake
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+)
+
+// Server is a fake sFlow-RT. Flows, thresholds, groups and the topology are stored as they are put, metrics, active
+// flows and events are set by the test.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	flows       map[string]v1alpha1.Flow
+	thresholds  map[string]v1alpha1.Threshold
+	groups      map[string]sflowrt.Groups
+	topology    sflowrt.Topology
+	metrics     map[string][]sflowrt.Metric
+	activeFlows map[string][]sflowrt.ActiveFlow
+	events      []v1alpha1.Event
+	lastEventID int32
+	// newEvents is closed when events are added, long-polls wait for it
+	newEvents chan struct{}
+	failures  []int
+	token     string
+	requests  []string
+}
+
+// NewServer starts a fake sFlow-RT serving http, it has to be closed by the caller.
+func NewServer() *Server {
+	s := newServer()
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// NewTLSServer starts a fake sFlow-RT serving https with a self-signed certificate, it has to be closed by the caller.
+func NewTLSServer() *Server {
+	s := newServer()
+	s.Server = httptest.NewTLSServer(s)
+	return s
+}
+
+func newServer() *Server {
+	return &Server{
+		flows:       map[string]v1alpha1.Flow{},
+		thresholds:  map[string]v1alpha1.Threshold{},
+		groups:      map[string]sflowrt.Groups{},
+		metrics:     map[string][]sflowrt.Metric{},
+		activeFlows: map[string][]sflowrt.ActiveFlow{},
+		newEvents:   make(chan struct{}),
+	}
+}
+
+// Endpoint returns the monitoring endpoint of the server.
+func (s *Server) Endpoint() v1alpha1.MonitoringEndpoint {
+	host, port, _ := net.SplitHostPort(s.Listener.Addr().String())
+	endpoint := v1alpha1.MonitoringEndpoint{IP: host, Port: port}
+	if strings.HasPrefix(s.URL, "https://") {
+		endpoint.Scheme = "https"
+	}
+	return endpoint
+}
+
+// RequireToken rejects requests without the bearer <token>.
+func (s *Server) RequireToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// FailNext answers the next <count> requests with <statusCode>.
+func (s *Server) FailNext(statusCode, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < count; i++ {
+		s.failures = append(s.failures, statusCode)
+	}
+}
+
+// AddEvents adds <events> and wakes up waiting long-polls. Events without an ID are numbered consecutively.
+func (s *Server) AddEvents(events ...v1alpha1.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range events {
+		if event.EventID == 0 {
+			event.EventID = s.lastEventID + 1
+		}
+		if event.EventID > s.lastEventID {
+			s.lastEventID = event.EventID
+		}
+		s.events = append(s.events, event)
+	}
+	close(s.newEvents)
+	s.newEvents = make(chan struct{})
+}
+
+// ResetEvents discards all events and numbers new ones from 1 again, like a restarted sFlow-RT.
+func (s *Server) ResetEvents() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = nil
+	s.lastEventID = 0
+}
+
+// SetMetrics sets the values of <metric> returned for <agent>.
+func (s *Server) SetMetrics(agent, metric string, metrics ...sflowrt.Metric) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics[agent+"/"+metric] = metrics
+}
+
+// SetActiveFlows sets the values of the flow <name> returned for <agent>.
+func (s *Server) SetActiveFlows(agent, name string, flows ...sflowrt.ActiveFlow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activeFlows[agent+"/"+name] = flows
+}
+
+// Flows returns the defined flows.
+func (s *Server) Flows() map[string]v1alpha1.Flow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flows := map[string]v1alpha1.Flow{}
+	for name, flow := range s.flows {
+		flows[name] = flow
+	}
+	return flows
+}
+
+// PutFlow defines <flow> as if it was put by a client.
+func (s *Server) PutFlow(flow v1alpha1.Flow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flows[flow.Name] = flow
+}
+
+// Thresholds returns the defined thresholds.
+func (s *Server) Thresholds() map[string]v1alpha1.Threshold {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	thresholds := map[string]v1alpha1.Threshold{}
+	for name, threshold := range s.thresholds {
+		thresholds[name] = threshold
+	}
+	return thresholds
+}
+
+// PutThreshold defines <threshold> as if it was put by a client.
+func (s *Server) PutThreshold(threshold v1alpha1.Threshold) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.thresholds[threshold.Name] = threshold
+}
+
+// Groups returns the defined address groups.
+func (s *Server) Groups() map[string]sflowrt.Groups {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := map[string]sflowrt.Groups{}
+	for name, group := range s.groups {
+		groups[name] = group
+	}
+	return groups
+}
+
+// Requests returns the method and path of all requests received, e.g. "PUT /flow/tcp/json".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, req.Method+" "+req.URL.Path)
+	if len(s.token) > 0 && req.Header.Get("Authorization") != "Bearer "+s.token {
+		s.mu.Unlock()
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if len(s.failures) > 0 {
+		statusCode := s.failures[0]
+		s.failures = s.failures[1:]
+		s.mu.Unlock()
+		http.Error(w, http.StatusText(statusCode), statusCode)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(parts) == 0 || parts[len(parts)-1] != "json" {
+		s.mu.Unlock()
+		http.NotFound(w, req)
+		return
+	}
+	parts = parts[:len(parts)-1]
+
+	if parts[0] == "events" && len(parts) == 1 {
+		s.mu.Unlock()
+		s.serveEvents(w, req)
+		return
+	}
+	defer s.mu.Unlock()
+
+	switch {
+	case parts[0] == "flow":
+		serveStore(w, req, parts[1:], s.flows, func(name string, body []byte) error {
+			flow := v1alpha1.Flow{}
+			err := json.Unmarshal(body, &flow)
+			flow.Name = name
+			s.flows[name] = flow
+			return err
+		}, func(name string) bool {
+			_, ok := s.flows[name]
+			delete(s.flows, name)
+			return ok
+		})
+	case parts[0] == "threshold":
+		serveStore(w, req, parts[1:], s.thresholds, func(name string, body []byte) error {
+			threshold := v1alpha1.Threshold{}
+			err := json.Unmarshal(body, &threshold)
+			threshold.Name = name
+			s.thresholds[name] = threshold
+			return err
+		}, func(name string) bool {
+			_, ok := s.thresholds[name]
+			delete(s.thresholds, name)
+			return ok
+		})
+	case parts[0] == "group":
+		serveStore(w, req, parts[1:], s.groups, func(name string, body []byte) error {
+			groups := sflowrt.Groups{}
+			err := json.Unmarshal(body, &groups)
+			s.groups[name] = groups
+			return err
+		}, func(name string) bool {
+			_, ok := s.groups[name]
+			delete(s.groups, name)
+			return ok
+		})
+	case parts[0] == "topology" && len(parts) == 1:
+		if req.Method == http.MethodPut {
+			topology := sflowrt.Topology{}
+			if err := json.NewDecoder(req.Body).Decode(&topology); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			s.topology = topology
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, s.topology)
+	case parts[0] == "metric" && len(parts) == 3:
+		writeJSON(w, s.metrics[parts[1]+"/"+parts[2]])
+	case parts[0] == "activeflows" && len(parts) == 3:
+		flows := s.activeFlows[parts[1]+"/"+parts[2]]
+		if maxFlows, err := strconv.Atoi(req.URL.Query().Get("maxFlows")); err == nil && maxFlows < len(flows) {
+			flows = flows[:maxFlows]
+		}
+		writeJSON(w, flows)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// serveStore serves the objects of <store>, all of them for an empty <name> and single ones otherwise.
+func serveStore(w http.ResponseWriter, req *http.Request, name []string, store interface{}, put func(string, []byte) error, remove func(string) bool) {
+	if len(name) == 0 {
+		writeJSON(w, store)
+		return
+	}
+	if len(name) != 1 {
+		http.NotFound(w, req)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		object, ok := lookup(store, name[0])
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		writeJSON(w, object)
+	case http.MethodPut:
+		var body json.RawMessage
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := put(name[0], body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if !remove(name[0]) {
+			http.NotFound(w, req)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func lookup(store interface{}, name string) (interface{}, bool) {
+	switch s := store.(type) {
+	case map[string]v1alpha1.Flow:
+		object, ok := s[name]
+		return object, ok
+	case map[string]v1alpha1.Threshold:
+		object, ok := s[name]
+		return object, ok
+	case map[string]sflowrt.Groups:
+		object, ok := s[name]
+		return object, ok
+	}
+	return nil, false
+}
+
+// serveEvents returns the events after the eventID of the query, the most recent first. It waits up to the timeout
+// of the query for new events if there are none.
+func (s *Server) serveEvents(w http.ResponseWriter, req *http.Request) {
+	var (
+		query        = req.URL.Query()
+		eventID, _   = strconv.Atoi(query.Get("eventID"))
+		maxEvents, _ = strconv.Atoi(query.Get("maxEvents"))
+		timeout, _   = strconv.Atoi(query.Get("timeout"))
+		deadline     = time.After(time.Duration(timeout) * time.Second)
+	)
+	for {
+		s.mu.Lock()
+		var events []v1alpha1.Event
+		for _, event := range s.events {
+			if event.EventID > int32(eventID) {
+				events = append(events, event)
+			}
+		}
+		newEvents := s.newEvents
+		s.mu.Unlock()
+
+		if len(events) > 0 || timeout <= 0 {
+			sort.Slice(events, func(i, j int) bool { return events[i].EventID > events[j].EventID })
+			if maxEvents > 0 && len(events) > maxEvents {
+				events = events[:maxEvents]
+			}
+			if events == nil {
+				events = []v1alpha1.Event{}
+			}
+			writeJSON(w, events)
+			return
+		}
+
+		select {
+		case <-newEvents:
+		case <-deadline:
+			timeout = 0
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, object interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(object)
+}
@@ -0,0 +1,116 @@
+package sflowrt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Options configure the connection to sFlow-RT.
+type Options struct {
+	// Timeout is the timeout of a single request, long-polls for events wait for their timeout in addition.
+	Timeout time.Duration
+	// Retries is the number of times requests which failed with a connection error or a server error are retried.
+	Retries int
+	// RetryWait is the time waited before the first retry, it grows linearly with every retry.
+	RetryWait time.Duration
+	// BearerTokenFile is a file containing a token sent with every request, e.g. to a proxy in front of sFlow-RT.
+	BearerTokenFile string
+	// Username and PasswordFile are the credentials sent with every request using basic authentication.
+	Username     string
+	PasswordFile string
+	// CAFile is the bundle of CAs verifying the certificate of sFlow-RT served via https.
+	CAFile             string
+	InsecureSkipVerify bool
+	// Headers are sent with every request.
+	Headers map[string]string
+	// CredentialEndpoints are the base URLs of the endpoints the credentials and headers are sent to, e.g.
+	// "https://10.0.0.1:8008". Endpoints named by NetworkMonitors are not trusted otherwise.
+	CredentialEndpoints []string
+}
+
+// DefaultOptions are the default options of the sFlow-RT clients.
+var DefaultOptions = &Options{
+	Timeout:   10 * time.Second,
+	Retries:   2,
+	RetryWait: 500 * time.Millisecond,
+}
+
+// AddFlags adds the sFlow-RT flags to <flags>.
+func (o *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.DurationVar(&o.Timeout, "sflowrt-timeout", o.Timeout, "timeout of requests to sFlow-RT, long-polls for events wait for their timeout in addition")
+	flags.IntVar(&o.Retries, "sflowrt-retries", o.Retries, "number of retries of requests to sFlow-RT which failed with a connection or server error")
+	flags.DurationVar(&o.RetryWait, "sflowrt-retry-wait", o.RetryWait, "time to wait before the first retry of a request to sFlow-RT")
+	flags.StringVar(&o.BearerTokenFile, "sflowrt-bearer-token-file", o.BearerTokenFile, "file containing a bearer token sent with requests to sFlow-RT")
+	flags.StringVar(&o.Username, "sflowrt-username", o.Username, "username sent with requests to sFlow-RT using basic authentication")
+	flags.StringVar(&o.PasswordFile, "sflowrt-password-file", o.PasswordFile, "file containing the password of the basic authentication")
+	flags.StringVar(&o.CAFile, "sflowrt-ca-file", o.CAFile, "CA bundle verifying the certificate of sFlow-RT endpoints served via https")
+	flags.BoolVar(&o.InsecureSkipVerify, "sflowrt-insecure-skip-verify", o.InsecureSkipVerify, "do not verify the certificate of sFlow-RT endpoints served via https")
+	flags.StringToStringVar(&o.Headers, "sflowrt-headers", o.Headers, "additional headers sent with requests to sFlow-RT")
+	flags.StringSliceVar(&o.CredentialEndpoints, "sflowrt-credential-endpoints", o.CredentialEndpoints, "base URLs of the sFlow-RT endpoints the credentials and headers are sent to, e.g. https://10.0.0.1:8008")
+}
+
+// sendsCredentials returns true if the credentials and headers are sent to the endpoint at <baseURL>.
+func (o *Options) sendsCredentials(baseURL string) bool {
+	for _, endpoint := range o.CredentialEndpoints {
+		if strings.TrimSuffix(endpoint, "/") == baseURL {
+			return true
+		}
+	}
+	return false
+}
+
+// credentials are the secrets sent with requests.
+type credentials struct {
+	token    string
+	password string
+}
+
+// credentials reads the bearer token and the password of the basic authentication.
+func (o *Options) credentials() (*credentials, error) {
+	token, err := readSecret(o.BearerTokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the sFlow-RT bearer token: %v", err)
+	}
+	password := ""
+	if len(o.Username) > 0 {
+		if password, err = readSecret(o.PasswordFile); err != nil {
+			return nil, fmt.Errorf("could not read the sFlow-RT password: %v", err)
+		}
+	}
+	return &credentials{token: token, password: password}, nil
+}
+
+// tlsConfig returns the TLS configuration of https endpoints.
+func (o *Options) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: o.InsecureSkipVerify}
+	if len(o.CAFile) == 0 {
+		return config, nil
+	}
+	bundle, err := ioutil.ReadFile(o.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the sFlow-RT CA bundle: %v", err)
+	}
+	config.RootCAs = x509.NewCertPool()
+	if !config.RootCAs.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("no certificates found in the sFlow-RT CA bundle %s", o.CAFile)
+	}
+	return config, nil
+}
+
+// readSecret returns the trimmed content of <file>, it is empty if no file is given.
+func readSecret(file string) (string, error) {
+	if len(file) == 0 {
+		return "", nil
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
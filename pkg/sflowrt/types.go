@@ -0,0 +1,96 @@
+package sflowrt
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ActiveFlow is the value of a key of a flow reported by sFlow-RT, e.g. the bytes sent between two addresses.
+type ActiveFlow struct {
+	Key        string  `json:"key"`
+	Value      float64 `json:"value"`
+	Agent      string  `json:"agent,omitempty"`
+	DataSource string  `json:"dataSource,omitempty"`
+}
+
+// Metric is the value of a metric of an agent reported by sFlow-RT.
+type Metric struct {
+	Agent       string  `json:"agent,omitempty"`
+	DataSource  string  `json:"dataSource,omitempty"`
+	MetricName  string  `json:"metricName"`
+	MetricValue float64 `json:"metricValue"`
+	// LastUpdate is the number of milliseconds since the metric was updated.
+	LastUpdate int64 `json:"lastUpdate,omitempty"`
+}
+
+// Topology describes the switches and links of a network, sFlow-RT uses it to locate flows.
+type Topology struct {
+	Nodes map[string]TopologyNode `json:"nodes,omitempty"`
+	Links map[string]TopologyLink `json:"links,omitempty"`
+}
+
+// TopologyNode is a switch of a Topology.
+type TopologyNode struct {
+	Agent string                  `json:"agent,omitempty"`
+	Ports map[string]TopologyPort `json:"ports,omitempty"`
+}
+
+// TopologyPort is a port of a switch.
+type TopologyPort struct {
+	IfIndex string `json:"ifindex"`
+}
+
+// TopologyLink is a link between the ports of two switches.
+type TopologyLink struct {
+	Node1 string `json:"node1"`
+	Port1 string `json:"port1"`
+	Node2 string `json:"node2"`
+	Port2 string `json:"port2"`
+}
+
+// Groups maps names to the CIDRs of an address group, e.g. {"pods": ["10.244.0.0/16"]}. Flows can use the name of
+// the group an address belongs to as key.
+type Groups map[string][]string
+
+// EventsQuery selects the events returned by sFlow-RT.
+type EventsQuery struct {
+	// EventID returns the events after the given one only.
+	EventID int32
+	// MaxEvents is the maximum number of events returned, the most recent events are returned first.
+	MaxEvents int
+	// Timeout waits up to the given time for new events if there are none.
+	Timeout time.Duration
+}
+
+// UnmarshalDefinition decodes the definition of a flow or threshold into the struct <definition> points to. sFlow-RT
+// returns some options as booleans or numbers which the API types hold as strings, such values are converted.
+func UnmarshalDefinition(data []byte, definition interface{}) error {
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	t := reflect.TypeOf(definition).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type.Kind() != reflect.String {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		switch value := fields[name].(type) {
+		case bool:
+			fields[name] = strconv.FormatBool(value)
+		case float64:
+			fields[name] = strconv.FormatFloat(value, 'f', -1, 64)
+		}
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, definition)
+}
@@ -0,0 +1,27 @@
+package sflowrt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+)
+
+func TestUnmarshalDefinition(t *testing.T) {
+	flow := v1alpha1.Flow{}
+	if err := UnmarshalDefinition([]byte(`{"keys":"ipsource","value":"bytes","log":true,"activeTimeout":2,"n":5}`), &flow); err != nil {
+		t.Fatal(err)
+	}
+	expected := v1alpha1.Flow{Keys: "ipsource", Value: "bytes", Log: "true", ActiveTimeout: "2"}
+	if !reflect.DeepEqual(flow, expected) {
+		t.Errorf("expected flow %+v, got %+v", expected, flow)
+	}
+
+	threshold := v1alpha1.Threshold{}
+	if err := UnmarshalDefinition([]byte(`{"metric":"tcp","value":1000,"byFlow":true}`), &threshold); err != nil {
+		t.Fatal(err)
+	}
+	if threshold.Value != 1000 || threshold.ByFlow != "true" || threshold.Metric != "tcp" {
+		t.Errorf("unexpected threshold %+v", threshold)
+	}
+}
@@ -0,0 +1,114 @@
+// Package audit records every command the operators execute inside pods to a configurable sink.
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/policy"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+// Sink stores audit records.
+type Sink interface {
+	Write(ctx context.Context, record *v1alpha1.NetworkAuditRecordSpec) error
+}
+
+// Executor is an executor.PodExecutor which writes an audit record for every execution.
+type Executor struct {
+	delegate executor.PodExecutor
+	sink     Sink
+	logger   logr.Logger
+}
+
+// NewExecutor returns an executor writing the records of all executions of <delegate> to <sink>.
+func NewExecutor(delegate executor.PodExecutor, sink Sink) *Executor {
+	return &Executor{
+		delegate: delegate,
+		sink:     sink,
+		logger:   log.Log.WithName("audit"),
+	}
+}
+
+// Execute implements executor.PodExecutor. Failing to write the record does not fail the execution, the command
+// has already been executed at this point. Probes are not recorded.
+func (e *Executor) Execute(ctx context.Context, options executor.PodExecOptions) (*executor.ExecResult, error) {
+	start := time.Now()
+	result, err := e.delegate.Execute(ctx, options)
+
+	if !options.Probe {
+		e.write(ctx, NewRecord(ctx, start, options, result, err))
+	}
+	return result, err
+}
+
+// Authorize implements executor.Authorizer by delegating to the authorizer of the delegate, if it has one. Denied
+// targets are recorded without a command, no command is executed in them.
+func (e *Executor) Authorize(ctx context.Context, namespace, name, node string) error {
+	authorizer, ok := e.delegate.(executor.Authorizer)
+	if !ok {
+		return nil
+	}
+	start := time.Now()
+	err := authorizer.Authorize(ctx, namespace, name, node)
+	if policy.IsDenied(err) {
+		e.write(ctx, NewRecord(ctx, start, executor.PodExecOptions{Namespace: namespace, Name: name, Node: node}, nil, err))
+	}
+	return err
+}
+
+func (e *Executor) write(ctx context.Context, record *v1alpha1.NetworkAuditRecordSpec) {
+	if err := e.sink.Write(ctx, record); err != nil {
+		e.logger.Error(err, "Could not write audit record", "namespace", record.Target.Namespace, "pod", record.Target.Pod)
+	}
+}
+
+// SupportsEphemeralContainers implements executor.EphemeralContainerSupport.
+func (e *Executor) SupportsEphemeralContainers(ctx context.Context) (bool, error) {
+	return executor.SupportsEphemeralContainers(ctx, e.delegate)
+}
+
+// NewRecord returns the audit record of an execution started at <start>, the origin is taken from <ctx>.
+func NewRecord(ctx context.Context, start time.Time, options executor.PodExecOptions, result *executor.ExecResult, err error) *v1alpha1.NetworkAuditRecordSpec {
+	record := &v1alpha1.NetworkAuditRecordSpec{
+		Timestamp: metav1.NewTime(start),
+		Target: v1alpha1.AuditTarget{
+			Namespace: options.Namespace,
+			Pod:       options.Name,
+			Container: options.Container,
+		},
+		Command:  options.Command,
+		Strategy: string(options.Strategy),
+		Result:   v1alpha1.AuditResult{ExitCode: -1},
+	}
+
+	if origin, ok := executor.OriginFrom(ctx); ok {
+		record.Origin = v1alpha1.AuditOrigin{
+			Kind: origin.Kind,
+			Name: origin.Name,
+			UID:  origin.UID,
+			Tool: origin.Tool,
+		}
+		if origin.Requester != nil {
+			record.Origin.Requester = origin.Requester.Username
+		}
+	}
+
+	if result != nil {
+		record.Target.Container = result.Container
+		record.Strategy = string(result.Strategy)
+		record.Result.ExitCode = int32(result.ExitCode)
+		record.Result.Duration = result.Duration.String()
+	}
+	if err != nil {
+		record.Result.Error = err.Error()
+		if executor.IsExecError(err) && result != nil {
+			record.Result.Error = result.Message()
+		}
+	}
+	return record
+}
@@ -0,0 +1,202 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor/fake"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/policy"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/test"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+type memorySink struct {
+	records []*v1alpha1.NetworkAuditRecordSpec
+}
+
+func (s *memorySink) Write(_ context.Context, record *v1alpha1.NetworkAuditRecordSpec) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func TestExecutor(t *testing.T) {
+	sink := &memorySink{}
+	podExecutor := fake.NewExecutor().
+		On(`^tc qdisc add `, fake.Succeeded()).
+		On(`^tc qdisc del `, fake.Failed("RTNETLINK answers: No such file or directory", 2))
+	auditExecutor := NewExecutor(podExecutor, sink)
+	ctx := executor.WithOrigin(context.TODO(), executor.Origin{
+		Kind:      "NetworkTrafficShaper",
+		Name:      "shaper",
+		Tool:      v1alpha1.ExecToolMutation,
+		Requester: &authenticationv1.UserInfo{Username: "alice"},
+	})
+
+	if _, err := auditExecutor.Execute(ctx, executor.PodExecOptions{Namespace: "default", Name: "pod", Container: "app", Command: "tc qdisc add dev eth0 root netem delay 200ms"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auditExecutor.Execute(ctx, executor.PodExecOptions{Namespace: "default", Name: "pod", Container: "app", Command: "tc qdisc del dev eth0 root"}); !executor.IsExecError(err) {
+		t.Fatalf("expected the exec error to be returned, got %v", err)
+	}
+
+	if len(sink.records) != 2 {
+		t.Fatalf("expected two records, got %d", len(sink.records))
+	}
+	succeeded, failed := sink.records[0], sink.records[1]
+	if succeeded.Origin.Kind != "NetworkTrafficShaper" || succeeded.Origin.Name != "shaper" || succeeded.Origin.Requester != "alice" || succeeded.Origin.Tool != v1alpha1.ExecToolMutation {
+		t.Errorf("unexpected origin %+v", succeeded.Origin)
+	}
+	if succeeded.Target != (v1alpha1.AuditTarget{Namespace: "default", Pod: "pod", Container: "app"}) || succeeded.Command != "tc qdisc add dev eth0 root netem delay 200ms" {
+		t.Errorf("unexpected record %+v", succeeded)
+	}
+	if succeeded.Result.ExitCode != 0 || len(succeeded.Result.Error) != 0 {
+		t.Errorf("unexpected result %+v", succeeded.Result)
+	}
+	if failed.Result.ExitCode != 2 || failed.Result.Error != "RTNETLINK answers: No such file or directory" {
+		t.Errorf("unexpected result %+v", failed.Result)
+	}
+}
+
+func TestExecutorRecordsDenials(t *testing.T) {
+	sink := &memorySink{}
+	c := test.NewFakeClient(
+		test.NewRunningPod("default", "pod", nil),
+		&v1alpha1.NetworkExecPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+			Spec:       v1alpha1.NetworkExecPolicySpec{Deny: []v1alpha1.ExecPolicyRule{{Namespaces: []string{"default"}}}},
+		},
+	)
+	podExecutor := fake.NewExecutor().On(`^tc `, fake.Succeeded())
+	auditExecutor := NewExecutor(policy.NewExecutor(podExecutor, c), sink)
+	ctx := executor.WithOrigin(context.TODO(), executor.Origin{Kind: "NetworkTrafficShaper", Name: "shaper", Tool: v1alpha1.ExecToolMutation})
+
+	if err := auditExecutor.Authorize(ctx, "default", "pod", ""); !policy.IsDenied(err) {
+		t.Fatalf("expected the target to be denied, got %v", err)
+	}
+	if _, err := auditExecutor.Execute(ctx, executor.PodExecOptions{Namespace: "default", Name: "pod", Container: "app", Command: "tc qdisc show"}); !policy.IsDenied(err) {
+		t.Fatalf("expected the execution to be denied, got %v", err)
+	}
+
+	if commands := podExecutor.Commands(); len(commands) != 0 {
+		t.Errorf("expected no command to be executed, got %v", commands)
+	}
+	if len(sink.records) != 2 {
+		t.Fatalf("expected both denials to be recorded, got %d records", len(sink.records))
+	}
+	for _, record := range sink.records {
+		if record.Target.Pod != "pod" || record.Result.ExitCode != -1 || !strings.Contains(record.Result.Error, "denied") {
+			t.Errorf("expected a denied record, got %+v", record)
+		}
+	}
+	if sink.records[1].Command != "tc qdisc show" {
+		t.Errorf("expected the denied command to be recorded, got %q", sink.records[1].Command)
+	}
+}
+
+func TestExecutorSkipsProbes(t *testing.T) {
+	sink := &memorySink{}
+	auditExecutor := NewExecutor(fake.NewExecutor().On(`^command -v `, fake.Succeeded()), sink)
+
+	if _, err := auditExecutor.Execute(context.TODO(), executor.PodExecOptions{Namespace: "default", Name: "pod", Container: "app", Command: "command -v tc >/dev/null", Probe: true}); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.records) != 0 {
+		t.Errorf("expected probes not to be recorded, got %+v", sink.records)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	sink, err := NewFileSink(path, 300, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	for i := 0; i < 10; i++ {
+		record := &v1alpha1.NetworkAuditRecordSpec{
+			Target:  v1alpha1.AuditTarget{Namespace: "default", Pod: "pod"},
+			Command: strings.Repeat("x", 50),
+		}
+		if err := sink.Write(context.TODO(), record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := filepath.Glob(path + "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected the file and two backups, got %v", files)
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > 300 {
+			t.Errorf("expected %s to be rotated, got %d bytes", file, len(data))
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			record := &v1alpha1.NetworkAuditRecordSpec{}
+			if err := json.Unmarshal([]byte(line), record); err != nil || record.Target.Pod != "pod" {
+				t.Errorf("unexpected line %q in %s: %v", line, file, err)
+			}
+		}
+	}
+}
+
+func TestRecordSink(t *testing.T) {
+	c := test.NewFakeClient()
+	sink := NewRecordSink(c, time.Hour, log.Log)
+	start := time.Now()
+
+	for _, name := range []string{"old", "new"} {
+		timestamp := start
+		if name == "old" {
+			timestamp = start.Add(-2 * time.Hour)
+		}
+		record := &v1alpha1.NetworkAuditRecordSpec{
+			Timestamp: metav1.NewTime(timestamp),
+			Origin:    v1alpha1.AuditOrigin{Kind: "NetworkConnectivityTest", Name: name},
+		}
+		if err := sink.Write(context.TODO(), record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := sink.DeleteExpired(context.TODO(), start); err != nil {
+		t.Fatal(err)
+	}
+	records := &v1alpha1.NetworkAuditRecordList{}
+	if err := c.List(context.TODO(), records); err != nil {
+		t.Fatal(err)
+	}
+	if len(records.Items) != 1 {
+		t.Fatalf("expected one record to be left, got %d", len(records.Items))
+	}
+	record := records.Items[0]
+	if record.Spec.Origin.Name != "new" || record.Labels[OriginKindLabel] != "networkconnectivitytest" || record.Labels[OriginNameLabel] != "new" {
+		t.Errorf("unexpected record %+v", record)
+	}
+	if record.Spec.ExpiresAt == nil || !record.Spec.ExpiresAt.Time.Equal(record.Spec.Timestamp.Add(time.Hour)) {
+		t.Errorf("expected record to expire after an hour, got %v", record.Spec.ExpiresAt)
+	}
+}
@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+const (
+	// SinkLog writes audit records to the controller log stream.
+	SinkLog = "log"
+	// SinkFile writes audit records to a rotating file.
+	SinkFile = "file"
+	// SinkRecord creates a NetworkAuditRecord for every audit record.
+	SinkRecord = "record"
+)
+
+// Options configure the audit sink.
+type Options struct {
+	Sink           string
+	File           string
+	FileMaxSize    int
+	FileMaxBackups int
+	RecordTTL      time.Duration
+}
+
+// DefaultOptions are the default audit options.
+var DefaultOptions = &Options{
+	Sink:           SinkLog,
+	File:           "/var/log/networkmachinery/audit.log",
+	FileMaxSize:    100,
+	FileMaxBackups: 5,
+	RecordTTL:      24 * time.Hour,
+}
+
+// AddFlags adds the audit flags to <flags>.
+func (o *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.Sink, "audit-sink", o.Sink, fmt.Sprintf("sink of the audit records of commands executed inside pods, one of %s, %s or %s", SinkLog, SinkFile, SinkRecord))
+	flags.StringVar(&o.File, "audit-file", o.File, "path of the audit file if the file sink is used")
+	flags.IntVar(&o.FileMaxSize, "audit-file-max-size", o.FileMaxSize, "maximum size in megabytes of the audit file before it is rotated")
+	flags.IntVar(&o.FileMaxBackups, "audit-file-max-backups", o.FileMaxBackups, "maximum number of rotated audit files to keep")
+	flags.DurationVar(&o.RecordTTL, "audit-record-ttl", o.RecordTTL, "time after which NetworkAuditRecords are deleted if the record sink is used, 0 keeps them forever")
+}
+
+// NewSink returns the configured sink, sinks which need to run in the background are added to <mgr>.
+func (o *Options) NewSink(mgr manager.Manager) (Sink, error) {
+	logger := log.Log.WithName("audit")
+	switch o.Sink {
+	case SinkLog:
+		return NewLogSink(logger), nil
+	case SinkFile:
+		return NewFileSink(o.File, int64(o.FileMaxSize)*1024*1024, o.FileMaxBackups)
+	case SinkRecord:
+		sink := NewRecordSink(mgr.GetClient(), o.RecordTTL, logger)
+		if err := mgr.Add(sink); err != nil {
+			return nil, err
+		}
+		return sink, nil
+	}
+	return nil, fmt.Errorf("unknown audit sink %q", o.Sink)
+}
+
+var (
+	sinkOnce sync.Once
+	sink     Sink
+	sinkErr  error
+)
+
+// SinkFor returns the sink configured by <options>. All controllers of a process share the same sink, it is created
+// by the first call.
+func SinkFor(mgr manager.Manager, options *Options) (Sink, error) {
+	sinkOnce.Do(func() {
+		sink, sinkErr = options.NewSink(mgr)
+	})
+	return sink, sinkErr
+}
@@ -0,0 +1,223 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// LogSink writes audit records to the log stream of the controller.
+type LogSink struct {
+	logger logr.Logger
+}
+
+// NewLogSink returns a sink logging audit records with <logger>.
+func NewLogSink(logger logr.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+// Write implements Sink.
+func (s *LogSink) Write(_ context.Context, record *v1alpha1.NetworkAuditRecordSpec) error {
+	s.logger.Info("Executed command",
+		"originKind", record.Origin.Kind,
+		"originName", record.Origin.Name,
+		"originUID", record.Origin.UID,
+		"tool", record.Origin.Tool,
+		"requester", record.Origin.Requester,
+		"namespace", record.Target.Namespace,
+		"pod", record.Target.Pod,
+		"container", record.Target.Container,
+		"command", record.Command,
+		"strategy", record.Strategy,
+		"exitCode", record.Result.ExitCode,
+		"duration", record.Result.Duration,
+		"error", record.Result.Error,
+	)
+	return nil
+}
+
+// FileSink appends audit records as JSON lines to a file, the file is rotated once it exceeds its maximum size.
+type FileSink struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	file *os.File
+	size int64
+}
+
+// NewFileSink returns a sink writing to <path>. Once the file would grow beyond <maxSize> bytes it is renamed to
+// <path>.1, previous backups are shifted and at most <maxBackups> backups are kept.
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	s := &FileSink{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write implements Sink.
+func (s *FileSink) Write(_ context.Context, record *v1alpha1.NetworkAuditRecordSpec) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+// Close closes the current file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+func (s *FileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	if s.maxBackups > 0 {
+		for i := s.maxBackups - 1; i > 0; i-- {
+			if err := os.Rename(s.backup(i), s.backup(i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(s.path, s.backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(s.path); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func (s *FileSink) backup(i int) string {
+	return fmt.Sprintf("%s.%d", s.path, i)
+}
+
+const (
+	// OriginKindLabel is the label holding the kind of the object which triggered an audited execution.
+	OriginKindLabel = "networkmachinery.io/origin-kind"
+	// OriginNameLabel is the label holding the name of the object which triggered an audited execution.
+	OriginNameLabel = "networkmachinery.io/origin-name"
+)
+
+// RecordSink creates a NetworkAuditRecord for every audit record. It also runs in the manager to delete expired
+// records.
+type RecordSink struct {
+	client   client.Client
+	ttl      time.Duration
+	interval time.Duration
+	logger   logr.Logger
+}
+
+// NewRecordSink returns a sink creating NetworkAuditRecords which expire after <ttl>, they are kept forever if
+// <ttl> is zero.
+func NewRecordSink(c client.Client, ttl time.Duration, logger logr.Logger) *RecordSink {
+	return &RecordSink{
+		client:   c,
+		ttl:      ttl,
+		interval: time.Minute,
+		logger:   logger,
+	}
+}
+
+// Write implements Sink.
+func (s *RecordSink) Write(ctx context.Context, record *v1alpha1.NetworkAuditRecordSpec) error {
+	spec := record.DeepCopy()
+	if s.ttl > 0 {
+		expiresAt := metav1.NewTime(spec.Timestamp.Add(s.ttl))
+		spec.ExpiresAt = &expiresAt
+	}
+
+	auditRecord := &v1alpha1.NetworkAuditRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("exec-%d-%s", spec.Timestamp.Unix(), utilrand.String(5)),
+			Labels: map[string]string{
+				OriginKindLabel: strings.ToLower(spec.Origin.Kind),
+			},
+		},
+		Spec: *spec,
+	}
+	// names of cluster scoped objects may be longer than label values
+	if len(validation.IsValidLabelValue(spec.Origin.Name)) == 0 {
+		auditRecord.Labels[OriginNameLabel] = spec.Origin.Name
+	}
+	return s.client.Create(ctx, auditRecord)
+}
+
+// Start implements manager.Runnable, it periodically deletes expired records until <stop> is closed.
+func (s *RecordSink) Start(stop <-chan struct{}) error {
+	wait.Until(func() {
+		if err := s.DeleteExpired(context.TODO(), time.Now()); err != nil {
+			s.logger.Error(err, "Could not delete expired NetworkAuditRecords")
+		}
+	}, s.interval, stop)
+	return nil
+}
+
+// DeleteExpired deletes all records which expired before <now>.
+func (s *RecordSink) DeleteExpired(ctx context.Context, now time.Time) error {
+	records := &v1alpha1.NetworkAuditRecordList{}
+	if err := s.client.List(ctx, records); err != nil {
+		return err
+	}
+	for i := range records.Items {
+		record := &records.Items[i]
+		if record.Spec.ExpiresAt == nil || record.Spec.ExpiresAt.Time.After(now) {
+			continue
+		}
+		if err := s.client.Delete(ctx, record); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/apimachinery"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+	"k8s.io/client-go/rest"
+)
+
+// DebugContainerName is the name of the ephemeral container used to run network tools inside pods.
+const DebugContainerName = "net-debug"
+
+// NewExecutor returns an executor.PodExecutor which runs commands through ExecInPod.
+func NewExecutor(config *rest.Config) executor.PodExecutor {
+	return &clusterExecutor{
+		config: config,
+	}
+}
+
+type clusterExecutor struct {
+	config *rest.Config
+
+	mu sync.Mutex
+	// ephemeralContainers caches whether the cluster supports ephemeral containers once it is known
+	ephemeralContainers *bool
+}
+
+// Execute implements executor.PodExecutor.
+func (c *clusterExecutor) Execute(ctx context.Context, options executor.PodExecOptions) (*executor.ExecResult, error) {
+	return ExecInPod(ctx, c.config, options)
+}
+
+// SupportsEphemeralContainers implements executor.EphemeralContainerSupport.
+func (c *clusterExecutor) SupportsEphemeralContainers(ctx context.Context) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ephemeralContainers == nil {
+		supported, err := ShouldUseEphemeralContainers(c.config)
+		if err != nil {
+			return false, err
+		}
+		c.ephemeralContainers = &supported
+	}
+	return *c.ephemeralContainers, nil
+}
+
+// ExecInPod executes the given command inside the pod. With executor.StrategyEphemeralContainer the command runs
+// inside a debug container attached to the pod, otherwise it runs in <options.Container>. Commands for node helpers
+// always run in <options.Container>, the debug container is not privileged.
+func ExecInPod(ctx context.Context, config *rest.Config, options executor.PodExecOptions) (*executor.ExecResult, error) {
+	if len(options.Node) > 0 {
+		options.Strategy = executor.StrategyExec
+	}
+	if options.Strategy == executor.StrategyEphemeralContainer {
+		if err := apimachinery.CreateOrUpdateEphemeralContainer(config, options.Namespace, options.Name, DebugContainerName); err != nil {
+			return nil, err
+		}
+		if err := apimachinery.EphemeralContainerInStatus(ctx, config, &v1alpha1.NetworkSourceEndpoint{Namespace: options.Namespace, Name: options.Name}); err != nil {
+			return nil, err
+		}
+		options.Container = DebugContainerName
+	}
+
+	return PodExec(ctx, config, options)
+}
+
+// ToExecStatus converts the result of an execution into its API representation.
+func ToExecStatus(result *executor.ExecResult) *v1alpha1.ExecStatus {
+	if result == nil {
+		return nil
+	}
+	return &v1alpha1.ExecStatus{
+		ExitCode:  int32(result.ExitCode),
+		Message:   result.Message(),
+		Stdout:    result.Stdout,
+		Stderr:    result.Stderr,
+		Duration:  result.Duration.String(),
+		Container: result.Container,
+		Strategy:  string(result.Strategy),
+	}
+}
@@ -16,13 +16,43 @@ package executor
 
 import (
 	"bytes"
-
 	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxOutputBytes is the maximum number of bytes of stdout / stderr kept in an ExecResult.
+const MaxOutputBytes = 1024
+
+// Strategy describes how a command reached the target pod.
+type Strategy string
+
+const (
+	// StrategyExec runs the command through the exec subresource of one of the pod's containers.
+	StrategyExec Strategy = "exec"
+	// StrategyEphemeralContainer runs the command inside an ephemeral debug container attached to the pod.
+	StrategyEphemeralContainer Strategy = "ephemeral-container"
 )
 
 // PodExecutor is the pod executor interface
 type PodExecutor interface {
-	Execute(ctx context.Context, options PodExecOptions) error
+	Execute(ctx context.Context, options PodExecOptions) (*ExecResult, error)
+}
+
+// EphemeralContainerSupport is implemented by executors which can run commands with StrategyEphemeralContainer,
+// executors wrapping others forward it.
+type EphemeralContainerSupport interface {
+	SupportsEphemeralContainers(ctx context.Context) (bool, error)
+}
+
+// SupportsEphemeralContainers returns true if <podExecutor> can run commands in ephemeral debug containers.
+func SupportsEphemeralContainers(ctx context.Context, podExecutor PodExecutor) (bool, error) {
+	if support, ok := podExecutor.(EphemeralContainerSupport); ok {
+		return support.SupportsEphemeralContainers(ctx)
+	}
+	return false, nil
 }
 
 // RemoteExecutor is the debug executor interface
@@ -33,3 +63,68 @@ type DebugExecutor interface {
 type StandardCmdOpts struct {
 	StdOut, StdErr *bytes.Buffer
 }
+
+// ExecResult is the outcome of a command executed inside a pod.
+type ExecResult struct {
+	// ExitCode is the exit code of the command, -1 if the command could not be started.
+	ExitCode int
+	// Stdout is the (truncated) standard output of the command.
+	Stdout string
+	// Stderr is the (truncated) standard error of the command.
+	Stderr string
+	// Duration is the time it took to run the command.
+	Duration time.Duration
+	// Container is the container the command ran in.
+	Container string
+	// Strategy is the strategy used to reach the container.
+	Strategy Strategy
+}
+
+// Message returns a short human readable description of the result, preferring what the command
+// itself reported on stderr over the generic error returned by the API server.
+func (r *ExecResult) Message() string {
+	if r == nil {
+		return ""
+	}
+	if msg := strings.TrimSpace(r.Stderr); len(msg) > 0 {
+		return msg
+	}
+	if r.ExitCode != 0 {
+		if msg := strings.TrimSpace(r.Stdout); len(msg) > 0 {
+			return msg
+		}
+		return fmt.Sprintf("command terminated with exit code %d", r.ExitCode)
+	}
+	return ""
+}
+
+// ExecError is returned when a command was executed but terminated with a non-zero exit code.
+type ExecError struct {
+	// Result holds the details of the failed execution.
+	Result *ExecResult
+	// Cause is the error returned by the API server.
+	Cause error
+}
+
+func (e *ExecError) Error() string {
+	return fmt.Sprintf("command failed in container %q (%s) with exit code %d: %s", e.Result.Container, e.Result.Strategy, e.Result.ExitCode, e.Result.Message())
+}
+
+// IsExecError returns true if the given error indicates a command which ran but failed, as opposed to
+// a command which could not be executed at all.
+func IsExecError(err error) bool {
+	_, ok := err.(*ExecError)
+	return ok
+}
+
+// Truncate shortens the given output to at most MaxOutputBytes, it does not split multi-byte characters.
+func Truncate(out string) string {
+	if len(out) <= MaxOutputBytes {
+		return out
+	}
+	end := MaxOutputBytes
+	for end > 0 && !utf8.RuneStart(out[end]) {
+		end--
+	}
+	return out[:end] + "...(truncated)"
+}
@@ -0,0 +1,22 @@
+package executor
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	if out := Truncate("short"); out != "short" {
+		t.Errorf("expected short output to be kept, got %q", out)
+	}
+
+	// the multi-byte character at the limit is dropped as a whole
+	out := Truncate(strings.Repeat("a", MaxOutputBytes-1) + "ü" + "b")
+	if !utf8.ValidString(out) {
+		t.Errorf("expected valid UTF-8, got %q", out[MaxOutputBytes-4:])
+	}
+	if expected := strings.Repeat("a", MaxOutputBytes-1) + "...(truncated)"; out != expected {
+		t.Errorf("expected the output to be truncated before the character, got %q", out[MaxOutputBytes-4:])
+	}
+}
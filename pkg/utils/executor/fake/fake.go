@@ -0,0 +1,140 @@
+// Package fake provides a scripted executor.PodExecutor which never talks to a cluster.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+)
+
+// Response is the canned output returned for a command.
+type Response struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	// Err is returned as is, simulating a command which could not be executed at all.
+	Err error
+}
+
+type script struct {
+	pod       string
+	container string
+	command   *regexp.Regexp
+	response  Response
+}
+
+// Executor is an executor.PodExecutor returning scripted responses. Commands are matched against the scripts in
+// the order they were added, commands without a matching script fail with exit code 127.
+type Executor struct {
+	// EphemeralContainers makes the executor report support for executor.StrategyEphemeralContainer.
+	EphemeralContainers bool
+
+	mu         sync.Mutex
+	scripts    []script
+	executions []executor.PodExecOptions
+}
+
+var _ executor.PodExecutor = &Executor{}
+
+// NewExecutor returns a new fake executor without any scripts.
+func NewExecutor() *Executor {
+	return &Executor{}
+}
+
+// On adds a script returning <response> for every command matching the regular expression <command>.
+func (e *Executor) On(command string, response Response) *Executor {
+	return e.OnPod("", command, response)
+}
+
+// OnPod adds a script returning <response> for every command matching the regular expression <command>
+// executed in the pod <namespace/name>. An empty pod matches all pods.
+func (e *Executor) OnPod(pod, command string, response Response) *Executor {
+	return e.OnContainer(pod, "", command, response)
+}
+
+// OnContainer adds a script returning <response> for every command matching the regular expression <command>
+// executed in <container> of the pod <namespace/name>. An empty pod or container matches all pods or containers.
+func (e *Executor) OnContainer(pod, container, command string, response Response) *Executor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.scripts = append(e.scripts, script{pod: pod, container: container, command: regexp.MustCompile(command), response: response})
+	return e
+}
+
+// Executions returns the options of all executions so far.
+func (e *Executor) Executions() []executor.PodExecOptions {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]executor.PodExecOptions(nil), e.executions...)
+}
+
+// Commands returns the commands of all executions so far.
+func (e *Executor) Commands() []string {
+	var commands []string
+	for _, execution := range e.Executions() {
+		commands = append(commands, execution.Command)
+	}
+	return commands
+}
+
+// SupportsEphemeralContainers implements executor.EphemeralContainerSupport.
+func (e *Executor) SupportsEphemeralContainers(ctx context.Context) (bool, error) {
+	return e.EphemeralContainers, nil
+}
+
+// Execute implements executor.PodExecutor.
+func (e *Executor) Execute(ctx context.Context, options executor.PodExecOptions) (*executor.ExecResult, error) {
+	e.mu.Lock()
+	e.executions = append(e.executions, options)
+	response, ok := e.lookup(options)
+	e.mu.Unlock()
+
+	if !ok {
+		response = Response{Stderr: fmt.Sprintf("sh: %s: not found", options.Command), ExitCode: 127}
+	}
+	if len(options.Strategy) == 0 {
+		options.Strategy = executor.StrategyExec
+	}
+
+	result := &executor.ExecResult{
+		ExitCode:  response.ExitCode,
+		Stdout:    executor.Truncate(response.Stdout),
+		Stderr:    executor.Truncate(response.Stderr),
+		Container: options.Container,
+		Strategy:  options.Strategy,
+	}
+	if options.StdOut != nil {
+		options.StdOut.WriteString(response.Stdout)
+	}
+	if options.StdErr != nil {
+		options.StdErr.WriteString(response.Stderr)
+	}
+
+	if response.Err != nil {
+		result.ExitCode = -1
+		return result, response.Err
+	}
+	if response.ExitCode != 0 {
+		return result, &executor.ExecError{Result: result, Cause: fmt.Errorf("command terminated with exit code %d", response.ExitCode)}
+	}
+	return result, nil
+}
+
+func (e *Executor) lookup(options executor.PodExecOptions) (Response, bool) {
+	pod := options.Namespace + "/" + options.Name
+	for _, s := range e.scripts {
+		if len(s.pod) > 0 && s.pod != pod {
+			continue
+		}
+		if len(s.container) > 0 && s.container != options.Container {
+			continue
+		}
+		if s.command.MatchString(options.Command) {
+			return s.response, true
+		}
+	}
+	return Response{}, false
+}
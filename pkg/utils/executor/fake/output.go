@@ -0,0 +1,43 @@
+package fake
+
+import "fmt"
+
+// PingSucceeded returns the output of a successful `ping -c3 <host>` with the given round trip times in ms.
+func PingSucceeded(host string, min, avg, max float64) Response {
+	return Response{
+		Stdout: fmt.Sprintf(`PING %[1]s (%[1]s): 56 data bytes
+64 bytes from %[1]s: icmp_seq=0 ttl=64 time=%.3[2]f ms
+64 bytes from %[1]s: icmp_seq=1 ttl=64 time=%.3[3]f ms
+64 bytes from %[1]s: icmp_seq=2 ttl=64 time=%.3[4]f ms
+
+--- %[1]s ping statistics ---
+3 packets transmitted, 3 received, 0%% packet loss, time 2003ms
+rtt min/avg/max/mdev = %.3[2]f/%.3[3]f/%.3[4]f/0.100 ms
+`, host, min, avg, max),
+	}
+}
+
+// PingFailed returns the output of a failed `ping -c3` with the given message on stderr.
+func PingFailed(message string) Response {
+	return Response{Stderr: message, ExitCode: 1}
+}
+
+// NetcatSucceeded returns the output of a successful `nc -z -v <host> <port> 2>&1`.
+func NetcatSucceeded(host, port string) Response {
+	return Response{Stdout: fmt.Sprintf("Connection to %s %s port [tcp/*] succeeded!\n", host, port)}
+}
+
+// NetcatRefused returns the output of a refused `nc -z -v <host> <port> 2>&1`.
+func NetcatRefused(host, port string) Response {
+	return Response{Stdout: fmt.Sprintf("nc: connect to %s port %s (tcp) failed: Connection refused\n", host, port), ExitCode: 1}
+}
+
+// Succeeded returns an empty successful response, e.g. for tc commands.
+func Succeeded() Response {
+	return Response{}
+}
+
+// Failed returns a failed response with the given message on stderr.
+func Failed(message string, exitCode int) Response {
+	return Response{Stderr: message, ExitCode: exitCode}
+}
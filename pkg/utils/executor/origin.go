@@ -0,0 +1,43 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Origin describes on whose behalf commands are executed.
+type Origin struct {
+	// Kind, Name and UID identify the object commands are executed for.
+	Kind string
+	Name string
+	UID  types.UID
+	// Tool is the category of the executed tools.
+	Tool v1alpha1.ExecTool
+	// Requester is the user who requested the object, nil if unknown.
+	Requester *authenticationv1.UserInfo
+	// Undo is set if the commands revert mutations which were executed on behalf of the requester before. The
+	// requester was authorized for them then and is not checked again.
+	Undo bool
+}
+
+type originKey struct{}
+
+// WithOrigin returns a copy of <ctx> carrying <origin>, executors use it to authorize and record executions.
+func WithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, origin)
+}
+
+// OriginFrom returns the origin carried by <ctx>.
+func OriginFrom(ctx context.Context) (Origin, bool) {
+	origin, ok := ctx.Value(originKey{}).(Origin)
+	return origin, ok
+}
+
+// Authorizer is implemented by executors which refuse executions in some pods, it allows to check a pod before
+// any command is executed in it. <node> is set if the pod is the helper pod of that node.
+type Authorizer interface {
+	Authorize(ctx context.Context, namespace, name, node string) error
+}
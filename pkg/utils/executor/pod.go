@@ -1,14 +1,17 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
 )
 
 // NewPodExecutor returns a podExecutor
@@ -27,15 +30,38 @@ type PodExecOptions struct {
 	Name      string
 	Container string
 	Command   string
+	// Node is set if the pod is the helper pod of a node, commands executed in it affect the whole node.
+	Node string
+	// Strategy is the strategy used to reach Container, defaults to StrategyExec.
+	Strategy Strategy
+	// Probe is set for commands which only inspect the container, e.g. whether it provides a binary. They are not
+	// audited.
+	Probe bool
 
 	StandardCmdOpts
 }
 
 // Execute executes a command on a pod
-func (p *podExecutor) Execute(ctx context.Context, options PodExecOptions) error {
+func (p *podExecutor) Execute(ctx context.Context, options PodExecOptions) (*ExecResult, error) {
+	if options.StdOut == nil {
+		options.StdOut = &bytes.Buffer{}
+	}
+	if options.StdErr == nil {
+		options.StdErr = &bytes.Buffer{}
+	}
+	if len(options.Strategy) == 0 {
+		options.Strategy = StrategyExec
+	}
+
+	result := &ExecResult{
+		ExitCode:  -1,
+		Container: options.Container,
+		Strategy:  options.Strategy,
+	}
+
 	client, err := corev1client.NewForConfig(p.config)
 	if err != nil {
-		return err
+		return result, err
 	}
 
 	request := client.RESTClient().
@@ -54,13 +80,28 @@ func (p *podExecutor) Execute(ctx context.Context, options PodExecOptions) error
 
 	executor, err := remotecommand.NewSPDYExecutor(p.config, http.MethodPost, request.URL())
 	if err != nil {
-		return fmt.Errorf("failed to initialize the debug executor: %v", err)
+		return result, fmt.Errorf("failed to initialize the debug executor: %v", err)
 	}
 
-	return executor.Stream(remotecommand.StreamOptions{
+	start := time.Now()
+	err = executor.Stream(remotecommand.StreamOptions{
 		Stdin:  strings.NewReader(options.Command),
 		Stdout: options.StdOut,
 		Stderr: options.StdErr,
 		Tty:    false,
 	})
+	result.Duration = time.Since(start)
+	result.Stdout = Truncate(options.StdOut.String())
+	result.Stderr = Truncate(options.StdErr.String())
+
+	if err != nil {
+		if exitErr, ok := err.(utilexec.ExitError); ok && exitErr.Exited() {
+			result.ExitCode = exitErr.ExitStatus()
+			return result, &ExecError{Result: result, Cause: err}
+		}
+		return result, err
+	}
+
+	result.ExitCode = 0
+	return result, nil
 }
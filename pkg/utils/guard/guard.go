@@ -0,0 +1,135 @@
+// Package guard limits the impact of NetworkTrafficShapers on the cluster.
+package guard
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/target"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConfirmAnnotation has to be set to "true" on NetworkTrafficShapers with high-impact targets.
+const ConfirmAnnotation = "networkmachinery.io/confirm"
+
+// Options configure the guards.
+type Options struct {
+	// ProtectedNamespaces are the namespaces whose pods can not be shaped.
+	ProtectedNamespaces []string
+	// MaxPodsPerShaper and MaxPodsInCluster limit the number of pods shaped by a single NetworkTrafficShaper and
+	// all of them, zero disables the limit.
+	MaxPodsPerShaper int
+	MaxPodsInCluster int
+	// KillSwitchNamespace and KillSwitchName name the kill switch ConfigMap, all shaping is reverted as long as
+	// it exists.
+	KillSwitchNamespace string
+	KillSwitchName      string
+}
+
+// DefaultOptions are the default safety guards.
+var DefaultOptions = &Options{
+	ProtectedNamespaces: []string{"kube-system", "kube-public", "kube-node-lease"},
+	MaxPodsPerShaper:    50,
+	MaxPodsInCluster:    200,
+	KillSwitchNamespace: "default",
+	KillSwitchName:      "networkmachinery-kill-switch",
+}
+
+// AddFlags adds the guard flags to <flags>.
+func (o *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.StringSliceVar(&o.ProtectedNamespaces, "shaper-protected-namespaces", o.ProtectedNamespaces, "namespaces whose pods can not be shaped by NetworkTrafficShapers")
+	flags.IntVar(&o.MaxPodsPerShaper, "shaper-max-pods", o.MaxPodsPerShaper, "maximum number of pods shaped by a single NetworkTrafficShaper, 0 disables the limit")
+	flags.IntVar(&o.MaxPodsInCluster, "shaper-max-pods-in-cluster", o.MaxPodsInCluster, "maximum number of pods shaped by all NetworkTrafficShapers, 0 disables the limit")
+	flags.StringVar(&o.KillSwitchNamespace, "shaper-kill-switch-namespace", o.KillSwitchNamespace, "namespace of the kill switch ConfigMap")
+	flags.StringVar(&o.KillSwitchName, "shaper-kill-switch-name", o.KillSwitchName, "name of the ConfigMap which reverts all traffic shaping as long as it exists")
+}
+
+// Validate returns the guard violations of <networkTrafficShaper> which can be detected from its spec, i.e.
+// targets in protected namespaces, unconfirmed high-impact targets and fixed selections above the pod limit.
+func (o *Options) Validate(networkTrafficShaper *v1alpha1.NetworkTrafficShaper) field.ErrorList {
+	var (
+		allErrs     field.ErrorList
+		targetsPath = field.NewPath("spec", "targets")
+		confirmed   = Confirmed(networkTrafficShaper)
+	)
+	for i, shaperTarget := range networkTrafficShaper.Spec.Targets {
+		targetPath := targetsPath.Index(i)
+		if shaperTarget.Kind != v1alpha1.Node && o.protected(shaperTarget.Namespace) {
+			allErrs = append(allErrs, field.Forbidden(targetPath.Child("namespace"), fmt.Sprintf("pods in the protected namespace %s can not be shaped", shaperTarget.Namespace)))
+		}
+		if impact := HighImpact(shaperTarget); len(impact) > 0 && !confirmed {
+			allErrs = append(allErrs, field.Forbidden(targetPath, fmt.Sprintf("the target %s, the %s annotation has to be set to \"true\" to confirm it", impact, ConfirmAnnotation)))
+		}
+		if shaperTarget.Mode == v1alpha1.SelectionModeFixed && o.MaxPodsPerShaper > 0 {
+			if selection, err := target.ParseSelection(shaperTarget.Mode, shaperTarget.Value); err == nil && selection.Count(math.MaxInt32) > o.MaxPodsPerShaper {
+				allErrs = append(allErrs, field.Forbidden(targetPath.Child("value"), fmt.Sprintf("at most %d pods can be shaped by a NetworkTrafficShaper", o.MaxPodsPerShaper)))
+			}
+		}
+	}
+	return allErrs
+}
+
+// HighImpact returns why <shaperTarget> affects more than the traffic of its pods, it is empty if it does not.
+func HighImpact(shaperTarget v1alpha1.ShaperTarget) string {
+	switch {
+	case shaperTarget.Kind == v1alpha1.Node:
+		return "shapes the traffic of whole nodes"
+	case shaperTarget.AllowHostNetwork:
+		return "may shape pods in the host network, i.e. the traffic of whole nodes"
+	case shaperTarget.Kind == v1alpha1.Selector && shaperTarget.SourceSelector != nil &&
+		len(shaperTarget.SourceSelector.MatchLabels) == 0 && len(shaperTarget.SourceSelector.MatchExpressions) == 0:
+		return "selects all pods of the namespace"
+	}
+	return ""
+}
+
+// Confirmed returns true if high-impact targets of <obj> are confirmed by the ConfirmAnnotation.
+func Confirmed(obj metav1.Object) bool {
+	return strings.EqualFold(obj.GetAnnotations()[ConfirmAnnotation], "true")
+}
+
+// CheckPods returns an error if <shaped> pods exceed the limit of a single NetworkTrafficShaper, or if they
+// exceed the cluster-wide limit together with the <others> shaped by the other NetworkTrafficShapers.
+func (o *Options) CheckPods(shaped, others int) error {
+	if o.MaxPodsPerShaper > 0 && shaped > o.MaxPodsPerShaper {
+		return fmt.Errorf("%d pods would be shaped, at most %d pods can be shaped by a NetworkTrafficShaper", shaped, o.MaxPodsPerShaper)
+	}
+	if o.MaxPodsInCluster > 0 && shaped+others > o.MaxPodsInCluster {
+		return fmt.Errorf("%d pods would be shaped in addition to the %d pods shaped by other NetworkTrafficShapers, at most %d pods can be shaped in the cluster",
+			shaped, others, o.MaxPodsInCluster)
+	}
+	return nil
+}
+
+// KillSwitchEngaged returns true if the kill switch ConfigMap exists.
+func (o *Options) KillSwitchEngaged(ctx context.Context, c client.Reader) (bool, error) {
+	if err := c.Get(ctx, client.ObjectKey{Namespace: o.KillSwitchNamespace, Name: o.KillSwitchName}, &corev1.ConfigMap{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// IsKillSwitch returns true if <obj> is the kill switch ConfigMap.
+func (o *Options) IsKillSwitch(obj metav1.Object) bool {
+	return obj.GetNamespace() == o.KillSwitchNamespace && obj.GetName() == o.KillSwitchName
+}
+
+func (o *Options) protected(namespace string) bool {
+	for _, protected := range o.ProtectedNamespaces {
+		if protected == namespace {
+			return true
+		}
+	}
+	return false
+}
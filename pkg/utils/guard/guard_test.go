@@ -0,0 +1,81 @@
+package guard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidate(t *testing.T) {
+	options := &Options{ProtectedNamespaces: []string{"kube-system"}, MaxPodsPerShaper: 10}
+	shaper := func(confirmed bool, targets ...v1alpha1.ShaperTarget) *v1alpha1.NetworkTrafficShaper {
+		networkTrafficShaper := &v1alpha1.NetworkTrafficShaper{Spec: v1alpha1.NetworkTrafficShaperSpec{Targets: targets}}
+		if confirmed {
+			networkTrafficShaper.Annotations = map[string]string{ConfirmAnnotation: "true"}
+		}
+		return networkTrafficShaper
+	}
+
+	for _, tc := range []struct {
+		name   string
+		shaper *v1alpha1.NetworkTrafficShaper
+		errs   int
+	}{
+		{name: "pod", shaper: shaper(false, v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "web"})},
+		{name: "protected pod", shaper: shaper(true, v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "kube-system", Name: "coredns"}), errs: 1},
+		{name: "selector", shaper: shaper(false, v1alpha1.ShaperTarget{Kind: v1alpha1.Selector, Namespace: "default", SourceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}})},
+		{name: "whole namespace", shaper: shaper(false, v1alpha1.ShaperTarget{Kind: v1alpha1.Selector, Namespace: "default", SourceSelector: &metav1.LabelSelector{}}), errs: 1},
+		{name: "confirmed whole namespace", shaper: shaper(true, v1alpha1.ShaperTarget{Kind: v1alpha1.Selector, Namespace: "default", SourceSelector: &metav1.LabelSelector{}})},
+		{name: "host network", shaper: shaper(false, v1alpha1.ShaperTarget{Kind: v1alpha1.Pod, Namespace: "default", Name: "web", AllowHostNetwork: true}), errs: 1},
+		{name: "node", shaper: shaper(false, v1alpha1.ShaperTarget{Kind: v1alpha1.Node, Name: "worker-1"}), errs: 1},
+		{name: "confirmed node", shaper: shaper(true, v1alpha1.ShaperTarget{Kind: v1alpha1.Node, Name: "worker-1"})},
+		{name: "fixed above limit", shaper: shaper(false, v1alpha1.ShaperTarget{Kind: v1alpha1.Selector, Namespace: "default",
+			SourceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}, Mode: v1alpha1.SelectionModeFixed, Value: "11"}), errs: 1},
+	} {
+		if errs := options.Validate(tc.shaper); len(errs) != tc.errs {
+			t.Errorf("%s: expected %d errors, got %v", tc.name, tc.errs, errs)
+		}
+	}
+}
+
+func TestCheckPods(t *testing.T) {
+	options := &Options{MaxPodsPerShaper: 5, MaxPodsInCluster: 10}
+	for _, tc := range []struct {
+		shaped, others int
+		allowed        bool
+	}{
+		{shaped: 5, others: 5, allowed: true},
+		{shaped: 6, others: 0},
+		{shaped: 5, others: 6},
+	} {
+		if err := options.CheckPods(tc.shaped, tc.others); (err == nil) != tc.allowed {
+			t.Errorf("%d pods besides %d: expected allowed to be %t, got %v", tc.shaped, tc.others, tc.allowed, err)
+		}
+	}
+
+	if err := (&Options{}).CheckPods(1000, 1000); err != nil {
+		t.Errorf("expected disabled limits to allow all pods, got %v", err)
+	}
+}
+
+func TestKillSwitchEngaged(t *testing.T) {
+	options := &Options{KillSwitchNamespace: "default", KillSwitchName: "kill-switch"}
+
+	engaged, err := options.KillSwitchEngaged(context.TODO(), test.NewFakeClient())
+	if err != nil || engaged {
+		t.Errorf("expected the kill switch to be released, got %t, %v", engaged, err)
+	}
+
+	killSwitch := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kill-switch"}}
+	engaged, err = options.KillSwitchEngaged(context.TODO(), test.NewFakeClient(killSwitch))
+	if err != nil || !engaged {
+		t.Errorf("expected the kill switch to be engaged, got %t, %v", engaged, err)
+	}
+	if !options.IsKillSwitch(killSwitch) {
+		t.Error("expected the ConfigMap to be the kill switch")
+	}
+}
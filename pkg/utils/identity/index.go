@@ -0,0 +1,221 @@
+// Package identity maps the addresses of pods, services and nodes to their Kubernetes identity.
+package identity
+
+import (
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+const (
+	// NamespaceGroups are the address groups of namespaces, the group of a namespace holds the addresses of its pods
+	// and services.
+	NamespaceGroups = "networkmachinery-namespaces"
+	// NodeGroups are the address groups of nodes, the group of a node holds its addresses and the ones of its pods.
+	NodeGroups = "networkmachinery-nodes"
+
+	// podTemplateHashLabel is set by the deployment controller on the pods of its ReplicaSets.
+	podTemplateHashLabel = "pod-template-hash"
+)
+
+// Index maps the addresses of pods, services and nodes to their identity. It is kept up to date by the informers it
+// is added to, pods of the host network are not indexed as their addresses are the ones of their node.
+type Index struct {
+	mu sync.RWMutex
+	// addresses are the addresses of an object by its kind, namespace and name
+	addresses map[string][]string
+	// endpoints and owners are the identity of an address and the object it belongs to
+	endpoints map[string]*v1alpha1.Endpoint
+	owners    map[string]string
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{
+		addresses: map[string][]string{},
+		endpoints: map[string]*v1alpha1.Endpoint{},
+		owners:    map[string]string{},
+	}
+}
+
+// AddToInformers registers the index with the pod, service and node informers of <informers>.
+func (i *Index) AddToInformers(informers cache.Informers) error {
+	for _, obj := range []runtime.Object{&corev1.Pod{}, &corev1.Service{}, &corev1.Node{}} {
+		informer, err := informers.GetInformer(obj)
+		if err != nil {
+			return err
+		}
+		informer.AddEventHandler(i)
+	}
+	return nil
+}
+
+// OnAdd implements toolscache.ResourceEventHandler.
+func (i *Index) OnAdd(obj interface{}) {
+	if key, endpoints, ok := endpointsOf(obj); ok {
+		i.set(key, endpoints)
+	}
+}
+
+// OnUpdate implements toolscache.ResourceEventHandler.
+func (i *Index) OnUpdate(_, obj interface{}) {
+	i.OnAdd(obj)
+}
+
+// OnDelete implements toolscache.ResourceEventHandler.
+func (i *Index) OnDelete(obj interface{}) {
+	if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if key, _, ok := endpointsOf(obj); ok {
+		i.set(key, nil)
+	}
+}
+
+// set replaces the endpoints of the object <key>. Addresses which were taken over by another object are kept.
+func (i *Index) set(key string, endpoints []*v1alpha1.Endpoint) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, ip := range i.addresses[key] {
+		if i.owners[ip] == key {
+			delete(i.endpoints, ip)
+			delete(i.owners, ip)
+		}
+	}
+	delete(i.addresses, key)
+
+	for _, endpoint := range endpoints {
+		i.endpoints[endpoint.IP] = endpoint
+		i.owners[endpoint.IP] = key
+		i.addresses[key] = append(i.addresses[key], endpoint.IP)
+	}
+}
+
+// Lookup returns the identity of the address <ip>, it is nil if the address is unknown.
+func (i *Index) Lookup(ip string) *v1alpha1.Endpoint {
+	address := net.ParseIP(ip)
+	if address == nil {
+		return nil
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.endpoints[address.String()].DeepCopy()
+}
+
+// Groups returns the address groups <name>, i.e. NamespaceGroups or NodeGroups, as they are defined in sFlow-RT.
+func (i *Index) Groups(name string) sflowrt.Groups {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	groups := sflowrt.Groups{}
+	for ip, endpoint := range i.endpoints {
+		var group string
+		switch {
+		case name == NamespaceGroups && endpoint.Kind != "Node":
+			group = endpoint.Namespace
+		case name == NodeGroups && endpoint.Kind != "Service":
+			group = endpoint.Node
+		}
+		if len(group) == 0 {
+			continue
+		}
+		prefix := "/32"
+		if strings.Contains(ip, ":") {
+			prefix = "/128"
+		}
+		groups[group] = append(groups[group], ip+prefix)
+	}
+	for _, cidrs := range groups {
+		sort.Strings(cidrs)
+	}
+	return groups
+}
+
+// endpointsOf returns the key and the endpoints of a pod, service or node.
+func endpointsOf(obj interface{}) (string, []*v1alpha1.Endpoint, bool) {
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		var ips []string
+		if !o.Spec.HostNetwork && o.Status.Phase != corev1.PodSucceeded && o.Status.Phase != corev1.PodFailed {
+			ips = append(ips, o.Status.PodIP)
+			for _, podIP := range o.Status.PodIPs {
+				ips = append(ips, podIP.IP)
+			}
+		}
+		return "Pod/" + o.Namespace + "/" + o.Name, newEndpoints(ips, v1alpha1.Endpoint{
+			Kind:      "Pod",
+			Name:      o.Name,
+			Namespace: o.Namespace,
+			Workload:  workloadOf(o),
+			Labels:    o.Labels,
+			Node:      o.Spec.NodeName,
+		}), true
+	case *corev1.Service:
+		ips := append([]string{o.Spec.ClusterIP}, o.Spec.ExternalIPs...)
+		for _, ingress := range o.Status.LoadBalancer.Ingress {
+			ips = append(ips, ingress.IP)
+		}
+		return "Service/" + o.Namespace + "/" + o.Name, newEndpoints(ips, v1alpha1.Endpoint{
+			Kind:      "Service",
+			Name:      o.Name,
+			Namespace: o.Namespace,
+			Labels:    o.Labels,
+		}), true
+	case *corev1.Node:
+		var ips []string
+		for _, address := range o.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP || address.Type == corev1.NodeExternalIP {
+				ips = append(ips, address.Address)
+			}
+		}
+		return "Node//" + o.Name, newEndpoints(ips, v1alpha1.Endpoint{
+			Kind:   "Node",
+			Name:   o.Name,
+			Labels: o.Labels,
+			Node:   o.Name,
+		}), true
+	}
+	return "", nil, false
+}
+
+// newEndpoints returns a copy of <endpoint> for each valid address of <ips>.
+func newEndpoints(ips []string, endpoint v1alpha1.Endpoint) []*v1alpha1.Endpoint {
+	var (
+		endpoints []*v1alpha1.Endpoint
+		seen      = map[string]bool{}
+	)
+	for _, ip := range ips {
+		address := net.ParseIP(ip)
+		if address == nil || seen[address.String()] {
+			continue
+		}
+		seen[address.String()] = true
+		e := endpoint.DeepCopy()
+		e.IP = address.String()
+		endpoints = append(endpoints, e)
+	}
+	return endpoints
+}
+
+// workloadOf returns the controller of <pod>, e.g. Deployment/frontend. Pods of a ReplicaSet of a deployment belong to
+// the deployment.
+func workloadOf(pod *corev1.Pod) string {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return ""
+	}
+	if hash, ok := pod.Labels[podTemplateHashLabel]; ok && owner.Kind == "ReplicaSet" && strings.HasSuffix(owner.Name, "-"+hash) {
+		return "Deployment/" + strings.TrimSuffix(owner.Name, "-"+hash)
+	}
+	return owner.Kind + "/" + owner.Name
+}
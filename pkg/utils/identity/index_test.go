@@ -0,0 +1,97 @@
+package identity
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/sflowrt"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+func newPod(name, ip string) *corev1.Pod {
+	controller := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "payments",
+			Name:            name,
+			Labels:          map[string]string{"app": "api", podTemplateHashLabel: "5d8f7"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "api-5d8f7", Controller: &controller}},
+		},
+		Spec:   corev1.PodSpec{NodeName: "worker-1"},
+		Status: corev1.PodStatus{PodIP: ip, PodIPs: []corev1.PodIP{{IP: ip}, {IP: "fd00::1"}}},
+	}
+}
+
+func TestIndex(t *testing.T) {
+	index := NewIndex()
+	index.OnAdd(newPod("api-1", "10.244.1.5"))
+	index.OnAdd(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "api"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.96.0.10"},
+	})
+	index.OnAdd(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "headless"},
+		Spec:       corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone},
+	})
+	index.OnAdd(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1"},
+		Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+			{Type: corev1.NodeInternalIP, Address: "192.168.1.10"},
+			{Type: corev1.NodeHostName, Address: "worker-1"},
+		}},
+	})
+	hostNetwork := newPod("exporter", "192.168.1.10")
+	hostNetwork.Spec.HostNetwork = true
+	index.OnAdd(hostNetwork)
+
+	expected := &v1alpha1.Endpoint{
+		IP:        "10.244.1.5",
+		Kind:      "Pod",
+		Name:      "api-1",
+		Namespace: "payments",
+		Workload:  "Deployment/api",
+		Labels:    map[string]string{"app": "api", podTemplateHashLabel: "5d8f7"},
+		Node:      "worker-1",
+	}
+	if endpoint := index.Lookup("10.244.1.5"); !reflect.DeepEqual(endpoint, expected) {
+		t.Errorf("expected endpoint %+v, got %+v", expected, endpoint)
+	}
+	if endpoint := index.Lookup("fd00:0::1"); endpoint == nil || endpoint.Name != "api-1" {
+		t.Errorf("expected the IPv6 address of the pod, got %+v", endpoint)
+	}
+	if endpoint := index.Lookup("10.96.0.10"); endpoint == nil || endpoint.Kind != "Service" || endpoint.Name != "api" {
+		t.Errorf("expected the service, got %+v", endpoint)
+	}
+	if endpoint := index.Lookup("192.168.1.10"); endpoint == nil || endpoint.Kind != "Node" || endpoint.Node != "worker-1" {
+		t.Errorf("expected the node rather than the host network pod, got %+v", endpoint)
+	}
+	if endpoint := index.Lookup("10.0.0.1"); endpoint != nil {
+		t.Errorf("expected an unknown address, got %+v", endpoint)
+	}
+
+	namespaces := sflowrt.Groups{"payments": {"10.244.1.5/32", "10.96.0.10/32", "fd00::1/128"}}
+	if groups := index.Groups(NamespaceGroups); !reflect.DeepEqual(groups, namespaces) {
+		t.Errorf("expected namespace groups %v, got %v", namespaces, groups)
+	}
+	nodes := sflowrt.Groups{"worker-1": {"10.244.1.5/32", "192.168.1.10/32", "fd00::1/128"}}
+	if groups := index.Groups(NodeGroups); !reflect.DeepEqual(groups, nodes) {
+		t.Errorf("expected node groups %v, got %v", nodes, groups)
+	}
+
+	// the address of a deleted pod is reused before its deletion is observed
+	index.OnAdd(newPod("api-2", "10.244.1.5"))
+	index.OnDelete(toolscache.DeletedFinalStateUnknown{Key: "payments/api-1", Obj: newPod("api-1", "10.244.1.5")})
+	if endpoint := index.Lookup("10.244.1.5"); endpoint == nil || endpoint.Name != "api-2" {
+		t.Errorf("expected the address to belong to the new pod, got %+v", endpoint)
+	}
+
+	completed := newPod("api-2", "10.244.1.5")
+	completed.Status.Phase = corev1.PodSucceeded
+	index.OnUpdate(nil, completed)
+	if endpoint := index.Lookup("10.244.1.5"); endpoint != nil {
+		t.Errorf("expected the addresses of completed pods to be released, got %+v", endpoint)
+	}
+}
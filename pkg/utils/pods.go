@@ -16,7 +16,6 @@ package utils
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
 
@@ -26,7 +25,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func PodExec(ctx context.Context, config *rest.Config, options executor.PodExecOptions) error {
+func PodExec(ctx context.Context, config *rest.Config, options executor.PodExecOptions) (*executor.ExecResult, error) {
 	return executor.NewPodExecutor(config).Execute(ctx, options)
 }
 
@@ -34,34 +33,11 @@ func DebugExec(ctx context.Context, config *rest.Config, options executor.DebugE
 	return executor.NewDebugExecutor(config).Execute(ctx, options)
 }
 
-// getFirstRunningPodWithLabels fetches the first running pod with the desired set of labels <labelsMap>
-func getFirstRunningPodWithLabels(ctx context.Context, labelsMap labels.Selector, namespace string, client client.Client) (*corev1.Pod, error) {
-	var (
-		podList *corev1.PodList
-		err     error
-	)
-	podList, err = GetPodsByLabels(ctx, client, labelsMap, namespace)
-	if err != nil {
-		return nil, err
-	}
-	if len(podList.Items) == 0 {
-		return nil, fmt.Errorf("no running pods found")
-	}
-
-	for _, pod := range podList.Items {
-		if pod.Status.Phase == corev1.PodRunning {
-			return &pod, nil
-		}
-	}
-
-	return nil, fmt.Errorf("no running pods found")
-}
-
 func GetPodsByLabels(ctx context.Context, c client.Client, labelsMap labels.Selector, namespace string) (*corev1.PodList, error) {
 	podList := &corev1.PodList{}
 	err := c.List(ctx, podList, &client.ListOptions{
 		Namespace:     namespace,
-		LabelSelector: labelsMap,	
+		LabelSelector: labelsMap,
 	})
 	if err != nil {
 		return nil, err
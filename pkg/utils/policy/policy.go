@@ -0,0 +1,277 @@
+// Package policy authorizes the execution of network tools in pods against the NetworkExecPolicies of the cluster
+// and the permissions of the user who requested the execution.
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+	"github.com/pkg/errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NodeExecVerb is the verb a requesting user needs on a node to execute tools on it, e.g. to shape its devices.
+// It is not used by Kubernetes itself and has to be granted explicitly by a ClusterRole.
+const NodeExecVerb = "exec"
+
+// RequesterAnnotation holds the JSON encoded user info of the user who created or last changed the spec of an
+// object, it is maintained by the requester admission webhook.
+const RequesterAnnotation = "networkmachinery.io/requested-by"
+
+// RequesterOf returns the user stored in the RequesterAnnotation of <obj>, nil if the annotation is not set.
+func RequesterOf(obj metav1.Object) (*authenticationv1.UserInfo, error) {
+	value, ok := obj.GetAnnotations()[RequesterAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	requester := &authenticationv1.UserInfo{}
+	if err := json.Unmarshal([]byte(value), requester); err != nil {
+		return nil, errors.Wrapf(err, "could not decode annotation %s", RequesterAnnotation)
+	}
+	return requester, nil
+}
+
+// SetRequester stores <requester> in the RequesterAnnotation of <obj>.
+func SetRequester(obj metav1.Object, requester authenticationv1.UserInfo) error {
+	value, err := json.Marshal(requester)
+	if err != nil {
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[RequesterAnnotation] = string(value)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// NewOrigin returns the origin of executions for <obj> of the given kind. A malformed requester annotation is
+// treated like a missing one.
+func NewOrigin(obj metav1.Object, kind string, tool v1alpha1.ExecTool) executor.Origin {
+	requester, _ := RequesterOf(obj)
+	return executor.Origin{
+		Kind:      kind,
+		Name:      obj.GetName(),
+		UID:       obj.GetUID(),
+		Tool:      tool,
+		Requester: requester,
+	}
+}
+
+// DeniedError is returned if an execution in a pod is not authorized.
+type DeniedError struct {
+	Namespace, Name string
+	Reason          string
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("execution in pod %s/%s denied: %s", e.Namespace, e.Name, e.Reason)
+}
+
+// IsDenied returns true if the given error indicates that an execution was not authorized.
+func IsDenied(err error) bool {
+	_, ok := errors.Cause(err).(*DeniedError)
+	return ok
+}
+
+// Executor is an executor.PodExecutor which authorizes every execution before delegating it.
+type Executor struct {
+	delegate executor.PodExecutor
+	client   client.Client
+}
+
+// NewExecutor returns an executor enforcing the NetworkExecPolicies and the permissions of the requesting user
+// carried in the executor.Origin of the context.
+func NewExecutor(delegate executor.PodExecutor, c client.Client) *Executor {
+	return &Executor{
+		delegate: delegate,
+		client:   c,
+	}
+}
+
+// Execute implements executor.PodExecutor.
+func (e *Executor) Execute(ctx context.Context, options executor.PodExecOptions) (*executor.ExecResult, error) {
+	if err := e.Authorize(ctx, options.Namespace, options.Name, options.Node); err != nil {
+		return nil, err
+	}
+	return e.delegate.Execute(ctx, options)
+}
+
+// SupportsEphemeralContainers implements executor.EphemeralContainerSupport.
+func (e *Executor) SupportsEphemeralContainers(ctx context.Context) (bool, error) {
+	return executor.SupportsEphemeralContainers(ctx, e.delegate)
+}
+
+// Authorize returns a DeniedError if tools of the origin in <ctx> may not be executed in the pod
+// <namespace>/<name>. If the pod is the helper pod of <node>, the requester has to be allowed to execute tools on
+// the node instead of exec into the pod. The NetworkExecPolicies apply to undoing mutations as well, the requester
+// is not checked then.
+func (e *Executor) Authorize(ctx context.Context, namespace, name, node string) error {
+	origin, _ := executor.OriginFrom(ctx)
+	denied := func(format string, args ...interface{}) error {
+		return &DeniedError{Namespace: namespace, Name: name, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	policies := &v1alpha1.NetworkExecPolicyList{}
+	if err := e.client.List(ctx, policies); err != nil {
+		return errors.Wrap(err, "could not list NetworkExecPolicies")
+	}
+
+	if len(policies.Items) > 0 {
+		pod := &corev1.Pod{}
+		if err := e.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, pod); err != nil {
+			return err
+		}
+		matcher := &matcher{client: e.client, pod: pod, tool: origin.Tool}
+
+		var allowed bool
+		for _, policy := range policies.Items {
+			if policy.Spec.RequireRequester && origin.Requester == nil {
+				return denied("NetworkExecPolicy %s requires a requesting user but %s %s has none", policy.Name, origin.Kind, origin.Name)
+			}
+			for _, rule := range policy.Spec.Deny {
+				ok, err := matcher.matches(ctx, rule)
+				if err != nil {
+					return err
+				}
+				if ok {
+					return denied("denied by NetworkExecPolicy %s", policy.Name)
+				}
+			}
+			for _, rule := range policy.Spec.Allow {
+				if allowed {
+					break
+				}
+				ok, err := matcher.matches(ctx, rule)
+				if err != nil {
+					return err
+				}
+				allowed = ok
+			}
+		}
+		if !allowed {
+			return denied("no NetworkExecPolicy allows %s tools", origin.Tool)
+		}
+	}
+
+	// the operator has to be able to revert what it did even if the requester lost the permission in the meantime
+	if origin.Requester != nil && !origin.Undo {
+		return e.authorizeRequester(ctx, origin.Requester, namespace, name, node)
+	}
+	return nil
+}
+
+// authorizeRequester makes sure the requesting user may exec into the pod on its own. Node helper pods run in a
+// namespace of the operator, executions in them are authorized with the cluster-scoped NodeExecVerb on the node.
+func (e *Executor) authorizeRequester(ctx context.Context, requester *authenticationv1.UserInfo, namespace, name, node string) error {
+	extra := make(map[string]authorizationv1.ExtraValue, len(requester.Extra))
+	for key, value := range requester.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   requester.Username,
+			Groups: requester.Groups,
+			UID:    requester.UID,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   namespace,
+				Verb:        "create",
+				Resource:    "pods",
+				Subresource: "exec",
+				Name:        name,
+			},
+		},
+	}
+	denial := "may not exec into the pod"
+	if len(node) > 0 {
+		review.Spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+			Verb:     NodeExecVerb,
+			Resource: "nodes",
+			Name:     node,
+		}
+		denial = fmt.Sprintf("may not %s on node %s", NodeExecVerb, node)
+	}
+	if err := e.client.Create(ctx, review); err != nil {
+		return errors.Wrap(err, "failed to create SubjectAccessReview")
+	}
+	if !review.Status.Allowed {
+		reason := fmt.Sprintf("user %s %s", requester.Username, denial)
+		if len(review.Status.Reason) > 0 {
+			reason = fmt.Sprintf("%s: %s", reason, review.Status.Reason)
+		}
+		return &DeniedError{Namespace: namespace, Name: name, Reason: reason}
+	}
+	return nil
+}
+
+// matcher matches policy rules against a pod, the namespace of the pod is only fetched if a rule selects
+// namespaces by labels.
+type matcher struct {
+	client    client.Client
+	pod       *corev1.Pod
+	tool      v1alpha1.ExecTool
+	namespace *corev1.Namespace
+}
+
+func (m *matcher) matches(ctx context.Context, rule v1alpha1.ExecPolicyRule) (bool, error) {
+	if len(rule.Tools) > 0 && !containsTool(rule.Tools, m.tool) {
+		return false, nil
+	}
+	if len(rule.Namespaces) > 0 && !containsString(rule.Namespaces, m.pod.Namespace) {
+		return false, nil
+	}
+	if rule.NamespaceSelector != nil {
+		if m.namespace == nil {
+			namespace := &corev1.Namespace{}
+			if err := m.client.Get(ctx, client.ObjectKey{Name: m.pod.Namespace}, namespace); err != nil {
+				return false, err
+			}
+			m.namespace = namespace
+		}
+		ok, err := selects(rule.NamespaceSelector, m.namespace.Labels)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	if rule.PodSelector != nil {
+		return selects(rule.PodSelector, m.pod.Labels)
+	}
+	return true, nil
+}
+
+func selects(selector *metav1.LabelSelector, set map[string]string) (bool, error) {
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false, err
+	}
+	return labelSelector.Matches(labels.Set(set)), nil
+}
+
+func containsTool(tools []v1alpha1.ExecTool, tool v1alpha1.ExecTool) bool {
+	for _, t := range tools {
+		if t == tool {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor/fake"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/test"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newPolicy(name string, spec v1alpha1.NetworkExecPolicySpec) *v1alpha1.NetworkExecPolicy {
+	return &v1alpha1.NetworkExecPolicy{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+}
+
+func TestAuthorize(t *testing.T) {
+	var (
+		web     = test.NewRunningPod("shop", "web", map[string]string{"app": "web"})
+		db      = test.NewRunningPod("shop", "db", map[string]string{"app": "db"})
+		system  = test.NewRunningPod("kube-system", "dns", nil)
+		shop    = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: map[string]string{"team": "shop"}}}
+		alice   = &authenticationv1.UserInfo{Username: "alice"}
+		mallory = &authenticationv1.UserInfo{Username: "mallory"}
+	)
+
+	tests := []struct {
+		name      string
+		policies  []runtime.Object
+		pod       *corev1.Pod
+		tool      v1alpha1.ExecTool
+		requester *authenticationv1.UserInfo
+		denied    bool
+	}{
+		{name: "no policy", pod: system, tool: v1alpha1.ExecToolMutation},
+		{
+			name:     "allowed namespace",
+			policies: []runtime.Object{newPolicy("p", v1alpha1.NetworkExecPolicySpec{Allow: []v1alpha1.ExecPolicyRule{{Namespaces: []string{"shop"}}}})},
+			pod:      web,
+			tool:     v1alpha1.ExecToolProbe,
+		},
+		{
+			name:     "namespace not allowed",
+			policies: []runtime.Object{newPolicy("p", v1alpha1.NetworkExecPolicySpec{Allow: []v1alpha1.ExecPolicyRule{{Namespaces: []string{"shop"}}}})},
+			pod:      system,
+			tool:     v1alpha1.ExecToolProbe,
+			denied:   true,
+		},
+		{
+			name: "tool not allowed",
+			policies: []runtime.Object{newPolicy("p", v1alpha1.NetworkExecPolicySpec{Allow: []v1alpha1.ExecPolicyRule{
+				{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "shop"}}, Tools: []v1alpha1.ExecTool{v1alpha1.ExecToolProbe}},
+			}})},
+			pod:    web,
+			tool:   v1alpha1.ExecToolMutation,
+			denied: true,
+		},
+		{
+			name: "deny rule of other policy",
+			policies: []runtime.Object{
+				newPolicy("allow", v1alpha1.NetworkExecPolicySpec{Allow: []v1alpha1.ExecPolicyRule{{Namespaces: []string{"shop"}}}}),
+				newPolicy("deny", v1alpha1.NetworkExecPolicySpec{Deny: []v1alpha1.ExecPolicyRule{{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}}}}),
+			},
+			pod:    db,
+			tool:   v1alpha1.ExecToolProbe,
+			denied: true,
+		},
+		{
+			name:     "requester required",
+			policies: []runtime.Object{newPolicy("p", v1alpha1.NetworkExecPolicySpec{Allow: []v1alpha1.ExecPolicyRule{{}}, RequireRequester: true})},
+			pod:      web,
+			tool:     v1alpha1.ExecToolProbe,
+			denied:   true,
+		},
+		{name: "requester allowed", pod: web, tool: v1alpha1.ExecToolProbe, requester: alice},
+		{name: "requester denied", pod: web, tool: v1alpha1.ExecToolProbe, requester: mallory, denied: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := test.NewFakeClient(append(tt.policies, web, db, system, shop)...)
+			c.DeniedUsers = map[string]string{"mallory": "RBAC: access denied"}
+			podExecutor := fake.NewExecutor().On(`^ping `, fake.Succeeded())
+			ctx := executor.WithOrigin(context.TODO(), executor.Origin{Kind: "Test", Name: "test", Tool: tt.tool, Requester: tt.requester})
+
+			_, err := NewExecutor(podExecutor, c).Execute(ctx, executor.PodExecOptions{Namespace: tt.pod.Namespace, Name: tt.pod.Name, Command: "ping 10.0.0.1"})
+			if tt.denied {
+				if !IsDenied(err) {
+					t.Fatalf("expected execution to be denied, got %v", err)
+				}
+				if commands := podExecutor.Commands(); len(commands) != 0 {
+					t.Errorf("expected no commands to be executed, got %v", commands)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected execution to be allowed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthorizeNode(t *testing.T) {
+	var (
+		helper = test.NewRunningPod("networkmachinery-node-helpers", "networkmachinery-node-helper-worker-1", nil)
+		c      = test.NewFakeClient(helper)
+		ctx    = executor.WithOrigin(context.TODO(), executor.Origin{Kind: "Test", Name: "test", Tool: v1alpha1.ExecToolMutation, Requester: &authenticationv1.UserInfo{Username: "alice"}})
+	)
+
+	if err := NewExecutor(fake.NewExecutor(), c).Authorize(ctx, helper.Namespace, helper.Name, "worker-1"); err != nil {
+		t.Fatalf("expected execution to be allowed, got %v", err)
+	}
+	if len(c.Reviews) != 1 {
+		t.Fatalf("expected one SubjectAccessReview, got %d", len(c.Reviews))
+	}
+	// the namespace of the helper pod is not used to authorize the requester
+	if attributes := c.Reviews[0].ResourceAttributes; attributes.Namespace != "" || attributes.Resource != "nodes" || attributes.Name != "worker-1" || attributes.Verb != NodeExecVerb {
+		t.Errorf("expected the requester to be authorized for the node, got %+v", attributes)
+	}
+
+	c.DeniedUsers = map[string]string{"alice": "RBAC: access denied"}
+	if err := NewExecutor(fake.NewExecutor(), c).Authorize(ctx, helper.Namespace, helper.Name, "worker-1"); !IsDenied(err) {
+		t.Errorf("expected execution to be denied, got %v", err)
+	}
+}
+
+func TestRequester(t *testing.T) {
+	obj := &v1alpha1.NetworkTrafficShaper{ObjectMeta: metav1.ObjectMeta{Name: "shaper"}}
+	if origin := NewOrigin(obj, "NetworkTrafficShaper", v1alpha1.ExecToolMutation); origin.Requester != nil {
+		t.Errorf("expected no requester, got %+v", origin.Requester)
+	}
+
+	if err := SetRequester(obj, authenticationv1.UserInfo{Username: "alice", Groups: []string{"devs"}}); err != nil {
+		t.Fatal(err)
+	}
+	origin := NewOrigin(obj, "NetworkTrafficShaper", v1alpha1.ExecToolMutation)
+	if origin.Requester == nil || origin.Requester.Username != "alice" || len(origin.Requester.Groups) != 1 {
+		t.Errorf("unexpected requester %+v", origin.Requester)
+	}
+}
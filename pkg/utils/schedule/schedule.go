@@ -0,0 +1,199 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearch bounds the search for activations, a schedule like "0 0 30 2 *" never activates.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// Schedule is a parsed cron expression of the five fields minute, hour, day of month, month and day of week.
+// Activations are computed in UTC.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domRestricted and dowRestricted are set if the field is not "*", a day matches either field then.
+	domRestricted, dowRestricted bool
+}
+
+type bounds struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	minutes = bounds{0, 59, nil}
+	hours   = bounds{0, 23, nil}
+	doms    = bounds{1, 31, nil}
+	months  = bounds{1, 12, map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is accepted for sunday as well
+	dows = bounds{0, 7, map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron expression like "*/15 9-17 * * mon-fri" or a descriptor like "@hourly". Fields are lists
+// of values, ranges and steps, months and days of week can be given by their three letter names.
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expression, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = expression
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q, expected 5 fields (minute, hour, day of month, month, day of week) but got %d", spec, len(fields))
+	}
+
+	var (
+		schedule = &Schedule{
+			domRestricted: fields[2] != "*" && fields[2] != "?",
+			dowRestricted: fields[4] != "*" && fields[4] != "?",
+		}
+		err error
+	)
+	for i, f := range []struct {
+		bits   *uint64
+		bounds bounds
+		name   string
+	}{
+		{&schedule.minute, minutes, "minute"},
+		{&schedule.hour, hours, "hour"},
+		{&schedule.dom, doms, "day of month"},
+		{&schedule.month, months, "month"},
+		{&schedule.dow, dows, "day of week"},
+	} {
+		if *f.bits, err = parseField(fields[i], f.bounds); err != nil {
+			return nil, fmt.Errorf("invalid %s in schedule %q: %v", f.name, spec, err)
+		}
+	}
+	// sunday is either 0 or 7
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	return schedule, nil
+}
+
+// parseField parses a comma separated list of values, ranges and steps into a bit set.
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, expression := range strings.Split(field, ",") {
+		var (
+			rangeAndStep = strings.SplitN(expression, "/", 2)
+			low, high    = b.min, b.max
+			step         = uint(1)
+			err          error
+		)
+		switch lowAndHigh := strings.SplitN(rangeAndStep[0], "-", 2); {
+		case rangeAndStep[0] == "*" || rangeAndStep[0] == "?":
+		case len(lowAndHigh) == 2:
+			if low, err = parseValue(lowAndHigh[0], b); err != nil {
+				return 0, err
+			}
+			if high, err = parseValue(lowAndHigh[1], b); err != nil {
+				return 0, err
+			}
+		default:
+			if low, err = parseValue(lowAndHigh[0], b); err != nil {
+				return 0, err
+			}
+			high = low
+			// "5/15" is a shorthand for "5-<max>/15"
+			if len(rangeAndStep) == 2 {
+				high = b.max
+			}
+		}
+		if len(rangeAndStep) == 2 {
+			value, err := strconv.ParseUint(rangeAndStep[1], 10, 8)
+			if err != nil || value == 0 {
+				return 0, fmt.Errorf("invalid step %q", rangeAndStep[1])
+			}
+			step = uint(value)
+		}
+		if low > high {
+			return 0, fmt.Errorf("invalid range %q, %d is greater than %d", rangeAndStep[0], low, high)
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(value string, b bounds) (uint, error) {
+	if number, ok := b.names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.ParseUint(value, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	if uint(number) < b.min || uint(number) > b.max {
+		return 0, fmt.Errorf("value %d is out of range [%d, %d]", number, b.min, b.max)
+	}
+	return uint(number), nil
+}
+
+// Next returns the first activation after <t>, the zero time if the schedule does not activate within the next
+// five years.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Last returns the latest activation at or before <t> whose window of <length> has not ended at <t>, i.e. the
+// start of the window <t> is in. The returned bool is false if <t> is in no window.
+func (s *Schedule) Last(t time.Time, length time.Duration) (time.Time, bool) {
+	// Next returns activations strictly after the given time, activations at the very start of the window end at <t>
+	start := s.Next(t.Add(-length))
+	if start.IsZero() || start.After(t) {
+		return time.Time{}, false
+	}
+	for {
+		next := s.Next(start)
+		if next.IsZero() || next.After(t) {
+			return start, true
+		}
+		start = next
+	}
+}
+
+// dayMatches returns true if the day of <t> matches the day of month and day of week. If both are restricted, a
+// day matching either of them matches.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
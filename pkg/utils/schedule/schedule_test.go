@@ -0,0 +1,95 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func date(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNext(t *testing.T) {
+	for _, tc := range []struct {
+		spec, after, next string
+	}{
+		{"* * * * *", "2019-10-01 10:00", "2019-10-01 10:01"},
+		{"*/15 * * * *", "2019-10-01 10:07", "2019-10-01 10:15"},
+		{"5/20 * * * *", "2019-10-01 10:30", "2019-10-01 10:45"},
+		{"0 9-17 * * mon-fri", "2019-10-04 17:00", "2019-10-07 09:00"},
+		{"30 2 1 * *", "2019-10-01 02:30", "2019-11-01 02:30"},
+		{"0 0 * * 7", "2019-10-01 00:00", "2019-10-06 00:00"},
+		{"0 0 29 feb *", "2019-03-01 00:00", "2020-02-29 00:00"},
+		{"@hourly", "2019-12-31 23:59", "2020-01-01 00:00"},
+		// either the day of month or the day of week has to match if both are restricted
+		{"0 0 13 * fri", "2019-10-01 00:00", "2019-10-04 00:00"},
+		{"0 0 30 2 *", "2019-10-01 00:00", ""},
+	} {
+		schedule, err := Parse(tc.spec)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.spec, err)
+		}
+		next := schedule.Next(date(tc.after))
+		if tc.next == "" {
+			if !next.IsZero() {
+				t.Errorf("%q: expected no activation but got %v", tc.spec, next)
+			}
+			continue
+		}
+		if expected := date(tc.next); !next.Equal(expected) {
+			t.Errorf("%q: expected next activation after %s at %v but got %v", tc.spec, tc.after, expected, next)
+		}
+	}
+}
+
+func TestLast(t *testing.T) {
+	schedule, err := Parse("0 * * * *")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tc := range []struct {
+		at     string
+		length time.Duration
+		start  string
+	}{
+		{"2019-10-01 10:00", 10 * time.Minute, "2019-10-01 10:00"},
+		{"2019-10-01 10:09", 10 * time.Minute, "2019-10-01 10:00"},
+		{"2019-10-01 10:10", 10 * time.Minute, ""},
+		{"2019-10-01 10:30", 10 * time.Minute, ""},
+		// overlapping windows, the latest one is returned
+		{"2019-10-01 11:30", 2 * time.Hour, "2019-10-01 11:00"},
+	} {
+		start, ok := schedule.Last(date(tc.at), tc.length)
+		if tc.start == "" {
+			if ok {
+				t.Errorf("%s: expected no window but got one starting at %v", tc.at, start)
+			}
+			continue
+		}
+		if expected := date(tc.start); !ok || !start.Equal(expected) {
+			t.Errorf("%s: expected window starting at %v but got %v", tc.at, expected, start)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * foo *",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"@often",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package target
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// NodeHelperLabel is set on node helper pods, its value is the name of the node.
+	NodeHelperLabel = "networkmachinery.io/node-helper"
+	// NodeHelperContainer is the container of node helper pods commands are executed in.
+	NodeHelperContainer = "helper"
+)
+
+// NodeHelperOptions configure the privileged host network pods commands affecting a node are executed in. The
+// namespace of the helper pods should be dedicated to them, nobody but the operator should be able to create pods
+// in it or exec into them.
+type NodeHelperOptions struct {
+	Namespace string
+	Image     string
+}
+
+// DefaultNodeHelperOptions are the default node helper options.
+var DefaultNodeHelperOptions = &NodeHelperOptions{
+	Namespace: "networkmachinery-node-helpers",
+	Image:     "nicolaka/netshoot",
+}
+
+// AddFlags adds the node helper flags to <flags>.
+func (o *NodeHelperOptions) AddFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&o.Namespace, "node-helper-namespace", o.Namespace, "namespace of the privileged host network pods commands affecting a node are executed in")
+	flags.StringVar(&o.Image, "node-helper-image", o.Image, "image of the node helper pods, it has to provide the tools executed on nodes, e.g. tc and ip")
+}
+
+// IsNodeHelper returns true if <pod> is the helper pod of <node>.
+func (o *NodeHelperOptions) IsNodeHelper(pod *corev1.Pod, node string) bool {
+	return pod.Namespace == o.Namespace && pod.Name == NodeHelperName(node) && pod.Labels[NodeHelperLabel] == node
+}
+
+// NodeHelperName returns the name of the helper pod of <node>.
+func NodeHelperName(node string) string {
+	return "networkmachinery-node-helper-" + node
+}
+
+// Node resolves the target for the helper pod of <node>. The helper pod is created if it does not exist yet and
+// <owner> is added to its owners, it is garbage collected once all owners are gone. The helper pod is not
+// eligible until it is ready. Executions in the helper pod are authorized for the node, not for the pod.
+func (r *Resolver) Node(ctx context.Context, node string, owner metav1.OwnerReference, options Options) (*Target, error) {
+	helper, err := r.ensureNodeHelper(ctx, node, owner)
+	if err != nil {
+		return nil, err
+	}
+	options.Container = NodeHelperContainer
+	options.AllowHostNetwork = true
+	options.Node = node
+	return r.Resolve(ctx, helper, options)
+}
+
+func (r *Resolver) ensureNodeHelper(ctx context.Context, node string, owner metav1.OwnerReference) (*corev1.Pod, error) {
+	helper := &corev1.Pod{}
+	err := r.client.Get(ctx, client.ObjectKey{Namespace: r.nodeHelperOptions.Namespace, Name: NodeHelperName(node)}, helper)
+	if apierrors.IsNotFound(err) {
+		helper = r.nodeHelperOptions.newNodeHelper(node, owner)
+		if err := r.client.Create(ctx, helper); err != nil {
+			return nil, fmt.Errorf("could not create the helper pod of node %s: %v", node, err)
+		}
+		return helper, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ownerReference := range helper.OwnerReferences {
+		if ownerReference.UID == owner.UID {
+			return helper, nil
+		}
+	}
+	helper.OwnerReferences = append(helper.OwnerReferences, owner)
+	if err := r.client.Update(ctx, helper); err != nil {
+		return nil, fmt.Errorf("could not add the owner of the helper pod of node %s: %v", node, err)
+	}
+	return helper, nil
+}
+
+func (o *NodeHelperOptions) newNodeHelper(node string, owner metav1.OwnerReference) *corev1.Pod {
+	privileged := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       o.Namespace,
+			Name:            NodeHelperName(node),
+			Labels:          map[string]string{NodeHelperLabel: node},
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:    node,
+			HostNetwork: true,
+			// the helper has to run on tainted nodes as well
+			Tolerations:   []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+			RestartPolicy: corev1.RestartPolicyAlways,
+			Containers: []corev1.Container{{
+				Name:            NodeHelperContainer,
+				Image:           o.Image,
+				ImagePullPolicy: corev1.PullIfNotPresent,
+				Command:         []string{"sleep", "infinity"},
+				SecurityContext: &corev1.SecurityContext{Privileged: &privileged},
+			}},
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package target
+
+import (
+	"context"
+	"testing"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor/fake"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var testNodeHelperOptions = &NodeHelperOptions{Namespace: "node-helpers", Image: "helper:test"}
+
+func TestNode(t *testing.T) {
+	var (
+		c        = test.NewFakeClient()
+		resolver = NewResolver(c, fake.NewExecutor(), testNodeHelperOptions)
+		first    = metav1.OwnerReference{APIVersion: "networkmachinery.io/v1alpha1", Kind: "NetworkTrafficShaper", Name: "first", UID: "1"}
+		second   = metav1.OwnerReference{APIVersion: "networkmachinery.io/v1alpha1", Kind: "NetworkTrafficShaper", Name: "second", UID: "2"}
+		key      = client.ObjectKey{Namespace: testNodeHelperOptions.Namespace, Name: NodeHelperName("worker-1")}
+	)
+
+	// the created helper is not running yet
+	if _, err := resolver.Node(context.TODO(), "worker-1", first, Options{}); !IsNotEligible(err) {
+		t.Fatalf("expected the new helper not to be eligible, got %v", err)
+	}
+	helper := &corev1.Pod{}
+	if err := c.Get(context.TODO(), key, helper); err != nil {
+		t.Fatalf("expected the helper to be created: %v", err)
+	}
+	container := helper.Spec.Containers[0]
+	if helper.Spec.NodeName != "worker-1" || !helper.Spec.HostNetwork || container.SecurityContext == nil || !*container.SecurityContext.Privileged {
+		t.Errorf("expected a privileged host network helper on worker-1, got %+v", helper.Spec)
+	}
+	if container.Image != testNodeHelperOptions.Image {
+		t.Errorf("expected the helper to run image %s, got %s", testNodeHelperOptions.Image, container.Image)
+	}
+
+	running := test.NewRunningPod(helper.Namespace, helper.Name, helper.Labels, NodeHelperContainer)
+	helper.Status = running.Status
+	if err := c.Update(context.TODO(), helper); err != nil {
+		t.Fatal(err)
+	}
+	target, err := resolver.Node(context.TODO(), "worker-1", second, Options{Binaries: []string{"tc"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Container != NodeHelperContainer {
+		t.Errorf("expected container %q, got %q", NodeHelperContainer, target.Container)
+	}
+	if err := c.Get(context.TODO(), key, helper); err != nil {
+		t.Fatal(err)
+	}
+	if len(helper.OwnerReferences) != 2 {
+		t.Errorf("expected the helper to be owned by both shapers, got %v", helper.OwnerReferences)
+	}
+}
+
+func TestResolveNodeHelper(t *testing.T) {
+	var (
+		resolver = NewResolver(test.NewFakeClient(), fake.NewExecutor(), testNodeHelperOptions)
+		labels   = map[string]string{NodeHelperLabel: "worker-1"}
+		helper   = test.NewRunningPod(testNodeHelperOptions.Namespace, NodeHelperName("worker-1"), labels, NodeHelperContainer)
+		spoofed  = test.NewRunningPod("default", NodeHelperName("worker-1"), labels, NodeHelperContainer)
+		options  = Options{Container: NodeHelperContainer, Node: "worker-1"}
+	)
+
+	target, err := resolver.Resolve(context.TODO(), helper, options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target.Node != "worker-1" || target.ExecOptions("tc").Node != "worker-1" {
+		t.Errorf("expected the target to execute on node worker-1, got %q", target.Node)
+	}
+	// only pods in the namespace of the node helpers are authorized for nodes
+	if _, err := resolver.Resolve(context.TODO(), spoofed, options); !IsNotEligible(err) {
+		t.Errorf("expected a labelled pod outside of the helper namespace not to be eligible, got %v", err)
+	}
+}
+
+func TestNodeSkipsEphemeralContainers(t *testing.T) {
+	var (
+		labels      = map[string]string{NodeHelperLabel: "worker-1"}
+		helper      = test.NewRunningPod(testNodeHelperOptions.Namespace, NodeHelperName("worker-1"), labels, NodeHelperContainer)
+		podExecutor = fake.NewExecutor().OnContainer(helper.Namespace+"/"+helper.Name, NodeHelperContainer, "^tc qdisc show$", fake.Succeeded())
+		resolver    = NewResolver(test.NewFakeClient(helper), podExecutor, testNodeHelperOptions)
+		owner       = metav1.OwnerReference{APIVersion: "networkmachinery.io/v1alpha1", Kind: "NetworkTrafficShaper", Name: "shaper", UID: "1"}
+	)
+	// the cluster supports ephemeral containers, but the debug container lacks the privileges of the helper
+	podExecutor.EphemeralContainers = true
+
+	target, err := resolver.Node(context.TODO(), "worker-1", owner, Options{Binaries: []string{"tc"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	options := target.ExecOptions("tc qdisc show")
+	if options.Container != NodeHelperContainer || options.Strategy != executor.StrategyExec {
+		t.Errorf("expected to exec in container %q, got container %q reached by %s", NodeHelperContainer, options.Container, options.Strategy)
+	}
+	if result, err := podExecutor.Execute(context.TODO(), options); err != nil || result.ExitCode != 0 {
+		t.Errorf("expected the command to run in the helper container, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,129 @@
+package target
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+)
+
+// Selection selects a number of pods from the pods matched by a target.
+type Selection struct {
+	mode   v1alpha1.SelectionMode
+	number int
+}
+
+// ParseSelection parses the <mode> of a target and its <value>, the number of pods for the fixed mode or the
+// percentage, e.g. "30%" or "30", for the percent mode.
+func ParseSelection(mode v1alpha1.SelectionMode, value string) (*Selection, error) {
+	selection := &Selection{mode: mode}
+	switch mode {
+	case "", v1alpha1.SelectionModeAll, v1alpha1.SelectionModeOne:
+		if len(value) > 0 {
+			return nil, fmt.Errorf("mode %q does not take a value", mode)
+		}
+	case v1alpha1.SelectionModeFixed:
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 {
+			return nil, fmt.Errorf("invalid value %q, expected a positive number of pods", value)
+		}
+		selection.number = number
+	case v1alpha1.SelectionModePercent:
+		number, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err != nil || number < 1 || number > 100 {
+			return nil, fmt.Errorf("invalid value %q, expected a percentage between 1%% and 100%%", value)
+		}
+		selection.number = number
+	default:
+		return nil, fmt.Errorf("unsupported mode %q", mode)
+	}
+	return selection, nil
+}
+
+// All returns true if all matched pods are selected.
+func (s *Selection) All() bool {
+	return len(s.mode) == 0 || s.mode == v1alpha1.SelectionModeAll
+}
+
+// Count returns the number of pods selected from <matched> pods. Percentages are rounded up, at least one pod is
+// selected if any is matched.
+func (s *Selection) Count(matched int) int {
+	var count int
+	switch s.mode {
+	case v1alpha1.SelectionModeOne:
+		count = 1
+	case v1alpha1.SelectionModeFixed:
+		count = s.number
+	case v1alpha1.SelectionModePercent:
+		count = (matched*s.number + 99) / 100
+	default:
+		count = matched
+	}
+	if count > matched {
+		return matched
+	}
+	return count
+}
+
+// Select selects the targets from <targets>, the eligible pods of the <matched> ones. Pods of <previous> stay
+// selected as long as they are matched, even if they are not eligible at the moment, so that the selection does not
+// change on every reconciliation. Missing pods are selected from the eligible ones in an order derived from <seed>,
+// e.g. the UID of the selecting object. The names of the selected pods are returned sorted.
+func (s *Selection) Select(seed string, previous, matched []string, targets []Target) ([]Target, []string) {
+	var (
+		count    = s.Count(len(matched))
+		selected = map[string]bool{}
+		names    []string
+	)
+	for _, name := range previous {
+		if len(names) < count && contains(matched, name) && !selected[name] {
+			selected[name] = true
+			names = append(names, name)
+		}
+	}
+
+	var candidates []string
+	for _, t := range targets {
+		if !selected[t.Pod.Name] {
+			candidates = append(candidates, t.Pod.Name)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return rank(seed, candidates[i]) < rank(seed, candidates[j])
+	})
+	for _, name := range candidates {
+		if len(names) >= count {
+			break
+		}
+		selected[name] = true
+		names = append(names, name)
+	}
+
+	var selectedTargets []Target
+	for _, t := range targets {
+		if selected[t.Pod.Name] {
+			selectedTargets = append(selectedTargets, t)
+		}
+	}
+	sort.Strings(names)
+	return selectedTargets, names
+}
+
+// rank returns the position of the pod <name> in the selection order of <seed>.
+func rank(seed, name string) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(seed + "/" + name))
+	return hash.Sum64()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
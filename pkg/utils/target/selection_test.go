@@ -0,0 +1,81 @@
+package target
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/test"
+)
+
+func TestSelectionCount(t *testing.T) {
+	for _, tc := range []struct {
+		mode    v1alpha1.SelectionMode
+		value   string
+		matched int
+		count   int
+	}{
+		{mode: "", matched: 5, count: 5},
+		{mode: v1alpha1.SelectionModeAll, matched: 5, count: 5},
+		{mode: v1alpha1.SelectionModeOne, matched: 5, count: 1},
+		{mode: v1alpha1.SelectionModeOne, matched: 0, count: 0},
+		{mode: v1alpha1.SelectionModeFixed, value: "2", matched: 5, count: 2},
+		{mode: v1alpha1.SelectionModeFixed, value: "7", matched: 5, count: 5},
+		{mode: v1alpha1.SelectionModePercent, value: "30%", matched: 10, count: 3},
+		{mode: v1alpha1.SelectionModePercent, value: "30", matched: 2, count: 1},
+		{mode: v1alpha1.SelectionModePercent, value: "100%", matched: 3, count: 3},
+	} {
+		selection, err := ParseSelection(tc.mode, tc.value)
+		if err != nil {
+			t.Fatalf("%s %s: unexpected error: %v", tc.mode, tc.value, err)
+		}
+		if count := selection.Count(tc.matched); count != tc.count {
+			t.Errorf("%s %s: expected %d of %d pods to be selected, got %d", tc.mode, tc.value, tc.count, tc.matched, count)
+		}
+	}
+
+	for _, invalid := range []struct {
+		mode  v1alpha1.SelectionMode
+		value string
+	}{
+		{v1alpha1.SelectionModeAll, "1"},
+		{v1alpha1.SelectionModeFixed, ""},
+		{v1alpha1.SelectionModeFixed, "0"},
+		{v1alpha1.SelectionModePercent, "0%"},
+		{v1alpha1.SelectionModePercent, "101"},
+		{"random", ""},
+	} {
+		if _, err := ParseSelection(invalid.mode, invalid.value); err == nil {
+			t.Errorf("%s %q: expected an error", invalid.mode, invalid.value)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	targets := func(names ...string) []Target {
+		var targets []Target
+		for _, name := range names {
+			targets = append(targets, Target{Pod: test.NewRunningPod("default", name, nil)})
+		}
+		return targets
+	}
+	selection, err := ParseSelection(v1alpha1.SelectionModeFixed, "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	matched := []string{"a", "b", "c", "d"}
+
+	_, first := selection.Select("uid", nil, matched, targets(matched...))
+	if _, again := selection.Select("uid", nil, matched, targets(matched...)); !reflect.DeepEqual(first, again) {
+		t.Errorf("expected the selection to be deterministic, got %v and %v", first, again)
+	}
+
+	// previously selected pods stay selected even if they are not eligible, missing pods are replaced
+	selected, names := selection.Select("uid", []string{"b", "gone"}, matched, targets("a", "c", "d"))
+	if len(names) != 2 || names[0] != "b" && names[1] != "b" {
+		t.Errorf("expected b to stay selected, got %v", names)
+	}
+	if len(selected) != 1 || selected[0].Pod.Name == "b" {
+		t.Errorf("expected only the eligible new pod to be returned, got %v", selected)
+	}
+}
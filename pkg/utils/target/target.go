@@ -0,0 +1,318 @@
+// Package target resolves the pods and containers network tools are executed in.
+package target
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/policy"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ContainerAnnotation can be set on a pod to name the container network tools are executed in.
+const ContainerAnnotation = "networkmachinery.io/container"
+
+// Options configure how targets are resolved.
+type Options struct {
+	// Container is the container configured in the spec, it takes precedence over the ContainerAnnotation.
+	Container string
+	// Binaries are the binaries which need to be available in the chosen container, e.g. "ping" or "tc".
+	// If no container is configured, the first container providing all binaries is chosen.
+	Binaries []string
+	// AllowHostNetwork allows targeting pods in the host network, commands executed in such pods affect the
+	// whole node.
+	AllowHostNetwork bool
+	// AllowNotReady allows targeting running pods which are not ready, e.g. to undo previous changes.
+	AllowNotReady bool
+	// Node is set to resolve the helper pod of a node, executions in it are authorized for the node. The pod has to
+	// be the helper of the node.
+	Node string
+}
+
+// Target is a pod and the container in it commands are executed in.
+type Target struct {
+	Pod       *corev1.Pod
+	Container string
+	// Strategy is the strategy used to reach the container.
+	Strategy executor.Strategy
+	// Node is set if the pod is the helper pod of the node.
+	Node string
+}
+
+// Key returns the <namespace>/<name> of the target pod.
+func (t *Target) Key() string {
+	return t.Pod.Namespace + "/" + t.Pod.Name
+}
+
+// ExecOptions returns the options to execute <command> in the target.
+func (t *Target) ExecOptions(command string) executor.PodExecOptions {
+	return executor.PodExecOptions{
+		Namespace: t.Pod.Namespace,
+		Name:      t.Pod.Name,
+		Container: t.Container,
+		Command:   command,
+		Strategy:  t.Strategy,
+		Node:      t.Node,
+	}
+}
+
+// Skipped is a pod which is not eligible as a target.
+type Skipped struct {
+	Pod    *corev1.Pod
+	Reason error
+}
+
+// NotEligibleError is returned if a pod can not be used as a target.
+type NotEligibleError struct {
+	Namespace, Name string
+	Reason          string
+}
+
+func (e *NotEligibleError) Error() string {
+	return fmt.Sprintf("pod %s/%s is not eligible: %s", e.Namespace, e.Name, e.Reason)
+}
+
+// NoTargetError is returned if no eligible pod matches a selector.
+type NoTargetError struct {
+	Namespace string
+	// Reasons describe why the matching pods are not eligible.
+	Reasons []string
+}
+
+func (e *NoTargetError) Error() string {
+	if len(e.Reasons) == 0 {
+		return fmt.Sprintf("no pods matching the selector found in namespace %s", e.Namespace)
+	}
+	return fmt.Sprintf("no eligible pods matching the selector found in namespace %s: %s", e.Namespace, strings.Join(e.Reasons, "; "))
+}
+
+// IsNotEligible returns true if the given error indicates that no eligible pod could be selected as target.
+func IsNotEligible(err error) bool {
+	switch err.(type) {
+	case *NotEligibleError, *NoTargetError:
+		return true
+	}
+	return false
+}
+
+func notEligible(pod *corev1.Pod, format string, args ...interface{}) error {
+	return &NotEligibleError{Namespace: pod.Namespace, Name: pod.Name, Reason: fmt.Sprintf(format, args...)}
+}
+
+// CheckPod returns a NotEligibleError if commands should not be executed in the given pod, i.e. if it is
+// terminating, not running, not ready or in the host network unless allowed by <options>.
+func CheckPod(pod *corev1.Pod, options Options) error {
+	switch {
+	case pod.DeletionTimestamp != nil:
+		return notEligible(pod, "pod is terminating")
+	case pod.Status.Phase != corev1.PodRunning:
+		return notEligible(pod, "pod is in phase %q", pod.Status.Phase)
+	case !options.AllowNotReady && !utils.IsPodReady(pod):
+		return notEligible(pod, "pod is not ready")
+	case pod.Spec.HostNetwork && !options.AllowHostNetwork:
+		return notEligible(pod, "pod uses the host network")
+	}
+	return nil
+}
+
+// Resolver resolves targets from pods, probing containers for the required binaries if needed.
+type Resolver struct {
+	client            client.Client
+	executor          executor.PodExecutor
+	nodeHelperOptions *NodeHelperOptions
+}
+
+// NewResolver returns a new Resolver, the helper pods of nodes are configured by <nodeHelperOptions>.
+func NewResolver(c client.Client, podExecutor executor.PodExecutor, nodeHelperOptions *NodeHelperOptions) *Resolver {
+	return &Resolver{
+		client:            c,
+		executor:          podExecutor,
+		nodeHelperOptions: nodeHelperOptions,
+	}
+}
+
+// Pod resolves the target for the pod <namespace>/<name>.
+func (r *Resolver) Pod(ctx context.Context, namespace, name string, options Options) (*Target, error) {
+	pod := &corev1.Pod{}
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, pod); err != nil {
+		return nil, err
+	}
+	return r.Resolve(ctx, pod, options)
+}
+
+// Selector resolves the targets for all pods in <namespace> matching <selector>. Pods which are not eligible are
+// returned as skipped.
+func (r *Resolver) Selector(ctx context.Context, namespace string, selector *metav1.LabelSelector, options Options) ([]Target, []Skipped, error) {
+	pods, err := r.list(ctx, namespace, selector)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		targets []Target
+		skipped []Skipped
+	)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		target, err := r.Resolve(ctx, pod, options)
+		if err != nil {
+			if !IsNotEligible(err) {
+				return nil, nil, err
+			}
+			skipped = append(skipped, Skipped{Pod: pod, Reason: err})
+			continue
+		}
+		targets = append(targets, *target)
+	}
+	return targets, skipped, nil
+}
+
+// First resolves the target for the first eligible pod in <namespace> matching <selector>.
+func (r *Resolver) First(ctx context.Context, namespace string, selector *metav1.LabelSelector, options Options) (*Target, error) {
+	pods, err := r.list(ctx, namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var reasons []string
+	for i := range pods.Items {
+		target, err := r.Resolve(ctx, &pods.Items[i], options)
+		if err == nil {
+			return target, nil
+		}
+		if !IsNotEligible(err) {
+			return nil, err
+		}
+		reasons = append(reasons, err.Error())
+	}
+	return nil, &NoTargetError{Namespace: namespace, Reasons: reasons}
+}
+
+// Resolve checks that the given pod is eligible and authorized by the executor and chooses the container to execute commands in.
+func (r *Resolver) Resolve(ctx context.Context, pod *corev1.Pod, options Options) (*Target, error) {
+	if err := CheckPod(pod, options); err != nil {
+		return nil, err
+	}
+	if len(options.Node) > 0 && !r.nodeHelperOptions.IsNodeHelper(pod, options.Node) {
+		return nil, notEligible(pod, "pod is not the helper pod of node %s", options.Node)
+	}
+	if authorizer, ok := r.executor.(executor.Authorizer); ok {
+		if err := authorizer.Authorize(ctx, pod.Namespace, pod.Name, options.Node); err != nil {
+			if policy.IsDenied(err) {
+				return nil, notEligible(pod, "%s", errors.Cause(err).(*policy.DeniedError).Reason)
+			}
+			return nil, err
+		}
+	}
+
+	container, strategy, err := r.container(ctx, pod, options)
+	if err != nil {
+		return nil, err
+	}
+	return &Target{Pod: pod, Container: container, Strategy: strategy, Node: options.Node}, nil
+}
+
+// container chooses the container commands are executed in and the strategy to reach it. An explicitly configured
+// container is always used, otherwise the ephemeral debug container if the executor supports it, or the first
+// running container providing the binaries. Node helpers are always reached with exec, the unprivileged debug
+// container cannot change the network of the node.
+func (r *Resolver) container(ctx context.Context, pod *corev1.Pod, options Options) (string, executor.Strategy, error) {
+	if len(options.Node) > 0 {
+		options.Container = NodeHelperContainer
+	}
+	name := options.Container
+	if len(name) == 0 {
+		name = pod.Annotations[ContainerAnnotation]
+	}
+	if len(name) > 0 {
+		if !isRunning(pod, name) {
+			return "", "", notEligible(pod, "container %q is not running", name)
+		}
+		return name, executor.StrategyExec, nil
+	}
+
+	ephemeral, err := executor.SupportsEphemeralContainers(ctx, r.executor)
+	if err != nil {
+		return "", "", err
+	}
+	if ephemeral {
+		// the debug container provides all network tools
+		return utils.DebugContainerName, executor.StrategyEphemeralContainer, nil
+	}
+
+	for _, container := range pod.Spec.Containers {
+		if !isRunning(pod, container.Name) {
+			continue
+		}
+		if len(options.Binaries) == 0 {
+			return container.Name, executor.StrategyExec, nil
+		}
+		ok, err := r.hasBinaries(ctx, pod, container.Name, options.Binaries)
+		if err != nil {
+			return "", "", err
+		}
+		if ok {
+			return container.Name, executor.StrategyExec, nil
+		}
+	}
+	if len(options.Binaries) == 0 {
+		return "", "", notEligible(pod, "no running container found")
+	}
+	return "", "", notEligible(pod, "no running container providing %s found", strings.Join(options.Binaries, ", "))
+}
+
+// hasBinaries probes whether all <binaries> are available in the given container.
+func (r *Resolver) hasBinaries(ctx context.Context, pod *corev1.Pod, container string, binaries []string) (bool, error) {
+	var probes []string
+	for _, binary := range binaries {
+		probes = append(probes, fmt.Sprintf("command -v %s >/dev/null", binary))
+	}
+
+	_, err := r.executor.Execute(ctx, executor.PodExecOptions{
+		Namespace: pod.Namespace,
+		Name:      pod.Name,
+		Container: container,
+		Command:   strings.Join(probes, " && "),
+		Probe:     true,
+	})
+	if err != nil {
+		if executor.IsExecError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *Resolver) list(ctx context.Context, namespace string, selector *metav1.LabelSelector) (*corev1.PodList, error) {
+	if selector == nil {
+		return nil, fmt.Errorf("no selector given to select pods in namespace %s", namespace)
+	}
+	labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	return utils.GetPodsByLabels(ctx, r.client, labelSelector, namespace)
+}
+
+// isRunning returns true if the container is running according to the pod status. Containers without a status
+// are assumed to be running, the pod phase has already been checked at this point.
+func isRunning(pod *corev1.Pod, container string) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == container {
+			return status.State.Running != nil
+		}
+	}
+	for _, c := range pod.Spec.Containers {
+		if c.Name == container {
+			return true
+		}
+	}
+	return false
+}
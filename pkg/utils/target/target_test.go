@@ -0,0 +1,110 @@
+package target
+
+import (
+	"context"
+	"testing"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/executor/fake"
+	"github.com/networkmachinery/networkmachinery-operators/pkg/utils/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckPod(t *testing.T) {
+	now := metav1.Now()
+	for _, tc := range []struct {
+		name     string
+		mutate   func(*corev1.Pod)
+		options  Options
+		eligible bool
+	}{
+		{name: "running and ready", mutate: func(*corev1.Pod) {}, eligible: true},
+		{name: "terminating", mutate: func(pod *corev1.Pod) { pod.DeletionTimestamp = &now }},
+		{name: "pending", mutate: func(pod *corev1.Pod) { pod.Status.Phase = corev1.PodPending }},
+		{name: "not ready", mutate: func(pod *corev1.Pod) { pod.Status.Conditions = nil }},
+		{name: "not ready allowed", mutate: func(pod *corev1.Pod) { pod.Status.Conditions = nil }, options: Options{AllowNotReady: true}, eligible: true},
+		{name: "host network", mutate: func(pod *corev1.Pod) { pod.Spec.HostNetwork = true }},
+		{name: "host network allowed", mutate: func(pod *corev1.Pod) { pod.Spec.HostNetwork = true }, options: Options{AllowHostNetwork: true}, eligible: true},
+	} {
+		pod := test.NewRunningPod("default", "pod", nil)
+		tc.mutate(pod)
+		err := CheckPod(pod, tc.options)
+		if tc.eligible && err != nil {
+			t.Errorf("%s: expected pod to be eligible, got %v", tc.name, err)
+		}
+		if !tc.eligible && !IsNotEligible(err) {
+			t.Errorf("%s: expected pod not to be eligible, got %v", tc.name, err)
+		}
+	}
+}
+
+func TestResolveContainer(t *testing.T) {
+	pod := test.NewRunningPod("default", "pod", nil, "proxy", "app")
+	podExecutor := fake.NewExecutor().OnContainer("default/pod", "app", `^command -v tc >/dev/null$`, fake.Succeeded())
+	resolver := NewResolver(test.NewFakeClient(), podExecutor, testNodeHelperOptions)
+
+	for _, tc := range []struct {
+		name       string
+		annotation string
+		options    Options
+		container  string
+	}{
+		{name: "spec", options: Options{Container: "proxy", Binaries: []string{"tc"}}, container: "proxy"},
+		{name: "annotation", annotation: "proxy", options: Options{Binaries: []string{"tc"}}, container: "proxy"},
+		{name: "spec over annotation", annotation: "proxy", options: Options{Container: "app"}, container: "app"},
+		{name: "probe", options: Options{Binaries: []string{"tc"}}, container: "app"},
+		{name: "no binaries", options: Options{}, container: "proxy"},
+	} {
+		pod.Annotations = map[string]string{ContainerAnnotation: tc.annotation}
+		target, err := resolver.Resolve(context.TODO(), pod, tc.options)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+			continue
+		}
+		if target.Container != tc.container {
+			t.Errorf("%s: expected container %q, got %q", tc.name, tc.container, target.Container)
+		}
+	}
+
+	if _, err := resolver.Resolve(context.TODO(), pod, Options{Binaries: []string{"ping"}}); !IsNotEligible(err) {
+		t.Errorf("expected pod without ping not to be eligible, got %v", err)
+	}
+}
+
+func TestResolveStrategy(t *testing.T) {
+	pod := test.NewRunningPod("default", "pod", nil, "proxy", "app")
+	podExecutor := fake.NewExecutor()
+	podExecutor.EphemeralContainers = true
+	resolver := NewResolver(test.NewFakeClient(), podExecutor, testNodeHelperOptions)
+
+	for _, tc := range []struct {
+		name       string
+		annotation string
+		options    Options
+		container  string
+		strategy   executor.Strategy
+	}{
+		{name: "spec", options: Options{Container: "app", Binaries: []string{"tc"}}, container: "app", strategy: executor.StrategyExec},
+		{name: "annotation", annotation: "proxy", options: Options{Binaries: []string{"tc"}}, container: "proxy", strategy: executor.StrategyExec},
+		{name: "debug container", options: Options{Binaries: []string{"tc"}}, container: utils.DebugContainerName, strategy: executor.StrategyEphemeralContainer},
+	} {
+		pod.Annotations = map[string]string{ContainerAnnotation: tc.annotation}
+		target, err := resolver.Resolve(context.TODO(), pod, tc.options)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+			continue
+		}
+		if target.Container != tc.container || target.Strategy != tc.strategy {
+			t.Errorf("%s: expected container %q reached by %s, got %q reached by %s", tc.name, tc.container, tc.strategy, target.Container, target.Strategy)
+		}
+		if options := target.ExecOptions("tc"); options.Container != tc.container || options.Strategy != tc.strategy {
+			t.Errorf("%s: expected the exec options to keep the resolved container, got %+v", tc.name, options)
+		}
+	}
+	// the debug container provides the binaries, no container is probed
+	if commands := podExecutor.Commands(); len(commands) != 0 {
+		t.Errorf("expected no probes, got %v", commands)
+	}
+}
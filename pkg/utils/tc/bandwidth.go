@@ -0,0 +1,201 @@
+package tc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// TBF is the token bucket filter qdisc.
+	TBF = "tbf"
+	// HTB is the hierarchical token bucket qdisc.
+	HTB = "htb"
+)
+
+// Bandwidth limits the rate of a device with a token bucket filter or a single htb class.
+type Bandwidth struct {
+	// Algorithm is either TBF or HTB.
+	Algorithm string
+	// Rate and Ceil are given in bits per second, Ceil is only used by htb.
+	Rate uint64
+	Ceil uint64
+	// Burst is the size of the bucket in bytes, htb calculates it if it is zero.
+	Burst uint32
+	// Latency or Limit bound the tbf queue, Limit is given in bytes.
+	Latency time.Duration
+	Limit   uint32
+}
+
+// Class is a traffic class as reported by `tc -j class show`, rates are given in bytes per second.
+type Class struct {
+	Class  string `json:"class"`
+	Handle string `json:"handle"`
+	Parent string `json:"parent,omitempty"`
+	Rate   uint64 `json:"rate"`
+	Ceil   uint64 `json:"ceil"`
+	Burst  uint32 `json:"burst"`
+}
+
+// tbfOptions are the tbf options printed by `tc -j`, the rate is given in bytes per second.
+type tbfOptions struct {
+	Rate  uint64 `json:"rate"`
+	Burst uint32 `json:"burst"`
+}
+
+// String returns the bandwidth limit in tc syntax.
+func (b *Bandwidth) String() string {
+	args := []string{b.Algorithm, "rate", FormatRate(b.Rate)}
+	if b.Algorithm == HTB && b.Ceil > 0 {
+		args = append(args, "ceil", FormatRate(b.Ceil))
+	}
+	if b.Burst > 0 {
+		args = append(args, "burst", FormatSize(b.Burst))
+	}
+	if b.Algorithm == TBF {
+		if b.Limit > 0 {
+			args = append(args, "limit", FormatSize(b.Limit))
+		} else {
+			args = append(args, "latency", formatDuration(b.Latency))
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// commands returns the commands adding or replacing, as given by <verb>, the qdisc at <parent> of <device>. The
+// qdisc gets the <handle>.
+func (b *Bandwidth) commands(device, verb, parent, handle string) []string {
+	if b.Algorithm == TBF {
+		args := []string{"rate", fmt.Sprintf("%dbit", b.Rate), "burst", strconv.FormatUint(uint64(b.Burst), 10)}
+		if b.Limit > 0 {
+			args = append(args, "limit", strconv.FormatUint(uint64(b.Limit), 10))
+		} else {
+			args = append(args, "latency", formatDuration(b.Latency))
+		}
+		return []string{fmt.Sprintf("tc qdisc %s dev %s %s tbf %s", verb, device, qdiscLocation(parent, handle), strings.Join(args, " "))}
+	}
+
+	args := []string{"rate", fmt.Sprintf("%dbit", b.Rate), "ceil", fmt.Sprintf("%dbit", b.ceil())}
+	if b.Burst > 0 {
+		args = append(args, "burst", strconv.FormatUint(uint64(b.Burst), 10))
+	}
+	return []string{
+		fmt.Sprintf("tc qdisc %s dev %s %s htb default 1", verb, device, qdiscLocation(parent, handle)),
+		fmt.Sprintf("tc class %s dev %s parent %s classid %s htb %s", verb, device, handle, defaultClass(handle), strings.Join(args, " ")),
+	}
+}
+
+// matches returns true if <qdisc> and its classes limit the bandwidth like <b>. tc does not report the tbf
+// latency and limit, they are not compared.
+func (b *Bandwidth) matches(qdisc *Qdisc, classes []Class) bool {
+	achieved := achievedBandwidth(qdisc, classes)
+	if achieved == nil || achieved.Algorithm != b.Algorithm || !approximately(float64(achieved.Rate), float64(b.Rate), 8) {
+		return false
+	}
+	if b.Algorithm == HTB && !approximately(float64(achieved.Ceil), float64(b.ceil()), 8) {
+		return false
+	}
+	// the kernel stores the burst as transmission time, it is rounded when converted back
+	return b.Burst == 0 || approximately(float64(achieved.Burst), float64(b.Burst), math.Max(8, float64(b.Burst)/100))
+}
+
+func (b *Bandwidth) ceil() uint64 {
+	if b.Ceil > 0 {
+		return b.Ceil
+	}
+	return b.Rate
+}
+
+// achievedBandwidth returns the bandwidth limit installed by the tbf or htb <qdisc>, nil if it is none.
+func achievedBandwidth(qdisc *Qdisc, classes []Class) *Bandwidth {
+	if qdisc == nil {
+		return nil
+	}
+	switch qdisc.Kind {
+	case TBF:
+		options := &tbfOptions{}
+		if err := json.Unmarshal(qdisc.Options, options); err != nil {
+			return nil
+		}
+		return &Bandwidth{Algorithm: TBF, Rate: options.Rate * 8, Burst: options.Burst}
+	case HTB:
+		for _, class := range classes {
+			if class.Class == HTB && class.Handle == defaultClass(qdisc.Handle) {
+				return &Bandwidth{Algorithm: HTB, Rate: class.Rate * 8, Ceil: class.Ceil * 8, Burst: class.Burst}
+			}
+		}
+	}
+	return nil
+}
+
+// defaultClass returns the class 1 of the qdisc with <handle>, e.g. "1:1" for "1:".
+func defaultClass(handle string) string {
+	return strings.TrimSuffix(handle, ":") + ":1"
+}
+
+func qdiscLocation(parent, handle string) string {
+	location := "root"
+	if len(parent) > 0 {
+		location = "parent " + parent
+	}
+	if len(handle) > 0 {
+		location += " handle " + handle
+	}
+	return location
+}
+
+var sizeUnits = []struct {
+	suffix     string
+	multiplier float64
+}{
+	// longer suffixes first, "kbit" also ends with "bit"
+	{"kbit", 1024 / 8}, {"mbit", 1024 * 1024 / 8}, {"gbit", 1024 * 1024 * 1024 / 8},
+	{"kb", 1024}, {"mb", 1024 * 1024}, {"gb", 1024 * 1024 * 1024},
+	{"k", 1024}, {"m", 1024 * 1024}, {"g", 1024 * 1024 * 1024},
+	{"b", 1}, {"", 1},
+}
+
+// ParseSize parses a size in tc units, e.g. "32kb" or "1500", and returns it in bytes.
+func ParseSize(value string) (uint32, error) {
+	lower := strings.ToLower(strings.TrimSpace(value))
+	for _, unit := range sizeUnits {
+		if !strings.HasSuffix(lower, unit.suffix) {
+			continue
+		}
+		number, err := strconv.ParseFloat(strings.TrimSuffix(lower, unit.suffix), 64)
+		if err != nil || number <= 0 || number*unit.multiplier > math.MaxUint32 {
+			break
+		}
+		return uint32(math.Round(number * unit.multiplier)), nil
+	}
+	return 0, fmt.Errorf("invalid size %q, expected a positive number of bytes or a unit like kb or mb", value)
+}
+
+// FormatRate formats a rate in bits per second in the largest tc unit it is a multiple of, e.g. "10mbit".
+func FormatRate(bits uint64) string {
+	for _, unit := range []struct {
+		suffix string
+		size   uint64
+	}{{"gbit", 1e9}, {"mbit", 1e6}, {"kbit", 1e3}} {
+		if bits >= unit.size && bits%unit.size == 0 {
+			return fmt.Sprintf("%d%s", bits/unit.size, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%dbit", bits)
+}
+
+// FormatSize formats a size in bytes in the largest tc unit it is a multiple of, e.g. "32kb".
+func FormatSize(bytes uint32) string {
+	for _, unit := range []struct {
+		suffix string
+		size   uint32
+	}{{"mb", 1024 * 1024}, {"kb", 1024}} {
+		if bytes >= unit.size && bytes%unit.size == 0 {
+			return fmt.Sprintf("%d%s", bytes/unit.size, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%db", bytes)
+}
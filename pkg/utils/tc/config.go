@@ -0,0 +1,433 @@
+package tc
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// devicePattern matches the names of network devices.
+var devicePattern = regexp.MustCompile(`^[A-Za-z0-9_.:@-]+$`)
+
+var distributions = []string{
+	string(v1alpha1.DistributionUniform),
+	string(v1alpha1.DistributionNormal),
+	string(v1alpha1.DistributionPareto),
+	string(v1alpha1.DistributionParetoNormal),
+}
+
+// ShapingFor validates the shaper configuration at <fldPath> and returns the shaping it describes.
+func ShapingFor(config v1alpha1.ShaperConfiguration, fldPath *field.Path) (*Shaping, field.ErrorList) {
+	var allErrs field.ErrorList
+	if len(config.Device) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("device"), "the device to shape has to be given"))
+	} else {
+		allErrs = append(allErrs, validateDevice(config.Device, fldPath.Child("device"))...)
+	}
+
+	if len(config.Type) > 0 {
+		if config.Netem != nil || config.Bandwidth != nil || config.Match != nil {
+			return nil, append(allErrs, field.Forbidden(fldPath.Child("type"), "type and value can not be combined with netem, bandwidth or match"))
+		}
+		shaping, errs := shapingForValue(config.Type, config.Value, fldPath)
+		return shaping, append(allErrs, errs...)
+	}
+	if config.Netem == nil && config.Bandwidth == nil {
+		return nil, append(allErrs, field.Required(fldPath.Child("netem"), "either netem, bandwidth or type and value have to be given"))
+	}
+
+	shaping := &Shaping{}
+	if config.Match != nil {
+		match, errs := matchFor(config.Match, fldPath.Child("match"))
+		shaping.Match = match
+		allErrs = append(allErrs, errs...)
+	}
+	if config.Netem != nil {
+		netem, errs := netemFor(config.Netem, fldPath.Child("netem"))
+		shaping.Netem = netem
+		allErrs = append(allErrs, errs...)
+	}
+	if config.Bandwidth != nil {
+		bandwidth, errs := bandwidthFor(config.Bandwidth, fldPath.Child("bandwidth"))
+		shaping.Bandwidth = bandwidth
+		allErrs = append(allErrs, errs...)
+
+		if config.Bandwidth.Ingress {
+			shaping.Ingress = true
+			shaping.IFBDevice = config.Bandwidth.IFBDevice
+			if len(shaping.IFBDevice) == 0 {
+				shaping.IFBDevice = ifbDeviceFor(config.Device)
+			}
+			ifbPath := fldPath.Child("bandwidth", "ifbDevice")
+			if len(config.Bandwidth.IFBDevice) > 0 {
+				allErrs = append(allErrs, validateDevice(config.Bandwidth.IFBDevice, ifbPath)...)
+			}
+			if shaping.IFBDevice == config.Device {
+				allErrs = append(allErrs, field.Invalid(ifbPath, shaping.IFBDevice, "the IFB device has to differ from the shaped device"))
+			}
+		}
+	}
+	return shaping, allErrs
+}
+
+// validateDevice validates the network device name <device>. Device names are part of the shell commands running tc
+// and ip, so only the characters of interface names are accepted, e.g. eth0, vxlan.calico or flannel.1.
+func validateDevice(device string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(device) > maxDeviceLength {
+		allErrs = append(allErrs, field.TooLong(fldPath, device, maxDeviceLength))
+	}
+	if !devicePattern.MatchString(device) || device == "." || device == ".." {
+		allErrs = append(allErrs, field.Invalid(fldPath, device, "a device name may only contain alphanumeric characters, '_', '.', ':', '@' or '-'"))
+	}
+	return allErrs
+}
+
+// shapingForValue returns the shaping of a single impairment, delays are given as "<delay> [<jitter>]", losses as
+// percentage and bandwidths as rate.
+func shapingForValue(shaperType v1alpha1.ShaperType, value string, fldPath *field.Path) (*Shaping, field.ErrorList) {
+	var (
+		allErrs   field.ErrorList
+		netem     = &Netem{}
+		fields    = strings.Fields(value)
+		valuePath = fldPath.Child("value")
+	)
+
+	switch shaperType {
+	case v1alpha1.Delay:
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, append(allErrs, field.Invalid(valuePath, value, "expected \"<delay> [<jitter>]\""))
+		}
+		for i, f := range fields {
+			d, err := time.ParseDuration(f)
+			if err != nil || d < 0 {
+				return nil, append(allErrs, field.Invalid(valuePath, value, "expected \"<delay> [<jitter>]\" with durations like 100ms"))
+			}
+			if i == 0 {
+				netem.Delay = d
+			} else {
+				netem.Jitter = d
+			}
+		}
+	case v1alpha1.Loss:
+		if len(fields) != 1 {
+			return nil, append(allErrs, field.Invalid(valuePath, value, "expected a percentage"))
+		}
+		loss, err := ParsePercent(fields[0])
+		if err != nil {
+			return nil, append(allErrs, field.Invalid(valuePath, value, err.Error()))
+		}
+		netem.Loss = loss
+	case v1alpha1.Bandwidth:
+		bandwidth, errs := bandwidthFor(&v1alpha1.BandwidthConfiguration{Rate: value}, fldPath)
+		for _, err := range errs {
+			err.Field = valuePath.String()
+		}
+		return &Shaping{Bandwidth: bandwidth}, errs
+	default:
+		return nil, append(allErrs, field.NotSupported(fldPath.Child("type"), shaperType, []string{string(v1alpha1.Delay), string(v1alpha1.Loss), string(v1alpha1.Bandwidth)}))
+	}
+	return &Shaping{Netem: netem}, allErrs
+}
+
+func netemFor(config *v1alpha1.NetemConfiguration, fldPath *field.Path) (*Netem, field.ErrorList) {
+	var (
+		allErrs field.ErrorList
+		netem   = &Netem{}
+	)
+	percent := func(value string, fldPath *field.Path, required bool) float64 {
+		if len(value) == 0 {
+			if required {
+				allErrs = append(allErrs, field.Required(fldPath, "a percentage has to be given"))
+			}
+			return 0
+		}
+		p, err := ParsePercent(value)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath, value, err.Error()))
+		}
+		return p
+	}
+
+	if delay := config.Delay; delay != nil {
+		delayPath := fldPath.Child("delay")
+		if delay.Time.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(delayPath.Child("time"), delay.Time.Duration.String(), "the delay has to be positive"))
+		}
+		netem.Delay = delay.Time.Duration
+		if delay.Jitter != nil {
+			if delay.Jitter.Duration < 0 {
+				allErrs = append(allErrs, field.Invalid(delayPath.Child("jitter"), delay.Jitter.Duration.String(), "the jitter can not be negative"))
+			}
+			netem.Jitter = delay.Jitter.Duration
+		}
+		if netem.Jitter == 0 {
+			if len(delay.Correlation) > 0 {
+				allErrs = append(allErrs, field.Forbidden(delayPath.Child("correlation"), "a correlation requires a jitter"))
+			}
+			if len(delay.Distribution) > 0 {
+				allErrs = append(allErrs, field.Forbidden(delayPath.Child("distribution"), "a distribution requires a jitter"))
+			}
+		}
+		netem.DelayCorrelation = percent(delay.Correlation, delayPath.Child("correlation"), false)
+		if len(delay.Distribution) > 0 {
+			if !contains(distributions, string(delay.Distribution)) {
+				allErrs = append(allErrs, field.NotSupported(delayPath.Child("distribution"), delay.Distribution, distributions))
+			}
+			netem.Distribution = string(delay.Distribution)
+		}
+	}
+
+	if loss := config.Loss; loss != nil {
+		lossPath := fldPath.Child("loss")
+		switch {
+		case len(loss.Percentage) > 0 && loss.GilbertElliott != nil:
+			allErrs = append(allErrs, field.Forbidden(lossPath.Child("gilbertElliott"), "random loss and the Gilbert-Elliott model can not be combined"))
+		case loss.GilbertElliott != nil:
+			if len(loss.Correlation) > 0 {
+				allErrs = append(allErrs, field.Forbidden(lossPath.Child("correlation"), "a correlation requires a random loss percentage"))
+			}
+			gePath := lossPath.Child("gilbertElliott")
+			ge := &GilbertElliott{
+				P:         percent(loss.GilbertElliott.P, gePath.Child("p"), true),
+				R:         percent(loss.GilbertElliott.R, gePath.Child("r"), false),
+				OneMinusH: 100,
+				OneMinusK: percent(loss.GilbertElliott.OneMinusK, gePath.Child("oneMinusK"), false),
+			}
+			if len(loss.GilbertElliott.R) == 0 {
+				ge.R = 100 - ge.P
+			}
+			if len(loss.GilbertElliott.OneMinusH) > 0 {
+				ge.OneMinusH = percent(loss.GilbertElliott.OneMinusH, gePath.Child("oneMinusH"), false)
+			}
+			netem.GilbertElliott = ge
+		default:
+			netem.Loss = percent(loss.Percentage, lossPath.Child("percentage"), true)
+			netem.LossCorrelation = percent(loss.Correlation, lossPath.Child("correlation"), false)
+		}
+	}
+
+	if duplicate := config.Duplicate; duplicate != nil {
+		netem.Duplicate = percent(duplicate.Percentage, fldPath.Child("duplicate", "percentage"), true)
+		netem.DuplicateCorrelation = percent(duplicate.Correlation, fldPath.Child("duplicate", "correlation"), false)
+	}
+
+	if corrupt := config.Corrupt; corrupt != nil {
+		netem.Corrupt = percent(corrupt.Percentage, fldPath.Child("corrupt", "percentage"), true)
+		netem.CorruptCorrelation = percent(corrupt.Correlation, fldPath.Child("corrupt", "correlation"), false)
+	}
+
+	if reorder := config.Reorder; reorder != nil {
+		reorderPath := fldPath.Child("reorder")
+		if config.Delay == nil {
+			allErrs = append(allErrs, field.Forbidden(reorderPath, "reordering requires a delay"))
+		}
+		if reorder.Gap < 0 {
+			allErrs = append(allErrs, field.Invalid(reorderPath.Child("gap"), reorder.Gap, "the gap can not be negative"))
+		}
+		netem.Reorder = percent(reorder.Percentage, reorderPath.Child("percentage"), true)
+		netem.ReorderCorrelation = percent(reorder.Correlation, reorderPath.Child("correlation"), false)
+		netem.Gap = uint32(reorder.Gap)
+	}
+
+	if rate := config.Rate; rate != nil {
+		ratePath := fldPath.Child("rate")
+		bits, err := ParseRate(rate.Rate)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(ratePath.Child("rate"), rate.Rate, err.Error()))
+		}
+		if rate.CellSize < 0 {
+			allErrs = append(allErrs, field.Invalid(ratePath.Child("cellSize"), rate.CellSize, "the cell size can not be negative"))
+		}
+		netem.Rate = bits
+		netem.PacketOverhead = rate.PacketOverhead
+		netem.CellSize = uint32(rate.CellSize)
+		netem.CellOverhead = rate.CellOverhead
+	}
+
+	if slot := config.Slot; slot != nil {
+		slotPath := fldPath.Child("slot")
+		s := &Slot{MinDelay: slot.MinDelay.Duration}
+		if s.MinDelay <= 0 {
+			allErrs = append(allErrs, field.Invalid(slotPath.Child("minDelay"), slot.MinDelay.Duration.String(), "the delay has to be positive"))
+		}
+		if slot.MaxDelay != nil {
+			if slot.MaxDelay.Duration < s.MinDelay {
+				allErrs = append(allErrs, field.Invalid(slotPath.Child("maxDelay"), slot.MaxDelay.Duration.String(), "the maximum delay can not be smaller than the minimum delay"))
+			}
+			s.MaxDelay = slot.MaxDelay.Duration
+		}
+		if slot.Packets < 0 {
+			allErrs = append(allErrs, field.Invalid(slotPath.Child("packets"), slot.Packets, "the number of packets can not be negative"))
+		}
+		if slot.Bytes < 0 {
+			allErrs = append(allErrs, field.Invalid(slotPath.Child("bytes"), slot.Bytes, "the number of bytes can not be negative"))
+		}
+		s.Packets, s.Bytes = uint32(slot.Packets), uint32(slot.Bytes)
+		netem.Slot = s
+	}
+
+	if len(netem.Args()) == 0 && len(allErrs) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath, "at least one impairment has to be configured"))
+	}
+	return netem, allErrs
+}
+
+const (
+	// maxDeviceLength is the maximum length of network device names.
+	maxDeviceLength = 15
+
+	defaultLatency  = 50 * time.Millisecond
+	minimumTBFBurst = 1600
+)
+
+func bandwidthFor(config *v1alpha1.BandwidthConfiguration, fldPath *field.Path) (*Bandwidth, field.ErrorList) {
+	var (
+		allErrs   field.ErrorList
+		bandwidth = &Bandwidth{Algorithm: string(config.Algorithm)}
+	)
+	if len(bandwidth.Algorithm) == 0 {
+		bandwidth.Algorithm = TBF
+	}
+	if bandwidth.Algorithm != TBF && bandwidth.Algorithm != HTB {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("algorithm"), config.Algorithm, []string{TBF, HTB}))
+	}
+
+	rate, err := ParseRate(config.Rate)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("rate"), config.Rate, err.Error()))
+	}
+	bandwidth.Rate = rate
+
+	if len(config.Burst) > 0 {
+		if bandwidth.Burst, err = ParseSize(config.Burst); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("burst"), config.Burst, err.Error()))
+		}
+	}
+
+	switch bandwidth.Algorithm {
+	case TBF:
+		if len(config.Ceil) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("ceil"), "ceil is only supported by htb"))
+		}
+		if bandwidth.Burst == 0 {
+			// the bucket has to hold at least one packet and everything sent within a timer tick
+			bandwidth.Burst = uint32(math.Max(minimumTBFBurst, math.Min(float64(rate/8/100), math.MaxUint32)))
+		}
+		switch {
+		case config.Latency != nil && len(config.Limit) > 0:
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("limit"), "latency and limit can not be combined"))
+		case len(config.Limit) > 0:
+			if bandwidth.Limit, err = ParseSize(config.Limit); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("limit"), config.Limit, err.Error()))
+			}
+		case config.Latency != nil:
+			if config.Latency.Duration <= 0 {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("latency"), config.Latency.Duration.String(), "the latency has to be positive"))
+			}
+			bandwidth.Latency = config.Latency.Duration
+		default:
+			bandwidth.Latency = defaultLatency
+		}
+	case HTB:
+		if config.Latency != nil || len(config.Limit) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("latency"), "latency and limit are only supported by tbf"))
+		}
+		if len(config.Ceil) > 0 {
+			ceil, err := ParseRate(config.Ceil)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("ceil"), config.Ceil, err.Error()))
+			} else if ceil < rate {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("ceil"), config.Ceil, "the ceil can not be smaller than the rate"))
+			}
+			bandwidth.Ceil = ceil
+		}
+	}
+
+	if !config.Ingress && len(config.IFBDevice) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("ifbDevice"), "an IFB device is only used for ingress shaping"))
+	}
+	return bandwidth, allErrs
+}
+
+var protocols = []string{string(v1alpha1.ProtocolTCP), string(v1alpha1.ProtocolUDP), string(v1alpha1.ProtocolICMP)}
+
+// matchFor validates the match, the peers of its destinations have to be added by the caller.
+func matchFor(config *v1alpha1.TrafficMatch, fldPath *field.Path) (*Match, field.ErrorList) {
+	var (
+		allErrs field.ErrorList
+		match   = &Match{AllPeers: len(config.Destinations) == 0 && len(config.CIDRs) == 0}
+	)
+	if match.AllPeers && len(config.Ports) == 0 && len(config.Protocol) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath, "at least one of destinations, cidrs, ports and protocol has to be given"))
+	}
+
+	for i, destination := range config.Destinations {
+		destinationPath := fldPath.Child("destinations").Index(i)
+		if len(destination.Namespace) == 0 {
+			allErrs = append(allErrs, field.Required(destinationPath.Child("namespace"), "the namespace of the destination has to be given"))
+		}
+		switch destination.Kind {
+		case v1alpha1.Pod, v1alpha1.Service:
+			if len(destination.Name) == 0 {
+				allErrs = append(allErrs, field.Required(destinationPath.Child("name"), "a pod or service destination needs a name"))
+			}
+		case v1alpha1.Selector:
+			if destination.Selector == nil {
+				allErrs = append(allErrs, field.Required(destinationPath.Child("selector"), "a selector destination needs a selector"))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(destinationPath.Child("kind"), destination.Kind, []string{string(v1alpha1.Pod), string(v1alpha1.Service), string(v1alpha1.Selector)}))
+		}
+	}
+
+	for i, cidr := range config.CIDRs {
+		peer, err := ParsePeer(cidr)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("cidrs").Index(i), cidr, err.Error()))
+			continue
+		}
+		match.Peers = append(match.Peers, peer)
+	}
+
+	for i, port := range config.Ports {
+		if port < 1 || port > 65535 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("ports").Index(i), port, "the port has to be between 1 and 65535"))
+			continue
+		}
+		match.Ports = append(match.Ports, uint16(port))
+	}
+
+	if len(config.Protocol) > 0 {
+		if !contains(protocols, string(config.Protocol)) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("protocol"), config.Protocol, protocols))
+		}
+		if config.Protocol == v1alpha1.ProtocolICMP && len(config.Ports) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("ports"), "ports can not be matched for icmp"))
+		}
+		match.Protocols = []string{string(config.Protocol)}
+	}
+	return match, allErrs
+}
+
+// ifbDeviceFor returns the default IFB device for <device>, truncated to the maximum device name length.
+func ifbDeviceFor(device string) string {
+	name := "ifb-" + device
+	if len(name) > maxDeviceLength {
+		name = name[:maxDeviceLength]
+	}
+	return name
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package tc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestShapingFor(t *testing.T) {
+	jitter := metav1.Duration{Duration: 10 * time.Millisecond}
+	delay := &v1alpha1.NetemDelay{Time: metav1.Duration{Duration: 100 * time.Millisecond}, Jitter: &jitter, Correlation: "25%", Distribution: v1alpha1.DistributionNormal}
+
+	tests := []struct {
+		name   string
+		config v1alpha1.ShaperConfiguration
+		args   string
+		errors []string
+	}{
+		{name: "delay value", config: v1alpha1.ShaperConfiguration{Type: v1alpha1.Delay, Device: "eth0", Value: "200ms 10ms"}, args: "netem delay 200ms 10ms"},
+		{name: "loss value", config: v1alpha1.ShaperConfiguration{Type: v1alpha1.Loss, Device: "eth0", Value: "10%"}, args: "netem loss 10%"},
+		{name: "invalid value", config: v1alpha1.ShaperConfiguration{Type: v1alpha1.Delay, Device: "eth0", Value: "slow"}, errors: []string{"config.value"}},
+		{name: "nothing configured", config: v1alpha1.ShaperConfiguration{Device: "eth0"}, errors: []string{"config.netem"}},
+		{
+			name: "combined",
+			config: v1alpha1.ShaperConfiguration{Device: "eth0", Netem: &v1alpha1.NetemConfiguration{
+				Delay:   delay,
+				Loss:    &v1alpha1.NetemLoss{GilbertElliott: &v1alpha1.GilbertElliottLoss{P: "5%"}},
+				Reorder: &v1alpha1.NetemReorder{Percentage: "25%", Gap: 5},
+				Rate:    &v1alpha1.NetemRate{Rate: "10mbit"},
+			}},
+			args: "netem delay 100ms 10ms 25% distribution normal loss gemodel 5% 95% 100% 0% reorder 25% gap 5 rate 10000000bit",
+		},
+		{
+			name: "invalid combination",
+			config: v1alpha1.ShaperConfiguration{Netem: &v1alpha1.NetemConfiguration{
+				Delay:   &v1alpha1.NetemDelay{Time: metav1.Duration{Duration: time.Second}, Distribution: "gauss"},
+				Loss:    &v1alpha1.NetemLoss{Percentage: "1%", GilbertElliott: &v1alpha1.GilbertElliottLoss{P: "5%"}},
+				Corrupt: &v1alpha1.NetemProbability{Percentage: "120%"},
+				Rate:    &v1alpha1.NetemRate{Rate: "fast"},
+			}},
+			errors: []string{
+				"config.device",
+				"config.netem.delay.distribution",
+				"config.netem.delay.distribution",
+				"config.netem.loss.gilbertElliott",
+				"config.netem.corrupt.percentage",
+				"config.netem.rate.rate",
+			},
+		},
+		{
+			name:   "reorder without delay",
+			config: v1alpha1.ShaperConfiguration{Device: "eth0", Netem: &v1alpha1.NetemConfiguration{Reorder: &v1alpha1.NetemReorder{Percentage: "25%"}}},
+			errors: []string{"config.netem.reorder"},
+		},
+		{
+			name:   "type and netem",
+			config: v1alpha1.ShaperConfiguration{Type: v1alpha1.Loss, Device: "eth0", Value: "1%", Netem: &v1alpha1.NetemConfiguration{Duplicate: &v1alpha1.NetemProbability{Percentage: "1%"}}},
+			errors: []string{"config.type"},
+		},
+		{
+			name:   "bandwidth value",
+			config: v1alpha1.ShaperConfiguration{Type: v1alpha1.Bandwidth, Device: "eth0", Value: "10mbit"},
+			args:   "tbf rate 10mbit burst 12500b latency 50ms",
+		},
+		{
+			name: "ingress htb",
+			config: v1alpha1.ShaperConfiguration{Device: "eth0", Bandwidth: &v1alpha1.BandwidthConfiguration{
+				Algorithm: v1alpha1.BandwidthHTB, Rate: "10mbit", Ceil: "20mbit", Burst: "15kb", Ingress: true,
+			}},
+			args: "htb rate 10mbit ceil 20mbit burst 15kb on ingress via ifb-eth0",
+		},
+		{
+			name: "invalid bandwidth",
+			config: v1alpha1.ShaperConfiguration{Device: "eth0", Bandwidth: &v1alpha1.BandwidthConfiguration{
+				Rate: "10mbit", Ceil: "20mbit", Limit: "10kb", Latency: &metav1.Duration{Duration: time.Second}, IFBDevice: "ifb0",
+			}},
+			errors: []string{"config.bandwidth.ceil", "config.bandwidth.limit", "config.bandwidth.ifbDevice"},
+		},
+		{
+			name: "match",
+			config: v1alpha1.ShaperConfiguration{Device: "eth0", Netem: &v1alpha1.NetemConfiguration{Delay: delay}, Match: &v1alpha1.TrafficMatch{
+				CIDRs: []string{"10.0.0.0/8", "fd00::1"}, Ports: []int32{53}, Protocol: v1alpha1.ProtocolUDP,
+			}},
+			args: "netem delay 100ms 10ms 25% distribution normal for peers 10.0.0.0/8 fd00::1/128 ports 53 protocols udp",
+		},
+		{
+			name: "invalid match",
+			config: v1alpha1.ShaperConfiguration{Device: "eth0", Netem: &v1alpha1.NetemConfiguration{Delay: delay}, Match: &v1alpha1.TrafficMatch{
+				Destinations: []v1alpha1.ShaperDestination{{Kind: v1alpha1.IP, Namespace: "default"}, {Kind: v1alpha1.Pod, Namespace: "default"}},
+				CIDRs:        []string{"10.0.0.0/33"},
+				Ports:        []int32{0},
+				Protocol:     v1alpha1.ProtocolICMP,
+			}},
+			errors: []string{
+				"config.match.destinations[0].kind",
+				"config.match.destinations[1].name",
+				"config.match.cidrs[0]",
+				"config.match.ports[0]",
+				"config.match.ports",
+			},
+		},
+		{
+			name:   "overlay device",
+			config: v1alpha1.ShaperConfiguration{Type: v1alpha1.Loss, Device: "vxlan.calico", Value: "1%"},
+			args:   "netem loss 1%",
+		},
+		{
+			name:   "device with shell syntax",
+			config: v1alpha1.ShaperConfiguration{Type: v1alpha1.Loss, Device: "eth0; touch /pwned #", Value: "1%"},
+			errors: []string{"config.device", "config.device"},
+		},
+		{
+			name:   "device name too long",
+			config: v1alpha1.ShaperConfiguration{Type: v1alpha1.Loss, Device: "averylongdevice0", Value: "1%"},
+			errors: []string{"config.device"},
+		},
+		{
+			name: "IFB device with shell syntax",
+			config: v1alpha1.ShaperConfiguration{Device: "flannel.1", Bandwidth: &v1alpha1.BandwidthConfiguration{
+				Rate: "10mbit", Ingress: true, IFBDevice: "$(reboot)",
+			}},
+			errors: []string{"config.bandwidth.ifbDevice"},
+		},
+		{
+			name:   "empty match",
+			config: v1alpha1.ShaperConfiguration{Device: "eth0", Netem: &v1alpha1.NetemConfiguration{Delay: delay}, Match: &v1alpha1.TrafficMatch{}},
+			errors: []string{"config.match"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shaping, errs := ShapingFor(tt.config, field.NewPath("config"))
+			if len(errs) != len(tt.errors) {
+				t.Fatalf("expected errors for %v, got %v", tt.errors, errs)
+			}
+			for i, err := range errs {
+				if err.Field != tt.errors[i] {
+					t.Errorf("expected error for %s, got %v", tt.errors[i], err)
+				}
+			}
+			if len(tt.errors) == 0 && shaping.String() != tt.args {
+				t.Errorf("expected shaping %q, got %q", tt.args, shaping.String())
+			}
+		})
+	}
+}
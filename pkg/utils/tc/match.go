@@ -0,0 +1,163 @@
+package tc
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// prioHandle is the handle of the prio qdisc classifying matched traffic.
+	prioHandle = "1:"
+	// matchedBand is the band of the prio qdisc the matched traffic is sent to. The default priomap only uses
+	// the first three bands, unmatched traffic is never sent to the fourth.
+	matchedBand = "1:4"
+)
+
+var protocolNumbers = map[string]struct{ ip, ipv6 int }{
+	"tcp":  {6, 6},
+	"udp":  {17, 17},
+	"icmp": {1, 58},
+}
+
+// Match restricts the shaping to the traffic exchanged with peers. The traffic sent to the peers is matched, or
+// the traffic received from them if ingress traffic is shaped.
+type Match struct {
+	// AllPeers matches the traffic of all peers, otherwise only that of Peers.
+	AllPeers bool
+	Peers    []*net.IPNet
+	// Ports are the ports of the peers, they require Protocols to be tcp or udp.
+	Ports     []uint16
+	Protocols []string
+}
+
+// ParsePeer parses a network like "10.0.0.0/8" or a single IP, which is returned as host network.
+func ParsePeer(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP %q", value)
+		}
+		if ipv4 := ip.To4(); ipv4 != nil {
+			return &net.IPNet{IP: ipv4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q", value)
+	}
+	return network, nil
+}
+
+// String returns the matched traffic, e.g. "peers 10.0.0.1/32 ports 80 protocols tcp".
+func (m *Match) String() string {
+	var parts []string
+	if !m.AllPeers {
+		peers := m.peers()
+		if len(peers) == 0 {
+			parts = append(parts, "peers none")
+		} else {
+			parts = append(parts, "peers "+strings.Join(peers, " "))
+		}
+	}
+	if len(m.Ports) > 0 {
+		var ports []string
+		for _, port := range m.Ports {
+			ports = append(ports, strconv.Itoa(int(port)))
+		}
+		parts = append(parts, "ports "+strings.Join(ports, " "))
+	}
+	if len(m.Protocols) > 0 {
+		parts = append(parts, "protocols "+strings.Join(m.Protocols, " "))
+	}
+	return strings.Join(parts, " ")
+}
+
+// filterCommands returns the commands adding the u32 filters which send the matched traffic of <device> to the
+// matched band. Peers are matched by destination, or by source if <ingress> is set.
+func (m *Match) filterCommands(device string, ingress bool) []string {
+	direction, portDirection := "dst", "dport"
+	if ingress {
+		direction, portDirection = "src", "sport"
+	}
+
+	type peer struct {
+		family  string
+		network string
+	}
+	var peers []peer
+	if m.AllPeers {
+		peers = []peer{{family: "ip"}, {family: "ip6"}}
+	}
+	for _, network := range m.peers() {
+		family := "ip"
+		if strings.Contains(network, ":") {
+			family = "ip6"
+		}
+		peers = append(peers, peer{family: family, network: network})
+	}
+
+	protocols := m.Protocols
+	if len(protocols) == 0 && len(m.Ports) > 0 {
+		protocols = []string{"tcp", "udp"}
+	}
+	if len(protocols) == 0 {
+		protocols = []string{""}
+	}
+	ports := m.Ports
+	if len(ports) == 0 {
+		ports = []uint16{0}
+	}
+
+	var commands []string
+	for _, p := range peers {
+		filterProtocol, prio := "ip", 1
+		if p.family == "ip6" {
+			filterProtocol, prio = "ipv6", 2
+		}
+		for _, protocol := range protocols {
+			for _, port := range ports {
+				var matches []string
+				if len(p.network) > 0 {
+					matches = append(matches, fmt.Sprintf("match %s %s %s", p.family, direction, p.network))
+				}
+				if numbers, ok := protocolNumbers[protocol]; ok {
+					number := numbers.ip
+					if p.family == "ip6" {
+						number = numbers.ipv6
+					}
+					matches = append(matches, fmt.Sprintf("match %s protocol %d 0xff", p.family, number))
+				}
+				if port > 0 {
+					matches = append(matches, fmt.Sprintf("match %s %s %d 0xffff", p.family, portDirection, port))
+				}
+				if len(matches) == 0 {
+					matches = append(matches, "match u32 0 0")
+				}
+				commands = append(commands, fmt.Sprintf("tc filter add dev %s parent %s protocol %s prio %d u32 %s flowid %s",
+					device, prioHandle, filterProtocol, prio, strings.Join(matches, " "), matchedBand))
+			}
+		}
+	}
+	return commands
+}
+
+// peers returns the sorted and deduplicated peer networks.
+func (m *Match) peers() []string {
+	var (
+		peers []string
+		seen  = map[string]bool{}
+	)
+	for _, network := range m.Peers {
+		if network == nil || seen[network.String()] {
+			continue
+		}
+		seen[network.String()] = true
+		peers = append(peers, network.String())
+	}
+	sort.Strings(peers)
+	return peers
+}
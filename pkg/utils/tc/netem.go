@@ -0,0 +1,383 @@
+package tc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Netem is the configuration of a netem qdisc. Percentages are given in the range 0 to 100.
+type Netem struct {
+	// Delay and Jitter delay all packets by Delay ± Jitter.
+	Delay            time.Duration
+	Jitter           time.Duration
+	DelayCorrelation float64
+	Distribution     string
+
+	// Loss is the percentage of randomly dropped packets.
+	Loss            float64
+	LossCorrelation float64
+	// GilbertElliott drops packets following the Gilbert-Elliott model instead.
+	GilbertElliott *GilbertElliott
+
+	Duplicate            float64
+	DuplicateCorrelation float64
+
+	Corrupt            float64
+	CorruptCorrelation float64
+
+	Reorder            float64
+	ReorderCorrelation float64
+	Gap                uint32
+
+	// Rate is the bandwidth in bits per second.
+	Rate           uint64
+	PacketOverhead int32
+	CellSize       uint32
+	CellOverhead   int32
+
+	Slot *Slot
+}
+
+// GilbertElliott are the transition and loss probabilities of the Gilbert-Elliott loss model.
+type GilbertElliott struct {
+	P, R, OneMinusH, OneMinusK float64
+}
+
+// Slot delivers packets in slots.
+type Slot struct {
+	MinDelay, MaxDelay time.Duration
+	Packets, Bytes     uint32
+}
+
+// Args returns the netem arguments in tc syntax.
+func (n *Netem) Args() string {
+	var args []string
+	if n.Delay > 0 {
+		args = append(args, "delay", formatDuration(n.Delay))
+		if n.Jitter > 0 {
+			args = append(args, formatDuration(n.Jitter))
+			if n.DelayCorrelation > 0 {
+				args = append(args, formatPercent(n.DelayCorrelation))
+			}
+			if len(n.Distribution) > 0 {
+				args = append(args, "distribution", n.Distribution)
+			}
+		}
+	}
+	if n.Loss > 0 {
+		args = append(args, "loss", formatPercent(n.Loss))
+		if n.LossCorrelation > 0 {
+			args = append(args, formatPercent(n.LossCorrelation))
+		}
+	}
+	if ge := n.GilbertElliott; ge != nil {
+		args = append(args, "loss", "gemodel", formatPercent(ge.P), formatPercent(ge.R), formatPercent(ge.OneMinusH), formatPercent(ge.OneMinusK))
+	}
+	if n.Duplicate > 0 {
+		args = append(args, "duplicate", formatPercent(n.Duplicate))
+		if n.DuplicateCorrelation > 0 {
+			args = append(args, formatPercent(n.DuplicateCorrelation))
+		}
+	}
+	if n.Corrupt > 0 {
+		args = append(args, "corrupt", formatPercent(n.Corrupt))
+		if n.CorruptCorrelation > 0 {
+			args = append(args, formatPercent(n.CorruptCorrelation))
+		}
+	}
+	if n.Reorder > 0 {
+		args = append(args, "reorder", formatPercent(n.Reorder))
+		if n.ReorderCorrelation > 0 {
+			args = append(args, formatPercent(n.ReorderCorrelation))
+		}
+		if n.Gap > 0 {
+			args = append(args, "gap", strconv.FormatUint(uint64(n.Gap), 10))
+		}
+	}
+	if n.Rate > 0 {
+		args = append(args, "rate", fmt.Sprintf("%dbit", n.Rate))
+		if n.PacketOverhead != 0 || n.CellSize > 0 || n.CellOverhead != 0 {
+			args = append(args, strconv.Itoa(int(n.PacketOverhead)))
+		}
+		if n.CellSize > 0 || n.CellOverhead != 0 {
+			args = append(args, strconv.FormatUint(uint64(n.CellSize), 10))
+		}
+		if n.CellOverhead != 0 {
+			args = append(args, strconv.Itoa(int(n.CellOverhead)))
+		}
+	}
+	if s := n.Slot; s != nil {
+		args = append(args, "slot", formatDuration(s.MinDelay))
+		if s.MaxDelay > 0 {
+			args = append(args, formatDuration(s.MaxDelay))
+		}
+		if s.Packets > 0 {
+			args = append(args, "packets", strconv.FormatUint(uint64(s.Packets), 10))
+		}
+		if s.Bytes > 0 {
+			args = append(args, "bytes", strconv.FormatUint(uint64(s.Bytes), 10))
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+// String returns the qdisc in tc syntax.
+func (n *Netem) String() string {
+	return strings.TrimSpace("netem " + n.Args())
+}
+
+// ReplaceCommand returns the command replacing the root qdisc of <device> by the netem qdisc.
+func (n *Netem) ReplaceCommand(device string) string {
+	return fmt.Sprintf("tc qdisc replace dev %s root %s", device, n)
+}
+
+// Matches returns true if <qdisc> is a netem qdisc configured like <n>. tc does not report the delay
+// distribution, it is not compared.
+func (n *Netem) Matches(qdisc *Qdisc) bool {
+	if qdisc == nil || qdisc.Kind != "netem" {
+		return false
+	}
+	actual, err := netemFromOptions(qdisc.Options)
+	if err != nil {
+		return false
+	}
+
+	expected := *n
+	expected.Distribution = ""
+	if expected.Jitter == 0 {
+		expected.DelayCorrelation = 0
+	}
+	return actual.equal(&expected)
+}
+
+func (n *Netem) equal(o *Netem) bool {
+	const (
+		timeEpsilon    = float64(time.Microsecond)
+		percentEpsilon = 1e-4
+	)
+	durationsEqual := func(a, b time.Duration) bool { return approximately(float64(a), float64(b), timeEpsilon) }
+	percentsEqual := func(a, b float64) bool { return approximately(a, b, percentEpsilon) }
+
+	if (n.GilbertElliott == nil) != (o.GilbertElliott == nil) || (n.Slot == nil) != (o.Slot == nil) {
+		return false
+	}
+	if ge, oge := n.GilbertElliott, o.GilbertElliott; ge != nil {
+		if !percentsEqual(ge.P, oge.P) || !percentsEqual(ge.R, oge.R) || !percentsEqual(ge.OneMinusH, oge.OneMinusH) || !percentsEqual(ge.OneMinusK, oge.OneMinusK) {
+			return false
+		}
+	}
+	if s, os := n.Slot, o.Slot; s != nil {
+		if !durationsEqual(s.MinDelay, os.MinDelay) || !durationsEqual(s.MaxDelay, os.MaxDelay) || s.Packets != os.Packets || s.Bytes != os.Bytes {
+			return false
+		}
+	}
+	return durationsEqual(n.Delay, o.Delay) &&
+		durationsEqual(n.Jitter, o.Jitter) &&
+		percentsEqual(n.DelayCorrelation, o.DelayCorrelation) &&
+		n.Distribution == o.Distribution &&
+		percentsEqual(n.Loss, o.Loss) &&
+		percentsEqual(n.LossCorrelation, o.LossCorrelation) &&
+		percentsEqual(n.Duplicate, o.Duplicate) &&
+		percentsEqual(n.DuplicateCorrelation, o.DuplicateCorrelation) &&
+		percentsEqual(n.Corrupt, o.Corrupt) &&
+		percentsEqual(n.CorruptCorrelation, o.CorruptCorrelation) &&
+		percentsEqual(n.Reorder, o.Reorder) &&
+		percentsEqual(n.ReorderCorrelation, o.ReorderCorrelation) &&
+		n.Gap == o.Gap &&
+		approximately(float64(n.Rate), float64(o.Rate), 8) &&
+		n.PacketOverhead == o.PacketOverhead &&
+		n.CellSize == o.CellSize &&
+		n.CellOverhead == o.CellOverhead
+}
+
+// netemOptions are the netem options printed by `tc -j`, delays are given in seconds and probabilities as
+// fractions.
+type netemOptions struct {
+	Delay *struct {
+		Delay       float64 `json:"delay"`
+		Jitter      float64 `json:"jitter"`
+		Correlation float64 `json:"correlation"`
+	} `json:"delay,omitempty"`
+	LossRandom *struct {
+		Loss        float64 `json:"loss"`
+		Correlation float64 `json:"correlation"`
+	} `json:"loss-random,omitempty"`
+	LossGE *struct {
+		P         float64 `json:"p"`
+		R         float64 `json:"r"`
+		OneMinusH float64 `json:"1-h"`
+		OneMinusK float64 `json:"1-k"`
+	} `json:"loss-ge,omitempty"`
+	Duplicate *struct {
+		Duplicate   float64 `json:"duplicate"`
+		Correlation float64 `json:"correlation"`
+	} `json:"duplicate,omitempty"`
+	Reorder *struct {
+		Reorder     float64 `json:"reorder"`
+		Correlation float64 `json:"correlation"`
+	} `json:"reorder,omitempty"`
+	Corrupt *struct {
+		Corrupt     float64 `json:"corrupt"`
+		Correlation float64 `json:"correlation"`
+	} `json:"corrupt,omitempty"`
+	Rate *struct {
+		// Rate is given in bytes per second, it is compared with a tolerance of one byte.
+		Rate           uint64 `json:"rate"`
+		PacketOverhead int32  `json:"packetoverhead"`
+		CellSize       uint32 `json:"cellsize"`
+		CellOverhead   int32  `json:"celloverhead"`
+	} `json:"rate,omitempty"`
+	Slot *struct {
+		MinDelay string `json:"min-delay"`
+		MaxDelay string `json:"max-delay"`
+		Packets  uint32 `json:"packets"`
+		Bytes    uint32 `json:"bytes"`
+	} `json:"slot,omitempty"`
+	Gap uint32 `json:"gap"`
+}
+
+func netemFromOptions(raw json.RawMessage) (*Netem, error) {
+	netem := &Netem{}
+	if len(raw) == 0 {
+		return netem, nil
+	}
+	options := &netemOptions{}
+	if err := json.Unmarshal(raw, options); err != nil {
+		return nil, err
+	}
+
+	if o := options.Delay; o != nil {
+		netem.Delay = seconds(o.Delay)
+		netem.Jitter = seconds(o.Jitter)
+		netem.DelayCorrelation = o.Correlation * 100
+	}
+	if o := options.LossRandom; o != nil {
+		netem.Loss = o.Loss * 100
+		netem.LossCorrelation = o.Correlation * 100
+	}
+	if o := options.LossGE; o != nil {
+		netem.GilbertElliott = &GilbertElliott{P: o.P * 100, R: o.R * 100, OneMinusH: o.OneMinusH * 100, OneMinusK: o.OneMinusK * 100}
+	}
+	if o := options.Duplicate; o != nil {
+		netem.Duplicate = o.Duplicate * 100
+		netem.DuplicateCorrelation = o.Correlation * 100
+	}
+	if o := options.Corrupt; o != nil {
+		netem.Corrupt = o.Corrupt * 100
+		netem.CorruptCorrelation = o.Correlation * 100
+	}
+	if o := options.Reorder; o != nil {
+		netem.Reorder = o.Reorder * 100
+		netem.ReorderCorrelation = o.Correlation * 100
+		netem.Gap = options.Gap
+	}
+	if o := options.Rate; o != nil {
+		netem.Rate = o.Rate * 8
+		netem.PacketOverhead = o.PacketOverhead
+		netem.CellSize = o.CellSize
+		netem.CellOverhead = o.CellOverhead
+	}
+	if o := options.Slot; o != nil {
+		minDelay, err := ParseTime(o.MinDelay)
+		if err != nil {
+			return nil, err
+		}
+		var maxDelay time.Duration
+		if len(o.MaxDelay) > 0 {
+			if maxDelay, err = ParseTime(o.MaxDelay); err != nil {
+				return nil, err
+			}
+		}
+		netem.Slot = &Slot{MinDelay: minDelay, MaxDelay: maxDelay, Packets: o.Packets, Bytes: o.Bytes}
+	}
+	return netem, nil
+}
+
+// ParsePercent parses a percentage like "10%" or "0.5".
+func ParsePercent(value string) (float64, error) {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(value), "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage %q", value)
+	}
+	if percent < 0 || percent > 100 {
+		return 0, fmt.Errorf("percentage %q is not between 0 and 100", value)
+	}
+	return percent, nil
+}
+
+var rateUnits = []struct {
+	suffix     string
+	multiplier float64
+}{
+	// longer suffixes first, "kbps" also ends with "bps"
+	{"kibit", 1024}, {"mibit", 1024 * 1024}, {"gibit", 1024 * 1024 * 1024}, {"tibit", 1024 * 1024 * 1024 * 1024},
+	{"kibps", 8 * 1024}, {"mibps", 8 * 1024 * 1024}, {"gibps", 8 * 1024 * 1024 * 1024}, {"tibps", 8 * 1024 * 1024 * 1024 * 1024},
+	{"kbit", 1e3}, {"mbit", 1e6}, {"gbit", 1e9}, {"tbit", 1e12},
+	{"kbps", 8e3}, {"mbps", 8e6}, {"gbps", 8e9}, {"tbps", 8e12},
+	{"bit", 1}, {"bps", 8},
+}
+
+// ParseRate parses a rate in tc units, e.g. "10mbit" or "1gbps", and returns it in bits per second.
+func ParseRate(value string) (uint64, error) {
+	lower := strings.ToLower(strings.TrimSpace(value))
+	for _, unit := range rateUnits {
+		if !strings.HasSuffix(lower, unit.suffix) {
+			continue
+		}
+		number, err := strconv.ParseFloat(strings.TrimSuffix(lower, unit.suffix), 64)
+		if err != nil || number <= 0 {
+			break
+		}
+		return uint64(math.Round(number * unit.multiplier)), nil
+	}
+	return 0, fmt.Errorf("invalid rate %q, expected a positive number with a unit like bit, kbit, mbit, gbit or bps", value)
+}
+
+var timeUnits = []struct {
+	suffix string
+	unit   time.Duration
+}{
+	{"usecs", time.Microsecond}, {"usec", time.Microsecond}, {"us", time.Microsecond},
+	{"msecs", time.Millisecond}, {"msec", time.Millisecond}, {"ms", time.Millisecond},
+	{"nsecs", time.Nanosecond}, {"nsec", time.Nanosecond}, {"ns", time.Nanosecond},
+	{"secs", time.Second}, {"sec", time.Second}, {"s", time.Second},
+}
+
+// ParseTime parses a time as printed by tc, e.g. "1.5ms" or "100us".
+func ParseTime(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	for _, unit := range timeUnits {
+		if !strings.HasSuffix(value, unit.suffix) {
+			continue
+		}
+		number, err := strconv.ParseFloat(strings.TrimSuffix(value, unit.suffix), 64)
+		if err != nil {
+			break
+		}
+		return time.Duration(math.Round(number * float64(unit.unit))), nil
+	}
+	return 0, fmt.Errorf("invalid time %q", value)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Round(s * float64(time.Second)))
+}
+
+func formatDuration(d time.Duration) string {
+	if d%time.Millisecond == 0 {
+		return fmt.Sprintf("%dms", d/time.Millisecond)
+	}
+	return fmt.Sprintf("%dus", d/time.Microsecond)
+}
+
+func formatPercent(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64) + "%"
+}
+
+func approximately(a, b, epsilon float64) bool {
+	return math.Abs(a-b) <= epsilon
+}
@@ -0,0 +1,50 @@
+// Package tc builds tc commands and parses the qdiscs reported by tc.
+package tc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Qdisc is a queueing discipline as reported by `tc -j qdisc show`.
+type Qdisc struct {
+	Kind    string          `json:"kind"`
+	Handle  string          `json:"handle"`
+	Parent  string          `json:"parent,omitempty"`
+	Root    bool            `json:"root,omitempty"`
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+// ShowCommand returns the command listing the qdiscs of <device> as JSON.
+func ShowCommand(device string) string {
+	return fmt.Sprintf("tc -j qdisc show dev %s", device)
+}
+
+// DeleteCommand returns the command deleting the root qdisc of <device>.
+func DeleteCommand(device string) string {
+	return fmt.Sprintf("tc qdisc del dev %s root", device)
+}
+
+// ParseQdiscs parses the output of ShowCommand.
+func ParseQdiscs(out string) ([]Qdisc, error) {
+	out = strings.TrimSpace(out)
+	if len(out) == 0 {
+		return nil, nil
+	}
+	var qdiscs []Qdisc
+	if err := json.Unmarshal([]byte(out), &qdiscs); err != nil {
+		return nil, fmt.Errorf("could not parse qdiscs: %v", err)
+	}
+	return qdiscs, nil
+}
+
+// RootQdisc returns the root qdisc of <qdiscs>, nil if there is none.
+func RootQdisc(qdiscs []Qdisc) *Qdisc {
+	for i := range qdiscs {
+		if qdiscs[i].Root || qdiscs[i].Parent == "root" {
+			return &qdiscs[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package tc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	netemHandle     = "10:"
+	bandwidthHandle = "20:"
+)
+
+// Shaping is the traffic shaping of a device: a netem qdisc, a bandwidth limit or both, in which case the
+// bandwidth qdisc is the child of the netem qdisc. With Ingress, the traffic received by the device is
+// redirected to the IFB device and shaped there. With Match, only the matched traffic is classified into a band
+// of a prio root qdisc the shaping qdiscs are attached to.
+type Shaping struct {
+	Netem     *Netem
+	Bandwidth *Bandwidth
+	Ingress   bool
+	IFBDevice string
+	Match     *Match
+}
+
+// State are the qdiscs and classes of a device as reported by the ShowCommand.
+type State struct {
+	Qdiscs  []Qdisc
+	Classes []Class
+}
+
+// String returns the shaping in tc syntax.
+func (s *Shaping) String() string {
+	var qdiscs []string
+	if s.Netem != nil {
+		qdiscs = append(qdiscs, s.Netem.String())
+	}
+	if s.Bandwidth != nil {
+		qdiscs = append(qdiscs, s.Bandwidth.String())
+	}
+	shaping := strings.Join(qdiscs, ", ")
+	if s.Match != nil {
+		shaping += " for " + s.Match.String()
+	}
+	if s.Ingress {
+		shaping += " on ingress via " + s.IFBDevice
+	}
+	return shaping
+}
+
+// Binaries returns the binaries required in the container to apply the shaping.
+func (s *Shaping) Binaries() []string {
+	if s.Ingress {
+		return []string{"tc", "ip"}
+	}
+	return []string{"tc"}
+}
+
+// ShapedDevice returns the device the qdiscs are installed on, the IFB device if ingress traffic is shaped.
+func (s *Shaping) ShapedDevice(device string) string {
+	if s.Ingress {
+		return s.IFBDevice
+	}
+	return device
+}
+
+// ReplaceCommand returns the command replacing the qdiscs of <device> by the shaping.
+func (s *Shaping) ReplaceCommand(device string) string {
+	var commands []string
+	shaped := s.ShapedDevice(device)
+	if s.Ingress {
+		commands = append(commands,
+			fmt.Sprintf("(ip link show dev %[1]s >/dev/null 2>&1 || ip link add %[1]s type ifb)", shaped),
+			fmt.Sprintf("ip link set dev %s up", shaped),
+			fmt.Sprintf("tc qdisc replace dev %s handle ffff: ingress", device),
+			fmt.Sprintf("tc filter replace dev %s parent ffff: protocol all prio 1 handle 800::800 u32 match u32 0 0 action mirred egress redirect dev %s", device, shaped),
+		)
+	}
+
+	switch {
+	case s.Match == nil && s.Bandwidth == nil:
+		commands = append(commands, s.Netem.ReplaceCommand(shaped))
+	case s.Match == nil && s.Netem == nil && s.Bandwidth.Algorithm == TBF:
+		commands = append(commands, s.Bandwidth.commands(shaped, "replace", "", "")...)
+	default:
+		// trees of qdiscs are rebuilt, they can not be replaced if the kind of a qdisc with the same handle changes
+		commands = append(commands, fmt.Sprintf("(tc qdisc del dev %s root 2>/dev/null || true)", shaped))
+		parent := ""
+		if s.Match != nil {
+			commands = append(commands, fmt.Sprintf("tc qdisc add dev %s root handle %s prio bands 4", shaped, prioHandle))
+			parent = matchedBand
+		}
+		if s.Netem != nil {
+			commands = append(commands, fmt.Sprintf("tc qdisc add dev %s %s %s", shaped, qdiscLocation(parent, netemHandle), s.Netem))
+			parent = defaultClass(netemHandle)
+		}
+		if s.Bandwidth != nil {
+			commands = append(commands, s.Bandwidth.commands(shaped, "add", parent, bandwidthHandle)...)
+		}
+		if s.Match != nil {
+			commands = append(commands, s.Match.filterCommands(shaped, s.Ingress)...)
+		}
+	}
+	return strings.Join(commands, " && ")
+}
+
+// DeleteCommand returns the command removing the shaping from <device>. The IFB device of ingress shaping is
+// deleted together with its qdiscs, the command succeeds if it has been removed already.
+func (s *Shaping) DeleteCommand(device string) string {
+	if !s.Ingress {
+		return DeleteCommand(device)
+	}
+	return fmt.Sprintf("if tc qdisc show dev %[1]s ingress | grep -q ingress; then tc qdisc del dev %[1]s ingress; fi && "+
+		"if ip link show dev %[2]s >/dev/null 2>&1; then ip link del dev %[2]s; fi", device, s.IFBDevice)
+}
+
+// ShowCommand returns the command listing the qdiscs, and for htb the classes, of the shaped device as JSON.
+func (s *Shaping) ShowCommand(device string) string {
+	shaped := s.ShapedDevice(device)
+	if s.Bandwidth != nil && s.Bandwidth.Algorithm == HTB {
+		return fmt.Sprintf("%s && tc -j class show dev %s", ShowCommand(shaped), shaped)
+	}
+	return ShowCommand(shaped)
+}
+
+// ParseState parses the output of the ShowCommand.
+func ParseState(out string) (*State, error) {
+	var (
+		state   = &State{}
+		decoder = json.NewDecoder(strings.NewReader(out))
+	)
+	if err := decoder.Decode(&state.Qdiscs); err != nil {
+		if strings.TrimSpace(out) == "" {
+			return state, nil
+		}
+		return nil, fmt.Errorf("could not parse qdiscs: %v", err)
+	}
+	if decoder.More() {
+		if err := decoder.Decode(&state.Classes); err != nil {
+			return nil, fmt.Errorf("could not parse classes: %v", err)
+		}
+	}
+	return state, nil
+}
+
+// Matches returns true if <state> is shaped like <s>. A nil state never matches. The filters of a Match are not
+// compared, changed peers have to be detected by comparing the String of the shaping.
+func (s *Shaping) Matches(state *State) bool {
+	if state == nil {
+		return false
+	}
+	qdisc := RootQdisc(state.Qdiscs)
+	if s.Match != nil {
+		if qdisc == nil || qdisc.Kind != "prio" {
+			return false
+		}
+		qdisc = childQdisc(state.Qdiscs, matchedClass(qdisc))
+	}
+	if s.Netem != nil {
+		if !s.Netem.Matches(qdisc) {
+			return false
+		}
+		if s.Bandwidth == nil {
+			return true
+		}
+		qdisc = childQdisc(state.Qdiscs, defaultClass(qdisc.Handle))
+	}
+	return s.Bandwidth.matches(qdisc, state.Classes)
+}
+
+// Shaped returns true if <state> may have been shaped by <s>, i.e. its root qdisc is of the kind installed by <s>
+// or the state is unknown.
+func (s *Shaping) Shaped(state *State) bool {
+	if state == nil || s.Ingress {
+		return true
+	}
+	root := RootQdisc(state.Qdiscs)
+	if root == nil {
+		return true
+	}
+	switch {
+	case s.Match != nil:
+		return root.Kind == "prio"
+	case s.Netem != nil:
+		return root.Kind == "netem"
+	}
+	return s.Bandwidth != nil && root.Kind == s.Bandwidth.Algorithm
+}
+
+// Installed returns true if the root qdisc of <state> is of a kind installed by any shaping or the state is
+// unknown. It is used to undo shapings whose configuration is no longer known.
+func Installed(state *State) bool {
+	if state == nil {
+		return true
+	}
+	root := RootQdisc(state.Qdiscs)
+	if root == nil {
+		return true
+	}
+	switch root.Kind {
+	case "prio", "netem", TBF, HTB:
+		return true
+	}
+	return false
+}
+
+// AchievedBandwidth returns the bandwidth limit installed in <state>, nil if there is none.
+func (s *Shaping) AchievedBandwidth(state *State) *Bandwidth {
+	if state == nil || s.Bandwidth == nil {
+		return nil
+	}
+	qdisc := RootQdisc(state.Qdiscs)
+	if s.Match != nil && qdisc != nil {
+		qdisc = childQdisc(state.Qdiscs, matchedClass(qdisc))
+	}
+	if s.Netem != nil && qdisc != nil {
+		qdisc = childQdisc(state.Qdiscs, defaultClass(qdisc.Handle))
+	}
+	return achievedBandwidth(qdisc, state.Classes)
+}
+
+// matchedClass returns the class of the <prio> qdisc the matched traffic is sent to.
+func matchedClass(prio *Qdisc) string {
+	return strings.TrimSuffix(prio.Handle, ":") + ":4"
+}
+
+// childQdisc returns the qdisc attached to the class with the <parent> handle.
+func childQdisc(qdiscs []Qdisc, parent string) *Qdisc {
+	for i := range qdiscs {
+		if qdiscs[i].Parent == parent {
+			return &qdiscs[i]
+		}
+	}
+	return nil
+}
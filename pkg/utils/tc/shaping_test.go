@@ -0,0 +1,187 @@
+package tc
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	tbfQdiscs      = `[{"kind":"tbf","handle":"8001:","root":true,"refcnt":2,"options":{"rate":1250000,"burst":12499,"lat":50000}}]`
+	netemTBFQdiscs = `[{"kind":"netem","handle":"10:","root":true,"refcnt":2,"options":{"limit":1000,"delay":{"delay":0.1,"jitter":0,"correlation":0},"ecn":false,"gap":0}},
+{"kind":"tbf","handle":"20:","parent":"10:1","options":{"rate":1250000,"burst":12500,"lat":50000}}]`
+	htbState = `[{"kind":"htb","handle":"20:","root":true,"refcnt":2,"options":{"r2q":10,"default":"0x1","direct_packets_stat":0}}]
+[{"class":"htb","handle":"20:1","root":true,"prio":0,"rate":1250000,"ceil":2500000,"burst":1600,"cburst":1600}]`
+	matchedQdiscs = `[{"kind":"prio","handle":"1:","root":true,"refcnt":2,"options":{"bands":4,"priomap":[1,2,2,2,1,2,0,0,1,1,1,1,1,1,1,1],"multiqueue":false}},
+{"kind":"netem","handle":"10:","parent":"1:4","options":{"limit":1000,"delay":{"delay":0.1,"jitter":0,"correlation":0},"ecn":false,"gap":0}},
+{"kind":"tbf","handle":"20:","parent":"10:1","options":{"rate":1250000,"burst":12500,"lat":50000}}]`
+)
+
+func TestShapingCommands(t *testing.T) {
+	var (
+		tbf   = &Bandwidth{Algorithm: TBF, Rate: 10000000, Burst: 12500, Latency: 50 * time.Millisecond}
+		htb   = &Bandwidth{Algorithm: HTB, Rate: 10000000, Ceil: 20000000}
+		netem = &Netem{Delay: 100 * time.Millisecond}
+	)
+
+	tests := []struct {
+		shaping  Shaping
+		replace  string
+		delete   string
+		show     string
+		describe string
+	}{
+		{
+			shaping:  Shaping{Bandwidth: tbf},
+			replace:  "tc qdisc replace dev eth0 root tbf rate 10000000bit burst 12500 latency 50ms",
+			delete:   "tc qdisc del dev eth0 root",
+			show:     "tc -j qdisc show dev eth0",
+			describe: "tbf rate 10mbit burst 12500b latency 50ms",
+		},
+		{
+			shaping: Shaping{Bandwidth: htb},
+			replace: "(tc qdisc del dev eth0 root 2>/dev/null || true) && " +
+				"tc qdisc add dev eth0 root handle 20: htb default 1 && " +
+				"tc class add dev eth0 parent 20: classid 20:1 htb rate 10000000bit ceil 20000000bit",
+			delete:   "tc qdisc del dev eth0 root",
+			show:     "tc -j qdisc show dev eth0 && tc -j class show dev eth0",
+			describe: "htb rate 10mbit ceil 20mbit",
+		},
+		{
+			shaping: Shaping{Netem: netem, Bandwidth: tbf},
+			replace: "(tc qdisc del dev eth0 root 2>/dev/null || true) && " +
+				"tc qdisc add dev eth0 root handle 10: netem delay 100ms && " +
+				"tc qdisc add dev eth0 parent 10:1 handle 20: tbf rate 10000000bit burst 12500 latency 50ms",
+			delete:   "tc qdisc del dev eth0 root",
+			show:     "tc -j qdisc show dev eth0",
+			describe: "netem delay 100ms, tbf rate 10mbit burst 12500b latency 50ms",
+		},
+		{
+			shaping: Shaping{Bandwidth: tbf, Ingress: true, IFBDevice: "ifb-eth0"},
+			replace: "(ip link show dev ifb-eth0 >/dev/null 2>&1 || ip link add ifb-eth0 type ifb) && " +
+				"ip link set dev ifb-eth0 up && " +
+				"tc qdisc replace dev eth0 handle ffff: ingress && " +
+				"tc filter replace dev eth0 parent ffff: protocol all prio 1 handle 800::800 u32 match u32 0 0 action mirred egress redirect dev ifb-eth0 && " +
+				"tc qdisc replace dev ifb-eth0 root tbf rate 10000000bit burst 12500 latency 50ms",
+			delete: "if tc qdisc show dev eth0 ingress | grep -q ingress; then tc qdisc del dev eth0 ingress; fi && " +
+				"if ip link show dev ifb-eth0 >/dev/null 2>&1; then ip link del dev ifb-eth0; fi",
+			show:     "tc -j qdisc show dev ifb-eth0",
+			describe: "tbf rate 10mbit burst 12500b latency 50ms on ingress via ifb-eth0",
+		},
+	}
+	for _, tt := range tests {
+		if replace := tt.shaping.ReplaceCommand("eth0"); replace != tt.replace {
+			t.Errorf("expected replace command %q, got %q", tt.replace, replace)
+		}
+		if deleteCommand := tt.shaping.DeleteCommand("eth0"); deleteCommand != tt.delete {
+			t.Errorf("expected delete command %q, got %q", tt.delete, deleteCommand)
+		}
+		if show := tt.shaping.ShowCommand("eth0"); show != tt.show {
+			t.Errorf("expected show command %q, got %q", tt.show, show)
+		}
+		if describe := tt.shaping.String(); describe != tt.describe {
+			t.Errorf("expected shaping %q, got %q", tt.describe, describe)
+		}
+	}
+}
+
+func TestShapingMatches(t *testing.T) {
+	var (
+		tbf   = &Bandwidth{Algorithm: TBF, Rate: 10000000, Burst: 12500, Latency: 50 * time.Millisecond}
+		htb   = &Bandwidth{Algorithm: HTB, Rate: 10000000, Ceil: 20000000}
+		netem = &Netem{Delay: 100 * time.Millisecond}
+	)
+
+	tests := []struct {
+		name     string
+		shaping  Shaping
+		state    string
+		matches  bool
+		achieved *Bandwidth
+	}{
+		{name: "tbf", shaping: Shaping{Bandwidth: tbf}, state: tbfQdiscs, matches: true, achieved: &Bandwidth{Algorithm: TBF, Rate: 10000000, Burst: 12499}},
+		{name: "tbf with other rate", shaping: Shaping{Bandwidth: &Bandwidth{Algorithm: TBF, Rate: 20000000, Burst: 25000}}, state: tbfQdiscs},
+		{name: "tbf instead of htb", shaping: Shaping{Bandwidth: htb}, state: tbfQdiscs},
+		{name: "htb", shaping: Shaping{Bandwidth: htb}, state: htbState, matches: true, achieved: &Bandwidth{Algorithm: HTB, Rate: 10000000, Ceil: 20000000, Burst: 1600}},
+		{name: "netem and tbf", shaping: Shaping{Netem: netem, Bandwidth: tbf}, state: netemTBFQdiscs, matches: true, achieved: &Bandwidth{Algorithm: TBF, Rate: 10000000, Burst: 12500}},
+		{name: "netem without tbf", shaping: Shaping{Netem: netem, Bandwidth: tbf}, state: qdiscs},
+		{name: "matched", shaping: Shaping{Netem: netem, Bandwidth: tbf, Match: &Match{AllPeers: true, Ports: []uint16{80}}}, state: matchedQdiscs, matches: true, achieved: &Bandwidth{Algorithm: TBF, Rate: 10000000, Burst: 12500}},
+		{name: "unmatched", shaping: Shaping{Netem: netem, Bandwidth: tbf}, state: matchedQdiscs},
+		{name: "matched without prio", shaping: Shaping{Netem: netem, Bandwidth: tbf, Match: &Match{AllPeers: true, Ports: []uint16{80}}}, state: netemTBFQdiscs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := ParseState(tt.state)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matches := tt.shaping.Matches(state); matches != tt.matches {
+				t.Errorf("expected matches to be %t", tt.matches)
+			}
+			achieved := tt.shaping.AchievedBandwidth(state)
+			if tt.achieved != nil && (achieved == nil || *achieved != *tt.achieved) {
+				t.Errorf("expected achieved bandwidth %+v, got %+v", tt.achieved, achieved)
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	sizes := map[string]uint32{"1500": 1500, "32kb": 32768, "1mb": 1048576, "8kbit": 1024, "100b": 100}
+	for value, expected := range sizes {
+		if size, err := ParseSize(value); err != nil || size != expected {
+			t.Errorf("expected size %q to be %d, got %d, %v", value, expected, size, err)
+		}
+	}
+	for _, value := range []string{"", "-1kb", "big"} {
+		if _, err := ParseSize(value); err == nil {
+			t.Errorf("expected size %q to be rejected", value)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	var peers []*net.IPNet
+	for _, value := range []string{"10.0.0.5", "fd00::/64", "10.0.0.5/32"} {
+		peer, err := ParsePeer(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		peers = append(peers, peer)
+	}
+	if _, err := ParsePeer("10.0.0.300"); err == nil {
+		t.Error("expected invalid IP to be rejected")
+	}
+
+	shaping := Shaping{Netem: &Netem{Delay: 200 * time.Millisecond}, Match: &Match{Peers: peers, Ports: []uint16{53}, Protocols: []string{"udp"}}}
+	expected := "(tc qdisc del dev eth0 root 2>/dev/null || true) && " +
+		"tc qdisc add dev eth0 root handle 1: prio bands 4 && " +
+		"tc qdisc add dev eth0 parent 1:4 handle 10: netem delay 200ms && " +
+		"tc filter add dev eth0 parent 1: protocol ip prio 1 u32 match ip dst 10.0.0.5/32 match ip protocol 17 0xff match ip dport 53 0xffff flowid 1:4 && " +
+		"tc filter add dev eth0 parent 1: protocol ipv6 prio 2 u32 match ip6 dst fd00::/64 match ip6 protocol 17 0xff match ip6 dport 53 0xffff flowid 1:4"
+	if replace := shaping.ReplaceCommand("eth0"); replace != expected {
+		t.Errorf("expected replace command %q, got %q", expected, replace)
+	}
+	if describe := shaping.String(); describe != "netem delay 200ms for peers 10.0.0.5/32 fd00::/64 ports 53 protocols udp" {
+		t.Errorf("unexpected shaping %q", describe)
+	}
+
+	ingress := Match{AllPeers: true, Ports: []uint16{443}}
+	expectedFilters := []string{
+		"tc filter add dev ifb0 parent 1: protocol ip prio 1 u32 match ip protocol 6 0xff match ip sport 443 0xffff flowid 1:4",
+		"tc filter add dev ifb0 parent 1: protocol ip prio 1 u32 match ip protocol 17 0xff match ip sport 443 0xffff flowid 1:4",
+		"tc filter add dev ifb0 parent 1: protocol ipv6 prio 2 u32 match ip6 protocol 6 0xff match ip6 sport 443 0xffff flowid 1:4",
+		"tc filter add dev ifb0 parent 1: protocol ipv6 prio 2 u32 match ip6 protocol 17 0xff match ip6 sport 443 0xffff flowid 1:4",
+	}
+	if filters := ingress.filterCommands("ifb0", true); !reflect.DeepEqual(filters, expectedFilters) {
+		t.Errorf("expected filters %v, got %v", expectedFilters, filters)
+	}
+
+	if describe := (&Match{}).String(); describe != "peers none" {
+		t.Errorf("expected unresolved peers to match nothing, got %q", describe)
+	}
+	if filters := (&Match{}).filterCommands("eth0", false); len(filters) != 0 {
+		t.Errorf("expected no filters without peers, got %v", filters)
+	}
+}
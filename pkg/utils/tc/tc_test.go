@@ -0,0 +1,118 @@
+package tc
+
+import (
+	"testing"
+	"time"
+)
+
+const qdiscs = `[{"kind":"netem","handle":"8001:","root":true,"refcnt":2,"options":{"limit":1000,"delay":{"delay":0.2,"jitter":0.01,"correlation":0},"loss-random":{"loss":0.1,"correlation":0},"ecn":false,"gap":0}},
+{"kind":"pfifo","handle":"8002:","parent":"8001:1","refcnt":1,"options":{"limit":1000}}]`
+
+func TestRootQdisc(t *testing.T) {
+	parsed, err := ParseQdiscs(qdiscs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := RootQdisc(parsed)
+	if root == nil || root.Kind != "netem" || root.Handle != "8001:" {
+		t.Fatalf("unexpected root qdisc %+v", root)
+	}
+
+	if empty, err := ParseQdiscs(""); err != nil || RootQdisc(empty) != nil {
+		t.Errorf("expected no root qdisc for empty output, got %v, %v", empty, err)
+	}
+	if _, err := ParseQdiscs("qdisc noqueue 0: root refcnt 2"); err == nil {
+		t.Error("expected non JSON output to be rejected")
+	}
+}
+
+func TestNetem(t *testing.T) {
+	parsed, err := ParseQdiscs(qdiscs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := RootQdisc(parsed)
+
+	tests := []struct {
+		netem   Netem
+		args    string
+		matches bool
+	}{
+		{netem: Netem{Delay: 200 * time.Millisecond, Jitter: 10 * time.Millisecond, Loss: 10}, args: "delay 200ms 10ms loss 10%", matches: true},
+		{netem: Netem{Delay: 200 * time.Millisecond, Jitter: 10 * time.Millisecond}, args: "delay 200ms 10ms"},
+		{netem: Netem{Delay: 100 * time.Millisecond, Jitter: 10 * time.Millisecond, Loss: 10}, args: "delay 100ms 10ms loss 10%"},
+		{netem: Netem{Delay: 1500 * time.Microsecond, Loss: 0.5}, args: "delay 1500us loss 0.5%"},
+	}
+	for _, tt := range tests {
+		if args := tt.netem.Args(); args != tt.args {
+			t.Errorf("expected args %q, got %q", tt.args, args)
+		}
+		if matches := tt.netem.Matches(root); matches != tt.matches {
+			t.Errorf("expected %q to match %t", tt.args, tt.matches)
+		}
+	}
+
+	if (&Netem{Delay: time.Second}).Matches(&Qdisc{Kind: "noqueue", Root: true}) {
+		t.Error("expected netem not to match noqueue")
+	}
+	if command := (&Netem{Loss: 100}).ReplaceCommand("eth0"); command != "tc qdisc replace dev eth0 root netem loss 100%" {
+		t.Errorf("unexpected command %q", command)
+	}
+}
+
+const combined = `[{"kind":"netem","handle":"8001:","root":true,"refcnt":2,"options":{"limit":1000,"delay":{"delay":0.1,"jitter":0.02,"correlation":0.25},"loss-ge":{"p":0.05,"r":0.95,"1-h":1,"1-k":0},"duplicate":{"duplicate":0.01,"correlation":0},"reorder":{"reorder":0.25,"correlation":0.5},"corrupt":{"corrupt":0.001,"correlation":0},"rate":{"rate":1250000,"packetoverhead":0,"cellsize":0,"celloverhead":0},"slot":{"min-delay":"1ms","max-delay":"5ms","packets":10,"bytes":0},"ecn":false,"gap":5}}]`
+
+func TestCombinedNetem(t *testing.T) {
+	parsed, err := ParseQdiscs(combined)
+	if err != nil {
+		t.Fatal(err)
+	}
+	netem := &Netem{
+		Delay:              100 * time.Millisecond,
+		Jitter:             20 * time.Millisecond,
+		DelayCorrelation:   25,
+		Distribution:       "normal",
+		GilbertElliott:     &GilbertElliott{P: 5, R: 95, OneMinusH: 100, OneMinusK: 0},
+		Duplicate:          1,
+		Corrupt:            0.1,
+		Reorder:            25,
+		ReorderCorrelation: 50,
+		Gap:                5,
+		Rate:               10000000,
+		Slot:               &Slot{MinDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, Packets: 10},
+	}
+
+	expected := "delay 100ms 20ms 25% distribution normal loss gemodel 5% 95% 100% 0% duplicate 1% corrupt 0.1% reorder 25% 50% gap 5 rate 10000000bit slot 1ms 5ms packets 10"
+	if args := netem.Args(); args != expected {
+		t.Errorf("expected args %q, got %q", expected, args)
+	}
+	if !netem.Matches(RootQdisc(parsed)) {
+		t.Error("expected combined netem to match")
+	}
+
+	netem.Gap = 3
+	if netem.Matches(RootQdisc(parsed)) {
+		t.Error("expected netem with different gap not to match")
+	}
+}
+
+func TestParseRateAndTime(t *testing.T) {
+	rates := map[string]uint64{"10mbit": 10000000, "1kbps": 8000, "1mibit": 1048576, "512bit": 512}
+	for value, expected := range rates {
+		if rate, err := ParseRate(value); err != nil || rate != expected {
+			t.Errorf("expected rate %q to be %d, got %d, %v", value, expected, rate, err)
+		}
+	}
+	for _, value := range []string{"10", "-1mbit", "fast"} {
+		if _, err := ParseRate(value); err == nil {
+			t.Errorf("expected rate %q to be rejected", value)
+		}
+	}
+
+	times := map[string]time.Duration{"1.5ms": 1500 * time.Microsecond, "100us": 100 * time.Microsecond, "2s": 2 * time.Second}
+	for value, expected := range times {
+		if d, err := ParseTime(value); err != nil || d != expected {
+			t.Errorf("expected time %q to be %v, got %v, %v", value, expected, d, err)
+		}
+	}
+}
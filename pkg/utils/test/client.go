@@ -0,0 +1,62 @@
+// Package test contains helpers for hermetic controller tests.
+package test
+
+import (
+	"context"
+	"sync"
+
+	"github.com/networkmachinery/networkmachinery-operators/pkg/apis/networkmachinery/install"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var installOnce sync.Once
+
+// Scheme returns the scheme used by the fake clients, it contains the kubernetes and the networkmachinery types.
+// The fake client decodes objects with the global client-go scheme, hence the networkmachinery types are
+// installed there.
+func Scheme() *runtime.Scheme {
+	installOnce.Do(func() {
+		install.Install(scheme.Scheme)
+	})
+	return scheme.Scheme
+}
+
+// NewFakeClient returns a fake client serving <objects>. Access reviews are allowed unless Denied is set.
+func NewFakeClient(objects ...runtime.Object) *Client {
+	return &Client{
+		Client: fake.NewFakeClientWithScheme(Scheme(), objects...),
+	}
+}
+
+// Client is a fake client which answers SelfSubjectAccessReviews and SubjectAccessReviews the way the API server
+// would.
+type Client struct {
+	client.Client
+	// Denied makes all SelfSubjectAccessReviews fail with the given reason.
+	Denied string
+	// DeniedUsers makes the SubjectAccessReviews of the given users fail with the mapped reason.
+	DeniedUsers map[string]string
+	// Reviews are the specs of all SubjectAccessReviews created.
+	Reviews []authorizationv1.SubjectAccessReviewSpec
+}
+
+// Create implements client.Client.
+func (c *Client) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if review, ok := obj.(*authorizationv1.SelfSubjectAccessReview); ok {
+		review.Status.Allowed = len(c.Denied) == 0
+		review.Status.Reason = c.Denied
+		return nil
+	}
+	if review, ok := obj.(*authorizationv1.SubjectAccessReview); ok {
+		c.Reviews = append(c.Reviews, review.Spec)
+		reason, denied := c.DeniedUsers[review.Spec.User]
+		review.Status.Allowed = !denied
+		review.Status.Reason = reason
+		return nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}